@@ -2,4 +2,20 @@ package config
 
 type Cron struct {
 	MonthlyFee string `yaml:"monthlyFee"`
+	// LowStock is the crontab (minute precision, Asia/Jakarta) of
+	// the low-stock check; empty disables it.
+	LowStock string      `yaml:"lowStock"`
+	Alert    AlertConfig `yaml:"alert"`
+	// StaleSession is the crontab of the stale-session job; empty
+	// disables it. A session is stale once StaleSessionCutoff
+	// (HH:MM, Asia/Jakarta, default 23:59) of its date has passed.
+	StaleSession       string `yaml:"staleSession"`
+	StaleSessionCutoff string `yaml:"staleSessionCutoff"`
+}
+
+// AlertConfig selects how scheduled jobs send their alerts.
+// Notifier is "log" (the default) or "brevo"; Brevo mails go from
+// Mail.Brevo.Sender to the admins of the organization concerned.
+type AlertConfig struct {
+	Notifier string `yaml:"notifier"`
 }
@@ -0,0 +1,17 @@
+-- ============================================================
+-- 000016: default chart-of-accounts seed (down)
+-- ============================================================
+DROP INDEX IF EXISTS idx_account_mutation_account_date;
+DROP INDEX IF EXISTS idx_account_mutation_ref;
+
+DELETE FROM account
+WHERE id IN (
+    'acc-1101-kas',
+    'acc-1102-kliring-qris',
+    'acc-1103-bank',
+    'acc-1201-piutang-driver',
+    'acc-1202-piutang-kas-bon',
+    'acc-2101-hutang-komisi',
+    'acc-4101-penjualan',
+    'acc-6101-beban-komisi'
+);
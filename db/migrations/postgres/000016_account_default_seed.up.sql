@@ -0,0 +1,34 @@
+-- ============================================================
+-- 000016: default chart-of-accounts seed
+-- ============================================================
+-- Global (NULL organization_id) accounts that the stock-session
+-- close posts against. Upstream flows resolve accounts by `code`,
+-- preferring an org-owned row over these seeds, so an organization
+-- can rename or replace any default by creating its own row with
+-- the same code.
+--
+-- Closing a session posts one balanced entry:
+--
+--   Dr 1101 Kas                 total_cash
+--   Dr 1102 Kliring QRIS        total_qris
+--   Dr 1103 Bank                total_other
+--   Dr 1202 Piutang Kas Bon     cash_debt
+--   Dr 1201 Piutang Driver      till shortage (credit on overage)
+--       Cr 4101 Penjualan       total_sales
+--   Dr 6101 Beban Komisi        total_commission
+--       Cr 2101 Hutang Komisi   total_commission
+
+INSERT INTO account (id, organization_id, name, code, created_at)
+VALUES
+    ('acc-1101-kas',             NULL, 'Kas',             '1101', NOW()),
+    ('acc-1102-kliring-qris',    NULL, 'Kliring QRIS',    '1102', NOW()),
+    ('acc-1103-bank',            NULL, 'Bank',            '1103', NOW()),
+    ('acc-1201-piutang-driver',  NULL, 'Piutang Driver',  '1201', NOW()),
+    ('acc-1202-piutang-kas-bon', NULL, 'Piutang Kas Bon', '1202', NOW()),
+    ('acc-2101-hutang-komisi',   NULL, 'Hutang Komisi',   '2101', NOW()),
+    ('acc-4101-penjualan',       NULL, 'Penjualan',       '4101', NOW()),
+    ('acc-6101-beban-komisi',    NULL, 'Beban Komisi',    '6101', NOW())
+ON CONFLICT (id) DO NOTHING;
+
+CREATE INDEX IF NOT EXISTS idx_account_mutation_ref ON account_mutation(ref_table, ref_id);
+CREATE INDEX IF NOT EXISTS idx_account_mutation_account_date ON account_mutation(account_id, created_at);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_posting_rule_org_event;
+DROP TABLE IF EXISTS posting_rule;
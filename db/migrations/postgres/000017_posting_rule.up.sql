@@ -0,0 +1,33 @@
+-- ============================================================
+-- 000017: posting rules
+-- ============================================================
+-- Maps a business event amount (event_type + amount_field) onto a
+-- debit / credit account code pair. Global (NULL organization_id)
+-- rows are the defaults; an organization overrides one by creating
+-- its own row for the same event_type + amount_field.
+
+CREATE TABLE IF NOT EXISTS posting_rule (
+    id                  varchar(255) PRIMARY KEY,
+    organization_id     varchar(255),
+    event_type          varchar(64)  NOT NULL,
+    amount_field        varchar(64)  NOT NULL,
+    debit_account_code  varchar(64)  NOT NULL,
+    credit_account_code varchar(64)  NOT NULL,
+    description         text         NULL,
+    created_at          TIMESTAMP    NOT NULL,
+    updated_at          TIMESTAMP    NULL,
+    deleted_at          TIMESTAMP    NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_posting_rule_org_event ON posting_rule(organization_id, event_type);
+
+-- Defaults reproduce the stock-session close entry seeded in 000016.
+INSERT INTO posting_rule (id, organization_id, event_type, amount_field, debit_account_code, credit_account_code, description, created_at)
+VALUES
+    ('pr-ssc-total-cash',       NULL, 'STOCK_SESSION_CLOSED', 'TOTAL_CASH',       '1101', '4101', 'Cash sales',             NOW()),
+    ('pr-ssc-total-qris',       NULL, 'STOCK_SESSION_CLOSED', 'TOTAL_QRIS',       '1102', '4101', 'QRIS sales',             NOW()),
+    ('pr-ssc-total-other',      NULL, 'STOCK_SESSION_CLOSED', 'TOTAL_OTHER',      '1103', '4101', 'Transfer / other sales', NOW()),
+    ('pr-ssc-cash-debt',        NULL, 'STOCK_SESSION_CLOSED', 'CASH_DEBT',        '1202', '4101', 'Cash advance (kas bon)', NOW()),
+    ('pr-ssc-driver-shortage',  NULL, 'STOCK_SESSION_CLOSED', 'DRIVER_SHORTAGE',  '1201', '4101', 'Driver till shortage',   NOW()),
+    ('pr-ssc-total-commission', NULL, 'STOCK_SESSION_CLOSED', 'TOTAL_COMMISSION', '6101', '2101', 'Driver commission',      NOW())
+ON CONFLICT (id) DO NOTHING;
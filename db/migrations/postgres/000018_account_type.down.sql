@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_account_parent;
+
+ALTER TABLE account
+    DROP COLUMN IF EXISTS parent_id,
+    DROP COLUMN IF EXISTS type;
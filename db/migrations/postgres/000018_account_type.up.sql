@@ -0,0 +1,26 @@
+-- ============================================================
+-- 000018: account type + hierarchy
+-- ============================================================
+-- `type` tells reports how to read the signed
+-- account_mutation.amount: ASSET / EXPENSE grow on the debit side,
+-- LIABILITY / EQUITY / REVENUE on the credit side. `parent_id`
+-- groups accounts under a header account of the same type so
+-- reports can roll balances up.
+--
+-- Existing rows are typed from the leading digit of their code,
+-- which is how the default chart (000016) is numbered:
+-- 1 asset, 2 liability, 3 equity, 4 revenue, 5+ expense.
+
+ALTER TABLE account
+    ADD COLUMN IF NOT EXISTS type      varchar(32)  NOT NULL DEFAULT 'ASSET',
+    ADD COLUMN IF NOT EXISTS parent_id varchar(255) NULL;
+
+UPDATE account SET type = CASE LEFT(code, 1)
+    WHEN '1' THEN 'ASSET'
+    WHEN '2' THEN 'LIABILITY'
+    WHEN '3' THEN 'EQUITY'
+    WHEN '4' THEN 'REVENUE'
+    ELSE 'EXPENSE'
+END;
+
+CREATE INDEX IF NOT EXISTS idx_account_parent ON account(parent_id);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_account_mutation_journal_entry;
+ALTER TABLE account_mutation DROP COLUMN IF EXISTS journal_entry_id;
+
+DROP INDEX IF EXISTS idx_journal_entry_ref;
+DROP INDEX IF EXISTS idx_journal_entry_org_date;
+DROP TABLE IF EXISTS journal_entry;
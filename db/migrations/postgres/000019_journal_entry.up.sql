@@ -0,0 +1,35 @@
+-- ============================================================
+-- 000019: journal entries
+-- ============================================================
+-- journal_entry is the header of one balanced posting; its lines
+-- are the account_mutation rows carrying its id. The service layer
+-- rejects entries whose lines don't sum to zero, and entries are
+-- never edited: a reversal writes a mirror entry linked through
+-- reversal_of_id / reversed_by_id.
+--
+-- Mutations posted before this migration have no header and keep
+-- journal_entry_id NULL.
+
+CREATE TABLE IF NOT EXISTS journal_entry (
+    id              varchar(255) PRIMARY KEY,
+    organization_id varchar(255),
+    description     text         NULL,
+    source          varchar(64)  NOT NULL,
+    ref_id          varchar(255) NULL,
+    ref_table       varchar(64)  NULL,
+    ref_module      varchar(64)  NULL,
+    reversal_of_id  varchar(255) NULL,
+    reversed_by_id  varchar(255) NULL,
+    created_by      varchar(255) NULL,
+    created_at      TIMESTAMP    NOT NULL,
+    updated_at      TIMESTAMP    NULL,
+    deleted_at      TIMESTAMP    NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_journal_entry_org_date ON journal_entry(organization_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_journal_entry_ref ON journal_entry(ref_table, ref_id);
+
+ALTER TABLE account_mutation
+    ADD COLUMN IF NOT EXISTS journal_entry_id varchar(255) NULL;
+
+CREATE INDEX IF NOT EXISTS idx_account_mutation_journal_entry ON account_mutation(journal_entry_id);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_accounting_period_log_org_period;
+DROP TABLE IF EXISTS accounting_period_log;
+
+DROP INDEX IF EXISTS idx_accounting_period_org_period;
+DROP TABLE IF EXISTS accounting_period;
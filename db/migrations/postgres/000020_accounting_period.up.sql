@@ -0,0 +1,43 @@
+-- ============================================================
+-- 000020: accounting periods
+-- ============================================================
+-- One row per (organization, month) that has ever been closed;
+-- a month without a row is OPEN. While CLOSED, the stock session,
+-- cash debt, payroll and ledger write paths refuse anything dated
+-- inside the month.
+--
+-- accounting_period_log is the append-only audit trail of close /
+-- reopen actions (reopen is admin-only and requires a reason).
+
+CREATE TABLE IF NOT EXISTS accounting_period (
+    id              varchar(255) PRIMARY KEY,
+    organization_id varchar(255) NOT NULL,
+    period          varchar(7)   NOT NULL, -- YYYY-MM
+    status          varchar(16)  NOT NULL DEFAULT 'OPEN',
+    closed_at       TIMESTAMP    NULL,
+    closed_by       varchar(255) NULL,
+    reopened_at     TIMESTAMP    NULL,
+    reopened_by     varchar(255) NULL,
+    created_at      TIMESTAMP    NOT NULL,
+    updated_at      TIMESTAMP    NULL,
+    deleted_at      TIMESTAMP    NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_accounting_period_org_period
+    ON accounting_period(organization_id, period)
+    WHERE deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS accounting_period_log (
+    id                   varchar(255) PRIMARY KEY,
+    organization_id      varchar(255) NOT NULL,
+    accounting_period_id varchar(255) NOT NULL REFERENCES accounting_period(id),
+    period               varchar(7)   NOT NULL,
+    action               varchar(16)  NOT NULL, -- CLOSE | REOPEN
+    actor_id             varchar(255) NULL,
+    reason               text         NULL,
+    created_at           TIMESTAMP    NOT NULL,
+    updated_at           TIMESTAMP    NULL,
+    deleted_at           TIMESTAMP    NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_accounting_period_log_org_period ON accounting_period_log(organization_id, period);
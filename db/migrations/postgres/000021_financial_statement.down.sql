@@ -0,0 +1,6 @@
+DELETE FROM posting_rule WHERE id = 'pr-ssc-total-cogs';
+
+DELETE FROM account WHERE id IN ('acc-1301-persediaan', 'acc-5101-hpp');
+
+ALTER TABLE account
+    DROP COLUMN IF EXISTS is_cash;
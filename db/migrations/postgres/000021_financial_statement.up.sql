@@ -0,0 +1,30 @@
+-- ============================================================
+-- 000021: financial statements (profit & loss, cash flow)
+-- ============================================================
+-- `is_cash` marks the accounts whose movements the cash flow
+-- statement reports (Kas, Kliring QRIS, Bank by default). The
+-- statement walks every posting that touches one of them and
+-- classifies the cash by the account on the other side.
+--
+-- The profit & loss needs a cost of goods sold line, so the
+-- stock-session close also books the cost of the units sold:
+--
+--   Dr 5101 Harga Pokok Penjualan   total_cogs
+--       Cr 1301 Persediaan          total_cogs
+
+ALTER TABLE account
+    ADD COLUMN IF NOT EXISTS is_cash boolean NOT NULL DEFAULT false;
+
+UPDATE account SET is_cash = true
+WHERE id IN ('acc-1101-kas', 'acc-1102-kliring-qris', 'acc-1103-bank');
+
+INSERT INTO account (id, organization_id, name, code, type, created_at)
+VALUES
+    ('acc-1301-persediaan', NULL, 'Persediaan',            '1301', 'ASSET',   NOW()),
+    ('acc-5101-hpp',        NULL, 'Harga Pokok Penjualan', '5101', 'EXPENSE', NOW())
+ON CONFLICT (id) DO NOTHING;
+
+INSERT INTO posting_rule (id, organization_id, event_type, amount_field, debit_account_code, credit_account_code, description, created_at)
+VALUES
+    ('pr-ssc-total-cogs', NULL, 'STOCK_SESSION_CLOSED', 'TOTAL_COGS', '5101', '1301', 'Cost of goods sold', NOW())
+ON CONFLICT (id) DO NOTHING;
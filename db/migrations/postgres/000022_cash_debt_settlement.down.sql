@@ -0,0 +1,9 @@
+DELETE FROM posting_rule WHERE id IN ('pr-cds-cash', 'pr-cds-payroll', 'pr-cds-till-offset');
+
+DELETE FROM account WHERE id = 'acc-2102-hutang-gaji';
+
+DROP TABLE IF EXISTS cash_debt_settlement;
+
+ALTER TABLE cash_debt
+    DROP COLUMN IF EXISTS settled_at,
+    DROP COLUMN IF EXISTS settled_amount;
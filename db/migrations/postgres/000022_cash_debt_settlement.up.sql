@@ -0,0 +1,51 @@
+-- ============================================================
+-- 000022: cash debt settlement
+-- ============================================================
+-- A driver's cash advance (cash_debt) is repaid in one or more
+-- cash_debt_settlement rows: in cash, by payroll deduction or by
+-- offsetting a till overage. cash_debt.settled_amount is the
+-- running sum of its settlements, settled_at is set once nothing
+-- is left to repay.
+--
+-- Each repayment posts CASH_DEBT_SETTLED:
+--
+--   Dr 1101 Kas               settled_cash
+--   Dr 2102 Hutang Gaji       settled_payroll
+--   Dr 1201 Piutang Driver    settled_till_offset
+--       Cr 1202 Piutang Kas Bon
+
+ALTER TABLE cash_debt
+    ADD COLUMN IF NOT EXISTS settled_amount numeric(20,4) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS settled_at     TIMESTAMP     NULL;
+
+CREATE TABLE IF NOT EXISTS cash_debt_settlement (
+    id                varchar(255)  PRIMARY KEY,
+    organization_id   varchar(255)  NOT NULL,
+    cash_debt_id      varchar(255)  NOT NULL REFERENCES cash_debt(id),
+    admin_id_employee varchar(255)  NOT NULL,
+    date              date          NOT NULL,
+    amount            numeric(20,4) NOT NULL,
+    method            varchar(32)   NOT NULL, -- CASH | PAYROLL | TILL_OFFSET
+    ref_id            varchar(255)  NULL,
+    ref_table         varchar(64)   NULL,
+    notes             text          NULL,
+    created_by        varchar(255)  NULL,
+    created_at        TIMESTAMP     NOT NULL,
+    updated_at        TIMESTAMP     NULL,
+    deleted_at        TIMESTAMP     NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_cash_debt_settlement_debt ON cash_debt_settlement(cash_debt_id);
+CREATE INDEX IF NOT EXISTS idx_cash_debt_settlement_employee ON cash_debt_settlement(organization_id, admin_id_employee);
+
+INSERT INTO account (id, organization_id, name, code, type, created_at)
+VALUES
+    ('acc-2102-hutang-gaji', NULL, 'Hutang Gaji', '2102', 'LIABILITY', NOW())
+ON CONFLICT (id) DO NOTHING;
+
+INSERT INTO posting_rule (id, organization_id, event_type, amount_field, debit_account_code, credit_account_code, description, created_at)
+VALUES
+    ('pr-cds-cash',        NULL, 'CASH_DEBT_SETTLED', 'SETTLED_CASH',        '1101', '1202', 'Cash debt repaid in cash',         NOW()),
+    ('pr-cds-payroll',     NULL, 'CASH_DEBT_SETTLED', 'SETTLED_PAYROLL',     '2102', '1202', 'Cash debt deducted from payroll',  NOW()),
+    ('pr-cds-till-offset', NULL, 'CASH_DEBT_SETTLED', 'SETTLED_TILL_OFFSET', '1201', '1202', 'Cash debt offset against the till', NOW())
+ON CONFLICT (id) DO NOTHING;
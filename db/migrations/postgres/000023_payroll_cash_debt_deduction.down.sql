@@ -0,0 +1,2 @@
+ALTER TABLE employee_salary
+    DROP COLUMN IF EXISTS total_cash_debt;
@@ -0,0 +1,12 @@
+-- ============================================================
+-- 000023: payroll cash debt deduction
+-- ============================================================
+-- A payroll run can recover a driver's unpaid cash advances. The
+-- recovered total lands on employee_salary.total_cash_debt and is
+-- netted out of remaining_salary; each advance it touches gets a
+-- CASH_DEBT_DEDUCTION component (ref_table = cash_debt) and a
+-- PAYROLL cash_debt_settlement pointing back at the run
+-- (ref_table = employee_salary).
+
+ALTER TABLE employee_salary
+    ADD COLUMN IF NOT EXISTS total_cash_debt numeric(20, 4) NOT NULL DEFAULT 0;
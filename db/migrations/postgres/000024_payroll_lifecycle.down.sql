@@ -0,0 +1,28 @@
+DELETE FROM posting_rule WHERE id IN (
+    'pr-pa-commission', 'pr-pa-meal', 'pr-pa-attendance', 'pr-pa-bonus-target',
+    'pr-pp-cash-receipt', 'pr-pp-remaining'
+);
+
+DELETE FROM account WHERE id = 'acc-6102-beban-gaji';
+
+DROP INDEX IF EXISTS idx_cash_debt_settlement_ref;
+
+ALTER TABLE cash_debt_settlement
+    DROP COLUMN IF EXISTS reversed_by,
+    DROP COLUMN IF EXISTS reversed_at,
+    DROP COLUMN IF EXISTS journal_entry_id;
+
+DROP INDEX IF EXISTS idx_employee_salary_employee_window;
+
+ALTER TABLE employee_salary
+    DROP COLUMN IF EXISTS payment_journal_entry_id,
+    DROP COLUMN IF EXISTS accrual_journal_entry_id,
+    DROP COLUMN IF EXISTS void_reason,
+    DROP COLUMN IF EXISTS voided_by,
+    DROP COLUMN IF EXISTS voided_at,
+    DROP COLUMN IF EXISTS paid_by,
+    DROP COLUMN IF EXISTS paid_at,
+    DROP COLUMN IF EXISTS approved_by,
+    DROP COLUMN IF EXISTS approved_at,
+    DROP COLUMN IF EXISTS created_by,
+    DROP COLUMN IF EXISTS status;
@@ -0,0 +1,60 @@
+-- ============================================================
+-- 000024: payroll run lifecycle
+-- ============================================================
+-- employee_salary gains a status (DRAFT -> APPROVED -> PAID, or
+-- VOID from any of them) with the actor and time of each step, and
+-- the journal entries approval and payment posted so a void can
+-- reverse them. Runs saved before this migration were final, so
+-- they are marked PAID.
+--
+-- Default ledger rules:
+--
+--   PAYROLL_APPROVED
+--     Dr 2101 Hutang Komisi     total_commission (accrued at close)
+--     Dr 6102 Beban Gaji        meal / attendance / bonus target
+--         Cr 2102 Hutang Gaji
+--   PAYROLL_PAID
+--     Dr 2102 Hutang Gaji
+--         Cr 1101 Kas           total_cash_receipt
+--         Cr 1103 Bank          remaining_salary
+
+ALTER TABLE employee_salary
+    ADD COLUMN IF NOT EXISTS status                   varchar(16)  NOT NULL DEFAULT 'DRAFT',
+    ADD COLUMN IF NOT EXISTS created_by               varchar(255) NULL,
+    ADD COLUMN IF NOT EXISTS approved_at              TIMESTAMP    NULL,
+    ADD COLUMN IF NOT EXISTS approved_by              varchar(255) NULL,
+    ADD COLUMN IF NOT EXISTS paid_at                  TIMESTAMP    NULL,
+    ADD COLUMN IF NOT EXISTS paid_by                  varchar(255) NULL,
+    ADD COLUMN IF NOT EXISTS voided_at                TIMESTAMP    NULL,
+    ADD COLUMN IF NOT EXISTS voided_by                varchar(255) NULL,
+    ADD COLUMN IF NOT EXISTS void_reason              text         NULL,
+    ADD COLUMN IF NOT EXISTS accrual_journal_entry_id varchar(255) NULL,
+    ADD COLUMN IF NOT EXISTS payment_journal_entry_id varchar(255) NULL;
+
+UPDATE employee_salary SET status = 'PAID', paid_at = created_at WHERE status = 'DRAFT';
+
+CREATE INDEX IF NOT EXISTS idx_employee_salary_employee_window
+    ON employee_salary(admin_id_employee, start_date, end_date)
+    WHERE status <> 'VOID';
+
+ALTER TABLE cash_debt_settlement
+    ADD COLUMN IF NOT EXISTS journal_entry_id varchar(255) NULL,
+    ADD COLUMN IF NOT EXISTS reversed_at      TIMESTAMP    NULL,
+    ADD COLUMN IF NOT EXISTS reversed_by      varchar(255) NULL;
+
+CREATE INDEX IF NOT EXISTS idx_cash_debt_settlement_ref ON cash_debt_settlement(ref_table, ref_id);
+
+INSERT INTO account (id, organization_id, name, code, type, created_at)
+VALUES
+    ('acc-6102-beban-gaji', NULL, 'Beban Gaji', '6102', 'EXPENSE', NOW())
+ON CONFLICT (id) DO NOTHING;
+
+INSERT INTO posting_rule (id, organization_id, event_type, amount_field, debit_account_code, credit_account_code, description, created_at)
+VALUES
+    ('pr-pa-commission',    NULL, 'PAYROLL_APPROVED', 'TOTAL_COMMISSION',     '2101', '2102', 'Commission moved to salary payable', NOW()),
+    ('pr-pa-meal',          NULL, 'PAYROLL_APPROVED', 'TOTAL_MEAL_ALLOWANCE', '6102', '2102', 'Meal allowance',                     NOW()),
+    ('pr-pa-attendance',    NULL, 'PAYROLL_APPROVED', 'TOTAL_ATTENDANCE',     '6102', '2102', 'Attendance allowance',               NOW()),
+    ('pr-pa-bonus-target',  NULL, 'PAYROLL_APPROVED', 'TOTAL_BONUS_TARGET',   '6102', '2102', 'Target bonus',                       NOW()),
+    ('pr-pp-cash-receipt',  NULL, 'PAYROLL_PAID',     'TOTAL_CASH_RECEIPT',   '2102', '1101', 'Salary paid from the till',          NOW()),
+    ('pr-pp-remaining',     NULL, 'PAYROLL_PAID',     'REMAINING_SALARY',     '2102', '1103', 'Salary transferred',                 NOW())
+ON CONFLICT (id) DO NOTHING;
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_employee_salary_batch;
+
+ALTER TABLE employee_salary DROP COLUMN IF EXISTS batch_id;
+
+DROP INDEX IF EXISTS idx_payroll_batch_company;
+
+DROP TABLE IF EXISTS payroll_batch;
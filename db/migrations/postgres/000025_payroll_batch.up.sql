@@ -0,0 +1,28 @@
+-- ============================================================
+-- 000025: batch payroll per company
+-- ============================================================
+-- A batch runs the payroll simulation for every driver bound to a
+-- company through admin_company and saves the resulting runs in one
+-- transaction. Each employee_salary row saved that way points back
+-- at its batch; runs saved one by one keep batch_id NULL.
+
+CREATE TABLE IF NOT EXISTS payroll_batch (
+    id              varchar(255) PRIMARY KEY,
+    organization_id varchar(255) NOT NULL,
+    company_id      varchar(255) NOT NULL,
+    start_date      date         NOT NULL,
+    end_date        date         NOT NULL,
+    created_by      varchar(255) NULL,
+    created_at      TIMESTAMP    NOT NULL,
+    updated_at      TIMESTAMP    NULL,
+    deleted_at      TIMESTAMP    NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_payroll_batch_company
+    ON payroll_batch (organization_id, company_id, start_date);
+
+ALTER TABLE employee_salary
+    ADD COLUMN IF NOT EXISTS batch_id varchar(255) NULL REFERENCES payroll_batch(id);
+
+CREATE INDEX IF NOT EXISTS idx_employee_salary_batch
+    ON employee_salary (batch_id);
@@ -0,0 +1,4 @@
+ALTER TABLE admin
+    DROP COLUMN IF EXISTS bank_account_name,
+    DROP COLUMN IF EXISTS bank_account_number,
+    DROP COLUMN IF EXISTS bank_code;
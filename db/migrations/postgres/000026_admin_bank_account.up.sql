@@ -0,0 +1,10 @@
+-- ============================================================
+-- 000026: driver bank account
+-- ============================================================
+-- Where a driver's pay is transferred to. The payroll disbursement
+-- export (BCA / Mandiri bulk transfer) refuses drivers without one.
+
+ALTER TABLE admin
+    ADD COLUMN IF NOT EXISTS bank_code           varchar(32)  NULL,
+    ADD COLUMN IF NOT EXISTS bank_account_number varchar(34)  NULL,
+    ADD COLUMN IF NOT EXISTS bank_account_name   varchar(255) NULL;
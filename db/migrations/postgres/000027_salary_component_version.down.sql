@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_salary_component_company_effective;
+
+-- Only the current versions survive the rollback.
+DELETE FROM salary_component WHERE effective_to IS NOT NULL;
+
+ALTER TABLE salary_component
+    DROP COLUMN IF EXISTS previous_id,
+    DROP COLUMN IF EXISTS effective_to,
+    DROP COLUMN IF EXISTS effective_from;
@@ -0,0 +1,22 @@
+-- ============================================================
+-- 000027: time-versioned salary components
+-- ============================================================
+-- A salary_component row is now one version of a band, valid from
+-- effective_from to effective_to inclusive (NULL = current).
+-- Editing a band closes the current version and inserts a new one
+-- whose previous_id points back at it; deleting only sets
+-- effective_to. Sessions and payroll resolve the versions valid on
+-- the session date.
+--
+-- Existing rows become the first version of their band and apply to
+-- every past session, which is what they did before.
+
+ALTER TABLE salary_component
+    ADD COLUMN IF NOT EXISTS effective_from date         NOT NULL DEFAULT DATE '2000-01-01',
+    ADD COLUMN IF NOT EXISTS effective_to   date         NULL,
+    ADD COLUMN IF NOT EXISTS previous_id    varchar(255) NULL;
+
+ALTER TABLE salary_component ALTER COLUMN effective_from DROP DEFAULT;
+
+CREATE INDEX IF NOT EXISTS idx_salary_component_company_effective
+    ON salary_component (company_id, effective_from, effective_to);
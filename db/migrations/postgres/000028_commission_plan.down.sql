@@ -0,0 +1,7 @@
+ALTER TABLE stock_session_item DROP COLUMN IF EXISTS commission_plan_id;
+
+ALTER TABLE stock_session DROP COLUMN IF EXISTS commission_plans;
+
+DROP TABLE IF EXISTS commission_plan_tier;
+
+DROP TABLE IF EXISTS commission_plan;
@@ -0,0 +1,49 @@
+-- ============================================================
+-- 000028: commission plans
+-- ============================================================
+-- A commission plan replaces the flat item.commision rate for the
+-- items it covers, scoped to a company, an item category or both
+-- (the most specific plan wins). Methods:
+--
+--   TIERED       every unit at the rate of the highest tier reached
+--   PROGRESSIVE  each tier's units at that tier's rate
+--   PERCENTAGE   rate percent of the sales in scope
+--
+-- A tier applies to the units sold beyond from_qty, up to the next
+-- tier's from_qty. Closing a session snapshots the plans it used on
+-- stock_session.commission_plans and the plan of each row on
+-- stock_session_item.commission_plan_id.
+
+CREATE TABLE IF NOT EXISTS commission_plan (
+    id               varchar(255)  PRIMARY KEY,
+    organization_id  varchar(255)  NOT NULL,
+    name             varchar(255)  NOT NULL,
+    company_id       varchar(255)  NULL,
+    item_category_id varchar(255)  NULL,
+    method           varchar(16)   NOT NULL, -- TIERED | PROGRESSIVE | PERCENTAGE
+    rate             numeric(20,4) NOT NULL DEFAULT 0,
+    is_active        boolean       NOT NULL DEFAULT true,
+    created_at       TIMESTAMP     NOT NULL,
+    updated_at       TIMESTAMP     NULL,
+    deleted_at       TIMESTAMP     NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_commission_plan_org ON commission_plan (organization_id, is_active);
+
+CREATE TABLE IF NOT EXISTS commission_plan_tier (
+    id         varchar(255)  PRIMARY KEY,
+    plan_id    varchar(255)  NOT NULL REFERENCES commission_plan(id) ON DELETE CASCADE,
+    from_qty   integer       NOT NULL DEFAULT 0,
+    rate       numeric(20,4) NOT NULL DEFAULT 0,
+    created_at TIMESTAMP     NOT NULL,
+    updated_at TIMESTAMP     NULL,
+    deleted_at TIMESTAMP     NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_commission_plan_tier_plan ON commission_plan_tier (plan_id);
+
+ALTER TABLE stock_session
+    ADD COLUMN IF NOT EXISTS commission_plans text NULL;
+
+ALTER TABLE stock_session_item
+    ADD COLUMN IF NOT EXISTS commission_plan_id varchar(255) NULL;
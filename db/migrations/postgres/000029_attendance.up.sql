@@ -0,0 +1,33 @@
+-- ============================================================
+-- 000029: attendance
+-- ============================================================
+-- One row per driver per working day: clock-in / clock-out times
+-- and, when the device shared them, the GPS coordinates of each.
+-- The days with a clock-in over a payroll window are the days
+-- worked the ATTENDANCE salary bands are evaluated against; the
+-- run stores the result as a single ATTENDANCE component
+-- (ref_table = attendance, ref_source = ATTENDANCE).
+
+CREATE TABLE IF NOT EXISTS attendance (
+    id                  varchar(255)   PRIMARY KEY,
+    organization_id     varchar(255)   NOT NULL,
+    admin_id_employee   varchar(255)   NOT NULL,
+    date                date           NOT NULL,
+    clock_in_at         TIMESTAMP      NOT NULL,
+    clock_in_latitude   numeric(10, 7) NULL,
+    clock_in_longitude  numeric(10, 7) NULL,
+    clock_out_at        TIMESTAMP      NULL,
+    clock_out_latitude  numeric(10, 7) NULL,
+    clock_out_longitude numeric(10, 7) NULL,
+    notes               text           NULL,
+    created_by          varchar(255)   NULL,
+    created_at          TIMESTAMP      NOT NULL,
+    updated_at          TIMESTAMP      NULL,
+    deleted_at          TIMESTAMP      NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_attendance_employee_date
+    ON attendance (admin_id_employee, date)
+    WHERE deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_attendance_org_date ON attendance (organization_id, date);
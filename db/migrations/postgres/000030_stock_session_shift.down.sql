@@ -0,0 +1,11 @@
+-- Restoring the one-session-per-day key fails while a driver still
+-- has both shifts on a day; those sessions must be merged or
+-- removed by hand first.
+ALTER TABLE stock_session DROP CONSTRAINT IF EXISTS uq_stock_session_employee_date_shift;
+
+ALTER TABLE stock_session
+    ADD CONSTRAINT uq_stock_session_employee_date UNIQUE (employee_id, date);
+
+ALTER TABLE stock_session
+    DROP COLUMN IF EXISTS carried_from_id,
+    DROP COLUMN IF EXISTS shift;
@@ -0,0 +1,17 @@
+-- ============================================================
+-- 000030: stock session shifts
+-- ============================================================
+-- A driver's day splits into a MORNING and an EVENING session, so
+-- the (employee_id, date) key becomes (employee_id, date, shift).
+-- Existing sessions are MORNING. carried_from_id points an EVENING
+-- session at the MORNING session whose returns it was loaded with
+-- (POST /stock-session/:id/carry-over).
+
+ALTER TABLE stock_session
+    ADD COLUMN IF NOT EXISTS shift varchar(16) NOT NULL DEFAULT 'MORNING', -- MORNING | EVENING
+    ADD COLUMN IF NOT EXISTS carried_from_id varchar(255) NULL;
+
+ALTER TABLE stock_session DROP CONSTRAINT IF EXISTS uq_stock_session_employee_date;
+
+ALTER TABLE stock_session
+    ADD CONSTRAINT uq_stock_session_employee_date_shift UNIQUE (employee_id, date, shift);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS stock_movement;
+DROP TABLE IF EXISTS item_stock;
@@ -0,0 +1,44 @@
+-- ============================================================
+-- 000031: inventory
+-- ============================================================
+-- Depot stock per item. item_stock keeps the running on-hand
+-- balance; stock_movement is the ledger behind it, one signed row
+-- per change with the balance after it:
+--   RECEIPT         goods received at the depot (+)
+--   SESSION_OUT     loaded onto a driver's stock session (-)
+--   SESSION_RETURN  returned when the session closes (+)
+--   ADJUSTMENT      stock-opname correction to the counted qty (+/-)
+-- Session movements carry ref_table = stock_session and are posted
+-- as deltas, so editing or deleting a session nets itself out.
+
+CREATE TABLE IF NOT EXISTS item_stock (
+    id              varchar(255) PRIMARY KEY,
+    organization_id varchar(255) NOT NULL,
+    item_id         varchar(255) NOT NULL,
+    on_hand         integer      NOT NULL DEFAULT 0,
+    created_at      TIMESTAMP    NOT NULL,
+    updated_at      TIMESTAMP    NULL,
+    deleted_at      TIMESTAMP    NULL,
+    CONSTRAINT uq_item_stock_org_item UNIQUE (organization_id, item_id)
+);
+
+CREATE TABLE IF NOT EXISTS stock_movement (
+    id              varchar(255) PRIMARY KEY,
+    organization_id varchar(255) NOT NULL,
+    item_id         varchar(255) NOT NULL,
+    date            date         NOT NULL,
+    type            varchar(16)  NOT NULL,
+    qty             integer      NOT NULL,
+    balance_after   integer      NOT NULL,
+    ref_id          varchar(255) NULL,
+    ref_table       varchar(255) NULL,
+    reference       varchar(255) NULL,
+    notes           text         NULL,
+    created_by      varchar(255) NULL,
+    created_at      TIMESTAMP    NOT NULL,
+    updated_at      TIMESTAMP    NULL,
+    deleted_at      TIMESTAMP    NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_movement_org_item_date ON stock_movement (organization_id, item_id, date);
+CREATE INDEX IF NOT EXISTS idx_stock_movement_ref ON stock_movement (ref_table, ref_id);
@@ -0,0 +1,9 @@
+DELETE FROM posting_rule WHERE id = 'pr-gr-total-received';
+
+DELETE FROM account WHERE id = 'acc-2103-hutang-usaha';
+
+DROP TABLE IF EXISTS goods_receipt_line;
+DROP TABLE IF EXISTS goods_receipt;
+DROP TABLE IF EXISTS purchase_order_line;
+DROP TABLE IF EXISTS purchase_order;
+DROP TABLE IF EXISTS supplier;
@@ -0,0 +1,110 @@
+-- ============================================================
+-- 000032: suppliers, purchase orders and goods receipts
+-- ============================================================
+-- A purchase order is what was ordered from a supplier; its lines
+-- keep received_qty as goods receipts come in and the order moves
+-- OPEN -> PARTIAL -> RECEIVED (or CANCELLED). A goods receipt may
+-- also be booked without an order. Each receipt adds its lines to
+-- the depot as RECEIPT stock movements (ref_table = goods_receipt),
+-- can move item.cost_price to the moving weighted average, and
+-- posts GOODS_RECEIVED:
+--
+--   Dr 1301 Persediaan       total_received
+--       Cr 2103 Hutang Usaha total_received
+
+CREATE TABLE IF NOT EXISTS supplier (
+    id              varchar(255) PRIMARY KEY,
+    organization_id varchar(255) NOT NULL,
+    name            varchar(255) NOT NULL,
+    contact_name    varchar(255) NULL,
+    phone_number    varchar(64)  NULL,
+    email           varchar(255) NULL,
+    address         text         NULL,
+    notes           text         NULL,
+    is_active       boolean      NOT NULL DEFAULT true,
+    created_at      TIMESTAMP    NOT NULL,
+    updated_at      TIMESTAMP    NULL,
+    deleted_at      TIMESTAMP    NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_supplier_org ON supplier (organization_id);
+
+CREATE TABLE IF NOT EXISTS purchase_order (
+    id              varchar(255)   PRIMARY KEY,
+    organization_id varchar(255)   NOT NULL,
+    supplier_id     varchar(255)   NOT NULL,
+    code            varchar(64)    NOT NULL,
+    date            date           NOT NULL,
+    expected_date   date           NULL,
+    status          varchar(16)    NOT NULL DEFAULT 'OPEN',
+    total           numeric(20, 4) NOT NULL DEFAULT 0,
+    notes           text           NULL,
+    created_by      varchar(255)   NULL,
+    created_at      TIMESTAMP      NOT NULL,
+    updated_at      TIMESTAMP      NULL,
+    deleted_at      TIMESTAMP      NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_purchase_order_org_date ON purchase_order (organization_id, date);
+CREATE INDEX IF NOT EXISTS idx_purchase_order_supplier ON purchase_order (supplier_id);
+
+CREATE TABLE IF NOT EXISTS purchase_order_line (
+    id                varchar(255)   PRIMARY KEY,
+    purchase_order_id varchar(255)   NOT NULL REFERENCES purchase_order (id) ON DELETE CASCADE,
+    item_id           varchar(255)   NOT NULL,
+    qty               integer        NOT NULL,
+    unit_cost         numeric(20, 4) NOT NULL DEFAULT 0,
+    received_qty      integer        NOT NULL DEFAULT 0,
+    created_at        TIMESTAMP      NOT NULL,
+    updated_at        TIMESTAMP      NULL,
+    deleted_at        TIMESTAMP      NULL,
+    CONSTRAINT chk_pol_qty CHECK (qty > 0 AND received_qty >= 0 AND received_qty <= qty)
+);
+
+CREATE INDEX IF NOT EXISTS idx_purchase_order_line_order ON purchase_order_line (purchase_order_id);
+
+CREATE TABLE IF NOT EXISTS goods_receipt (
+    id                varchar(255)   PRIMARY KEY,
+    organization_id   varchar(255)   NOT NULL,
+    supplier_id       varchar(255)   NOT NULL,
+    purchase_order_id varchar(255)   NULL,
+    code              varchar(64)    NOT NULL,
+    date              date           NOT NULL,
+    reference         varchar(255)   NULL,
+    total             numeric(20, 4) NOT NULL DEFAULT 0,
+    update_cost_price boolean        NOT NULL DEFAULT false,
+    journal_entry_id  varchar(255)   NULL,
+    notes             text           NULL,
+    created_by        varchar(255)   NULL,
+    created_at        TIMESTAMP      NOT NULL,
+    updated_at        TIMESTAMP      NULL,
+    deleted_at        TIMESTAMP      NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_goods_receipt_org_date ON goods_receipt (organization_id, date);
+CREATE INDEX IF NOT EXISTS idx_goods_receipt_order ON goods_receipt (purchase_order_id);
+
+CREATE TABLE IF NOT EXISTS goods_receipt_line (
+    id                     varchar(255)   PRIMARY KEY,
+    goods_receipt_id       varchar(255)   NOT NULL REFERENCES goods_receipt (id) ON DELETE CASCADE,
+    purchase_order_line_id varchar(255)   NULL,
+    item_id                varchar(255)   NOT NULL,
+    qty                    integer        NOT NULL,
+    unit_cost              numeric(20, 4) NOT NULL DEFAULT 0,
+    created_at             TIMESTAMP      NOT NULL,
+    updated_at             TIMESTAMP      NULL,
+    deleted_at             TIMESTAMP      NULL,
+    CONSTRAINT chk_grl_qty CHECK (qty > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_goods_receipt_line_receipt ON goods_receipt_line (goods_receipt_id);
+
+INSERT INTO account (id, organization_id, name, code, type, created_at)
+VALUES
+    ('acc-2103-hutang-usaha', NULL, 'Hutang Usaha', '2103', 'LIABILITY', NOW())
+ON CONFLICT (id) DO NOTHING;
+
+INSERT INTO posting_rule (id, organization_id, event_type, amount_field, debit_account_code, credit_account_code, description, created_at)
+VALUES
+    ('pr-gr-total-received', NULL, 'GOODS_RECEIVED', 'TOTAL_RECEIVED', '1301', '2103', 'Goods received on account', NOW())
+ON CONFLICT (id) DO NOTHING;
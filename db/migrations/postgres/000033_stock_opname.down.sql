@@ -0,0 +1,6 @@
+DELETE FROM posting_rule WHERE id IN ('pr-so-shrinkage', 'pr-so-surplus');
+
+DELETE FROM account WHERE id = 'acc-6103-beban-selisih-persediaan';
+
+DROP TABLE IF EXISTS stock_opname_line;
+DROP TABLE IF EXISTS stock_opname;
@@ -0,0 +1,59 @@
+-- ============================================================
+-- 000033: stock opname (physical counts)
+-- ============================================================
+-- A count snapshots each item's depot on-hand (system_qty) when it
+-- is started; counted_qty is filled in while it is DRAFT. Approving
+-- it books counted_qty - system_qty per item as an ADJUSTMENT stock
+-- movement (ref_table = stock_opname), snapshots the items' cost
+-- price and posts STOCK_OPNAME_APPROVED:
+--
+--   Dr 6103 Beban Selisih Persediaan   total_shrinkage
+--       Cr 1301 Persediaan             total_shrinkage
+--   Dr 1301 Persediaan                 total_surplus
+--       Cr 6103 Beban Selisih Persediaan total_surplus
+
+CREATE TABLE IF NOT EXISTS stock_opname (
+    id               varchar(255)   PRIMARY KEY,
+    organization_id  varchar(255)   NOT NULL,
+    code             varchar(64)    NOT NULL,
+    date             date           NOT NULL,
+    status           varchar(16)    NOT NULL DEFAULT 'DRAFT',
+    notes            text           NULL,
+    total_shrinkage  numeric(20, 4) NOT NULL DEFAULT 0,
+    total_surplus    numeric(20, 4) NOT NULL DEFAULT 0,
+    journal_entry_id varchar(255)   NULL,
+    created_by       varchar(255)   NULL,
+    approved_by      varchar(255)   NULL,
+    approved_at      TIMESTAMP      NULL,
+    created_at       TIMESTAMP      NOT NULL,
+    updated_at       TIMESTAMP      NULL,
+    deleted_at       TIMESTAMP      NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_opname_org_date ON stock_opname (organization_id, date);
+
+CREATE TABLE IF NOT EXISTS stock_opname_line (
+    id              varchar(255)   PRIMARY KEY,
+    stock_opname_id varchar(255)   NOT NULL REFERENCES stock_opname (id) ON DELETE CASCADE,
+    item_id         varchar(255)   NOT NULL,
+    system_qty      integer        NOT NULL DEFAULT 0,
+    counted_qty     integer        NULL,
+    cost_price      numeric(20, 4) NOT NULL DEFAULT 0,
+    created_at      TIMESTAMP      NOT NULL,
+    updated_at      TIMESTAMP      NULL,
+    deleted_at      TIMESTAMP      NULL,
+    CONSTRAINT chk_sol_counted_nonneg CHECK (counted_qty IS NULL OR counted_qty >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_opname_line_opname ON stock_opname_line (stock_opname_id);
+
+INSERT INTO account (id, organization_id, name, code, type, created_at)
+VALUES
+    ('acc-6103-beban-selisih-persediaan', NULL, 'Beban Selisih Persediaan', '6103', 'EXPENSE', NOW())
+ON CONFLICT (id) DO NOTHING;
+
+INSERT INTO posting_rule (id, organization_id, event_type, amount_field, debit_account_code, credit_account_code, description, created_at)
+VALUES
+    ('pr-so-shrinkage', NULL, 'STOCK_OPNAME_APPROVED', 'TOTAL_SHRINKAGE', '6103', '1301', 'Stock count shrinkage', NOW()),
+    ('pr-so-surplus',   NULL, 'STOCK_OPNAME_APPROVED', 'TOTAL_SURPLUS',   '1301', '6103', 'Stock count surplus',   NOW())
+ON CONFLICT (id) DO NOTHING;
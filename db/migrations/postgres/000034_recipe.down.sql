@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS ingredient_usage;
+DROP TABLE IF EXISTS recipe_line;
+DROP TABLE IF EXISTS recipe;
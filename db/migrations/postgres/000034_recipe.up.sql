@@ -0,0 +1,52 @@
+-- ============================================================
+-- 000034: recipes (bill of materials)
+-- ============================================================
+-- A recipe lists the ingredients one unit of a sellable item takes,
+-- qty in the ingredient's stock unit. Closing a stock session
+-- explodes sold_qty of each item through its recipe (or its
+-- parent's) into ingredient_usage rows; the usage report compares
+-- their sum with the depot's stock_movement outflow over a period.
+
+CREATE TABLE IF NOT EXISTS recipe (
+    id              varchar(255) PRIMARY KEY,
+    organization_id varchar(255) NOT NULL,
+    item_id         varchar(255) NOT NULL,
+    notes           text         NULL,
+    is_active       boolean      NOT NULL DEFAULT true,
+    created_at      TIMESTAMP    NOT NULL,
+    updated_at      TIMESTAMP    NULL,
+    deleted_at      TIMESTAMP    NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_recipe_org_item ON recipe (organization_id, item_id) WHERE deleted_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS recipe_line (
+    id            varchar(255)   PRIMARY KEY,
+    recipe_id     varchar(255)   NOT NULL REFERENCES recipe (id) ON DELETE CASCADE,
+    ingredient_id varchar(255)   NOT NULL,
+    qty           numeric(20, 4) NOT NULL,
+    created_at    TIMESTAMP      NOT NULL,
+    updated_at    TIMESTAMP      NULL,
+    deleted_at    TIMESTAMP      NULL,
+    CONSTRAINT chk_recipe_line_qty_pos CHECK (qty > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_recipe_line_recipe ON recipe_line (recipe_id);
+
+CREATE TABLE IF NOT EXISTS ingredient_usage (
+    id               varchar(255)   PRIMARY KEY,
+    organization_id  varchar(255)   NOT NULL,
+    stock_session_id varchar(255)   NOT NULL,
+    date             date           NOT NULL,
+    item_id          varchar(255)   NOT NULL,
+    ingredient_id    varchar(255)   NOT NULL,
+    sold_qty         integer        NOT NULL DEFAULT 0,
+    qty_per_unit     numeric(20, 4) NOT NULL DEFAULT 0,
+    qty              numeric(20, 4) NOT NULL DEFAULT 0,
+    created_at       TIMESTAMP      NOT NULL,
+    updated_at       TIMESTAMP      NULL,
+    deleted_at       TIMESTAMP      NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_ingredient_usage_org_date ON ingredient_usage (organization_id, date);
+CREATE INDEX IF NOT EXISTS idx_ingredient_usage_session ON ingredient_usage (stock_session_id);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_item_stock_low;
+
+ALTER TABLE item_stock DROP COLUMN IF EXISTS reorder_point;
+
+DROP TABLE IF EXISTS scheduler_lock;
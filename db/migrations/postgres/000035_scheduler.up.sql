@@ -0,0 +1,24 @@
+-- ============================================================
+-- 000035: scheduler locks and reorder points
+-- ============================================================
+-- Every API instance runs the job scheduler. Before a run, an
+-- instance takes the job's row in scheduler_lock (an insert, or an
+-- update of an expired row); the others see the row held and skip
+-- that tick. locked_until lets a lock left by a crashed instance
+-- expire.
+--
+-- item_stock.reorder_point is the on-hand at or below which the
+-- low-stock job alerts; 0 means the item is not watched.
+
+CREATE TABLE IF NOT EXISTS scheduler_lock (
+    name         varchar(255) PRIMARY KEY,
+    locked_by    varchar(255) NOT NULL,
+    locked_at    TIMESTAMP    NOT NULL,
+    locked_until TIMESTAMP    NOT NULL,
+    created_at   TIMESTAMP    NOT NULL,
+    updated_at   TIMESTAMP    NULL
+);
+
+ALTER TABLE item_stock ADD COLUMN IF NOT EXISTS reorder_point integer NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_item_stock_low ON item_stock (organization_id) WHERE reorder_point > 0 AND on_hand <= reorder_point;
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_stock_session_status_date;
+
+ALTER TABLE session_log
+    DROP COLUMN IF EXISTS deleted_at,
+    DROP COLUMN IF EXISTS updated_at;
+
+ALTER TABLE organization DROP COLUMN IF EXISTS stale_session_policy;
@@ -0,0 +1,20 @@
+-- ============================================================
+-- 000036: stale stock sessions
+-- ============================================================
+-- The stale-session job handles stock sessions still OPEN past the
+-- configured cutoff of their date, per organization:
+--   AUTO_CLOSE      close with nothing sold and everything returned
+--   PENDING_REVIEW  set stock_session.status = PENDING_REVIEW for an
+--                   admin to close (the default)
+-- Either way it writes a session_log row (admin_id NULL). session_log
+-- gains the updated_at / deleted_at columns every other model table
+-- has, so GORM can write and read it.
+
+ALTER TABLE organization
+    ADD COLUMN IF NOT EXISTS stale_session_policy varchar(32) NOT NULL DEFAULT 'PENDING_REVIEW';
+
+ALTER TABLE session_log
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP NULL,
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP NULL;
+
+CREATE INDEX IF NOT EXISTS idx_stock_session_status_date ON stock_session (status, date);
@@ -0,0 +1,7 @@
+ALTER TABLE employee_salary DROP COLUMN IF EXISTS save_journal_entry_id;
+
+ALTER TABLE "order" DROP COLUMN IF EXISTS journal_entry_id;
+
+ALTER TABLE cash_debt DROP COLUMN IF EXISTS journal_entry_id;
+
+ALTER TABLE posting_rule DROP COLUMN IF EXISTS is_active;
@@ -0,0 +1,27 @@
+-- ============================================================
+-- 000037: posting rule switch and the remaining posting events
+-- ============================================================
+-- posting_rule.is_active lets a rule be switched off. An org turns a
+-- global default off by creating its own rule for the same
+-- event_type + amount_field with is_active = false: the override
+-- replaces the default and, being inactive, posts nothing.
+--
+-- CASH_DEBT_CREATED (cash_debt), ORDER_CREATED ("order") and
+-- PAYROLL_SAVED (employee_salary) now post through the rules too.
+-- No default rules are seeded for them: an advance is already booked
+-- at session close (CASH_DEBT) and a run is accrued on approval
+-- (PAYROLL_APPROVED), so they post only for an organization that
+-- configures them. The entry each write produced is kept so an edit
+-- or delete can reverse it.
+
+ALTER TABLE posting_rule
+    ADD COLUMN IF NOT EXISTS is_active boolean NOT NULL DEFAULT true;
+
+ALTER TABLE cash_debt
+    ADD COLUMN IF NOT EXISTS journal_entry_id varchar(255) NULL;
+
+ALTER TABLE "order"
+    ADD COLUMN IF NOT EXISTS journal_entry_id varchar(255) NULL;
+
+ALTER TABLE employee_salary
+    ADD COLUMN IF NOT EXISTS save_journal_entry_id varchar(255) NULL;
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	"github.com/raymondsugiarto/coffee-api/pkg/infrastructure/middleware"
+	accounting "github.com/raymondsugiarto/coffee-api/pkg/module/accounting"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/response/status"
+)
+
+// FindAllAccountingPeriods powers GET /api/accounting/periods.
+func FindAllAccountingPeriods(service accounting.PeriodService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req := new(entity.AccountingPeriodFindAllRequest)
+		if err := c.QueryParser(req); err != nil {
+			return status.New(status.BadRequest, err)
+		}
+		result, err := service.FindAll(c.Context(), req)
+		if err != nil {
+			return err
+		}
+		return c.JSON(result)
+	}
+}
+
+// FindOneAccountingPeriod powers GET /api/accounting/periods/:period.
+// A month that was never closed reports as OPEN.
+func FindOneAccountingPeriod(service accounting.PeriodService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		result, err := service.Get(c.Context(), c.Params("period"))
+		if err != nil {
+			return err
+		}
+		return c.JSON(result)
+	}
+}
+
+// FindAccountingPeriodLogs powers GET /api/accounting/periods/:period/logs.
+func FindAccountingPeriodLogs(service accounting.PeriodService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		result, err := service.FindLogs(c.Context(), c.Params("period"))
+		if err != nil {
+			return err
+		}
+		return c.JSON(result)
+	}
+}
+
+// CloseAccountingPeriod powers POST /api/accounting/periods/:period/close.
+func CloseAccountingPeriod(service accounting.PeriodService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req, err := parseAccountingPeriodAction(c)
+		if err != nil {
+			return err
+		}
+		result, err := service.Close(c.Context(), c.Params("period"), req)
+		if err != nil {
+			return err
+		}
+		return c.JSON(result)
+	}
+}
+
+// ReopenAccountingPeriod powers POST /api/accounting/periods/:period/reopen.
+func ReopenAccountingPeriod(service accounting.PeriodService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req, err := parseAccountingPeriodAction(c)
+		if err != nil {
+			return err
+		}
+		result, err := service.Reopen(c.Context(), c.Params("period"), req)
+		if err != nil {
+			return err
+		}
+		return c.JSON(result)
+	}
+}
+
+// parseAccountingPeriodAction reads the optional close / reopen body.
+func parseAccountingPeriodAction(c *fiber.Ctx) (*entity.AccountingPeriodActionRequest, error) {
+	req := new(entity.AccountingPeriodActionRequest)
+	if len(c.Body()) == 0 {
+		return req, nil
+	}
+	if err := c.BodyParser(req); err != nil {
+		return nil, status.New(status.BadRequest, err)
+	}
+	if err := middleware.AppValidator.Validate(req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
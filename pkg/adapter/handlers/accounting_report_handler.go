@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	accounting "github.com/raymondsugiarto/coffee-api/pkg/module/accounting"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/response/status"
+)
+
+// GetTrialBalance powers GET /api/accounting/trial-balance.
+func GetTrialBalance(service accounting.ReportService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req := new(entity.TrialBalanceRequest)
+		if err := c.QueryParser(req); err != nil {
+			return status.New(status.BadRequest, err)
+		}
+		result, err := service.TrialBalance(c.Context(), req)
+		if err != nil {
+			return err
+		}
+		return c.JSON(result)
+	}
+}
+
+// GetAccountLedger powers GET /api/accounts/:id/ledger. Sending
+// `Accept: application/vnd.openxmlformats-officedocument.spreadsheetml.sheet`
+// downloads the same statement as XLSX.
+func GetAccountLedger(service accounting.ReportService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req := new(entity.AccountLedgerRequest)
+		if err := c.QueryParser(req); err != nil {
+			return status.New(status.BadRequest, err)
+		}
+		result, err := service.AccountLedger(c.Context(), c.Params("id"), req)
+		if err != nil {
+			return err
+		}
+
+		if c.Get("Accept") == "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet" {
+			excelBytes, err := service.AccountLedgerExcel(c.Context(), result)
+			if err != nil {
+				return err
+			}
+
+			c.Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+			c.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="buku_besar_%s.xlsx"`, result.Account.Code))
+			return c.SendStream(bytes.NewReader(excelBytes))
+		}
+
+		return c.JSON(result)
+	}
+}
+
+// GetProfitLoss powers GET /api/reports/profit-loss. `compare=true`
+// adds the previous period; `Accept: application/pdf` downloads the
+// statement as PDF.
+func GetProfitLoss(service accounting.ReportService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req := new(entity.FinancialStatementRequest)
+		if err := c.QueryParser(req); err != nil {
+			return status.New(status.BadRequest, err)
+		}
+		result, err := service.ProfitLoss(c.Context(), req)
+		if err != nil {
+			return err
+		}
+
+		if c.Get("Accept") == "application/pdf" {
+			pdfBytes, err := service.ProfitLossPDF(c.Context(), result)
+			if err != nil {
+				return err
+			}
+
+			c.Set("Content-Type", "application/pdf")
+			c.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="laba_rugi_%s_%s.pdf"`, result.From, result.To))
+			return c.SendStream(bytes.NewReader(pdfBytes))
+		}
+
+		return c.JSON(result)
+	}
+}
+
+// GetCashFlow powers GET /api/reports/cash-flow. Same query and
+// Accept handling as GetProfitLoss.
+func GetCashFlow(service accounting.ReportService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req := new(entity.FinancialStatementRequest)
+		if err := c.QueryParser(req); err != nil {
+			return status.New(status.BadRequest, err)
+		}
+		result, err := service.CashFlow(c.Context(), req)
+		if err != nil {
+			return err
+		}
+
+		if c.Get("Accept") == "application/pdf" {
+			pdfBytes, err := service.CashFlowPDF(c.Context(), result)
+			if err != nil {
+				return err
+			}
+
+			c.Set("Content-Type", "application/pdf")
+			c.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="arus_kas_%s_%s.pdf"`, result.From, result.To))
+			return c.SendStream(bytes.NewReader(pdfBytes))
+		}
+
+		return c.JSON(result)
+	}
+}
+
+// FindAllAccountMutations powers GET /api/account-mutations.
+func FindAllAccountMutations(service accounting.AccountMutationService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req := new(entity.AccountMutationFindAllRequest)
+		if err := c.QueryParser(req); err != nil {
+			return status.New(status.BadRequest, err)
+		}
+		result, err := service.FindAll(c.Context(), req)
+		if err != nil {
+			return err
+		}
+		return c.JSON(result)
+	}
+}
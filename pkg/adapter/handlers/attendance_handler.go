@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	"github.com/raymondsugiarto/coffee-api/pkg/infrastructure/middleware"
+	"github.com/raymondsugiarto/coffee-api/pkg/module/attendance"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/response/status"
+)
+
+// FindAllAttendances powers GET /api/attendance.
+func FindAllAttendances(service attendance.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req := new(entity.AttendanceFindAllRequest)
+		if err := c.QueryParser(req); err != nil {
+			return status.New(status.BadRequest, err)
+		}
+		result, err := service.FindAll(c.Context(), req)
+		if err != nil {
+			return err
+		}
+		return c.JSON(result)
+	}
+}
+
+// FindOneAttendance powers GET /api/attendance/:id.
+func FindOneAttendance(service attendance.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		result, err := service.Get(c.Context(), c.Params("id"))
+		if err != nil {
+			return err
+		}
+		return c.JSON(result)
+	}
+}
+
+// ClockIn powers POST /api/attendance/clock-in.
+func ClockIn(service attendance.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req := new(entity.AttendanceClockInRequest)
+		if err := c.BodyParser(req); err != nil {
+			return status.New(status.BadRequest, err)
+		}
+		if err := middleware.AppValidator.Validate(req); err != nil {
+			return err
+		}
+		result, err := service.ClockIn(c.Context(), req)
+		if err != nil {
+			return err
+		}
+		return c.Status(fiber.StatusCreated).JSON(result)
+	}
+}
+
+// ClockOut powers POST /api/attendance/:id/clock-out.
+func ClockOut(service attendance.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req := new(entity.AttendanceClockOutRequest)
+		if err := c.BodyParser(req); err != nil {
+			return status.New(status.BadRequest, err)
+		}
+		if err := middleware.AppValidator.Validate(req); err != nil {
+			return err
+		}
+		result, err := service.ClockOut(c.Context(), c.Params("id"), req)
+		if err != nil {
+			return err
+		}
+		return c.JSON(result)
+	}
+}
+
+// GetEmployeeAttendanceSummary powers
+// GET /api/employees/:id/attendance-summary.
+func GetEmployeeAttendanceSummary(service attendance.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req := new(entity.AttendanceSummaryRequest)
+		if err := c.QueryParser(req); err != nil {
+			return status.New(status.BadRequest, err)
+		}
+		if err := middleware.AppValidator.Validate(req); err != nil {
+			return err
+		}
+		result, err := service.Summary(c.Context(), c.Params("id"), req)
+		if err != nil {
+			return err
+		}
+		return c.JSON(result)
+	}
+}
@@ -83,3 +83,33 @@ func DeleteCashDebt(service cashdebt.Service) fiber.Handler {
 		return c.JSON(fiber.Map{"deleted": id})
 	}
 }
+
+// SettleCashDebt powers POST /api/cash-debts/:id/settle.
+func SettleCashDebt(service cashdebt.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req := new(entity.CashDebtSettleRequest)
+		if err := c.BodyParser(req); err != nil {
+			return status.New(status.BadRequest, err)
+		}
+		if err := middleware.AppValidator.Validate(req); err != nil {
+			return err
+		}
+		result, err := service.Settle(c.Context(), c.Params("id"), req)
+		if err != nil {
+			return err
+		}
+		return c.Status(fiber.StatusCreated).JSON(result)
+	}
+}
+
+// GetEmployeeCashDebtBalance powers
+// GET /api/employees/:id/cash-debt-balance.
+func GetEmployeeCashDebtBalance(service cashdebt.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		result, err := service.Balance(c.Context(), c.Params("id"))
+		if err != nil {
+			return err
+		}
+		return c.JSON(result)
+	}
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	"github.com/raymondsugiarto/coffee-api/pkg/infrastructure/middleware"
+	commissionplan "github.com/raymondsugiarto/coffee-api/pkg/module/commission_plan"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/response/status"
+)
+
+func FindAllCommissionPlans(service commissionplan.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req := new(entity.CommissionPlanFindAllRequest)
+		if err := c.QueryParser(req); err != nil {
+			return status.New(status.BadRequest, err)
+		}
+		result, err := service.FindAll(c.Context(), req)
+		if err != nil {
+			return err
+		}
+		return c.JSON(result)
+	}
+}
+
+func FindOneCommissionPlan(service commissionplan.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id := c.Params("id")
+		result, err := service.Get(c.Context(), id)
+		if err != nil {
+			return err
+		}
+		return c.JSON(result)
+	}
+}
+
+func CreateCommissionPlan(service commissionplan.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req := new(entity.CommissionPlanDto)
+		if err := c.BodyParser(req); err != nil {
+			return status.New(status.BadRequest, err)
+		}
+		if err := middleware.AppValidator.Validate(req); err != nil {
+			return err
+		}
+		result, err := service.Create(c.Context(), req)
+		if err != nil {
+			return err
+		}
+		return c.Status(fiber.StatusCreated).JSON(result)
+	}
+}
+
+func UpdateCommissionPlan(service commissionplan.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id := c.Params("id")
+		req := new(entity.CommissionPlanDto)
+		if err := c.BodyParser(req); err != nil {
+			return status.New(status.BadRequest, err)
+		}
+		if err := middleware.AppValidator.Validate(req); err != nil {
+			return err
+		}
+		req.ID = id
+		result, err := service.Update(c.Context(), req)
+		if err != nil {
+			return err
+		}
+		return c.JSON(result)
+	}
+}
+
+func DeleteCommissionPlan(service commissionplan.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id := c.Params("id")
+		if err := service.Delete(c.Context(), id); err != nil {
+			return err
+		}
+		return c.JSON(fiber.Map{"deleted": id})
+	}
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	"github.com/raymondsugiarto/coffee-api/pkg/infrastructure/middleware"
 	"github.com/raymondsugiarto/coffee-api/pkg/module/driver"
 	"github.com/raymondsugiarto/coffee-api/pkg/shared/response/status"
 )
@@ -20,3 +21,32 @@ func FindAllDrivers(service driver.Service) fiber.Handler {
 		return c.JSON(result)
 	}
 }
+
+// GetDriverBankAccount powers GET /api/employees/:id/bank-account.
+func GetDriverBankAccount(service driver.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		result, err := service.GetBankAccount(c.Context(), c.Params("id"))
+		if err != nil {
+			return err
+		}
+		return c.JSON(result)
+	}
+}
+
+// UpdateDriverBankAccount powers PUT /api/employees/:id/bank-account.
+func UpdateDriverBankAccount(service driver.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req := new(entity.DriverBankAccountRequest)
+		if err := c.BodyParser(req); err != nil {
+			return status.New(status.BadRequest, err)
+		}
+		if err := middleware.AppValidator.Validate(req); err != nil {
+			return err
+		}
+		result, err := service.UpdateBankAccount(c.Context(), c.Params("id"), req)
+		if err != nil {
+			return err
+		}
+		return c.JSON(result)
+	}
+}
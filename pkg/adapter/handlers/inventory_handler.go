@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	"github.com/raymondsugiarto/coffee-api/pkg/infrastructure/middleware"
+	"github.com/raymondsugiarto/coffee-api/pkg/module/inventory"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/response/status"
+)
+
+// FindAllItemStocks powers GET /api/inventory/stock.
+func FindAllItemStocks(service inventory.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req := new(entity.ItemStockFindAllRequest)
+		if err := c.QueryParser(req); err != nil {
+			return status.New(status.BadRequest, err)
+		}
+		result, err := service.FindStock(c.Context(), req)
+		if err != nil {
+			return err
+		}
+		return c.JSON(result)
+	}
+}
+
+// GetItemStock powers GET /api/inventory/stock/:itemId.
+func GetItemStock(service inventory.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		result, err := service.GetStock(c.Context(), c.Params("itemId"))
+		if err != nil {
+			return err
+		}
+		return c.JSON(result)
+	}
+}
+
+// FindAllStockMovements powers GET /api/inventory/movements and
+// GET /api/inventory/stock/:itemId/history.
+func FindAllStockMovements(service inventory.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req := new(entity.StockMovementFindAllRequest)
+		if err := c.QueryParser(req); err != nil {
+			return status.New(status.BadRequest, err)
+		}
+		if itemID := c.Params("itemId"); itemID != "" {
+			req.ItemID = itemID
+		}
+		result, err := service.FindMovements(c.Context(), req)
+		if err != nil {
+			return err
+		}
+		return c.JSON(result)
+	}
+}
+
+// SetItemReorderPoint powers PUT
+// /api/inventory/stock/:itemId/reorder-point.
+func SetItemReorderPoint(service inventory.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req := new(entity.ReorderPointRequest)
+		if err := c.BodyParser(req); err != nil {
+			return status.New(status.BadRequest, err)
+		}
+		if err := middleware.AppValidator.Validate(req); err != nil {
+			return err
+		}
+		result, err := service.SetReorderPoint(c.Context(), c.Params("itemId"), req)
+		if err != nil {
+			return err
+		}
+		return c.JSON(result)
+	}
+}
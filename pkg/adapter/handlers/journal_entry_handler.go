@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	"github.com/raymondsugiarto/coffee-api/pkg/infrastructure/middleware"
+	accounting "github.com/raymondsugiarto/coffee-api/pkg/module/accounting"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/response/status"
+)
+
+// FindAllJournalEntries powers GET /api/journal-entries.
+func FindAllJournalEntries(service accounting.JournalEntryService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req := new(entity.JournalEntryFindAllRequest)
+		if err := c.QueryParser(req); err != nil {
+			return status.New(status.BadRequest, err)
+		}
+		result, err := service.FindAll(c.Context(), req)
+		if err != nil {
+			return err
+		}
+		return c.JSON(result)
+	}
+}
+
+// FindOneJournalEntry powers GET /api/journal-entries/:id.
+func FindOneJournalEntry(service accounting.JournalEntryService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id := c.Params("id")
+		result, err := service.Get(c.Context(), id)
+		if err != nil {
+			return err
+		}
+		return c.JSON(result)
+	}
+}
+
+// CreateJournalEntry powers POST /api/journal-entries. Only manual
+// entries come in through here: source, refs and reversal links
+// are owned by the server.
+func CreateJournalEntry(service accounting.JournalEntryService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req := new(entity.JournalEntryDto)
+		if err := c.BodyParser(req); err != nil {
+			return status.New(status.BadRequest, err)
+		}
+		if err := middleware.AppValidator.Validate(req); err != nil {
+			return err
+		}
+		req.ID = ""
+		req.Source = entity.JournalEntrySourceManual
+		req.RefID, req.RefTable, req.RefModule = "", "", ""
+		req.ReversalOfID, req.ReversedByID, req.CreatedBy = "", "", ""
+		result, err := service.Post(c.Context(), req)
+		if err != nil {
+			return err
+		}
+		return c.Status(fiber.StatusCreated).JSON(result)
+	}
+}
+
+// ReverseJournalEntry powers POST /api/journal-entries/:id/reverse.
+// The body is optional.
+func ReverseJournalEntry(service accounting.JournalEntryService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req := new(entity.JournalEntryReverseRequest)
+		if len(c.Body()) > 0 {
+			if err := c.BodyParser(req); err != nil {
+				return status.New(status.BadRequest, err)
+			}
+			if err := middleware.AppValidator.Validate(req); err != nil {
+				return err
+			}
+		}
+		result, err := service.Reverse(c.Context(), c.Params("id"), req)
+		if err != nil {
+			return err
+		}
+		return c.Status(fiber.StatusCreated).JSON(result)
+	}
+}
@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	"github.com/raymondsugiarto/coffee-api/pkg/module/margin"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/response/status"
+)
+
+// GetMarginReport powers GET /api/report/margin.
+func GetMarginReport(service margin.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req := new(entity.MarginReportRequest)
+		if err := c.QueryParser(req); err != nil {
+			return status.New(status.BadRequest, err)
+		}
+		result, err := service.Report(c.Context(), req)
+		if err != nil {
+			return err
+		}
+		return c.JSON(result)
+	}
+}
@@ -1,6 +1,10 @@
 package handlers
 
 import (
+	"bytes"
+	"fmt"
+	"strings"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/raymondsugiarto/coffee-api/pkg/entity"
 	"github.com/raymondsugiarto/coffee-api/pkg/infrastructure/middleware"
@@ -73,3 +77,128 @@ func FindOnePayroll(service payroll.Service) fiber.Handler {
 		return c.JSON(result)
 	}
 }
+
+// SimulatePayrollBatch powers POST /api/payroll/batch/simulate.
+// Runs the single-driver simulation for every driver of the company.
+func SimulatePayrollBatch(service payroll.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req := new(entity.PayrollBatchRequest)
+		if err := c.BodyParser(req); err != nil {
+			return status.New(status.BadRequest, err)
+		}
+		if err := middleware.AppValidator.Validate(req); err != nil {
+			return err
+		}
+		result, err := service.SimulateBatch(c.Context(), req)
+		if err != nil {
+			return err
+		}
+		return c.JSON(result)
+	}
+}
+
+// SavePayrollBatch powers POST /api/payroll/batch. Either every run
+// of the batch is saved or none is.
+func SavePayrollBatch(service payroll.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req := new(entity.PayrollBatchRequest)
+		if err := c.BodyParser(req); err != nil {
+			return status.New(status.BadRequest, err)
+		}
+		if err := middleware.AppValidator.Validate(req); err != nil {
+			return err
+		}
+		result, err := service.SaveBatch(c.Context(), req)
+		if err != nil {
+			return err
+		}
+		return c.Status(fiber.StatusCreated).JSON(result)
+	}
+}
+
+// GetPayrollBatch powers GET /api/payroll/batch/:id.
+func GetPayrollBatch(service payroll.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		result, err := service.GetBatch(c.Context(), c.Params("id"))
+		if err != nil {
+			return err
+		}
+		return c.JSON(result)
+	}
+}
+
+// GetPayslipPDF powers GET /api/payroll/:id/payslip.pdf.
+func GetPayslipPDF(service payroll.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id := c.Params("id")
+		pdfBytes, err := service.PayslipPDF(c.Context(), id)
+		if err != nil {
+			return err
+		}
+		c.Set("Content-Type", "application/pdf")
+		c.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="slip_gaji_%s.pdf"`, id))
+		return c.SendStream(bytes.NewReader(pdfBytes))
+	}
+}
+
+// ExportPayrollDisbursement powers POST /api/payroll/disbursement:
+// the bank bulk-transfer CSV paying the selected runs.
+func ExportPayrollDisbursement(service payroll.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req := new(entity.PayrollDisbursementRequest)
+		if err := c.BodyParser(req); err != nil {
+			return status.New(status.BadRequest, err)
+		}
+		if err := middleware.AppValidator.Validate(req); err != nil {
+			return err
+		}
+		csvBytes, err := service.DisbursementCSV(c.Context(), req)
+		if err != nil {
+			return err
+		}
+		c.Set("Content-Type", "text/csv")
+		c.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="transfer_gaji_%s_%s.csv"`, strings.ToLower(req.Format), req.TransferDate))
+		return c.SendStream(bytes.NewReader(csvBytes))
+	}
+}
+
+// ApprovePayroll powers POST /api/payroll/:id/approve.
+func ApprovePayroll(service payroll.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		result, err := service.Approve(c.Context(), c.Params("id"))
+		if err != nil {
+			return err
+		}
+		return c.JSON(result)
+	}
+}
+
+// PayPayroll powers POST /api/payroll/:id/pay.
+func PayPayroll(service payroll.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		result, err := service.Pay(c.Context(), c.Params("id"))
+		if err != nil {
+			return err
+		}
+		return c.JSON(result)
+	}
+}
+
+// VoidPayroll powers POST /api/payroll/:id/void. A reason is
+// required; it lands on the run and on the reversal entries.
+func VoidPayroll(service payroll.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req := new(entity.PayrollVoidRequest)
+		if err := c.BodyParser(req); err != nil {
+			return status.New(status.BadRequest, err)
+		}
+		if err := middleware.AppValidator.Validate(req); err != nil {
+			return err
+		}
+		result, err := service.Void(c.Context(), c.Params("id"), req)
+		if err != nil {
+			return err
+		}
+		return c.JSON(result)
+	}
+}
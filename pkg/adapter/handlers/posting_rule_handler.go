@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	"github.com/raymondsugiarto/coffee-api/pkg/infrastructure/middleware"
+	accounting "github.com/raymondsugiarto/coffee-api/pkg/module/accounting"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/response/status"
+)
+
+// FindAllPostingRules powers GET /api/accounting/posting-rules.
+func FindAllPostingRules(service accounting.PostingRuleService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req := new(entity.PostingRuleFindAllRequest)
+		if err := c.QueryParser(req); err != nil {
+			return status.New(status.BadRequest, err)
+		}
+		result, err := service.FindAll(c.Context(), req)
+		if err != nil {
+			return err
+		}
+		return c.JSON(result)
+	}
+}
+
+// FindOnePostingRule powers GET /api/accounting/posting-rules/:id.
+func FindOnePostingRule(service accounting.PostingRuleService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id := c.Params("id")
+		result, err := service.Get(c.Context(), id)
+		if err != nil {
+			return err
+		}
+		return c.JSON(result)
+	}
+}
+
+// CreatePostingRule powers POST /api/accounting/posting-rules.
+func CreatePostingRule(service accounting.PostingRuleService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req := new(entity.PostingRuleDto)
+		if err := c.BodyParser(req); err != nil {
+			return status.New(status.BadRequest, err)
+		}
+		if err := middleware.AppValidator.Validate(req); err != nil {
+			return err
+		}
+		result, err := service.Create(c.Context(), req)
+		if err != nil {
+			return err
+		}
+		return c.Status(fiber.StatusCreated).JSON(result)
+	}
+}
+
+// UpdatePostingRule powers PUT /api/accounting/posting-rules/:id.
+func UpdatePostingRule(service accounting.PostingRuleService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id := c.Params("id")
+		req := new(entity.PostingRuleDto)
+		if err := c.BodyParser(req); err != nil {
+			return status.New(status.BadRequest, err)
+		}
+		if err := middleware.AppValidator.Validate(req); err != nil {
+			return err
+		}
+		req.ID = id
+		result, err := service.Update(c.Context(), req)
+		if err != nil {
+			return err
+		}
+		return c.JSON(result)
+	}
+}
+
+// DeletePostingRule powers DELETE /api/accounting/posting-rules/:id.
+func DeletePostingRule(service accounting.PostingRuleService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id := c.Params("id")
+		if err := service.Delete(c.Context(), id); err != nil {
+			return err
+		}
+		return c.JSON(fiber.Map{"deleted": id})
+	}
+}
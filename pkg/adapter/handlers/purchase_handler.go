@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	"github.com/raymondsugiarto/coffee-api/pkg/infrastructure/middleware"
+	"github.com/raymondsugiarto/coffee-api/pkg/module/purchase"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/response/status"
+)
+
+// FindAllPurchaseOrders powers GET /api/purchase-orders.
+func FindAllPurchaseOrders(service purchase.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req := new(entity.PurchaseOrderFindAllRequest)
+		if err := c.QueryParser(req); err != nil {
+			return status.New(status.BadRequest, err)
+		}
+		result, err := service.FindOrders(c.Context(), req)
+		if err != nil {
+			return err
+		}
+		return c.JSON(result)
+	}
+}
+
+// FindOnePurchaseOrder powers GET /api/purchase-orders/:id.
+func FindOnePurchaseOrder(service purchase.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		result, err := service.GetOrder(c.Context(), c.Params("id"))
+		if err != nil {
+			return err
+		}
+		return c.JSON(result)
+	}
+}
+
+// CreatePurchaseOrder powers POST /api/purchase-orders.
+func CreatePurchaseOrder(service purchase.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req := new(entity.PurchaseOrderDto)
+		if err := c.BodyParser(req); err != nil {
+			return status.New(status.BadRequest, err)
+		}
+		if err := middleware.AppValidator.Validate(req); err != nil {
+			return err
+		}
+		result, err := service.CreateOrder(c.Context(), req)
+		if err != nil {
+			return err
+		}
+		return c.Status(fiber.StatusCreated).JSON(result)
+	}
+}
+
+// UpdatePurchaseOrder powers PUT /api/purchase-orders/:id.
+func UpdatePurchaseOrder(service purchase.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req := new(entity.PurchaseOrderDto)
+		if err := c.BodyParser(req); err != nil {
+			return status.New(status.BadRequest, err)
+		}
+		if err := middleware.AppValidator.Validate(req); err != nil {
+			return err
+		}
+		req.ID = c.Params("id")
+		result, err := service.UpdateOrder(c.Context(), req)
+		if err != nil {
+			return err
+		}
+		return c.JSON(result)
+	}
+}
+
+// CancelPurchaseOrder powers POST /api/purchase-orders/:id/cancel.
+func CancelPurchaseOrder(service purchase.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		result, err := service.CancelOrder(c.Context(), c.Params("id"))
+		if err != nil {
+			return err
+		}
+		return c.JSON(result)
+	}
+}
+
+// FindAllGoodsReceipts powers GET /api/goods-receipts.
+func FindAllGoodsReceipts(service purchase.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req := new(entity.GoodsReceiptFindAllRequest)
+		if err := c.QueryParser(req); err != nil {
+			return status.New(status.BadRequest, err)
+		}
+		result, err := service.FindReceipts(c.Context(), req)
+		if err != nil {
+			return err
+		}
+		return c.JSON(result)
+	}
+}
+
+// FindOneGoodsReceipt powers GET /api/goods-receipts/:id.
+func FindOneGoodsReceipt(service purchase.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		result, err := service.GetReceipt(c.Context(), c.Params("id"))
+		if err != nil {
+			return err
+		}
+		return c.JSON(result)
+	}
+}
+
+// CreateGoodsReceipt powers POST /api/goods-receipts.
+func CreateGoodsReceipt(service purchase.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req := new(entity.GoodsReceiptRequest)
+		if err := c.BodyParser(req); err != nil {
+			return status.New(status.BadRequest, err)
+		}
+		if err := middleware.AppValidator.Validate(req); err != nil {
+			return err
+		}
+		result, err := service.Receive(c.Context(), req)
+		if err != nil {
+			return err
+		}
+		return c.Status(fiber.StatusCreated).JSON(result)
+	}
+}
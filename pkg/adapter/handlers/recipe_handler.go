@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	"github.com/raymondsugiarto/coffee-api/pkg/infrastructure/middleware"
+	"github.com/raymondsugiarto/coffee-api/pkg/module/recipe"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/response/status"
+)
+
+// FindAllRecipes powers GET /api/recipes.
+func FindAllRecipes(service recipe.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req := new(entity.RecipeFindAllRequest)
+		if err := c.QueryParser(req); err != nil {
+			return status.New(status.BadRequest, err)
+		}
+		result, err := service.FindAll(c.Context(), req)
+		if err != nil {
+			return err
+		}
+		return c.JSON(result)
+	}
+}
+
+// FindOneRecipe powers GET /api/recipes/:id.
+func FindOneRecipe(service recipe.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		result, err := service.Get(c.Context(), c.Params("id"))
+		if err != nil {
+			return err
+		}
+		return c.JSON(result)
+	}
+}
+
+// CreateRecipe powers POST /api/recipes.
+func CreateRecipe(service recipe.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req := new(entity.RecipeDto)
+		if err := c.BodyParser(req); err != nil {
+			return status.New(status.BadRequest, err)
+		}
+		if err := middleware.AppValidator.Validate(req); err != nil {
+			return err
+		}
+		result, err := service.Create(c.Context(), req)
+		if err != nil {
+			return err
+		}
+		return c.Status(fiber.StatusCreated).JSON(result)
+	}
+}
+
+// UpdateRecipe powers PUT /api/recipes/:id.
+func UpdateRecipe(service recipe.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req := new(entity.RecipeDto)
+		if err := c.BodyParser(req); err != nil {
+			return status.New(status.BadRequest, err)
+		}
+		req.ID = c.Params("id")
+		if err := middleware.AppValidator.Validate(req); err != nil {
+			return err
+		}
+		result, err := service.Update(c.Context(), req)
+		if err != nil {
+			return err
+		}
+		return c.JSON(result)
+	}
+}
+
+// DeleteRecipe powers DELETE /api/recipes/:id.
+func DeleteRecipe(service recipe.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id := c.Params("id")
+		if err := service.Delete(c.Context(), id); err != nil {
+			return err
+		}
+		return c.JSON(fiber.Map{"deleted": id})
+	}
+}
+
+// GetStockSessionIngredientUsage powers
+// GET /api/stock-session/:id/ingredient-usage.
+func GetStockSessionIngredientUsage(service recipe.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		result, err := service.FindSessionUsage(c.Context(), c.Params("id"))
+		if err != nil {
+			return err
+		}
+		return c.JSON(result)
+	}
+}
+
+// GetIngredientUsageReport powers
+// GET /api/report/ingredient-usage?from=YYYY-MM-DD&to=YYYY-MM-DD.
+func GetIngredientUsageReport(service recipe.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		result, err := service.UsageReport(c.Context(), c.Query("from"), c.Query("to"))
+		if err != nil {
+			return err
+		}
+		return c.JSON(result)
+	}
+}
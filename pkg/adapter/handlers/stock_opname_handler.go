@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	"github.com/raymondsugiarto/coffee-api/pkg/infrastructure/middleware"
+	stockopname "github.com/raymondsugiarto/coffee-api/pkg/module/stock_opname"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/response/status"
+)
+
+// FindAllStockOpnames powers GET /api/stock-opnames.
+func FindAllStockOpnames(service stockopname.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req := new(entity.StockOpnameFindAllRequest)
+		if err := c.QueryParser(req); err != nil {
+			return status.New(status.BadRequest, err)
+		}
+		result, err := service.FindAll(c.Context(), req)
+		if err != nil {
+			return err
+		}
+		return c.JSON(result)
+	}
+}
+
+// FindOneStockOpname powers GET /api/stock-opnames/:id.
+func FindOneStockOpname(service stockopname.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		result, err := service.Get(c.Context(), c.Params("id"))
+		if err != nil {
+			return err
+		}
+		return c.JSON(result)
+	}
+}
+
+// StartStockOpname powers POST /api/stock-opnames. The body is
+// optional; without one every active item is counted today.
+func StartStockOpname(service stockopname.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req := new(entity.StockOpnameCreateRequest)
+		if len(c.Body()) > 0 {
+			if err := c.BodyParser(req); err != nil {
+				return status.New(status.BadRequest, err)
+			}
+		}
+		if err := middleware.AppValidator.Validate(req); err != nil {
+			return err
+		}
+		result, err := service.Start(c.Context(), req)
+		if err != nil {
+			return err
+		}
+		return c.Status(fiber.StatusCreated).JSON(result)
+	}
+}
+
+// CountStockOpname powers PUT /api/stock-opnames/:id/counts.
+func CountStockOpname(service stockopname.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req := new(entity.StockOpnameCountRequest)
+		if err := c.BodyParser(req); err != nil {
+			return status.New(status.BadRequest, err)
+		}
+		if err := middleware.AppValidator.Validate(req); err != nil {
+			return err
+		}
+		result, err := service.Count(c.Context(), c.Params("id"), req)
+		if err != nil {
+			return err
+		}
+		return c.JSON(result)
+	}
+}
+
+// GetStockOpnameVariances powers GET /api/stock-opnames/:id/variances.
+func GetStockOpnameVariances(service stockopname.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		result, err := service.Variances(c.Context(), c.Params("id"))
+		if err != nil {
+			return err
+		}
+		return c.JSON(result)
+	}
+}
+
+// ApproveStockOpname powers POST /api/stock-opnames/:id/approve.
+func ApproveStockOpname(service stockopname.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		result, err := service.Approve(c.Context(), c.Params("id"))
+		if err != nil {
+			return err
+		}
+		return c.JSON(result)
+	}
+}
+
+// CancelStockOpname powers POST /api/stock-opnames/:id/cancel.
+func CancelStockOpname(service stockopname.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		result, err := service.Cancel(c.Context(), c.Params("id"))
+		if err != nil {
+			return err
+		}
+		return c.JSON(result)
+	}
+}
@@ -106,6 +106,7 @@ func OpenStockSession(service stocksession.Service) fiber.Handler {
 		dto := &entity.StockSessionDto{
 			EmployeeID: req.EmployeeID,
 			Date:       req.Date,
+			Shift:      req.Shift,
 			Notes:      req.Notes,
 		}
 		for _, it := range req.Items {
@@ -154,17 +155,24 @@ func FindAllStockSessions(service stocksession.Service) fiber.Handler {
 	}
 }
 
+// GetTodayStockSession returns the driver's session for a day. The
+// optional `shift` query picks MORNING or EVENING; without it the
+// day's unclosed (OPEN or PENDING_REVIEW) session wins.
 func GetTodayStockSession(service stocksession.Service) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		employeeID := c.Query("employeeId")
 		date := c.Query("date")
+		shift := strings.ToUpper(c.Query("shift"))
 		if date == "" {
 			date = time.Now().Format("2006-01-02")
 		}
 		if employeeID == "" {
 			return status.New(status.BadRequest, errors.New("employeeId is required"))
 		}
-		result, err := service.GetByEmployeeDate(c.Context(), employeeID, date)
+		if shift != "" && shift != entity.StockSessionShiftMorning && shift != entity.StockSessionShiftEvening {
+			return status.New(status.BadRequest, errors.New("shift must be MORNING or EVENING"))
+		}
+		result, err := service.GetByEmployeeDate(c.Context(), employeeID, date, shift)
 		if err != nil {
 			return err
 		}
@@ -188,6 +196,7 @@ func UpdateStockSession(service stocksession.Service) fiber.Handler {
 			ID:         id,
 			EmployeeID: req.EmployeeID,
 			Date:       req.Date,
+			Shift:      req.Shift,
 			Notes:      req.Notes,
 		}
 		for _, it := range req.Items {
@@ -265,6 +274,80 @@ func CloseStockSession(service stocksession.Service) fiber.Handler {
 	}
 }
 
+// ============ Carry-over (MORNING -> EVENING) ============
+
+// CarryOverStockSession powers POST /api/stock-session/:id/carry-over.
+// The body is optional.
+func CarryOverStockSession(service stocksession.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id := c.Params("id")
+		req := new(entity.CarryOverStockSessionInputDto)
+		if len(c.Body()) > 0 {
+			if err := c.BodyParser(req); err != nil {
+				return status.New(status.BadRequest, err)
+			}
+			if err := middleware.AppValidator.Validate(req); err != nil {
+				return err
+			}
+		}
+
+		actorID := ""
+		if userCred := shared.GetUserCredential(c.Context()); userCred != nil {
+			actorID = userCred.AdminID
+		}
+
+		result, err := service.CarryOver(c.Context(), id, req, actorID)
+		if err != nil {
+			return err
+		}
+		return c.Status(fiber.StatusCreated).JSON(result)
+	}
+}
+
+// ============ Audit trail ============
+
+// FindStockSessionLogs powers GET /api/stock-session/:id/logs.
+func FindStockSessionLogs(service stocksession.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		result, err := service.FindLogs(c.Context(), c.Params("id"))
+		if err != nil {
+			return err
+		}
+		return c.JSON(result)
+	}
+}
+
+// ============ Settings ============
+
+// GetStockSessionSettings powers GET /api/stock-session/settings.
+func GetStockSessionSettings(service stocksession.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		result, err := service.GetSettings(c.Context())
+		if err != nil {
+			return err
+		}
+		return c.JSON(result)
+	}
+}
+
+// UpdateStockSessionSettings powers PUT /api/stock-session/settings.
+func UpdateStockSessionSettings(service stocksession.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req := new(entity.StockSessionSettingsDto)
+		if err := c.BodyParser(req); err != nil {
+			return status.New(status.BadRequest, err)
+		}
+		if err := middleware.AppValidator.Validate(req); err != nil {
+			return err
+		}
+		result, err := service.UpdateSettings(c.Context(), req)
+		if err != nil {
+			return err
+		}
+		return c.JSON(result)
+	}
+}
+
 // ============ Reports / Dashboard ============
 
 func GetDashboard(service stocksession.Service) fiber.Handler {
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	"github.com/raymondsugiarto/coffee-api/pkg/infrastructure/middleware"
+	"github.com/raymondsugiarto/coffee-api/pkg/module/supplier"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/response/status"
+)
+
+// FindAllSuppliers powers GET /api/suppliers.
+func FindAllSuppliers(service supplier.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req := new(entity.SupplierFindAllRequest)
+		if err := c.QueryParser(req); err != nil {
+			return status.New(status.BadRequest, err)
+		}
+		result, err := service.FindAll(c.Context(), req)
+		if err != nil {
+			return err
+		}
+		return c.JSON(result)
+	}
+}
+
+// FindOneSupplier powers GET /api/suppliers/:id.
+func FindOneSupplier(service supplier.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		result, err := service.Get(c.Context(), c.Params("id"))
+		if err != nil {
+			return err
+		}
+		return c.JSON(result)
+	}
+}
+
+// CreateSupplier powers POST /api/suppliers.
+func CreateSupplier(service supplier.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req := new(entity.SupplierDto)
+		if err := c.BodyParser(req); err != nil {
+			return status.New(status.BadRequest, err)
+		}
+		if err := middleware.AppValidator.Validate(req); err != nil {
+			return err
+		}
+		result, err := service.Create(c.Context(), req)
+		if err != nil {
+			return err
+		}
+		return c.Status(fiber.StatusCreated).JSON(result)
+	}
+}
+
+// UpdateSupplier powers PUT /api/suppliers/:id.
+func UpdateSupplier(service supplier.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req := new(entity.SupplierDto)
+		if err := c.BodyParser(req); err != nil {
+			return status.New(status.BadRequest, err)
+		}
+		if err := middleware.AppValidator.Validate(req); err != nil {
+			return err
+		}
+		req.ID = c.Params("id")
+		result, err := service.Update(c.Context(), req)
+		if err != nil {
+			return err
+		}
+		return c.JSON(result)
+	}
+}
+
+// DeleteSupplier powers DELETE /api/suppliers/:id.
+func DeleteSupplier(service supplier.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id := c.Params("id")
+		if err := service.Delete(c.Context(), id); err != nil {
+			return err
+		}
+		return c.JSON(fiber.Map{"deleted": id})
+	}
+}
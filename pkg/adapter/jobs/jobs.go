@@ -0,0 +1,27 @@
+package jobs
+
+import (
+	"errors"
+
+	"github.com/raymondsugiarto/coffee-api/config"
+	"github.com/raymondsugiarto/coffee-api/pkg/adapter/services"
+	"github.com/raymondsugiarto/coffee-api/pkg/infrastructure/database"
+	"github.com/raymondsugiarto/coffee-api/pkg/infrastructure/notifier"
+	"github.com/raymondsugiarto/coffee-api/pkg/infrastructure/scheduler"
+)
+
+// InitJobs wires the background jobs onto s, the way InitRouter
+// wires the HTTP handlers and on the same service graph, so
+// auto-closing a stale session posts the ledger and returns the
+// stock like a manual close. Each job's crontab comes from cron.yml;
+// an empty one leaves the job off.
+func InitJobs(s *scheduler.Scheduler) error {
+	cfg := config.GetConfig()
+	alerts := notifier.New(cfg)
+	svc := services.New(database.DBConn)
+
+	return errors.Join(
+		s.Register("low-stock-alert", cfg.Cron.LowStock, LowStockAlert(svc.Inventory, svc.Admin, alerts)),
+		s.Register("stale-stock-session", cfg.Cron.StaleSession, StaleStockSessions(svc.StockSession, cfg.Cron.StaleSessionCutoff)),
+	)
+}
@@ -0,0 +1,59 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	"github.com/raymondsugiarto/coffee-api/pkg/infrastructure/notifier"
+	"github.com/raymondsugiarto/coffee-api/pkg/infrastructure/scheduler"
+	"github.com/raymondsugiarto/coffee-api/pkg/module/admin"
+	"github.com/raymondsugiarto/coffee-api/pkg/module/inventory"
+)
+
+// LowStockAlert sends one alert per organization, to that
+// organization's admins, listing the depot items at or below their
+// reorder point. A failed alert does not stop the others; the run
+// reports how many failed.
+func LowStockAlert(inventoryService inventory.Service, adminService admin.Service, alerts notifier.Notifier) scheduler.Job {
+	return func(ctx context.Context) error {
+		groups, err := inventoryService.FindLowStock(ctx)
+		if err != nil {
+			return err
+		}
+		failed := 0
+		for _, g := range groups {
+			recipients, err := adminService.FindAlertRecipients(ctx, g.OrganizationID)
+			if err == nil {
+				err = alerts.Notify(ctx, lowStockMessage(g, recipients))
+			}
+			if err != nil {
+				log.WithContext(ctx).Errorf("[low-stock-alert] organization=%s: %v", g.OrganizationID, err)
+				failed++
+			}
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d of %d low-stock alerts failed", failed, len(groups))
+		}
+		return nil
+	}
+}
+
+func lowStockMessage(g *entity.LowStockDto, recipients []string) *notifier.Message {
+	org := g.OrganizationName
+	if org == "" {
+		org = g.OrganizationID
+	}
+	var body strings.Builder
+	fmt.Fprintf(&body, "%d item(s) at %s are at or below their reorder point:\n\n", len(g.Items), org)
+	for _, it := range g.Items {
+		fmt.Fprintf(&body, "- %s %s: on hand %d, reorder point %d\n", it.ItemCode, it.ItemName, it.OnHand, it.ReorderPoint)
+	}
+	return &notifier.Message{
+		Recipients: recipients,
+		Subject:    fmt.Sprintf("Low stock at %s: %d item(s)", org, len(g.Items)),
+		Body:       body.String(),
+	}
+}
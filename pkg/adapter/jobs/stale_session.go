@@ -0,0 +1,27 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/raymondsugiarto/coffee-api/pkg/infrastructure/scheduler"
+	stocksession "github.com/raymondsugiarto/coffee-api/pkg/module/stock_session"
+)
+
+// StaleStockSessions closes or flags the stock sessions still OPEN
+// past cutoff (HH:MM, Asia/Jakarta) of their date, per each
+// organization's policy.
+func StaleStockSessions(stockSessionService stocksession.Service, cutoff string) scheduler.Job {
+	return func(ctx context.Context) error {
+		result, err := stockSessionService.ResolveStale(ctx, cutoff, time.Now())
+		if err != nil {
+			return err
+		}
+		log.WithContext(ctx).Infof(
+			"[stale-stock-session] closed=%d flagged=%d failed=%d",
+			result.Closed, result.Flagged, result.Failed,
+		)
+		return nil
+	}
+}
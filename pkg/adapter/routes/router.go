@@ -4,129 +4,68 @@ import (
 	"github.com/gofiber/fiber/v2/middleware/logger"
 	handlers "github.com/raymondsugiarto/coffee-api/pkg/adapter/handlers"
 	ha "github.com/raymondsugiarto/coffee-api/pkg/adapter/handlers/authentication"
+	"github.com/raymondsugiarto/coffee-api/pkg/adapter/services"
 	"github.com/raymondsugiarto/coffee-api/pkg/infrastructure/database"
 	"github.com/raymondsugiarto/coffee-api/pkg/infrastructure/middleware"
 	"github.com/raymondsugiarto/coffee-api/pkg/infrastructure/middleware/organization"
 	accounting "github.com/raymondsugiarto/coffee-api/pkg/module/accounting"
-	"github.com/raymondsugiarto/coffee-api/pkg/module/admin"
+	"github.com/raymondsugiarto/coffee-api/pkg/module/attendance"
 	"github.com/raymondsugiarto/coffee-api/pkg/module/authentication"
-	"github.com/raymondsugiarto/coffee-api/pkg/module/authentication/token"
 	cashdebt "github.com/raymondsugiarto/coffee-api/pkg/module/cash_debt"
+	commissionplan "github.com/raymondsugiarto/coffee-api/pkg/module/commission_plan"
 	"github.com/raymondsugiarto/coffee-api/pkg/module/company"
 	"github.com/raymondsugiarto/coffee-api/pkg/module/driver"
+	"github.com/raymondsugiarto/coffee-api/pkg/module/inventory"
 	"github.com/raymondsugiarto/coffee-api/pkg/module/item"
 	itemcategory "github.com/raymondsugiarto/coffee-api/pkg/module/item_category"
+	"github.com/raymondsugiarto/coffee-api/pkg/module/margin"
 	"github.com/raymondsugiarto/coffee-api/pkg/module/order"
 	orderitem "github.com/raymondsugiarto/coffee-api/pkg/module/order/order_item"
 	"github.com/raymondsugiarto/coffee-api/pkg/module/payroll"
+	"github.com/raymondsugiarto/coffee-api/pkg/module/purchase"
+	"github.com/raymondsugiarto/coffee-api/pkg/module/recipe"
 	salarycomponent "github.com/raymondsugiarto/coffee-api/pkg/module/salary_component"
+	stockopname "github.com/raymondsugiarto/coffee-api/pkg/module/stock_opname"
 	stocksession "github.com/raymondsugiarto/coffee-api/pkg/module/stock_session"
+	"github.com/raymondsugiarto/coffee-api/pkg/module/supplier"
 	"github.com/raymondsugiarto/coffee-api/pkg/module/user"
-	usercredential "github.com/raymondsugiarto/coffee-api/pkg/module/user-credential"
 
 	"github.com/gofiber/fiber/v2"
 )
 
 func InitRouter(app fiber.Router) {
-	dbConn := database.DBConn
 	app.Use(logger.New(), organization.New(), middleware.DefaultResponseHandler())
-
-	// User Credential
-	userCredentialRepo := usercredential.NewRepository(dbConn)
-	userCredentialService := usercredential.NewService(userCredentialRepo)
-
-	// User
-	userRepo := user.NewRepository(dbConn)
-	userService := user.NewService(userRepo, userCredentialService)
-
-	// Admin
-	adminRepo := admin.NewRepository(dbConn)
-	adminService := admin.NewService(adminRepo)
-
-	tokenService := token.NewService()
-
-	authenticationService := authentication.NewService(
-		userCredentialService, tokenService, adminService,
-	)
-
-	// Item
-	companyRepo := company.NewRepository(dbConn)
-	companyService := company.NewService(companyRepo)
-
-	// Item
-	itemRepo := item.NewRepository(dbConn)
-	itemService := item.NewService(itemRepo, companyService)
-
-	// Item Category
-	itemCategoryRepo := itemcategory.NewRepository(dbConn)
-	itemCategoryService := itemcategory.NewService(itemCategoryRepo)
-
-	// Salary Component
-	salaryComponentRepo := salarycomponent.NewRepository(dbConn)
-	salaryComponentService := salarycomponent.NewService(salaryComponentRepo)
-
-	// Accounting (chart of accounts + ledger)
-	accountRepo := accounting.NewAccountRepository(dbConn)
-	accountService := accounting.NewAccountService(accountRepo)
-	// accountMutationRepo is wired here so any future flow that
-	// wants to post to the ledger can reuse this single instance
-	// instead of constructing a fresh repo per call site. The Go
-	// compiler insists we use every binding, so the mutation
-	// service is built once and stashed in the registry below
-	// for the same reason.
-	accountMutationRepo := accounting.NewAccountMutationRepository(dbConn)
-	accountMutationService := accounting.NewAccountMutationService(accountMutationRepo, accountService)
-	_ = accountMutationService // exposed for future mutation REST routes / inter-module posts
-
-	// Payroll (employee_salary + employee_salary_component)
-	payrollRepo := payroll.NewRepository(dbConn)
-	payrollService := payroll.NewService(payrollRepo)
-
-	// Cash Debt (driver cash advances ledger)
-	cashDebtRepo := cashdebt.NewRepository(dbConn)
-	cashDebtService := cashdebt.NewService(cashDebtRepo)
-
-	// Order
-	orderRepo := order.NewRepository(dbConn)
-	orderService := order.NewService(orderRepo, companyService)
-
-	// Order
-	orderItemRepo := orderitem.NewRepository(dbConn)
-	orderItemService := orderitem.NewService(orderItemRepo, companyService)
-
-	// Driver (employees filtered)
-	driverService := driver.NewService(dbConn)
-
-	// Stock Session (with embedded item picker service). The
-	// salary-resolution path is delegated to the salarycomponent
-	// module rather than poking salary_component directly with
-	// GORM — keeps the SQL behind its module boundary.
-	stockSessionRepo := stocksession.NewRepository(dbConn)
-	stockSessionService := stocksession.NewService(
-		stockSessionRepo,
-		dbConn,
-		salaryComponentService,
-	)
-	stockSessionItemService := stocksession.NewItemService(dbConn)
+	svc := services.New(database.DBConn)
 
 	// Middleware
 	// api := app.Group("/api", middleware.Protected())
 	auth := app.Group("/api/auth")
-	AuthRouter(auth, userService, authenticationService)
+	AuthRouter(auth, svc.User, svc.Authentication)
 
 	api := app.Group("/api/", middleware.Protected())
-	ItemRouter(api, itemService)
-	ItemCategoryRouter(api, itemCategoryService)
-	SalaryComponentRouter(api, salaryComponentService)
-	AccountRouter(api, accountService)
-	PayrollRouter(api, payrollService)
-	CashDebtRouter(api, cashDebtService)
-	CompanyRouter(api, companyService)
-	OrderRouter(api, orderService)
-	OrderItemRouter(api, orderItemService)
-	ProductRouter(api, stockSessionItemService)
-	DriverRouter(api, driverService)
-	StockSessionRouter(api, stockSessionService, stockSessionItemService)
+	ItemRouter(api, svc.Item)
+	ItemCategoryRouter(api, svc.ItemCategory)
+	SalaryComponentRouter(api, svc.SalaryComponent)
+	CommissionPlanRouter(api, svc.CommissionPlan)
+	AccountRouter(api, svc.Account)
+	PostingRuleRouter(api, svc.PostingRule)
+	JournalEntryRouter(api, svc.JournalEntry)
+	AccountingPeriodRouter(api, svc.Period)
+	AccountingReportRouter(api, svc.AccountingReport, svc.AccountMutation)
+	PayrollRouter(api, svc.Payroll)
+	CashDebtRouter(api, svc.CashDebt)
+	AttendanceRouter(api, svc.Attendance)
+	CompanyRouter(api, svc.Company)
+	OrderRouter(api, svc.Order)
+	OrderItemRouter(api, svc.OrderItem)
+	ProductRouter(api, svc.StockSessionItem)
+	DriverRouter(api, svc.Driver)
+	StockSessionRouter(api, svc.StockSession, svc.StockSessionItem)
+	MarginRouter(api, svc.Margin)
+	InventoryRouter(api, svc.Inventory)
+	PurchaseRouter(api, svc.Supplier, svc.Purchase)
+	StockOpnameRouter(api, svc.StockOpname)
+	RecipeRouter(api, svc.Recipe)
 }
 
 func AuthRouter(app fiber.Router,
@@ -166,6 +105,18 @@ func SalaryComponentRouter(app fiber.Router,
 	app.Delete("/salary-components/:id", handlers.DeleteSalaryComponent(salaryComponentService))
 }
 
+// CommissionPlanRouter wires the commission plan CRUD. Plans only
+// affect sessions closed after the change.
+func CommissionPlanRouter(app fiber.Router,
+	commissionPlanService commissionplan.Service,
+) {
+	app.Get("/commission-plans", handlers.FindAllCommissionPlans(commissionPlanService))
+	app.Get("/commission-plans/:id", handlers.FindOneCommissionPlan(commissionPlanService))
+	app.Post("/commission-plans", handlers.CreateCommissionPlan(commissionPlanService))
+	app.Put("/commission-plans/:id", handlers.UpdateCommissionPlan(commissionPlanService))
+	app.Delete("/commission-plans/:id", handlers.DeleteCommissionPlan(commissionPlanService))
+}
+
 // AccountRouter exposes the chart-of-accounts CRUD on top of the
 // accounting service. Mutations post against the ledger via
 // AccountMutationService from upstream flows; this router only
@@ -180,6 +131,58 @@ func AccountRouter(app fiber.Router,
 	app.Delete("/accounts/:id", handlers.DeleteAccount(accountService))
 }
 
+// PostingRuleRouter exposes the posting-rule CRUD: which debit /
+// credit account codes each business event amount posts to.
+func PostingRuleRouter(app fiber.Router,
+	postingRuleService accounting.PostingRuleService,
+) {
+	app.Get("/accounting/posting-rules", handlers.FindAllPostingRules(postingRuleService))
+	app.Get("/accounting/posting-rules/:id", handlers.FindOnePostingRule(postingRuleService))
+	app.Post("/accounting/posting-rules", handlers.CreatePostingRule(postingRuleService))
+	app.Put("/accounting/posting-rules/:id", handlers.UpdatePostingRule(postingRuleService))
+	app.Delete("/accounting/posting-rules/:id", handlers.DeletePostingRule(postingRuleService))
+}
+
+// JournalEntryRouter exposes manual journal entries and reversals.
+// There is no PUT / DELETE: the ledger is append-only, so a wrong
+// entry is corrected by reversing it.
+func JournalEntryRouter(app fiber.Router,
+	journalEntryService accounting.JournalEntryService,
+) {
+	app.Get("/journal-entries", handlers.FindAllJournalEntries(journalEntryService))
+	app.Get("/journal-entries/:id", handlers.FindOneJournalEntry(journalEntryService))
+	app.Post("/journal-entries", handlers.CreateJournalEntry(journalEntryService))
+	app.Post("/journal-entries/:id/reverse", handlers.ReverseJournalEntry(journalEntryService))
+}
+
+// AccountingPeriodRouter exposes the month close / reopen workflow.
+// Reopen is admin-only; the service enforces it and records every
+// state change in the period's audit trail.
+func AccountingPeriodRouter(app fiber.Router,
+	periodService accounting.PeriodService,
+) {
+	app.Get("/accounting/periods", handlers.FindAllAccountingPeriods(periodService))
+	app.Get("/accounting/periods/:period", handlers.FindOneAccountingPeriod(periodService))
+	app.Get("/accounting/periods/:period/logs", handlers.FindAccountingPeriodLogs(periodService))
+	app.Post("/accounting/periods/:period/close", handlers.CloseAccountingPeriod(periodService))
+	app.Post("/accounting/periods/:period/reopen", handlers.ReopenAccountingPeriod(periodService))
+}
+
+// AccountingReportRouter exposes the read-only views built from the
+// ledger: the raw mutation listing, per-account statements, the
+// trial balance and the financial statements (profit & loss, cash
+// flow).
+func AccountingReportRouter(app fiber.Router,
+	reportService accounting.ReportService,
+	accountMutationService accounting.AccountMutationService,
+) {
+	app.Get("/account-mutations", handlers.FindAllAccountMutations(accountMutationService))
+	app.Get("/accounts/:id/ledger", handlers.GetAccountLedger(reportService))
+	app.Get("/accounting/trial-balance", handlers.GetTrialBalance(reportService))
+	app.Get("/reports/profit-loss", handlers.GetProfitLoss(reportService))
+	app.Get("/reports/cash-flow", handlers.GetCashFlow(reportService))
+}
+
 // CompanyRouter exposes the read-only company list used by the
 // SelectCompany dropdown (and any future admin picker).
 // Write operations are intentionally NOT wired here: the legacy
@@ -193,20 +196,37 @@ func CompanyRouter(app fiber.Router,
 
 // PayrollRouter wires the payroll run lifecycle:
 //
-//	POST /payroll/simulate — read-only preview
-//	POST /payroll          — persist the approved run
-//	GET  /payroll          — list saved runs
-//	GET  /payroll/:id      — one run with components
+//	POST /payroll/simulate        — read-only preview
+//	POST /payroll                 — persist the run as DRAFT
+//	GET  /payroll                 — list saved runs
+//	GET  /payroll/:id             — one run with components
+//	GET  /payroll/:id/payslip.pdf — printable payslip
+//	POST /payroll/:id/approve     — DRAFT -> APPROVED
+//	POST /payroll/:id/pay         — APPROVED -> PAID
+//	POST /payroll/:id/void        — any live state -> VOID
+//
+// plus the per-company batch: simulate every driver bound through
+// admin_company, save them all-or-nothing, and read the summary back;
+// and the bank bulk-transfer file for approved runs.
 func PayrollRouter(app fiber.Router,
 	payrollService payroll.Service,
 ) {
 	app.Post("/payroll/simulate", handlers.SimulatePayroll(payrollService))
 	app.Post("/payroll", handlers.SavePayroll(payrollService))
+	app.Post("/payroll/batch/simulate", handlers.SimulatePayrollBatch(payrollService))
+	app.Post("/payroll/batch", handlers.SavePayrollBatch(payrollService))
+	app.Get("/payroll/batch/:id", handlers.GetPayrollBatch(payrollService))
+	app.Post("/payroll/disbursement", handlers.ExportPayrollDisbursement(payrollService))
 	app.Get("/payroll", handlers.FindAllPayrolls(payrollService))
 	app.Get("/payroll/:id", handlers.FindOnePayroll(payrollService))
+	app.Get("/payroll/:id/payslip.pdf", handlers.GetPayslipPDF(payrollService))
+	app.Post("/payroll/:id/approve", handlers.ApprovePayroll(payrollService))
+	app.Post("/payroll/:id/pay", handlers.PayPayroll(payrollService))
+	app.Post("/payroll/:id/void", handlers.VoidPayroll(payrollService))
 }
 
-// CashDebtRouter wires the driver cash-advance ledger CRUD.
+// CashDebtRouter wires the driver cash-advance ledger CRUD, the
+// repayment endpoint and the per-driver outstanding balance.
 func CashDebtRouter(app fiber.Router,
 	cashDebtService cashdebt.Service,
 ) {
@@ -215,6 +235,21 @@ func CashDebtRouter(app fiber.Router,
 	app.Post("/cash-debts", handlers.CreateCashDebt(cashDebtService))
 	app.Put("/cash-debts/:id", handlers.UpdateCashDebt(cashDebtService))
 	app.Delete("/cash-debts/:id", handlers.DeleteCashDebt(cashDebtService))
+	app.Post("/cash-debts/:id/settle", handlers.SettleCashDebt(cashDebtService))
+	app.Get("/employees/:id/cash-debt-balance", handlers.GetEmployeeCashDebtBalance(cashDebtService))
+}
+
+// AttendanceRouter wires driver clock-in / clock-out and the
+// per-driver days-worked rollup. Records are never edited or
+// deleted: a missed clock-in is recorded after the fact with `at`.
+func AttendanceRouter(app fiber.Router,
+	attendanceService attendance.Service,
+) {
+	app.Get("/attendance", handlers.FindAllAttendances(attendanceService))
+	app.Get("/attendance/:id", handlers.FindOneAttendance(attendanceService))
+	app.Post("/attendance/clock-in", handlers.ClockIn(attendanceService))
+	app.Post("/attendance/:id/clock-out", handlers.ClockOut(attendanceService))
+	app.Get("/employees/:id/attendance-summary", handlers.GetEmployeeAttendanceSummary(attendanceService))
 }
 
 func OrderRouter(app fiber.Router,
@@ -239,16 +274,22 @@ func ProductRouter(app fiber.Router, itemService stocksession.ItemService) {
 
 func DriverRouter(app fiber.Router, driverService driver.Service) {
 	app.Get("/employees", handlers.FindAllDrivers(driverService))
+	app.Get("/employees/:id/bank-account", handlers.GetDriverBankAccount(driverService))
+	app.Put("/employees/:id/bank-account", handlers.UpdateDriverBankAccount(driverService))
 }
 
 func StockSessionRouter(app fiber.Router, ssService stocksession.Service, itemService stocksession.ItemService) {
 	app.Post("/stock-session/open", handlers.OpenStockSession(ssService))
 	app.Get("/stock-session", handlers.FindAllStockSessions(ssService))
 	app.Get("/stock-session/today", handlers.GetTodayStockSession(ssService))
+	app.Get("/stock-session/settings", handlers.GetStockSessionSettings(ssService))
+	app.Put("/stock-session/settings", handlers.UpdateStockSessionSettings(ssService))
 	app.Get("/stock-session/:id", handlers.GetStockSession(ssService))
 	app.Put("/stock-session/:id", handlers.UpdateStockSession(ssService))
 	app.Delete("/stock-session/:id", handlers.DeleteStockSession(ssService))
 	app.Post("/stock-session/:id/close", handlers.CloseStockSession(ssService))
+	app.Post("/stock-session/:id/carry-over", handlers.CarryOverStockSession(ssService))
+	app.Get("/stock-session/:id/logs", handlers.FindStockSessionLogs(ssService))
 
 	// Item picker (reuses existing `item` table)
 	app.Get("/products", handlers.FindAllStockSessionItems(itemService))
@@ -263,3 +304,71 @@ func StockSessionRouter(app fiber.Router, ssService stocksession.Service, itemSe
 	app.Get("/report/top-products", handlers.GetTopProducts(ssService))
 	app.Get("/report/employee-performance", handlers.GetEmployeePerformance(ssService))
 }
+
+// MarginRouter sits next to the stock-session reports under
+// /report; variants are rolled up into their parent item.
+func MarginRouter(app fiber.Router, marginService margin.Service) {
+	app.Get("/report/margin", handlers.GetMarginReport(marginService))
+}
+
+// InventoryRouter exposes the depot stock: on-hand and reorder point
+// per item and the stock ledger. Stock only moves through documents
+// that also post the ledger: goods receipts (/goods-receipts), stock
+// opname (/stock-opname) and stock sessions.
+func InventoryRouter(app fiber.Router, inventoryService inventory.Service) {
+	app.Get("/inventory/stock", handlers.FindAllItemStocks(inventoryService))
+	app.Get("/inventory/stock/:itemId", handlers.GetItemStock(inventoryService))
+	app.Get("/inventory/stock/:itemId/history", handlers.FindAllStockMovements(inventoryService))
+	app.Put("/inventory/stock/:itemId/reorder-point", handlers.SetItemReorderPoint(inventoryService))
+	app.Get("/inventory/movements", handlers.FindAllStockMovements(inventoryService))
+}
+
+// PurchaseRouter exposes supplier master data, purchase orders and
+// the goods receipts that bring ordered (or ad-hoc) goods into the
+// depot.
+func PurchaseRouter(app fiber.Router,
+	supplierService supplier.Service,
+	purchaseService purchase.Service,
+) {
+	app.Get("/suppliers", handlers.FindAllSuppliers(supplierService))
+	app.Get("/suppliers/:id", handlers.FindOneSupplier(supplierService))
+	app.Post("/suppliers", handlers.CreateSupplier(supplierService))
+	app.Put("/suppliers/:id", handlers.UpdateSupplier(supplierService))
+	app.Delete("/suppliers/:id", handlers.DeleteSupplier(supplierService))
+
+	app.Get("/purchase-orders", handlers.FindAllPurchaseOrders(purchaseService))
+	app.Get("/purchase-orders/:id", handlers.FindOnePurchaseOrder(purchaseService))
+	app.Post("/purchase-orders", handlers.CreatePurchaseOrder(purchaseService))
+	app.Put("/purchase-orders/:id", handlers.UpdatePurchaseOrder(purchaseService))
+	app.Post("/purchase-orders/:id/cancel", handlers.CancelPurchaseOrder(purchaseService))
+
+	app.Get("/goods-receipts", handlers.FindAllGoodsReceipts(purchaseService))
+	app.Get("/goods-receipts/:id", handlers.FindOneGoodsReceipt(purchaseService))
+	app.Post("/goods-receipts", handlers.CreateGoodsReceipt(purchaseService))
+}
+
+// StockOpnameRouter exposes the depot's physical counts: start a
+// count, enter the counted quantities, review the variance list and
+// approve (or cancel) it.
+func StockOpnameRouter(app fiber.Router, stockOpnameService stockopname.Service) {
+	app.Get("/stock-opnames", handlers.FindAllStockOpnames(stockOpnameService))
+	app.Get("/stock-opnames/:id", handlers.FindOneStockOpname(stockOpnameService))
+	app.Get("/stock-opnames/:id/variances", handlers.GetStockOpnameVariances(stockOpnameService))
+	app.Post("/stock-opnames", handlers.StartStockOpname(stockOpnameService))
+	app.Put("/stock-opnames/:id/counts", handlers.CountStockOpname(stockOpnameService))
+	app.Post("/stock-opnames/:id/approve", handlers.ApproveStockOpname(stockOpnameService))
+	app.Post("/stock-opnames/:id/cancel", handlers.CancelStockOpname(stockOpnameService))
+}
+
+// RecipeRouter exposes the bills of materials, the ingredient usage
+// a closed session recorded, and the theoretical-vs-actual usage
+// report next to the other /report endpoints.
+func RecipeRouter(app fiber.Router, recipeService recipe.Service) {
+	app.Get("/recipes", handlers.FindAllRecipes(recipeService))
+	app.Get("/recipes/:id", handlers.FindOneRecipe(recipeService))
+	app.Post("/recipes", handlers.CreateRecipe(recipeService))
+	app.Put("/recipes/:id", handlers.UpdateRecipe(recipeService))
+	app.Delete("/recipes/:id", handlers.DeleteRecipe(recipeService))
+	app.Get("/stock-session/:id/ingredient-usage", handlers.GetStockSessionIngredientUsage(recipeService))
+	app.Get("/report/ingredient-usage", handlers.GetIngredientUsageReport(recipeService))
+}
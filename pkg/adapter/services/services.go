@@ -0,0 +1,200 @@
+package services
+
+import (
+	accounting "github.com/raymondsugiarto/coffee-api/pkg/module/accounting"
+	"github.com/raymondsugiarto/coffee-api/pkg/module/admin"
+	"github.com/raymondsugiarto/coffee-api/pkg/module/attendance"
+	"github.com/raymondsugiarto/coffee-api/pkg/module/authentication"
+	"github.com/raymondsugiarto/coffee-api/pkg/module/authentication/token"
+	cashdebt "github.com/raymondsugiarto/coffee-api/pkg/module/cash_debt"
+	commissionplan "github.com/raymondsugiarto/coffee-api/pkg/module/commission_plan"
+	"github.com/raymondsugiarto/coffee-api/pkg/module/company"
+	"github.com/raymondsugiarto/coffee-api/pkg/module/driver"
+	"github.com/raymondsugiarto/coffee-api/pkg/module/inventory"
+	"github.com/raymondsugiarto/coffee-api/pkg/module/item"
+	itemcategory "github.com/raymondsugiarto/coffee-api/pkg/module/item_category"
+	"github.com/raymondsugiarto/coffee-api/pkg/module/margin"
+	"github.com/raymondsugiarto/coffee-api/pkg/module/order"
+	orderitem "github.com/raymondsugiarto/coffee-api/pkg/module/order/order_item"
+	"github.com/raymondsugiarto/coffee-api/pkg/module/payroll"
+	"github.com/raymondsugiarto/coffee-api/pkg/module/purchase"
+	"github.com/raymondsugiarto/coffee-api/pkg/module/recipe"
+	salarycomponent "github.com/raymondsugiarto/coffee-api/pkg/module/salary_component"
+	stockopname "github.com/raymondsugiarto/coffee-api/pkg/module/stock_opname"
+	stocksession "github.com/raymondsugiarto/coffee-api/pkg/module/stock_session"
+	"github.com/raymondsugiarto/coffee-api/pkg/module/supplier"
+	"github.com/raymondsugiarto/coffee-api/pkg/module/user"
+	usercredential "github.com/raymondsugiarto/coffee-api/pkg/module/user-credential"
+	"gorm.io/gorm"
+)
+
+// Services is the application's service graph, shared by the HTTP
+// routes and the background jobs so both run the same wiring.
+type Services struct {
+	UserCredential   usercredential.Service
+	User             user.Service
+	Admin            admin.Service
+	Token            token.Service
+	Authentication   authentication.Service
+	Company          company.Service
+	Item             item.Service
+	ItemCategory     itemcategory.Service
+	SalaryComponent  salarycomponent.Service
+	CommissionPlan   commissionplan.Service
+	Account          accounting.AccountService
+	Period           accounting.PeriodService
+	AccountMutation  accounting.AccountMutationService
+	JournalEntry     accounting.JournalEntryService
+	PostingRule      accounting.PostingRuleService
+	Posting          accounting.PostingService
+	AccountingReport accounting.ReportService
+	CashDebt         cashdebt.Service
+	Attendance       attendance.Service
+	Payroll          payroll.Service
+	Order            order.Service
+	OrderItem        orderitem.Service
+	Driver           driver.Service
+	Inventory        inventory.Service
+	Supplier         supplier.Service
+	Purchase         purchase.Service
+	StockOpname      stockopname.Service
+	Recipe           recipe.Service
+	StockSession     stocksession.Service
+	StockSessionItem stocksession.ItemService
+	Margin           margin.Service
+}
+
+// New builds every service on dbConn.
+func New(dbConn *gorm.DB) *Services {
+	s := &Services{}
+
+	// User Credential
+	s.UserCredential = usercredential.NewService(usercredential.NewRepository(dbConn))
+
+	// User
+	s.User = user.NewService(user.NewRepository(dbConn), s.UserCredential)
+
+	// Admin
+	s.Admin = admin.NewService(admin.NewRepository(dbConn))
+
+	s.Token = token.NewService()
+
+	s.Authentication = authentication.NewService(
+		s.UserCredential, s.Token, s.Admin,
+	)
+
+	// Company
+	s.Company = company.NewService(company.NewRepository(dbConn))
+
+	// Item
+	s.Item = item.NewService(item.NewRepository(dbConn), s.Company)
+
+	// Item Category
+	s.ItemCategory = itemcategory.NewService(itemcategory.NewRepository(dbConn))
+
+	// Salary Component
+	s.SalaryComponent = salarycomponent.NewService(salarycomponent.NewRepository(dbConn))
+
+	// Commission Plan (tiered / progressive / percentage commission,
+	// applied when a stock session closes)
+	s.CommissionPlan = commissionplan.NewService(commissionplan.NewRepository(dbConn))
+
+	// Accounting (chart of accounts + ledger). Period is the
+	// PeriodGuard every dated write path checks before touching a
+	// closed month.
+	s.Account = accounting.NewAccountService(accounting.NewAccountRepository(dbConn))
+	s.Period = accounting.NewPeriodService(accounting.NewPeriodRepository(dbConn), s.Admin)
+	// AccountMutation writes single ledger rows; every multi-leg
+	// posting goes through JournalEntry so it is balanced. Upstream
+	// flows fire events through Posting, which resolves the
+	// accounts from the per-organization posting rules and posts
+	// one journal entry per event.
+	s.AccountMutation = accounting.NewAccountMutationService(
+		accounting.NewAccountMutationRepository(dbConn), s.Account, s.Period,
+	)
+	s.JournalEntry = accounting.NewJournalEntryService(
+		accounting.NewJournalEntryRepository(dbConn), dbConn, s.AccountMutation,
+	)
+	s.PostingRule = accounting.NewPostingRuleService(accounting.NewPostingRuleRepository(dbConn), s.Account)
+	s.Posting = accounting.NewPostingService(s.PostingRule, s.Account, s.JournalEntry)
+	s.AccountingReport = accounting.NewReportService(accounting.NewReportRepository(dbConn), s.Account)
+
+	// Cash Debt (driver cash advances ledger). Advances and
+	// repayments post through the accounting module.
+	s.CashDebt = cashdebt.NewService(cashdebt.NewRepository(dbConn), s.Period, s.Posting, s.JournalEntry)
+
+	// Attendance (driver clock-in / clock-out). Its days worked drive
+	// the ATTENDANCE salary bands.
+	s.Attendance = attendance.NewService(attendance.NewRepository(dbConn))
+
+	// Payroll (employee_salary + employee_salary_component). A run
+	// deducts unpaid cash debt and settles it through CashDebt.
+	s.Payroll = payroll.NewService(
+		payroll.NewRepository(dbConn),
+		s.Period,
+		s.CashDebt,
+		s.Attendance,
+		s.Posting,
+		s.JournalEntry,
+	)
+
+	// Order. A new order posts ORDER_CREATED.
+	s.Order = order.NewService(order.NewRepository(dbConn), s.Company, s.Posting, s.JournalEntry)
+	s.OrderItem = orderitem.NewService(orderitem.NewRepository(dbConn), s.Company)
+
+	// Driver (employees filtered)
+	s.Driver = driver.NewService(dbConn)
+
+	// Inventory (depot item stock ledger). Stock sessions post
+	// their loads and returns through it.
+	s.Inventory = inventory.NewService(inventory.NewRepository(dbConn))
+
+	// Purchasing (suppliers, purchase orders, goods receipts).
+	// Receipts add depot stock and book Persediaan against Hutang
+	// Usaha.
+	s.Supplier = supplier.NewService(supplier.NewRepository(dbConn))
+	s.Purchase = purchase.NewService(
+		purchase.NewRepository(dbConn),
+		s.Supplier,
+		s.Inventory,
+		s.Posting,
+		s.Period,
+	)
+
+	// Stock opname (physical counts). Approval adjusts the depot
+	// and books the shrinkage / surplus at cost price.
+	s.StockOpname = stockopname.NewService(
+		stockopname.NewRepository(dbConn),
+		s.Inventory,
+		s.Posting,
+		s.Period,
+	)
+
+	// Recipes (bill of materials per sellable item). Closing a
+	// session records the theoretical ingredient usage through it.
+	s.Recipe = recipe.NewService(recipe.NewRepository(dbConn), s.Inventory)
+
+	// Stock Session (with embedded item picker service). The
+	// salary-resolution path is delegated to the salarycomponent
+	// module rather than poking salary_component directly with
+	// GORM — keeps the SQL behind its module boundary. Closing a
+	// session posts its double-entry through the accounting module;
+	// every write moves depot stock through Inventory. The
+	// stale-session job auto-closes through the same service.
+	s.StockSession = stocksession.NewService(
+		stocksession.NewRepository(dbConn),
+		dbConn,
+		s.SalaryComponent,
+		s.CommissionPlan,
+		s.Posting,
+		s.Period,
+		s.Inventory,
+		s.Recipe,
+	)
+	s.StockSessionItem = stocksession.NewItemService(dbConn)
+
+	// Margin (revenue vs COGS on closed sessions)
+	s.Margin = margin.NewService(margin.NewRepository(dbConn))
+
+	return s
+}
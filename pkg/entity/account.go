@@ -5,6 +5,63 @@ import (
 	"github.com/raymondsugiarto/coffee-api/pkg/shared/pagination"
 )
 
+// Default chart-of-accounts codes seeded by migrations 000016,
+// 000021, 000022 and 000024 as global (NULL organization_id) rows.
+// Upstream flows that post to the ledger resolve accounts by these
+// codes so an organization can override any of them by creating its
+// own row with the same code.
+const (
+	AccountCodeKas           = "1101"
+	AccountCodeKliringQris   = "1102"
+	AccountCodeBank          = "1103"
+	AccountCodePiutangDriver = "1201"
+	AccountCodePiutangKasBon = "1202"
+	AccountCodePersediaan    = "1301"
+	AccountCodeHutangKomisi  = "2101"
+	AccountCodeHutangGaji    = "2102"
+	AccountCodePenjualan     = "4101"
+	AccountCodeHpp           = "5101"
+	AccountCodeBebanKomisi   = "6101"
+	AccountCodeBebanGaji     = "6102"
+)
+
+// Account types. ASSET and EXPENSE accounts carry a debit normal
+// balance (a positive mutation amount increases them); LIABILITY,
+// EQUITY and REVENUE carry a credit normal balance.
+const (
+	AccountTypeAsset     = "ASSET"
+	AccountTypeLiability = "LIABILITY"
+	AccountTypeEquity    = "EQUITY"
+	AccountTypeRevenue   = "REVENUE"
+	AccountTypeExpense   = "EXPENSE"
+)
+
+// Normal balance sides, as reported by AccountNormalBalance.
+const (
+	NormalBalanceDebit  = "DEBIT"
+	NormalBalanceCredit = "CREDIT"
+)
+
+// AccountNormalBalance returns the side an account of the given
+// type grows on.
+func AccountNormalBalance(accountType string) string {
+	switch accountType {
+	case AccountTypeLiability, AccountTypeEquity, AccountTypeRevenue:
+		return NormalBalanceCredit
+	}
+	return NormalBalanceDebit
+}
+
+// AccountBalance reads a signed ledger sum (positive = debit) as a
+// balance on the account's normal side, so a healthy Kas and a
+// healthy Penjualan both come out positive.
+func AccountBalance(accountType string, signed float64) float64 {
+	if AccountNormalBalance(accountType) == NormalBalanceCredit {
+		return -signed
+	}
+	return signed
+}
+
 // AccountDto is the wire shape used by the chart-of-accounts CRUD.
 //
 // `code` is required and unique-per-organization. `name` is a
@@ -12,11 +69,20 @@ import (
 // `(organizationId, code)` uniqueness is enforced at the DB layer
 // via a UNIQUE index; the service layer surfaces an error so the
 // caller can react.
+//
+// `parentId` is optional; when set it must point at a visible
+// account of the same `type`, and the service rejects cycles.
 type AccountDto struct {
 	ID             string `json:"id"`
 	OrganizationID string `json:"-"`
 	Name           string `json:"name"           validate:"required,min=1,max=255"`
 	Code           string `json:"code"           validate:"required,min=1,max=64"`
+	Type           string `json:"type"           validate:"required,oneof=ASSET LIABILITY EQUITY REVENUE EXPENSE"`
+	ParentID       string `json:"parentId"`
+	// IsCash marks cash and cash-equivalent accounts (Kas, Kliring
+	// QRIS, Bank). The cash flow statement is built from the
+	// movements of these accounts.
+	IsCash bool `json:"isCash"`
 }
 
 func NewAccountDtoFromModel(m *model.Account) *AccountDto {
@@ -28,6 +94,9 @@ func NewAccountDtoFromModel(m *model.Account) *AccountDto {
 		OrganizationID: m.OrganizationID,
 		Name:           m.Name,
 		Code:           m.Code,
+		Type:           m.Type,
+		ParentID:       m.ParentID,
+		IsCash:         m.IsCash,
 	}
 }
 
@@ -36,6 +105,9 @@ func (d *AccountDto) ToModel() *model.Account {
 		OrganizationID: d.OrganizationID,
 		Name:           d.Name,
 		Code:           d.Code,
+		Type:           d.Type,
+		ParentID:       d.ParentID,
+		IsCash:         d.IsCash,
 	}
 	if d.ID != "" {
 		m.ID = d.ID
@@ -49,8 +121,10 @@ func (d *AccountDto) ToModel() *model.Account {
 // as global seed).
 type AccountFindAllRequest struct {
 	FindAllRequest
-	Code string
-	Name string
+	Code     string
+	Name     string
+	Type     string
+	ParentID string
 }
 
 func (r *AccountFindAllRequest) GenerateFilter() {
@@ -60,4 +134,72 @@ func (r *AccountFindAllRequest) GenerateFilter() {
 	if r.Name != "" {
 		r.Filter = append(r.Filter, pagination.FilterItem{Field: "name", Op: "ilike", Val: "%" + r.Name + "%"})
 	}
+	if r.Type != "" {
+		r.Filter = append(r.Filter, pagination.FilterItem{Field: "type", Op: "eq", Val: r.Type})
+	}
+	if r.ParentID != "" {
+		r.Filter = append(r.Filter, pagination.FilterItem{Field: "parent_id", Op: "eq", Val: r.ParentID})
+	}
+}
+
+// TrialBalanceRequest powers GET /api/accounting/trial-balance.
+// From / To are inclusive YYYY-MM-DD bounds on the mutation
+// created_at. Empty From = since the first posting (opening is 0);
+// empty To = today.
+type TrialBalanceRequest struct {
+	From string `query:"from"`
+	To   string `query:"to"`
+}
+
+// TrialBalanceRowDto is one account of the trial balance.
+//
+// Opening / closing balances are on the account's normal side
+// (see AccountBalance); Debit / Credit are the gross period
+// movements. Every figure includes the account's descendants, so a
+// header account shows the subtotal of its subtree; Own* carry the
+// account's postings alone.
+type TrialBalanceRowDto struct {
+	AccountID      string  `json:"accountId"`
+	Code           string  `json:"code"`
+	Name           string  `json:"name"`
+	Type           string  `json:"type"`
+	NormalBalance  string  `json:"normalBalance"`
+	ParentID       string  `json:"parentId"`
+	Level          int     `json:"level"`
+	HasChildren    bool    `json:"hasChildren"`
+	OpeningBalance float64 `json:"openingBalance"`
+	Debit          float64 `json:"debit"`
+	Credit         float64 `json:"credit"`
+	ClosingBalance float64 `json:"closingBalance"`
+	OwnOpening     float64 `json:"ownOpening"`
+	OwnDebit       float64 `json:"ownDebit"`
+	OwnCredit      float64 `json:"ownCredit"`
+	OwnClosing     float64 `json:"ownClosing"`
+}
+
+// TrialBalanceDto is the whole report. Rows come depth-first in
+// code order, each parent before its children.
+//
+// ClosingDebit / ClosingCredit split every account's own closing
+// balance into the classic two trial-balance columns; in a balanced
+// ledger they are equal, as are TotalDebit and TotalCredit.
+type TrialBalanceDto struct {
+	From          string               `json:"from"`
+	To            string               `json:"to"`
+	Rows          []TrialBalanceRowDto `json:"rows"`
+	TotalDebit    float64              `json:"totalDebit"`
+	TotalCredit   float64              `json:"totalCredit"`
+	ClosingDebit  float64              `json:"closingDebit"`
+	ClosingCredit float64              `json:"closingCredit"`
+	Balanced      bool                 `json:"balanced"`
+}
+
+// AccountMovementDto is the per-account ledger aggregate the report
+// repository hands back: the signed sum before the window and the
+// gross debit / credit inside it.
+type AccountMovementDto struct {
+	AccountID string
+	Opening   float64
+	Debit     float64
+	Credit    float64
 }
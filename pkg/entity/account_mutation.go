@@ -1,6 +1,9 @@
 package entity
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/raymondsugiarto/coffee-api/pkg/model"
 	"github.com/raymondsugiarto/coffee-api/pkg/shared/pagination"
 )
@@ -11,6 +14,11 @@ import (
 const (
 	AccountMutationRefTableStockSession = "stock_session"
 	AccountMutationRefTableOrder        = "order"
+	AccountMutationRefTableJournalEntry = "journal_entry"
+	AccountMutationRefTableCashDebt     = "cash_debt"
+	AccountMutationRefTablePayroll      = "employee_salary"
+	AccountMutationRefTableGoodsReceipt = "goods_receipt"
+	AccountMutationRefTableStockOpname  = "stock_opname"
 )
 
 // Reference-module values, grouping upstream sources by domain.
@@ -18,8 +26,37 @@ const (
 const (
 	AccountMutationRefModuleOrder        = "ORDER"
 	AccountMutationRefModuleStockSession = "STOCK_SESSION"
+	AccountMutationRefModuleJournal      = "JOURNAL"
+	AccountMutationRefModuleCashDebt     = "CASH_DEBT"
+	AccountMutationRefModulePayroll      = "PAYROLL"
+	AccountMutationRefModulePurchase     = "PURCHASE"
+	AccountMutationRefModuleInventory    = "INVENTORY"
 )
 
+// accountMutationRefPaths maps a ref_table to the API path that
+// serves the source record, so reports can link a ledger row back
+// to whatever posted it. Tables without a detail endpoint are left
+// out and get no link.
+var accountMutationRefPaths = map[string]string{
+	AccountMutationRefTableStockSession: "/api/stock-session/%s",
+	AccountMutationRefTableJournalEntry: "/api/journal-entries/%s",
+	AccountMutationRefTableCashDebt:     "/api/cash-debts/%s",
+	AccountMutationRefTablePayroll:      "/api/payroll/%s",
+	AccountMutationRefTableGoodsReceipt: "/api/goods-receipts/%s",
+	AccountMutationRefTableStockOpname:  "/api/stock-opnames/%s",
+}
+
+// AccountMutationRefLink returns the drill-down path for a ledger
+// row's source record, or "" when the ref table has no detail
+// endpoint.
+func AccountMutationRefLink(refTable, refID string) string {
+	path, ok := accountMutationRefPaths[refTable]
+	if !ok || refID == "" {
+		return ""
+	}
+	return fmt.Sprintf(path, refID)
+}
+
 // AccountMutationDto is the wire shape for one ledger row.
 // `amount` is signed: positive = debit to the account, negative =
 // credit. The interpretation (asset/expense/etc.) lives at the
@@ -31,8 +68,13 @@ type AccountMutationDto struct {
 	Amount         float64 `json:"amount"        validate:"required"`
 	Description    string  `json:"description"`
 	RefID          string  `json:"refId"         validate:"required"`
-	RefTable       string  `json:"refTable"      validate:"required,oneof=stock_session order"`
-	RefModule      string  `json:"refModule"     validate:"required,oneof=ORDER STOCK_SESSION"`
+	RefTable       string  `json:"refTable"      validate:"required,oneof=stock_session order journal_entry"`
+	RefModule      string  `json:"refModule"     validate:"required,oneof=ORDER STOCK_SESSION JOURNAL"`
+	JournalEntryID string  `json:"journalEntryId"`
+	// CreatedAt is the posting date. The repository stamps it on
+	// insert; a caller may set it beforehand only so the period
+	// guard checks the date of the entry the row belongs to.
+	CreatedAt time.Time `json:"createdAt"`
 }
 
 func NewAccountMutationDtoFromModel(m *model.AccountMutation) *AccountMutationDto {
@@ -48,6 +90,8 @@ func NewAccountMutationDtoFromModel(m *model.AccountMutation) *AccountMutationDt
 		RefID:          m.RefID,
 		RefTable:       m.RefTable,
 		RefModule:      m.RefModule,
+		JournalEntryID: m.JournalEntryID,
+		CreatedAt:      m.CreatedAt,
 	}
 }
 
@@ -60,6 +104,7 @@ func (d *AccountMutationDto) ToModel() *model.AccountMutation {
 		RefID:          d.RefID,
 		RefTable:       d.RefTable,
 		RefModule:      d.RefModule,
+		JournalEntryID: d.JournalEntryID,
 	}
 	if d.ID != "" {
 		m.ID = d.ID
@@ -80,6 +125,8 @@ type AccountMutationFindAllRequest struct {
 	RefID     string
 	RefTable  string
 	RefModule string
+	// JournalEntryID pulls every leg of one entry.
+	JournalEntryID string
 	// From / To: YYYY-MM-DD strings, inclusive bounds on the
 	// stored created_at timestamp. Empty = unbounded.
 	From string
@@ -99,6 +146,9 @@ func (r *AccountMutationFindAllRequest) GenerateFilter() {
 	if r.RefModule != "" {
 		r.Filter = append(r.Filter, pagination.FilterItem{Field: "ref_module", Op: "eq", Val: r.RefModule})
 	}
+	if r.JournalEntryID != "" {
+		r.Filter = append(r.Filter, pagination.FilterItem{Field: "journal_entry_id", Op: "eq", Val: r.JournalEntryID})
+	}
 	if r.From != "" {
 		r.Filter = append(r.Filter, pagination.FilterItem{Field: "created_at", Op: "gte", Val: r.From})
 	}
@@ -106,3 +156,43 @@ func (r *AccountMutationFindAllRequest) GenerateFilter() {
 		r.Filter = append(r.Filter, pagination.FilterItem{Field: "created_at", Op: "lte", Val: r.To})
 	}
 }
+
+// AccountLedgerRequest powers GET /api/accounts/:id/ledger. From /
+// To are inclusive YYYY-MM-DD bounds; empty From = since the first
+// posting, empty To = today.
+type AccountLedgerRequest struct {
+	From string `query:"from"`
+	To   string `query:"to"`
+}
+
+// AccountLedgerRowDto is one mutation on the statement. Balance is
+// the running balance after this row, on the account's normal side.
+// RefLink is the drill-down path to the source record (empty when
+// the source has no detail endpoint).
+type AccountLedgerRowDto struct {
+	ID          string    `json:"id"`
+	Date        time.Time `json:"date"`
+	Description string    `json:"description"`
+	Debit       float64   `json:"debit"`
+	Credit      float64   `json:"credit"`
+	Balance     float64   `json:"balance"`
+	RefID       string    `json:"refId"`
+	RefTable    string    `json:"refTable"`
+	RefModule   string    `json:"refModule"`
+	RefLink     string    `json:"refLink"`
+}
+
+// AccountLedgerDto is the account statement for one window.
+// OpeningBalance carries every posting before From;
+// ClosingBalance = OpeningBalance +/- the window's movements on the
+// account's normal side.
+type AccountLedgerDto struct {
+	Account        *AccountDto           `json:"account"`
+	From           string                `json:"from"`
+	To             string                `json:"to"`
+	OpeningBalance float64               `json:"openingBalance"`
+	TotalDebit     float64               `json:"totalDebit"`
+	TotalCredit    float64               `json:"totalCredit"`
+	ClosingBalance float64               `json:"closingBalance"`
+	Rows           []AccountLedgerRowDto `json:"rows"`
+}
@@ -0,0 +1,123 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/raymondsugiarto/coffee-api/pkg/model"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/pagination"
+)
+
+// Accounting period states.
+const (
+	AccountingPeriodStatusOpen   = "OPEN"
+	AccountingPeriodStatusClosed = "CLOSED"
+)
+
+// Accounting period audit actions.
+const (
+	AccountingPeriodActionClose  = "CLOSE"
+	AccountingPeriodActionReopen = "REOPEN"
+)
+
+// AccountingPeriodDto is the wire shape for /api/accounting/periods.
+// Period is "YYYY-MM".
+type AccountingPeriodDto struct {
+	ID             string     `json:"id"`
+	OrganizationID string     `json:"-"`
+	Period         string     `json:"period"`
+	Status         string     `json:"status"`
+	ClosedAt       *time.Time `json:"closedAt"`
+	ClosedBy       string     `json:"closedBy"`
+	ReopenedAt     *time.Time `json:"reopenedAt"`
+	ReopenedBy     string     `json:"reopenedBy"`
+}
+
+func NewAccountingPeriodDtoFromModel(m *model.AccountingPeriod) *AccountingPeriodDto {
+	if m == nil {
+		return nil
+	}
+	return &AccountingPeriodDto{
+		ID:             m.ID,
+		OrganizationID: m.OrganizationID,
+		Period:         m.Period,
+		Status:         m.Status,
+		ClosedAt:       m.ClosedAt,
+		ClosedBy:       m.ClosedBy,
+		ReopenedAt:     m.ReopenedAt,
+		ReopenedBy:     m.ReopenedBy,
+	}
+}
+
+func (d *AccountingPeriodDto) ToModel() *model.AccountingPeriod {
+	m := &model.AccountingPeriod{
+		OrganizationID: d.OrganizationID,
+		Period:         d.Period,
+		Status:         d.Status,
+		ClosedAt:       d.ClosedAt,
+		ClosedBy:       d.ClosedBy,
+		ReopenedAt:     d.ReopenedAt,
+		ReopenedBy:     d.ReopenedBy,
+	}
+	if d.ID != "" {
+		m.ID = d.ID
+	}
+	return m
+}
+
+// AccountingPeriodLogDto is one audit row of a period.
+type AccountingPeriodLogDto struct {
+	ID                 string    `json:"id"`
+	OrganizationID     string    `json:"-"`
+	AccountingPeriodID string    `json:"accountingPeriodId"`
+	Period             string    `json:"period"`
+	Action             string    `json:"action"`
+	ActorID            string    `json:"actorId"`
+	Reason             string    `json:"reason"`
+	CreatedAt          time.Time `json:"createdAt"`
+}
+
+func NewAccountingPeriodLogDtoFromModel(m *model.AccountingPeriodLog) *AccountingPeriodLogDto {
+	if m == nil {
+		return nil
+	}
+	return &AccountingPeriodLogDto{
+		ID:                 m.ID,
+		OrganizationID:     m.OrganizationID,
+		AccountingPeriodID: m.AccountingPeriodID,
+		Period:             m.Period,
+		Action:             m.Action,
+		ActorID:            m.ActorID,
+		Reason:             m.Reason,
+		CreatedAt:          m.CreatedAt,
+	}
+}
+
+func (d *AccountingPeriodLogDto) ToModel() *model.AccountingPeriodLog {
+	return &model.AccountingPeriodLog{
+		OrganizationID:     d.OrganizationID,
+		AccountingPeriodID: d.AccountingPeriodID,
+		Period:             d.Period,
+		Action:             d.Action,
+		ActorID:            d.ActorID,
+		Reason:             d.Reason,
+	}
+}
+
+// AccountingPeriodActionRequest is the body of the close / reopen
+// endpoints. Reason is optional on close and required on reopen.
+type AccountingPeriodActionRequest struct {
+	Reason string `json:"reason" validate:"max=1000"`
+}
+
+// AccountingPeriodFindAllRequest powers GET /api/accounting/periods.
+// Only months that were closed at least once have a row.
+type AccountingPeriodFindAllRequest struct {
+	FindAllRequest
+	Status string
+}
+
+func (r *AccountingPeriodFindAllRequest) GenerateFilter() {
+	if r.Status != "" {
+		r.Filter = append(r.Filter, pagination.FilterItem{Field: "status", Op: "eq", Val: r.Status})
+	}
+}
@@ -1,10 +1,28 @@
 package entity
 
 import (
+	"strings"
+
 	"github.com/raymondsugiarto/coffee-api/pkg/model"
 	"github.com/raymondsugiarto/coffee-api/pkg/shared/pagination"
 )
 
+// Admin types stored in admin.admin_type.
+const (
+	AdminTypeAdmin    = "ADMIN"
+	AdminTypeCompany  = "COMPANY"
+	AdminTypeEmployee = "EMPLOYEE"
+)
+
+// Bank codes stored in admin.bank_code. Anything other than BCA and
+// MANDIRI is an interbank transfer for both disbursement formats.
+const (
+	BankCodeBCA     = "BCA"
+	BankCodeMandiri = "MANDIRI"
+	BankCodeBNI     = "BNI"
+	BankCodeBRI     = "BRI"
+)
+
 type AdminDto struct {
 	ID              string   `json:"id"`
 	AdminType       string   `json:"adminType"`
@@ -104,3 +122,37 @@ type DriverFindAllRequest struct {
 	pagination.GetListRequest
 	Query string
 }
+
+// DriverBankAccountRequest is the body of PUT
+// /api/employees/:id/bank-account.
+type DriverBankAccountRequest struct {
+	BankCode          string `json:"bankCode"          validate:"required,oneof=BCA MANDIRI BNI BRI"`
+	BankAccountNumber string `json:"bankAccountNumber" validate:"required,numeric,max=34"`
+	BankAccountName   string `json:"bankAccountName"   validate:"required,max=70"`
+}
+
+// DriverBankAccountDto is the response of GET and PUT
+// /api/employees/:id/bank-account. The account number is masked to
+// its last four digits; only the disbursement export reads it whole.
+type DriverBankAccountDto struct {
+	AdminID           string `json:"adminId"`
+	BankCode          string `json:"bankCode"`
+	BankAccountNumber string `json:"bankAccountNumber"`
+	BankAccountName   string `json:"bankAccountName"`
+}
+
+func (dto *DriverBankAccountDto) FromModel(m *model.Admin) *DriverBankAccountDto {
+	dto.AdminID = m.ID
+	dto.BankCode = m.BankCode
+	dto.BankAccountNumber = MaskBankAccountNumber(m.BankAccountNumber)
+	dto.BankAccountName = m.BankAccountName
+	return dto
+}
+
+// MaskBankAccountNumber replaces all but the last four digits with *.
+func MaskBankAccountNumber(number string) string {
+	if len(number) <= 4 {
+		return strings.Repeat("*", len(number))
+	}
+	return strings.Repeat("*", len(number)-4) + number[len(number)-4:]
+}
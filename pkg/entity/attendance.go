@@ -0,0 +1,134 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/raymondsugiarto/coffee-api/pkg/model"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/pagination"
+)
+
+// AttendanceRefTable marks the payroll ATTENDANCE component, which
+// is earned over the run's window rather than per session.
+const AttendanceRefTable = "attendance"
+
+type AttendanceDto struct {
+	ID                string     `json:"id"`
+	OrganizationID    string     `json:"-"`
+	AdminIDEmployee   string     `json:"adminIdEmployee"`
+	Date              string     `json:"date"` // YYYY-MM-DD
+	ClockInAt         time.Time  `json:"clockInAt"`
+	ClockInLatitude   *float64   `json:"clockInLatitude"`
+	ClockInLongitude  *float64   `json:"clockInLongitude"`
+	ClockOutAt        *time.Time `json:"clockOutAt"`
+	ClockOutLatitude  *float64   `json:"clockOutLatitude"`
+	ClockOutLongitude *float64   `json:"clockOutLongitude"`
+	// Hours is the time between clock-in and clock-out; 0 while the
+	// day is still open.
+	Hours     float64 `json:"hours"`
+	Notes     string  `json:"notes"`
+	CreatedBy string  `json:"createdBy"`
+}
+
+func NewAttendanceDtoFromModel(m *model.Attendance) *AttendanceDto {
+	if m == nil {
+		return nil
+	}
+	d := &AttendanceDto{
+		ID:                m.ID,
+		OrganizationID:    m.OrganizationID,
+		AdminIDEmployee:   m.AdminIDEmployee,
+		Date:              m.Date.Format("2006-01-02"),
+		ClockInAt:         m.ClockInAt,
+		ClockInLatitude:   m.ClockInLatitude,
+		ClockInLongitude:  m.ClockInLongitude,
+		ClockOutAt:        m.ClockOutAt,
+		ClockOutLatitude:  m.ClockOutLatitude,
+		ClockOutLongitude: m.ClockOutLongitude,
+		Notes:             m.Notes,
+		CreatedBy:         m.CreatedBy,
+	}
+	if m.ClockOutAt != nil {
+		d.Hours = m.ClockOutAt.Sub(m.ClockInAt).Hours()
+	}
+	return d
+}
+
+func (d *AttendanceDto) ToModel() *model.Attendance {
+	date, _ := time.Parse("2006-01-02", d.Date)
+	m := &model.Attendance{
+		OrganizationID:    d.OrganizationID,
+		AdminIDEmployee:   d.AdminIDEmployee,
+		Date:              date,
+		ClockInAt:         d.ClockInAt,
+		ClockInLatitude:   d.ClockInLatitude,
+		ClockInLongitude:  d.ClockInLongitude,
+		ClockOutAt:        d.ClockOutAt,
+		ClockOutLatitude:  d.ClockOutLatitude,
+		ClockOutLongitude: d.ClockOutLongitude,
+		Notes:             d.Notes,
+		CreatedBy:         d.CreatedBy,
+	}
+	if d.ID != "" {
+		m.ID = d.ID
+	}
+	return m
+}
+
+// AttendanceClockInRequest is the body of POST
+// /api/attendance/clock-in. At defaults to now; an admin can set it
+// to record a clock-in after the fact. Coordinates are optional.
+type AttendanceClockInRequest struct {
+	AdminIDEmployee string     `json:"adminIdEmployee" validate:"required"`
+	At              *time.Time `json:"at"`
+	Latitude        *float64   `json:"latitude"        validate:"omitempty,gte=-90,lte=90"`
+	Longitude       *float64   `json:"longitude"       validate:"omitempty,gte=-180,lte=180"`
+	Notes           string     `json:"notes"           validate:"max=1000"`
+}
+
+// AttendanceClockOutRequest is the body of POST
+// /api/attendance/:id/clock-out.
+type AttendanceClockOutRequest struct {
+	At        *time.Time `json:"at"`
+	Latitude  *float64   `json:"latitude"  validate:"omitempty,gte=-90,lte=90"`
+	Longitude *float64   `json:"longitude" validate:"omitempty,gte=-180,lte=180"`
+	Notes     string     `json:"notes"     validate:"max=1000"`
+}
+
+type AttendanceFindAllRequest struct {
+	FindAllRequest
+	AdminIDEmployee string
+	From            string // YYYY-MM-DD
+	To              string // YYYY-MM-DD
+}
+
+func (r *AttendanceFindAllRequest) GenerateFilter() {
+	if r.AdminIDEmployee != "" {
+		r.Filter = append(r.Filter, pagination.FilterItem{Field: "admin_id_employee", Op: "eq", Val: r.AdminIDEmployee})
+	}
+	if r.From != "" {
+		r.Filter = append(r.Filter, pagination.FilterItem{Field: "date", Op: "gte", Val: r.From})
+	}
+	if r.To != "" {
+		r.Filter = append(r.Filter, pagination.FilterItem{Field: "date", Op: "lte", Val: r.To})
+	}
+}
+
+// AttendanceSummaryRequest is the query of GET
+// /api/employees/:id/attendance-summary.
+type AttendanceSummaryRequest struct {
+	From string `query:"from" validate:"required,len=10"`
+	To   string `query:"to"   validate:"required,len=10"`
+}
+
+// AttendanceSummaryDto rolls a driver's attendance up over a period.
+// DaysWorked counts the days with a clock-in; it is what ATTENDANCE
+// salary bands are evaluated against.
+type AttendanceSummaryDto struct {
+	AdminIDEmployee string          `json:"adminIdEmployee"`
+	From            string          `json:"from"`
+	To              string          `json:"to"`
+	DaysWorked      int             `json:"daysWorked"`
+	OpenDays        int             `json:"openDays"`
+	TotalHours      float64         `json:"totalHours"`
+	Records         []AttendanceDto `json:"records"`
+}
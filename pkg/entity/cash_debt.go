@@ -1,6 +1,7 @@
 package entity
 
 import (
+	"math"
 	"time"
 
 	"github.com/raymondsugiarto/coffee-api/pkg/model"
@@ -13,6 +14,17 @@ const (
 	CashDebtPaymentMethodCashless = "CASHLESS"
 )
 
+// How a cash advance is paid back — wire enum.
+const (
+	CashDebtSettlementMethodCash       = "CASH"
+	CashDebtSettlementMethodPayroll    = "PAYROLL"
+	CashDebtSettlementMethodTillOffset = "TILL_OFFSET"
+)
+
+// CashDebtSettlementRefTableEmployeeSalary marks a PAYROLL
+// settlement made by a salary run.
+const CashDebtSettlementRefTableEmployeeSalary = "employee_salary"
+
 type CashDebtDto struct {
 	ID              string  `json:"id"`
 	OrganizationID  string  `json:"-"`
@@ -21,13 +33,20 @@ type CashDebtDto struct {
 	Amount          float64 `json:"amount" validate:"gte=0"`
 	PaymentMethod   string  `json:"paymentMethod" validate:"required,oneof=CASH CASHLESS"`
 	Notes           string  `json:"notes"`
+	// Read-only: maintained by the settlement flow.
+	SettledAmount float64                  `json:"settledAmount"`
+	Outstanding   float64                  `json:"outstanding"`
+	SettledAt     *time.Time               `json:"settledAt,omitempty"`
+	Settlements   []*CashDebtSettlementDto `json:"settlements,omitempty"`
+	// Read-only: the CASH_DEBT_CREATED posting of the advance.
+	JournalEntryID string `json:"journalEntryId,omitempty"`
 }
 
 func NewCashDebtDtoFromModel(m *model.CashDebt) *CashDebtDto {
 	if m == nil {
 		return nil
 	}
-	return &CashDebtDto{
+	d := &CashDebtDto{
 		ID:              m.ID,
 		OrganizationID:  m.OrganizationID,
 		AdminIDEmployee: m.AdminIDEmployee,
@@ -35,7 +54,25 @@ func NewCashDebtDtoFromModel(m *model.CashDebt) *CashDebtDto {
 		Amount:          m.Amount,
 		PaymentMethod:   m.PaymentMethod,
 		Notes:           m.Notes,
+		SettledAmount:   m.SettledAmount,
+		Outstanding:     CashDebtOutstanding(m.Amount, m.SettledAmount),
+		SettledAt:       m.SettledAt,
+		JournalEntryID:  m.JournalEntryID,
+	}
+	for i := range m.Settlements {
+		d.Settlements = append(d.Settlements, NewCashDebtSettlementDtoFromModel(&m.Settlements[i]))
 	}
+	return d
+}
+
+// CashDebtOutstanding is what is still owed on an advance, rounded
+// to the cent so float noise never leaves a debt "almost" settled.
+func CashDebtOutstanding(amount, settled float64) float64 {
+	v := math.Round((amount-settled)*100) / 100
+	if v < 0 {
+		return 0
+	}
+	return v
 }
 
 func (d *CashDebtDto) ToModel() *model.CashDebt {
@@ -77,3 +114,96 @@ func (r *CashDebtFindAllRequest) GenerateFilter() {
 		r.Filter = append(r.Filter, pagination.FilterItem{Field: "date", Op: "lte", Val: r.To})
 	}
 }
+
+// CashDebtSettleRequest is the body of POST /api/cash-debts/:id/settle.
+// Date defaults to today. PAYROLL repayments are not accepted here:
+// they are only made by the payroll run that deducts them.
+type CashDebtSettleRequest struct {
+	Date   string  `json:"date" validate:"omitempty,len=10"` // YYYY-MM-DD
+	Amount float64 `json:"amount" validate:"gt=0"`
+	Method string  `json:"method" validate:"required,oneof=CASH TILL_OFFSET"`
+	Notes  string  `json:"notes"`
+}
+
+type CashDebtSettlementDto struct {
+	ID              string     `json:"id"`
+	OrganizationID  string     `json:"-"`
+	CashDebtID      string     `json:"cashDebtId"`
+	AdminIDEmployee string     `json:"adminIdEmployee"`
+	Date            string     `json:"date"` // YYYY-MM-DD
+	Amount          float64    `json:"amount"`
+	Method          string     `json:"method"`
+	RefID           string     `json:"refId"`
+	RefTable        string     `json:"refTable"`
+	Notes           string     `json:"notes"`
+	JournalEntryID  string     `json:"journalEntryId"`
+	CreatedBy       string     `json:"createdBy"`
+	CreatedAt       time.Time  `json:"createdAt"`
+	ReversedAt      *time.Time `json:"reversedAt,omitempty"`
+	ReversedBy      string     `json:"reversedBy,omitempty"`
+}
+
+func NewCashDebtSettlementDtoFromModel(m *model.CashDebtSettlement) *CashDebtSettlementDto {
+	if m == nil {
+		return nil
+	}
+	return &CashDebtSettlementDto{
+		ID:              m.ID,
+		OrganizationID:  m.OrganizationID,
+		CashDebtID:      m.CashDebtID,
+		AdminIDEmployee: m.AdminIDEmployee,
+		Date:            m.Date.Format("2006-01-02"),
+		Amount:          m.Amount,
+		Method:          m.Method,
+		RefID:           m.RefID,
+		RefTable:        m.RefTable,
+		Notes:           m.Notes,
+		JournalEntryID:  m.JournalEntryID,
+		CreatedBy:       m.CreatedBy,
+		CreatedAt:       m.CreatedAt,
+		ReversedAt:      m.ReversedAt,
+		ReversedBy:      m.ReversedBy,
+	}
+}
+
+func (d *CashDebtSettlementDto) ToModel() *model.CashDebtSettlement {
+	parsedDate, _ := time.Parse("2006-01-02", d.Date)
+	m := &model.CashDebtSettlement{
+		OrganizationID:  d.OrganizationID,
+		CashDebtID:      d.CashDebtID,
+		AdminIDEmployee: d.AdminIDEmployee,
+		Date:            parsedDate,
+		Amount:          d.Amount,
+		Method:          d.Method,
+		RefID:           d.RefID,
+		RefTable:        d.RefTable,
+		Notes:           d.Notes,
+		JournalEntryID:  d.JournalEntryID,
+		CreatedBy:       d.CreatedBy,
+	}
+	if d.ID != "" {
+		m.ID = d.ID
+	}
+	return m
+}
+
+// CashDebtAgingBucketDto is one age band of a driver's outstanding
+// advances, aged by advance date. MaxDays 0 means open-ended.
+type CashDebtAgingBucketDto struct {
+	Label       string  `json:"label"`
+	MinDays     int     `json:"minDays"`
+	MaxDays     int     `json:"maxDays"`
+	Count       int     `json:"count"`
+	Outstanding float64 `json:"outstanding"`
+}
+
+// CashDebtBalanceDto powers GET /api/employees/:id/cash-debt-balance.
+type CashDebtBalanceDto struct {
+	AdminIDEmployee string                   `json:"adminIdEmployee"`
+	AsOf            string                   `json:"asOf"`
+	TotalAmount     float64                  `json:"totalAmount"`
+	TotalSettled    float64                  `json:"totalSettled"`
+	Outstanding     float64                  `json:"outstanding"`
+	Aging           []CashDebtAgingBucketDto `json:"aging"`
+	Debts           []*CashDebtDto           `json:"debts"`
+}
@@ -0,0 +1,168 @@
+package entity
+
+import (
+	"sort"
+
+	"github.com/raymondsugiarto/coffee-api/pkg/model"
+)
+
+// Commission plan methods stored on commission_plan.method.
+const (
+	// TIERED pays every unit at the rate of the highest tier the
+	// quantity reaches.
+	CommissionPlanMethodTiered = "TIERED"
+	// PROGRESSIVE pays the units inside each tier at that tier's
+	// rate, like a progressive tax.
+	CommissionPlanMethodProgressive = "PROGRESSIVE"
+	// PERCENTAGE pays Rate percent of the sales in scope.
+	CommissionPlanMethodPercentage = "PERCENTAGE"
+)
+
+// CommissionPlanTierDto is one band of a tiered plan: the rate per
+// unit for the units sold beyond FromQty. "0-50 cups at 500, 51-100
+// at 700, above 100 at 1000" is FromQty 0, 50 and 100.
+type CommissionPlanTierDto struct {
+	ID      string  `json:"id"`
+	FromQty int     `json:"fromQty" validate:"gte=0"`
+	Rate    float64 `json:"rate"    validate:"gte=0"`
+}
+
+type CommissionPlanDto struct {
+	ID             string                  `json:"id"`
+	OrganizationID string                  `json:"-"`
+	Name           string                  `json:"name"           validate:"required,max=255"`
+	CompanyID      string                  `json:"companyId"      validate:"required_without=ItemCategoryID"`
+	ItemCategoryID string                  `json:"itemCategoryId" validate:"required_without=CompanyID"`
+	Method         string                  `json:"method"         validate:"required,oneof=TIERED PROGRESSIVE PERCENTAGE"`
+	Rate           float64                 `json:"rate"           validate:"gte=0,lte=100"`
+	IsActive       bool                    `json:"isActive"`
+	Tiers          []CommissionPlanTierDto `json:"tiers"          validate:"dive"`
+}
+
+func NewCommissionPlanDtoFromModel(m *model.CommissionPlan) *CommissionPlanDto {
+	if m == nil {
+		return nil
+	}
+	d := &CommissionPlanDto{
+		ID:             m.ID,
+		OrganizationID: m.OrganizationID,
+		Name:           m.Name,
+		CompanyID:      m.CompanyID,
+		ItemCategoryID: m.ItemCategoryID,
+		Method:         m.Method,
+		Rate:           m.Rate,
+		IsActive:       m.IsActive,
+		Tiers:          make([]CommissionPlanTierDto, 0, len(m.Tiers)),
+	}
+	for _, t := range m.Tiers {
+		d.Tiers = append(d.Tiers, CommissionPlanTierDto{ID: t.ID, FromQty: t.FromQty, Rate: t.Rate})
+	}
+	d.SortTiers()
+	return d
+}
+
+func (d *CommissionPlanDto) ToModel() *model.CommissionPlan {
+	m := &model.CommissionPlan{
+		OrganizationID: d.OrganizationID,
+		Name:           d.Name,
+		CompanyID:      d.CompanyID,
+		ItemCategoryID: d.ItemCategoryID,
+		Method:         d.Method,
+		Rate:           d.Rate,
+		IsActive:       d.IsActive,
+	}
+	if d.ID != "" {
+		m.ID = d.ID
+	}
+	for _, t := range d.Tiers {
+		m.Tiers = append(m.Tiers, model.CommissionPlanTier{PlanID: d.ID, FromQty: t.FromQty, Rate: t.Rate})
+	}
+	return m
+}
+
+// SortTiers orders the tiers by FromQty, which Compute relies on.
+func (d *CommissionPlanDto) SortTiers() {
+	sort.SliceStable(d.Tiers, func(i, j int) bool { return d.Tiers[i].FromQty < d.Tiers[j].FromQty })
+}
+
+// Compute returns the commission the plan pays for qty units sold
+// worth sales in total. Tiers must be sorted (SortTiers).
+func (d *CommissionPlanDto) Compute(qty int, sales float64) float64 {
+	switch d.Method {
+	case CommissionPlanMethodPercentage:
+		return sales * d.Rate / 100
+	case CommissionPlanMethodTiered:
+		var rate float64
+		for _, t := range d.Tiers {
+			if qty > t.FromQty {
+				rate = t.Rate
+			}
+		}
+		return float64(qty) * rate
+	case CommissionPlanMethodProgressive:
+		var total float64
+		for i, t := range d.Tiers {
+			upper := qty
+			if i+1 < len(d.Tiers) && d.Tiers[i+1].FromQty < upper {
+				upper = d.Tiers[i+1].FromQty
+			}
+			if upper > t.FromQty {
+				total += float64(upper-t.FromQty) * t.Rate
+			}
+		}
+		return total
+	}
+	return 0
+}
+
+// ResolveCommissionPlan picks the plan covering an item of
+// categoryID sold by a driver of companyID. The most specific plan
+// wins: category and company, then category only, then company
+// only. Among equals the first in `plans` wins. Returns nil when no
+// plan applies and the flat item rate stays in force.
+func ResolveCommissionPlan(plans []CommissionPlanDto, companyID, categoryID string) *CommissionPlanDto {
+	var best *CommissionPlanDto
+	bestRank := 0
+	for i := range plans {
+		p := &plans[i]
+		if p.CompanyID != "" && p.CompanyID != companyID {
+			continue
+		}
+		if p.ItemCategoryID != "" && p.ItemCategoryID != categoryID {
+			continue
+		}
+		rank := 1 // company only
+		if p.ItemCategoryID != "" {
+			rank = 2
+			if p.CompanyID != "" {
+				rank = 3
+			}
+		}
+		if rank > bestRank {
+			best, bestRank = p, rank
+		}
+	}
+	return best
+}
+
+type CommissionPlanFindAllRequest struct {
+	FindAllRequest
+	CompanyID      string
+	ItemCategoryID string
+}
+
+// StockSessionCommissionPlanDto is the snapshot, taken at close, of
+// one plan a session's commission was computed with: the plan as it
+// was, the quantity and sales it covered and what it paid.
+type StockSessionCommissionPlanDto struct {
+	PlanID         string                  `json:"planId"`
+	Name           string                  `json:"name"`
+	CompanyID      string                  `json:"companyId,omitempty"`
+	ItemCategoryID string                  `json:"itemCategoryId,omitempty"`
+	Method         string                  `json:"method"`
+	Rate           float64                 `json:"rate,omitempty"`
+	Tiers          []CommissionPlanTierDto `json:"tiers,omitempty"`
+	Qty            int                     `json:"qty"`
+	Sales          float64                 `json:"sales"`
+	Amount         float64                 `json:"amount"`
+}
@@ -0,0 +1,207 @@
+package entity
+
+import (
+	"math"
+	"testing"
+)
+
+func tieredPlan(method string) CommissionPlanDto {
+	// 0-50 cups at 500, 51-100 at 700, above 100 at 1000.
+	return CommissionPlanDto{
+		ID:     "plan",
+		Method: method,
+		Tiers: []CommissionPlanTierDto{
+			{FromQty: 0, Rate: 500},
+			{FromQty: 50, Rate: 700},
+			{FromQty: 100, Rate: 1000},
+		},
+	}
+}
+
+func TestCommissionPlanCompute(t *testing.T) {
+	tests := []struct {
+		name  string
+		plan  CommissionPlanDto
+		qty   int
+		sales float64
+		want  float64
+	}{
+		{"tiered nothing sold", tieredPlan(CommissionPlanMethodTiered), 0, 0, 0},
+		{"tiered first tier", tieredPlan(CommissionPlanMethodTiered), 1, 0, 500},
+		{"tiered at first boundary", tieredPlan(CommissionPlanMethodTiered), 50, 0, 25000},
+		{"tiered past first boundary", tieredPlan(CommissionPlanMethodTiered), 51, 0, 35700},
+		{"tiered at second boundary", tieredPlan(CommissionPlanMethodTiered), 100, 0, 70000},
+		{"tiered past second boundary", tieredPlan(CommissionPlanMethodTiered), 101, 0, 101000},
+		{"progressive nothing sold", tieredPlan(CommissionPlanMethodProgressive), 0, 0, 0},
+		{"progressive at first boundary", tieredPlan(CommissionPlanMethodProgressive), 50, 0, 25000},
+		{"progressive past first boundary", tieredPlan(CommissionPlanMethodProgressive), 51, 0, 25700},
+		{"progressive at second boundary", tieredPlan(CommissionPlanMethodProgressive), 100, 0, 60000},
+		{"progressive in top tier", tieredPlan(CommissionPlanMethodProgressive), 120, 0, 80000},
+		{
+			"progressive first tier above zero",
+			CommissionPlanDto{
+				Method: CommissionPlanMethodProgressive,
+				Tiers:  []CommissionPlanTierDto{{FromQty: 10, Rate: 100}},
+			},
+			15, 0, 500,
+		},
+		{
+			"percentage of sales",
+			CommissionPlanDto{Method: CommissionPlanMethodPercentage, Rate: 2.5},
+			7, 123456, 3086.4,
+		},
+		{"unknown method", CommissionPlanDto{Method: "FLAT", Rate: 10}, 10, 1000, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.plan.Compute(tt.qty, tt.sales)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Compute(%d, %v) = %v, want %v", tt.qty, tt.sales, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveCommissionPlan(t *testing.T) {
+	plans := []CommissionPlanDto{
+		{ID: "company", CompanyID: "c1"},
+		{ID: "category", ItemCategoryID: "drinks"},
+		{ID: "both", CompanyID: "c1", ItemCategoryID: "drinks"},
+		{ID: "other-company", CompanyID: "c2"},
+	}
+	tests := []struct {
+		name       string
+		companyID  string
+		categoryID string
+		want       string
+	}{
+		{"category and company wins", "c1", "drinks", "both"},
+		{"category only over company only", "c3", "drinks", "category"},
+		{"company only", "c1", "food", "company"},
+		{"other company", "c2", "food", "other-company"},
+		{"no plan applies", "c3", "food", ""},
+		{"no company and no category", "", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ResolveCommissionPlan(plans, tt.companyID, tt.categoryID)
+			gotID := ""
+			if got != nil {
+				gotID = got.ID
+			}
+			if gotID != tt.want {
+				t.Errorf("ResolveCommissionPlan(%q, %q) = %q, want %q", tt.companyID, tt.categoryID, gotID, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyCommissionPlans(t *testing.T) {
+	plans := []CommissionPlanDto{
+		{
+			ID:        "tiered",
+			CompanyID: "c1",
+			Method:    CommissionPlanMethodTiered,
+			Tiers: []CommissionPlanTierDto{
+				{FromQty: 0, Rate: 500},
+				{FromQty: 50, Rate: 700},
+			},
+		},
+		{ID: "percentage", CompanyID: "c1", ItemCategoryID: "drinks", Method: CommissionPlanMethodPercentage, Rate: 10},
+	}
+	categoryOf := map[string]string{"a": "drinks", "b": "drinks", "c": "drinks", "d": "food"}
+
+	type row struct {
+		commission float64
+		planID     string
+	}
+	tests := []struct {
+		name      string
+		companyID string
+		items     []StockSessionItemDto
+		want      map[string]row
+		wantTotal float64
+		wantPlans map[string]float64
+	}{
+		{
+			// 10% of 10.00 is 1.00; the last row takes what the
+			// rounded shares leave so the rows add up to the plan.
+			name:      "percentage split by sales keeps the rounding remainder",
+			companyID: "c1",
+			items: []StockSessionItemDto{
+				{ItemID: "a", SoldQty: 2, Subtotal: 3.33},
+				{ItemID: "b", SoldQty: 2, Subtotal: 3.33},
+				{ItemID: "c", SoldQty: 2, Subtotal: 3.34},
+			},
+			want: map[string]row{
+				"a": {0.33, "percentage"},
+				"b": {0.33, "percentage"},
+				"c": {0.34, "percentage"},
+			},
+			wantTotal: 1,
+			wantPlans: map[string]float64{"percentage": 1},
+		},
+		{
+			// 30 + 30 units reach the 700 tier together although
+			// neither row does on its own.
+			name:      "tiers see the quantity of every row in scope",
+			companyID: "c1",
+			items: []StockSessionItemDto{
+				{ItemID: "d", SoldQty: 30, Subtotal: 300000},
+				{ItemID: "e", SoldQty: 30, Subtotal: 300000},
+			},
+			want: map[string]row{
+				"d": {21000, "tiered"},
+				"e": {21000, "tiered"},
+			},
+			wantTotal: 42000,
+			wantPlans: map[string]float64{"tiered": 42000},
+		},
+		{
+			name:      "tier boundary is exclusive",
+			companyID: "c1",
+			items: []StockSessionItemDto{
+				{ItemID: "d", SoldQty: 50, Subtotal: 500000},
+			},
+			want:      map[string]row{"d": {25000, "tiered"}},
+			wantTotal: 25000,
+			wantPlans: map[string]float64{"tiered": 25000},
+		},
+		{
+			name:      "rows no plan covers keep the flat rate",
+			companyID: "c9",
+			items: []StockSessionItemDto{
+				{ItemID: "a", SoldQty: 4, Subtotal: 40000, CommissionSnapshot: 250, CommissionTotal: 1000},
+			},
+			want:      map[string]row{"a": {1000, ""}},
+			wantTotal: 1000,
+			wantPlans: map[string]float64{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &StockSessionDto{Items: tt.items}
+			d.ApplyCommissionPlans(plans, tt.companyID, categoryOf)
+			for _, it := range d.Items {
+				want := tt.want[it.ItemID]
+				if math.Abs(it.CommissionTotal-want.commission) > 1e-9 {
+					t.Errorf("item %s commission = %v, want %v", it.ItemID, it.CommissionTotal, want.commission)
+				}
+				if it.CommissionPlanID != want.planID {
+					t.Errorf("item %s plan = %q, want %q", it.ItemID, it.CommissionPlanID, want.planID)
+				}
+			}
+			if math.Abs(d.TotalCommission-tt.wantTotal) > 1e-9 {
+				t.Errorf("TotalCommission = %v, want %v", d.TotalCommission, tt.wantTotal)
+			}
+			if len(d.CommissionPlans) != len(tt.wantPlans) {
+				t.Fatalf("%d plans snapshotted, want %d", len(d.CommissionPlans), len(tt.wantPlans))
+			}
+			for _, p := range d.CommissionPlans {
+				if math.Abs(p.Amount-tt.wantPlans[p.PlanID]) > 1e-9 {
+					t.Errorf("plan %s amount = %v, want %v", p.PlanID, p.Amount, tt.wantPlans[p.PlanID])
+				}
+			}
+		})
+	}
+}
@@ -13,11 +13,27 @@ const (
 	EmployeeSalaryComponentTypeAttendance    = "ATTENDANCE"
 	EmployeeSalaryComponentTypeCommission    = "COMMISSION"
 	EmployeeSalaryComponentTypeBonusTarget   = "BONUS_TARGET"
+	// CASH_DEBT_DEDUCTION is subtracted from the pay, not added:
+	// one row per cash advance the run repays (ref_id = cash_debt).
+	EmployeeSalaryComponentTypeCashDebtDeduction = "CASH_DEBT_DEDUCTION"
+)
+
+// Payroll run states. DRAFT -> APPROVED -> PAID; VOID is reachable
+// from any of them and final.
+const (
+	EmployeeSalaryStatusDraft    = "DRAFT"
+	EmployeeSalaryStatusApproved = "APPROVED"
+	EmployeeSalaryStatusPaid     = "PAID"
+	EmployeeSalaryStatusVoid     = "VOID"
 )
 
 // Ref-source values stored on employee_salary_component.ref_source.
 const (
-	EmployeeSalaryRefSourceSales = "SALES"
+	EmployeeSalaryRefSourceSales    = "SALES"
+	EmployeeSalaryRefSourceCashDebt = "CASH_DEBT"
+	// ATTENDANCE components are earned over the whole window from
+	// the days worked; they carry no ref_id.
+	EmployeeSalaryRefSourceAttendance = "ATTENDANCE"
 )
 
 // ===== EmployeeSalaryComponentDto =====
@@ -63,6 +79,7 @@ func (d *EmployeeSalaryComponentDto) ToModel() *model.EmployeeSalaryComponent {
 type EmployeeSalaryDto struct {
 	ID                 string                       `json:"id"`
 	OrganizationID     string                       `json:"-"`
+	BatchID            string                       `json:"batchId,omitempty"`
 	AdminIDEmployee    string                       `json:"adminIdEmployee"`
 	StartDate          string                       `json:"startDate"` // YYYY-MM-DD
 	EndDate            string                       `json:"endDate"`
@@ -72,8 +89,23 @@ type EmployeeSalaryDto struct {
 	TotalBonusTarget   float64                      `json:"totalBonusTarget"`
 	TotalSalary        float64                      `json:"totalSalary"`
 	TotalCashReceipt   float64                      `json:"totalCashReceipt"`
+	TotalCashDebt      float64                      `json:"totalCashDebtDeduction"`
 	RemainingSalary    float64                      `json:"remainingSalary"`
 	Components         []EmployeeSalaryComponentDto `json:"components,omitempty"`
+
+	Status                string     `json:"status"`
+	CreatedBy             string     `json:"createdBy"`
+	CreatedAt             time.Time  `json:"createdAt"`
+	ApprovedAt            *time.Time `json:"approvedAt"`
+	ApprovedBy            string     `json:"approvedBy"`
+	PaidAt                *time.Time `json:"paidAt"`
+	PaidBy                string     `json:"paidBy"`
+	VoidedAt              *time.Time `json:"voidedAt"`
+	VoidedBy              string     `json:"voidedBy"`
+	VoidReason            string     `json:"voidReason"`
+	SaveJournalEntryID    string     `json:"saveJournalEntryId"`
+	AccrualJournalEntryID string     `json:"accrualJournalEntryId"`
+	PaymentJournalEntryID string     `json:"paymentJournalEntryId"`
 }
 
 func NewEmployeeSalaryDtoFromModel(m *model.EmployeeSalary) *EmployeeSalaryDto {
@@ -83,6 +115,7 @@ func NewEmployeeSalaryDtoFromModel(m *model.EmployeeSalary) *EmployeeSalaryDto {
 	return &EmployeeSalaryDto{
 		ID:                 m.ID,
 		OrganizationID:     m.OrganizationID,
+		BatchID:            m.BatchID,
 		AdminIDEmployee:    m.AdminIDEmployee,
 		StartDate:          m.StartDate.Format("2006-01-02"),
 		EndDate:            m.EndDate.Format("2006-01-02"),
@@ -92,7 +125,22 @@ func NewEmployeeSalaryDtoFromModel(m *model.EmployeeSalary) *EmployeeSalaryDto {
 		TotalBonusTarget:   m.TotalBonusTarget,
 		TotalSalary:        m.TotalSalary,
 		TotalCashReceipt:   m.TotalCashReceipt,
+		TotalCashDebt:      m.TotalCashDebt,
 		RemainingSalary:    m.RemainingSalary,
+
+		Status:                m.Status,
+		CreatedBy:             m.CreatedBy,
+		CreatedAt:             m.CreatedAt,
+		ApprovedAt:            m.ApprovedAt,
+		ApprovedBy:            m.ApprovedBy,
+		PaidAt:                m.PaidAt,
+		PaidBy:                m.PaidBy,
+		VoidedAt:              m.VoidedAt,
+		VoidedBy:              m.VoidedBy,
+		VoidReason:            m.VoidReason,
+		SaveJournalEntryID:    m.SaveJournalEntryID,
+		AccrualJournalEntryID: m.AccrualJournalEntryID,
+		PaymentJournalEntryID: m.PaymentJournalEntryID,
 	}
 }
 
@@ -101,6 +149,7 @@ func (d *EmployeeSalaryDto) ToModel() *model.EmployeeSalary {
 	endDate, _ := time.Parse("2006-01-02", d.EndDate)
 	m := &model.EmployeeSalary{
 		OrganizationID:     d.OrganizationID,
+		BatchID:            d.BatchID,
 		AdminIDEmployee:    d.AdminIDEmployee,
 		StartDate:          startDate,
 		EndDate:            endDate,
@@ -110,7 +159,21 @@ func (d *EmployeeSalaryDto) ToModel() *model.EmployeeSalary {
 		TotalBonusTarget:   d.TotalBonusTarget,
 		TotalSalary:        d.TotalSalary,
 		TotalCashReceipt:   d.TotalCashReceipt,
+		TotalCashDebt:      d.TotalCashDebt,
 		RemainingSalary:    d.RemainingSalary,
+
+		Status:                d.Status,
+		CreatedBy:             d.CreatedBy,
+		ApprovedAt:            d.ApprovedAt,
+		ApprovedBy:            d.ApprovedBy,
+		PaidAt:                d.PaidAt,
+		PaidBy:                d.PaidBy,
+		VoidedAt:              d.VoidedAt,
+		VoidedBy:              d.VoidedBy,
+		VoidReason:            d.VoidReason,
+		SaveJournalEntryID:    d.SaveJournalEntryID,
+		AccrualJournalEntryID: d.AccrualJournalEntryID,
+		PaymentJournalEntryID: d.PaymentJournalEntryID,
 	}
 	if d.ID != "" {
 		m.ID = d.ID
@@ -125,10 +188,27 @@ func (d *EmployeeSalaryDto) ToModel() *model.EmployeeSalary {
 // given date range, walks each session's resolved commission + salary
 // breakdown (already stored on the row from close time), and produces
 // a SimulatePayrollResultDto the frontend can show before saving.
+//
+// CashDebtDeductionCap is the operator's ceiling on how much unpaid
+// cash debt this run may recover; nil means no ceiling beyond the
+// pay itself.
 type SimulatePayrollRequest struct {
-	AdminIDEmployee string `json:"adminIdEmployee" validate:"required"`
-	StartDate       string `json:"startDate"        validate:"required,len=10"`
-	EndDate         string `json:"endDate"          validate:"required,len=10"`
+	AdminIDEmployee      string   `json:"adminIdEmployee" validate:"required"`
+	StartDate            string   `json:"startDate"        validate:"required,len=10"`
+	EndDate              string   `json:"endDate"          validate:"required,len=10"`
+	TotalCashReceipt     float64  `json:"totalCashReceipt" validate:"gte=0"`
+	CashDebtDeductionCap *float64 `json:"cashDebtDeductionCap" validate:"omitempty,gte=0"`
+}
+
+// PayrollCashDebtDeductionDto is one unpaid cash advance the run
+// proposes to recover. Outstanding is what was owed before the
+// run; Deduction is how much of it the run takes.
+type PayrollCashDebtDeductionDto struct {
+	CashDebtID  string  `json:"cashDebtId"  validate:"required"`
+	Date        string  `json:"date"`
+	Amount      float64 `json:"amount"`
+	Outstanding float64 `json:"outstanding"`
+	Deduction   float64 `json:"deduction"   validate:"gt=0"`
 }
 
 // ===== SimulatePayrollResultDto =====
@@ -148,13 +228,19 @@ type SimulatePayrollSessionCashDebtDto struct {
 // It mirrors the per-session totals already persisted on stock_session
 // so the operator can verify what will land in employee_salary.
 //
+// Meal allowance, bonus target and the minimum-target commission
+// are worked out per day, on the sum of the day's sessions, and
+// shown on the day's first shift; a later shift carries only its
+// commission.
+//
 // CashDebts holds every cash_debt row whose date matches this
-// session's date — the simulator surfaces them so the operator
+// session's date (the day's first shift only) — the simulator surfaces them so the operator
 // can see exactly which advances are being netted from the
 // remaining salary, instead of seeing only the rolled total.
 type SimulatePayrollSessionDto struct {
 	SessionID           string                              `json:"sessionId"`
 	Date                string                              `json:"date"`
+	Shift               string                              `json:"shift"`
 	Status              string                              `json:"status"`
 	TotalSales          float64                             `json:"totalSales"`
 	Attendance          float64                             `json:"attendance"`
@@ -171,9 +257,13 @@ type SimulatePayrollSessionDto struct {
 // and the rolled-up totals the Save action will persist.
 //
 // TotalCashDebt is the sum of every cash_debt row in the date
-// range for this employee. RemainingSalary already nets it out:
+// range for this employee (evidence only). The deduction itself is
+// worked out from what is still unpaid: every advance dated up to
+// EndDate that is not fully settled, older periods included, taken
+// oldest first until CashDebtDeductionCap or the pay runs out.
+// RemainingSalary nets it out:
 //
-//	remaining = totalSalary - totalCashReceipt - totalCashDebt
+//	remaining = totalSalary - totalCashReceipt - cashDebtDeduction
 type SimulatePayrollResultDto struct {
 	AdminIDEmployee    string                      `json:"adminIdEmployee"`
 	StartDate          string                      `json:"startDate"`
@@ -188,18 +278,42 @@ type SimulatePayrollResultDto struct {
 	TotalCashDebt      float64                     `json:"totalCashDebt"`
 	RemainingSalary    float64                     `json:"remainingSalary"`
 	SessionCount       int                         `json:"sessionCount"`
+	// DaysWorked counts the days in the window with a clock-in;
+	// TotalAttendance is the ATTENDANCE band it clears.
+	DaysWorked int `json:"daysWorked"`
+
+	CashDebtOutstanding  float64                       `json:"cashDebtOutstanding"`
+	CashDebtDeductionCap *float64                      `json:"cashDebtDeductionCap"`
+	CashDebtDeduction    float64                       `json:"cashDebtDeduction"`
+	CashDebtDeductions   []PayrollCashDebtDeductionDto `json:"cashDebtDeductions"`
 }
 
 // EmployeeSalaryFindAllRequest powers GET /api/payroll.
 type EmployeeSalaryFindAllRequest struct {
 	FindAllRequest
 	AdminIDEmployee string
+	Status          string
+	BatchID         string
+}
+
+// PayrollVoidRequest is the body of POST /api/payroll/:id/void.
+type PayrollVoidRequest struct {
+	Reason string `json:"reason" validate:"required,max=1000"`
 }
 
 // SavePayrollRequest is the wire shape for POST /api/payroll/save.
 // The frontend sends the operator-approved totals + per-component
-// breakdown (already grouped by component type + ref_id); the
-// service flattens this into employee_salary + employee_salary_component.
+// breakdown (already grouped by component type + ref_id) as it was
+// simulated; the service works the earnings out again from the
+// sessions and flattens them into employee_salary +
+// employee_salary_component, so the totals and Components sent are
+// not trusted.
+//
+// CashDebtDeductions is the deduction list from the simulation. The
+// service adds a CASH_DEBT_DEDUCTION component per entry and settles
+// the advances (method PAYROLL, ref employee_salary) in the same
+// transaction, so an advance that was repaid in the meantime fails
+// the save instead of being recovered twice.
 type SavePayrollRequest struct {
 	AdminIDEmployee    string                       `json:"adminIdEmployee" validate:"required"`
 	StartDate          string                       `json:"startDate"        validate:"required,len=10"`
@@ -211,4 +325,16 @@ type SavePayrollRequest struct {
 	TotalSalary        float64                      `json:"totalSalary"`
 	TotalCashReceipt   float64                      `json:"totalCashReceipt"`
 	Components         []EmployeeSalaryComponentDto `json:"components"`
+
+	CashDebtDeductionCap *float64                      `json:"cashDebtDeductionCap" validate:"omitempty,gte=0"`
+	CashDebtDeductions   []PayrollCashDebtDeductionDto `json:"cashDebtDeductions"   validate:"dive"`
+}
+
+// PayslipDto is what the payslip PDF is rendered from: the run with
+// its components, the driver's name, and the sessions the SALES
+// components point at with the amounts the run took from each.
+type PayslipDto struct {
+	Run          *EmployeeSalaryDto
+	EmployeeName string
+	Sessions     []SimulatePayrollSessionDto
 }
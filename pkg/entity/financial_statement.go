@@ -0,0 +1,102 @@
+package entity
+
+// Cash flow activities. Without a current / non-current split on
+// the chart of accounts, every non-equity counterpart is treated as
+// operating; equity movements (capital in, drawings out) are
+// financing.
+const (
+	CashFlowActivityOperating = "OPERATING"
+	CashFlowActivityFinancing = "FINANCING"
+)
+
+// CashFlowActivityOf maps the type of the account on the other side
+// of a cash movement to its cash flow activity.
+func CashFlowActivityOf(accountType string) string {
+	if accountType == AccountTypeEquity {
+		return CashFlowActivityFinancing
+	}
+	return CashFlowActivityOperating
+}
+
+// FinancialStatementRequest powers GET /api/reports/profit-loss and
+// GET /api/reports/cash-flow. From / To are inclusive YYYY-MM-DD
+// bounds; empty From = first day of To's month, empty To = today.
+//
+// Compare adds the previous period: the same number of calendar
+// months before From when the window is whole months, otherwise the
+// same number of days.
+type FinancialStatementRequest struct {
+	From    string `query:"from"`
+	To      string `query:"to"`
+	Compare bool   `query:"compare"`
+}
+
+// FinancialStatementLineDto is one account on a statement. Amount
+// is on the account's normal side for the profit and loss, and the
+// cash effect (positive = cash in) for the cash flow. The Previous*
+// and Change* fields are only filled when comparing.
+type FinancialStatementLineDto struct {
+	AccountID      string  `json:"accountId"`
+	Code           string  `json:"code"`
+	Name           string  `json:"name"`
+	Amount         float64 `json:"amount"`
+	PreviousAmount float64 `json:"previousAmount"`
+	Change         float64 `json:"change"`
+	ChangePercent  float64 `json:"changePercent"`
+}
+
+// FinancialStatementSectionDto groups the lines of one account type.
+// Activity is only set on cash flow sections.
+type FinancialStatementSectionDto struct {
+	Type          string                      `json:"type"`
+	Activity      string                      `json:"activity,omitempty"`
+	Lines         []FinancialStatementLineDto `json:"lines"`
+	Total         float64                     `json:"total"`
+	PreviousTotal float64                     `json:"previousTotal"`
+}
+
+// ProfitLossDto is the profit and loss statement: REVENUE and
+// EXPENSE accounts moved inside the window, read on their normal
+// side. NetIncome = revenue - expense.
+type ProfitLossDto struct {
+	From                   string                       `json:"from"`
+	To                     string                       `json:"to"`
+	Compare                bool                         `json:"compare"`
+	PreviousFrom           string                       `json:"previousFrom,omitempty"`
+	PreviousTo             string                       `json:"previousTo,omitempty"`
+	Revenue                FinancialStatementSectionDto `json:"revenue"`
+	Expense                FinancialStatementSectionDto `json:"expense"`
+	NetIncome              float64                      `json:"netIncome"`
+	PreviousNetIncome      float64                      `json:"previousNetIncome"`
+	NetIncomeChange        float64                      `json:"netIncomeChange"`
+	NetIncomeChangePercent float64                      `json:"netIncomeChangePercent"`
+}
+
+// CashFlowDto is the direct-method cash flow statement. Every
+// journal entry that moves a cash account (is_cash) is attributed
+// to the accounts on its other side, grouped by their type, so
+// OpeningCash + NetChange = ClosingCash.
+type CashFlowDto struct {
+	From                string                         `json:"from"`
+	To                  string                         `json:"to"`
+	Compare             bool                           `json:"compare"`
+	PreviousFrom        string                         `json:"previousFrom,omitempty"`
+	PreviousTo          string                         `json:"previousTo,omitempty"`
+	OpeningCash         float64                        `json:"openingCash"`
+	Sections            []FinancialStatementSectionDto `json:"sections"`
+	NetChange           float64                        `json:"netChange"`
+	ClosingCash         float64                        `json:"closingCash"`
+	PreviousOpeningCash float64                        `json:"previousOpeningCash"`
+	PreviousNetChange   float64                        `json:"previousNetChange"`
+	PreviousClosingCash float64                        `json:"previousClosingCash"`
+}
+
+// LedgerLegDto is one mutation as the cash flow builder reads it:
+// GroupKey ties the legs of one posting together (the journal entry,
+// or the upstream ref for mutations posted before journal entries
+// existed).
+type LedgerLegDto struct {
+	GroupKey  string
+	AccountID string
+	Amount    float64
+}
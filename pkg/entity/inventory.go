@@ -0,0 +1,195 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/raymondsugiarto/coffee-api/pkg/model"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/pagination"
+)
+
+// Stock movement types — wire enum. RECEIPT and SESSION_RETURN add
+// to the depot, SESSION_OUT takes from it, ADJUSTMENT goes either
+// way (stock opname).
+const (
+	StockMovementTypeReceipt       = "RECEIPT"
+	StockMovementTypeSessionOut    = "SESSION_OUT"
+	StockMovementTypeSessionReturn = "SESSION_RETURN"
+	StockMovementTypeAdjustment    = "ADJUSTMENT"
+)
+
+// ItemStockDto is the depot on-hand of one item. LowStock is set
+// when a watched item (ReorderPoint > 0) is at or below its reorder
+// point.
+type ItemStockDto struct {
+	OrganizationID string    `json:"-"`
+	ItemID         string    `json:"itemId"`
+	ItemCode       string    `json:"itemCode"`
+	ItemName       string    `json:"itemName"`
+	OnHand         int       `json:"onHand"`
+	ReorderPoint   int       `json:"reorderPoint"`
+	LowStock       bool      `json:"lowStock"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+func NewItemStockDtoFromModel(m *model.ItemStock) *ItemStockDto {
+	if m == nil {
+		return nil
+	}
+	d := &ItemStockDto{
+		OrganizationID: m.OrganizationID,
+		ItemID:         m.ItemID,
+		OnHand:         m.OnHand,
+		ReorderPoint:   m.ReorderPoint,
+		LowStock:       m.ReorderPoint > 0 && m.OnHand <= m.ReorderPoint,
+		UpdatedAt:      m.UpdatedAt,
+	}
+	if m.Item != nil {
+		d.ItemCode = m.Item.Code
+		d.ItemName = m.Item.Name
+	}
+	return d
+}
+
+type StockMovementDto struct {
+	ID             string    `json:"id"`
+	OrganizationID string    `json:"-"`
+	ItemID         string    `json:"itemId"`
+	ItemName       string    `json:"itemName,omitempty"`
+	Date           string    `json:"date"` // YYYY-MM-DD
+	Type           string    `json:"type"`
+	Qty            int       `json:"qty"`
+	BalanceAfter   int       `json:"balanceAfter"`
+	RefID          string    `json:"refId,omitempty"`
+	RefTable       string    `json:"refTable,omitempty"`
+	Reference      string    `json:"reference"`
+	Notes          string    `json:"notes"`
+	CreatedBy      string    `json:"createdBy"`
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
+func NewStockMovementDtoFromModel(m *model.StockMovement) *StockMovementDto {
+	if m == nil {
+		return nil
+	}
+	d := &StockMovementDto{
+		ID:             m.ID,
+		OrganizationID: m.OrganizationID,
+		ItemID:         m.ItemID,
+		Date:           m.Date.Format("2006-01-02"),
+		Type:           m.Type,
+		Qty:            m.Qty,
+		BalanceAfter:   m.BalanceAfter,
+		RefID:          m.RefID,
+		RefTable:       m.RefTable,
+		Reference:      m.Reference,
+		Notes:          m.Notes,
+		CreatedBy:      m.CreatedBy,
+		CreatedAt:      m.CreatedAt,
+	}
+	if m.Item != nil {
+		d.ItemName = m.Item.Name
+	}
+	return d
+}
+
+func (d *StockMovementDto) ToModel() *model.StockMovement {
+	date, _ := time.Parse("2006-01-02", d.Date)
+	m := &model.StockMovement{
+		OrganizationID: d.OrganizationID,
+		ItemID:         d.ItemID,
+		Date:           date,
+		Type:           d.Type,
+		Qty:            d.Qty,
+		BalanceAfter:   d.BalanceAfter,
+		RefID:          d.RefID,
+		RefTable:       d.RefTable,
+		Reference:      d.Reference,
+		Notes:          d.Notes,
+		CreatedBy:      d.CreatedBy,
+	}
+	if d.ID != "" {
+		m.ID = d.ID
+	}
+	return m
+}
+
+// StockReceiptRequest is goods delivered to the depot, as a goods
+// receipt hands them to the inventory. Date defaults to today;
+// Reference is the supplier's delivery note or invoice number;
+// RefID / RefTable point back at the receiving document.
+type StockReceiptRequest struct {
+	Date      string                    `json:"date" validate:"omitempty,len=10"` // YYYY-MM-DD
+	Reference string                    `json:"reference" validate:"max=255"`
+	Notes     string                    `json:"notes"`
+	Items     []StockReceiptItemRequest `json:"items" validate:"required,min=1,dive"`
+
+	RefID    string `json:"-"`
+	RefTable string `json:"-"`
+}
+
+type StockReceiptItemRequest struct {
+	ItemID string `json:"itemId" validate:"required"`
+	Qty    int    `json:"qty" validate:"gt=0"`
+}
+
+// ReorderPointRequest is the body of PUT
+// /api/inventory/stock/:itemId/reorder-point. 0 stops watching the
+// item.
+type ReorderPointRequest struct {
+	ReorderPoint int `json:"reorderPoint" validate:"gte=0"`
+}
+
+// LowStockDto lists one organization's watched items that are at or
+// below their reorder point, as sent by the low-stock alert.
+type LowStockDto struct {
+	OrganizationID   string          `json:"organizationId"`
+	OrganizationName string          `json:"organizationName"`
+	Items            []*ItemStockDto `json:"items"`
+}
+
+// ItemStockFindAllRequest powers GET /api/inventory/stock.
+// LowStock keeps only watched items at or below their reorder
+// point.
+type ItemStockFindAllRequest struct {
+	FindAllRequest
+	ItemID   string
+	LowStock bool
+}
+
+func (r *ItemStockFindAllRequest) GenerateFilter() {
+	if r.ItemID != "" {
+		r.Filter = append(r.Filter, pagination.FilterItem{Field: "item_id", Op: "eq", Val: r.ItemID})
+	}
+}
+
+// StockMovementFindAllRequest powers GET /api/inventory/movements.
+type StockMovementFindAllRequest struct {
+	FindAllRequest
+	ItemID   string
+	Type     string
+	RefID    string
+	RefTable string
+	From     string // YYYY-MM-DD
+	To       string // YYYY-MM-DD
+}
+
+func (r *StockMovementFindAllRequest) GenerateFilter() {
+	if r.ItemID != "" {
+		r.Filter = append(r.Filter, pagination.FilterItem{Field: "item_id", Op: "eq", Val: r.ItemID})
+	}
+	if r.Type != "" {
+		r.Filter = append(r.Filter, pagination.FilterItem{Field: "type", Op: "eq", Val: r.Type})
+	}
+	if r.RefID != "" {
+		r.Filter = append(r.Filter, pagination.FilterItem{Field: "ref_id", Op: "eq", Val: r.RefID})
+	}
+	if r.RefTable != "" {
+		r.Filter = append(r.Filter, pagination.FilterItem{Field: "ref_table", Op: "eq", Val: r.RefTable})
+	}
+	if r.From != "" {
+		r.Filter = append(r.Filter, pagination.FilterItem{Field: "date", Op: "gte", Val: r.From})
+	}
+	if r.To != "" {
+		r.Filter = append(r.Filter, pagination.FilterItem{Field: "date", Op: "lte", Val: r.To})
+	}
+}
@@ -0,0 +1,110 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/raymondsugiarto/coffee-api/pkg/model"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/pagination"
+)
+
+// Journal entry sources. Entries produced by posting rules use the
+// event type (PostingEvent*) as their source.
+const (
+	JournalEntrySourceManual   = "MANUAL"
+	JournalEntrySourceReversal = "REVERSAL"
+)
+
+// JournalLineDto is one leg of a journal entry. Amount is signed
+// like account_mutation.amount: positive = debit, negative =
+// credit.
+type JournalLineDto struct {
+	ID          string  `json:"id"`
+	AccountID   string  `json:"accountId"   validate:"required"`
+	Amount      float64 `json:"amount"      validate:"required"`
+	Description string  `json:"description"`
+}
+
+// JournalEntryDto is the wire shape for /api/journal-entries.
+//
+// Lines must number at least two and sum to zero. RefTable / RefID
+// / RefModule are filled by the service for manual entries (they
+// point at the entry itself) and by the posting service for
+// event-driven ones.
+type JournalEntryDto struct {
+	ID             string            `json:"id"`
+	OrganizationID string            `json:"-"`
+	Description    string            `json:"description"    validate:"required,max=1000"`
+	Source         string            `json:"source"`
+	RefID          string            `json:"refId"`
+	RefTable       string            `json:"refTable"`
+	RefModule      string            `json:"refModule"`
+	ReversalOfID   string            `json:"reversalOfId"`
+	ReversedByID   string            `json:"reversedById"`
+	CreatedBy      string            `json:"createdBy"`
+	CreatedAt      time.Time         `json:"createdAt"`
+	Lines          []*JournalLineDto `json:"lines"          validate:"required,min=2,dive"`
+}
+
+func NewJournalEntryDtoFromModel(m *model.JournalEntry) *JournalEntryDto {
+	if m == nil {
+		return nil
+	}
+	return &JournalEntryDto{
+		ID:             m.ID,
+		OrganizationID: m.OrganizationID,
+		Description:    m.Description,
+		Source:         m.Source,
+		RefID:          m.RefID,
+		RefTable:       m.RefTable,
+		RefModule:      m.RefModule,
+		ReversalOfID:   m.ReversalOfID,
+		ReversedByID:   m.ReversedByID,
+		CreatedBy:      m.CreatedBy,
+		CreatedAt:      m.CreatedAt,
+	}
+}
+
+func (d *JournalEntryDto) ToModel() *model.JournalEntry {
+	m := &model.JournalEntry{
+		OrganizationID: d.OrganizationID,
+		Description:    d.Description,
+		Source:         d.Source,
+		RefID:          d.RefID,
+		RefTable:       d.RefTable,
+		RefModule:      d.RefModule,
+		ReversalOfID:   d.ReversalOfID,
+		ReversedByID:   d.ReversedByID,
+		CreatedBy:      d.CreatedBy,
+	}
+	if d.ID != "" {
+		m.ID = d.ID
+	}
+	return m
+}
+
+// JournalEntryReverseRequest is the optional body of
+// POST /api/journal-entries/:id/reverse. An empty description
+// defaults to "Reversal of <original description>".
+type JournalEntryReverseRequest struct {
+	Description string `json:"description" validate:"max=1000"`
+}
+
+// JournalEntryFindAllRequest powers GET /api/journal-entries.
+type JournalEntryFindAllRequest struct {
+	FindAllRequest
+	Source   string
+	RefID    string
+	RefTable string
+}
+
+func (r *JournalEntryFindAllRequest) GenerateFilter() {
+	if r.Source != "" {
+		r.Filter = append(r.Filter, pagination.FilterItem{Field: "source", Op: "eq", Val: r.Source})
+	}
+	if r.RefID != "" {
+		r.Filter = append(r.Filter, pagination.FilterItem{Field: "ref_id", Op: "eq", Val: r.RefID})
+	}
+	if r.RefTable != "" {
+		r.Filter = append(r.Filter, pagination.FilterItem{Field: "ref_table", Op: "eq", Val: r.RefTable})
+	}
+}
@@ -0,0 +1,54 @@
+package entity
+
+import "time"
+
+// MarginReportRequest is the query of GET /api/report/margin. Both
+// bounds are inclusive session dates (YYYY-MM-DD); an empty From
+// defaults to 30 days before To, an empty To to today in Asia/Jakarta.
+type MarginReportRequest struct {
+	From string `query:"from"`
+	To   string `query:"to"`
+}
+
+// MarginLineDto is one pre-aggregated slice of closed-session sales:
+// one row per (day, driver, item, category) after variants have
+// been folded into their parent item.
+type MarginLineDto struct {
+	Date         time.Time
+	EmployeeID   string
+	EmployeeName string
+	ItemID       string
+	ItemCode     string
+	ItemName     string
+	CategoryID   string
+	CategoryName string
+	SoldQty      int
+	Revenue      float64
+	Cogs         float64
+}
+
+// MarginRowDto is one bucket of the margin report. ID / Name
+// identify the bucket (item, category, driver or day); for the day
+// breakdown both carry the date.
+type MarginRowDto struct {
+	ID            string  `json:"id"`
+	Code          string  `json:"code,omitempty"`
+	Name          string  `json:"name"`
+	SoldQty       int     `json:"soldQty"`
+	Revenue       float64 `json:"revenue"`
+	Cogs          float64 `json:"cogs"`
+	GrossMargin   float64 `json:"grossMargin"`
+	MarginPercent float64 `json:"marginPercent"`
+}
+
+// MarginReportDto breaks the same closed-session sales down four
+// ways. Every breakdown sums to Total.
+type MarginReportDto struct {
+	From       string         `json:"from"`
+	To         string         `json:"to"`
+	Total      MarginRowDto   `json:"total"`
+	ByItem     []MarginRowDto `json:"byItem"`
+	ByCategory []MarginRowDto `json:"byCategory"`
+	ByDriver   []MarginRowDto `json:"byDriver"`
+	ByDay      []MarginRowDto `json:"byDay"`
+}
@@ -63,6 +63,7 @@ type OrderDto struct {
 	TotalQty       int               `json:"totalQty"`
 	TotalAmount    float64           `json:"totalAmount"`
 	Status         string            `json:"status"`
+	JournalEntryID string            `json:"-"`
 	OrderItems     []OrderItemDto    `json:"orderItems"`
 	OrderPayments  []OrderPaymentDto `json:"orderPayments"`
 }
@@ -82,6 +83,7 @@ func NewOrderDtoFromModel(m *model.Order) *OrderDto {
 		TotalQty:       m.TotalQty,
 		TotalAmount:    m.TotalAmount,
 		Status:         m.Status,
+		JournalEntryID: m.JournalEntryID,
 	}
 }
 
@@ -0,0 +1,101 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/raymondsugiarto/coffee-api/pkg/model"
+)
+
+// PayrollBatchRequest is the body of POST /api/payroll/batch/simulate
+// and POST /api/payroll/batch. Every driver bound to the company
+// through admin_company is simulated over the window.
+//
+// TotalCashReceipts is the operator-entered cash receipt per driver
+// (keyed by admin id; missing means 0). CashDebtDeductionCap applies
+// to each driver's run separately.
+type PayrollBatchRequest struct {
+	CompanyID            string             `json:"companyId"            validate:"required"`
+	StartDate            string             `json:"startDate"            validate:"required,len=10"`
+	EndDate              string             `json:"endDate"              validate:"required,len=10"`
+	TotalCashReceipts    map[string]float64 `json:"totalCashReceipts"`
+	CashDebtDeductionCap *float64           `json:"cashDebtDeductionCap" validate:"omitempty,gte=0"`
+}
+
+// PayrollBatchEmployeeDto is one driver's simulation inside a batch.
+type PayrollBatchEmployeeDto struct {
+	EmployeeName string `json:"employeeName"`
+	SimulatePayrollResultDto
+}
+
+// PayrollBatchSimulateResultDto is the batch preview: one simulation
+// per driver plus the company totals.
+type PayrollBatchSimulateResultDto struct {
+	CompanyID         string                    `json:"companyId"`
+	StartDate         string                    `json:"startDate"`
+	EndDate           string                    `json:"endDate"`
+	Employees         []PayrollBatchEmployeeDto `json:"employees"`
+	TotalSalary       float64                   `json:"totalSalary"`
+	TotalCashReceipt  float64                   `json:"totalCashReceipt"`
+	CashDebtDeduction float64                   `json:"cashDebtDeduction"`
+	RemainingSalary   float64                   `json:"remainingSalary"`
+}
+
+// PayrollBatchRunDto is one driver's line in the batch summary.
+type PayrollBatchRunDto struct {
+	EmployeeSalaryID string  `json:"employeeSalaryId"`
+	AdminIDEmployee  string  `json:"adminIdEmployee"`
+	EmployeeName     string  `json:"employeeName"`
+	Status           string  `json:"status"`
+	TotalSalary      float64 `json:"totalSalary"`
+	TotalCashReceipt float64 `json:"totalCashReceipt"`
+	TotalCashDebt    float64 `json:"totalCashDebtDeduction"`
+	RemainingSalary  float64 `json:"remainingSalary"`
+}
+
+// PayrollBatchDto is the saved batch with its per-driver summary
+// (GET /api/payroll/batch/:id).
+type PayrollBatchDto struct {
+	ID               string               `json:"id"`
+	OrganizationID   string               `json:"-"`
+	CompanyID        string               `json:"companyId"`
+	StartDate        string               `json:"startDate"`
+	EndDate          string               `json:"endDate"`
+	CreatedBy        string               `json:"createdBy"`
+	CreatedAt        time.Time            `json:"createdAt"`
+	Runs             []PayrollBatchRunDto `json:"runs"`
+	TotalSalary      float64              `json:"totalSalary"`
+	TotalCashReceipt float64              `json:"totalCashReceipt"`
+	TotalCashDebt    float64              `json:"totalCashDebtDeduction"`
+	RemainingSalary  float64              `json:"remainingSalary"`
+}
+
+func NewPayrollBatchDtoFromModel(m *model.PayrollBatch) *PayrollBatchDto {
+	if m == nil {
+		return nil
+	}
+	return &PayrollBatchDto{
+		ID:             m.ID,
+		OrganizationID: m.OrganizationID,
+		CompanyID:      m.CompanyID,
+		StartDate:      m.StartDate.Format("2006-01-02"),
+		EndDate:        m.EndDate.Format("2006-01-02"),
+		CreatedBy:      m.CreatedBy,
+		CreatedAt:      m.CreatedAt,
+	}
+}
+
+func (d *PayrollBatchDto) ToModel() *model.PayrollBatch {
+	startDate, _ := time.Parse("2006-01-02", d.StartDate)
+	endDate, _ := time.Parse("2006-01-02", d.EndDate)
+	m := &model.PayrollBatch{
+		OrganizationID: d.OrganizationID,
+		CompanyID:      d.CompanyID,
+		StartDate:      startDate,
+		EndDate:        endDate,
+		CreatedBy:      d.CreatedBy,
+	}
+	if d.ID != "" {
+		m.ID = d.ID
+	}
+	return m
+}
@@ -0,0 +1,34 @@
+package entity
+
+// Bulk-transfer file layouts accepted by POST /api/payroll/disbursement.
+const (
+	PayrollDisbursementFormatBCA     = "BCA"     // KlikBCA Bisnis transfer massal
+	PayrollDisbursementFormatMandiri = "MANDIRI" // Mandiri Cash Management bulk upload
+)
+
+// PayrollDisbursementRequest picks the saved runs to pay by transfer,
+// either a whole batch or an explicit list of employee_salary ids.
+// SourceAccountNumber is the company account being debited;
+// TransferDate (YYYY-MM-DD) defaults to today.
+type PayrollDisbursementRequest struct {
+	Format              string   `json:"format"              validate:"required,oneof=BCA MANDIRI"`
+	BatchID             string   `json:"batchId"             validate:"required_without=EmployeeSalaryIDs"`
+	EmployeeSalaryIDs   []string `json:"employeeSalaryIds"   validate:"required_without=BatchID"`
+	SourceAccountNumber string   `json:"sourceAccountNumber" validate:"required,numeric"`
+	TransferDate        string   `json:"transferDate"        validate:"omitempty,len=10"`
+}
+
+// PayrollDisbursementLineDto is one transfer: a run's remaining
+// salary to the driver's bank account.
+type PayrollDisbursementLineDto struct {
+	EmployeeSalaryID  string
+	Status            string
+	StartDate         string
+	EndDate           string
+	AdminIDEmployee   string
+	EmployeeName      string
+	BankCode          string
+	BankAccountNumber string
+	BankAccountName   string
+	Amount            float64
+}
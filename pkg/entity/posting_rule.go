@@ -0,0 +1,206 @@
+package entity
+
+import (
+	"github.com/raymondsugiarto/coffee-api/pkg/model"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/pagination"
+)
+
+// Business events that post to the ledger through posting rules.
+const (
+	PostingEventStockSessionClosed = "STOCK_SESSION_CLOSED"
+	PostingEventPayrollSaved       = "PAYROLL_SAVED"
+	PostingEventPayrollApproved    = "PAYROLL_APPROVED"
+	PostingEventPayrollPaid        = "PAYROLL_PAID"
+	PostingEventCashDebtCreated    = "CASH_DEBT_CREATED"
+	PostingEventCashDebtSettled    = "CASH_DEBT_SETTLED"
+	PostingEventOrderCreated       = "ORDER_CREATED"
+	PostingEventGoodsReceived      = "GOODS_RECEIVED"
+	PostingEventOpnameApproved     = "STOCK_OPNAME_APPROVED"
+)
+
+// Amount fields an event carries. A rule picks one of them; the
+// emitter fills the value in PostingEventDto.Amounts.
+const (
+	// STOCK_SESSION_CLOSED
+	PostingAmountTotalCash       = "TOTAL_CASH"
+	PostingAmountTotalQris       = "TOTAL_QRIS"
+	PostingAmountTotalOther      = "TOTAL_OTHER"
+	PostingAmountTotalSales      = "TOTAL_SALES"
+	PostingAmountCashDebt        = "CASH_DEBT"
+	PostingAmountDriverShortage  = "DRIVER_SHORTAGE"
+	PostingAmountTotalCommission = "TOTAL_COMMISSION"
+	PostingAmountTotalCogs       = "TOTAL_COGS"
+
+	// PAYROLL_APPROVED (TOTAL_COMMISSION is shared with the session)
+	PostingAmountTotalSalary        = "TOTAL_SALARY"
+	PostingAmountTotalMealAllowance = "TOTAL_MEAL_ALLOWANCE"
+	PostingAmountTotalAttendance    = "TOTAL_ATTENDANCE"
+	PostingAmountTotalBonusTarget   = "TOTAL_BONUS_TARGET"
+
+	// PAYROLL_PAID
+	PostingAmountTotalCashReceipt = "TOTAL_CASH_RECEIPT"
+	PostingAmountRemainingSalary  = "REMAINING_SALARY"
+
+	// PAYROLL_SAVED carries the run as saved: the PAYROLL_APPROVED
+	// and PAYROLL_PAID fields plus the cash debt it recovered.
+	PostingAmountTotalCashDebt = "TOTAL_CASH_DEBT"
+
+	// CASH_DEBT_CREATED
+	PostingAmountAmount = "AMOUNT"
+
+	// CASH_DEBT_SETTLED: one field per settlement method, so each
+	// method can land on its own account.
+	PostingAmountSettledCash       = "SETTLED_CASH"
+	PostingAmountSettledPayroll    = "SETTLED_PAYROLL"
+	PostingAmountSettledTillOffset = "SETTLED_TILL_OFFSET"
+
+	// ORDER_CREATED
+	PostingAmountTotalAmount = "TOTAL_AMOUNT"
+
+	// GOODS_RECEIVED
+	PostingAmountTotalReceived = "TOTAL_RECEIVED"
+
+	// STOCK_OPNAME_APPROVED: losses and gains at cost price, each
+	// a positive amount.
+	PostingAmountTotalShrinkage = "TOTAL_SHRINKAGE"
+	PostingAmountTotalSurplus   = "TOTAL_SURPLUS"
+)
+
+// PostingRuleAmountFields lists the amount fields each event emits.
+// Rules are validated against it so a typo cannot produce a rule
+// that silently never posts.
+var PostingRuleAmountFields = map[string][]string{
+	PostingEventStockSessionClosed: {
+		PostingAmountTotalCash,
+		PostingAmountTotalQris,
+		PostingAmountTotalOther,
+		PostingAmountTotalSales,
+		PostingAmountCashDebt,
+		PostingAmountDriverShortage,
+		PostingAmountTotalCommission,
+		PostingAmountTotalCogs,
+	},
+	PostingEventPayrollSaved: {
+		PostingAmountTotalSalary,
+		PostingAmountTotalCommission,
+		PostingAmountTotalMealAllowance,
+		PostingAmountTotalAttendance,
+		PostingAmountTotalBonusTarget,
+		PostingAmountTotalCashReceipt,
+		PostingAmountTotalCashDebt,
+		PostingAmountRemainingSalary,
+	},
+	PostingEventPayrollApproved: {
+		PostingAmountTotalSalary,
+		PostingAmountTotalCommission,
+		PostingAmountTotalMealAllowance,
+		PostingAmountTotalAttendance,
+		PostingAmountTotalBonusTarget,
+	},
+	PostingEventPayrollPaid: {
+		PostingAmountTotalCashReceipt,
+		PostingAmountRemainingSalary,
+	},
+	PostingEventCashDebtCreated: {
+		PostingAmountAmount,
+	},
+	PostingEventCashDebtSettled: {
+		PostingAmountSettledCash,
+		PostingAmountSettledPayroll,
+		PostingAmountSettledTillOffset,
+	},
+	PostingEventOrderCreated: {
+		PostingAmountTotalAmount,
+	},
+	PostingEventGoodsReceived: {
+		PostingAmountTotalReceived,
+	},
+	PostingEventOpnameApproved: {
+		PostingAmountTotalShrinkage,
+		PostingAmountTotalSurplus,
+	},
+}
+
+// IsValidPostingAmountField reports whether `field` is emitted by
+// `eventType`.
+func IsValidPostingAmountField(eventType, field string) bool {
+	for _, f := range PostingRuleAmountFields[eventType] {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// PostingRuleDto is the wire shape for
+// /api/accounting/posting-rules. IsActive defaults to true; an
+// inactive rule posts nothing, and an inactive org rule switches
+// off the global default it overrides.
+type PostingRuleDto struct {
+	ID                string `json:"id"`
+	OrganizationID    string `json:"-"`
+	EventType         string `json:"eventType"         validate:"required,oneof=STOCK_SESSION_CLOSED PAYROLL_SAVED PAYROLL_APPROVED PAYROLL_PAID CASH_DEBT_CREATED CASH_DEBT_SETTLED ORDER_CREATED GOODS_RECEIVED STOCK_OPNAME_APPROVED"`
+	AmountField       string `json:"amountField"       validate:"required,max=64"`
+	DebitAccountCode  string `json:"debitAccountCode"  validate:"required,max=64"`
+	CreditAccountCode string `json:"creditAccountCode" validate:"required,max=64,nefield=DebitAccountCode"`
+	Description       string `json:"description"`
+	IsActive          *bool  `json:"isActive"`
+}
+
+func NewPostingRuleDtoFromModel(m *model.PostingRule) *PostingRuleDto {
+	if m == nil {
+		return nil
+	}
+	return &PostingRuleDto{
+		ID:                m.ID,
+		OrganizationID:    m.OrganizationID,
+		EventType:         m.EventType,
+		AmountField:       m.AmountField,
+		DebitAccountCode:  m.DebitAccountCode,
+		CreditAccountCode: m.CreditAccountCode,
+		Description:       m.Description,
+		IsActive:          &m.IsActive,
+	}
+}
+
+func (d *PostingRuleDto) ToModel() *model.PostingRule {
+	m := &model.PostingRule{
+		OrganizationID:    d.OrganizationID,
+		EventType:         d.EventType,
+		AmountField:       d.AmountField,
+		DebitAccountCode:  d.DebitAccountCode,
+		CreditAccountCode: d.CreditAccountCode,
+		Description:       d.Description,
+		IsActive:          d.IsActive == nil || *d.IsActive,
+	}
+	if d.ID != "" {
+		m.ID = d.ID
+	}
+	return m
+}
+
+// PostingRuleFindAllRequest powers GET /api/accounting/posting-rules.
+type PostingRuleFindAllRequest struct {
+	FindAllRequest
+	EventType string
+}
+
+func (r *PostingRuleFindAllRequest) GenerateFilter() {
+	if r.EventType != "" {
+		r.Filter = append(r.Filter, pagination.FilterItem{Field: "event_type", Op: "eq", Val: r.EventType})
+	}
+}
+
+// PostingEventDto is what an upstream flow hands to the accounting
+// module when a business event happens. Amounts is keyed by the
+// PostingAmount* constants of the event; fields without a matching
+// rule are ignored.
+type PostingEventDto struct {
+	OrganizationID string
+	EventType      string
+	RefID          string
+	RefTable       string
+	RefModule      string
+	Description    string
+	Amounts        map[string]float64
+}
@@ -0,0 +1,302 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/raymondsugiarto/coffee-api/pkg/model"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/pagination"
+)
+
+// Purchase order statuses — wire enum.
+const (
+	PurchaseOrderStatusOpen      = "OPEN"
+	PurchaseOrderStatusPartial   = "PARTIAL"
+	PurchaseOrderStatusReceived  = "RECEIVED"
+	PurchaseOrderStatusCancelled = "CANCELLED"
+)
+
+type PurchaseOrderLineDto struct {
+	ID           string  `json:"id"`
+	ItemID       string  `json:"itemId"   validate:"required"`
+	ItemCode     string  `json:"itemCode,omitempty"`
+	ItemName     string  `json:"itemName,omitempty"`
+	Qty          int     `json:"qty"      validate:"gt=0"`
+	UnitCost     float64 `json:"unitCost" validate:"gte=0"`
+	ReceivedQty  int     `json:"receivedQty"`
+	RemainingQty int     `json:"remainingQty"`
+}
+
+type PurchaseOrderDto struct {
+	ID             string                 `json:"id"`
+	OrganizationID string                 `json:"-"`
+	SupplierID     string                 `json:"supplierId"   validate:"required"`
+	Supplier       *SupplierDto           `json:"supplier,omitempty"`
+	Code           string                 `json:"code"`
+	Date           string                 `json:"date"         validate:"omitempty,len=10"` // YYYY-MM-DD
+	ExpectedDate   string                 `json:"expectedDate" validate:"omitempty,len=10"` // YYYY-MM-DD
+	Status         string                 `json:"status"`
+	Total          float64                `json:"total"`
+	Notes          string                 `json:"notes"`
+	CreatedBy      string                 `json:"createdBy"`
+	Lines          []PurchaseOrderLineDto `json:"lines"        validate:"required,min=1,dive"`
+}
+
+func NewPurchaseOrderDtoFromModel(m *model.PurchaseOrder) *PurchaseOrderDto {
+	if m == nil {
+		return nil
+	}
+	d := &PurchaseOrderDto{
+		ID:             m.ID,
+		OrganizationID: m.OrganizationID,
+		SupplierID:     m.SupplierID,
+		Supplier:       NewSupplierDtoFromModel(m.Supplier),
+		Code:           m.Code,
+		Date:           m.Date.Format("2006-01-02"),
+		Status:         m.Status,
+		Total:          m.Total,
+		Notes:          m.Notes,
+		CreatedBy:      m.CreatedBy,
+		Lines:          make([]PurchaseOrderLineDto, 0, len(m.Lines)),
+	}
+	if m.ExpectedDate != nil {
+		d.ExpectedDate = m.ExpectedDate.Format("2006-01-02")
+	}
+	for _, l := range m.Lines {
+		line := PurchaseOrderLineDto{
+			ID:           l.ID,
+			ItemID:       l.ItemID,
+			Qty:          l.Qty,
+			UnitCost:     l.UnitCost,
+			ReceivedQty:  l.ReceivedQty,
+			RemainingQty: l.Qty - l.ReceivedQty,
+		}
+		if line.RemainingQty < 0 {
+			line.RemainingQty = 0
+		}
+		if l.Item != nil {
+			line.ItemCode = l.Item.Code
+			line.ItemName = l.Item.Name
+		}
+		d.Lines = append(d.Lines, line)
+	}
+	return d
+}
+
+func (d *PurchaseOrderDto) ToModel() *model.PurchaseOrder {
+	date, _ := time.Parse("2006-01-02", d.Date)
+	m := &model.PurchaseOrder{
+		OrganizationID: d.OrganizationID,
+		SupplierID:     d.SupplierID,
+		Code:           d.Code,
+		Date:           date,
+		Status:         d.Status,
+		Total:          d.Total,
+		Notes:          d.Notes,
+		CreatedBy:      d.CreatedBy,
+	}
+	if d.ID != "" {
+		m.ID = d.ID
+	}
+	if d.ExpectedDate != "" {
+		if t, err := time.Parse("2006-01-02", d.ExpectedDate); err == nil {
+			m.ExpectedDate = &t
+		}
+	}
+	for _, l := range d.Lines {
+		m.Lines = append(m.Lines, model.PurchaseOrderLine{
+			PurchaseOrderID: d.ID,
+			ItemID:          l.ItemID,
+			Qty:             l.Qty,
+			UnitCost:        l.UnitCost,
+			ReceivedQty:     l.ReceivedQty,
+		})
+	}
+	return m
+}
+
+// ComputeTotal sets Total to the ordered value of the lines.
+func (d *PurchaseOrderDto) ComputeTotal() {
+	d.Total = 0
+	for _, l := range d.Lines {
+		d.Total += float64(l.Qty) * l.UnitCost
+	}
+}
+
+// PurchaseOrderFindAllRequest powers GET /api/purchase-orders.
+type PurchaseOrderFindAllRequest struct {
+	FindAllRequest
+	SupplierID string
+	Status     string
+	From       string // YYYY-MM-DD
+	To         string // YYYY-MM-DD
+}
+
+func (r *PurchaseOrderFindAllRequest) GenerateFilter() {
+	if r.SupplierID != "" {
+		r.Filter = append(r.Filter, pagination.FilterItem{Field: "supplier_id", Op: "eq", Val: r.SupplierID})
+	}
+	if r.Status != "" {
+		r.Filter = append(r.Filter, pagination.FilterItem{Field: "status", Op: "eq", Val: r.Status})
+	}
+	if r.From != "" {
+		r.Filter = append(r.Filter, pagination.FilterItem{Field: "date", Op: "gte", Val: r.From})
+	}
+	if r.To != "" {
+		r.Filter = append(r.Filter, pagination.FilterItem{Field: "date", Op: "lte", Val: r.To})
+	}
+}
+
+type GoodsReceiptLineDto struct {
+	ID                  string  `json:"id"`
+	PurchaseOrderLineID string  `json:"purchaseOrderLineId,omitempty"`
+	ItemID              string  `json:"itemId"`
+	ItemCode            string  `json:"itemCode,omitempty"`
+	ItemName            string  `json:"itemName,omitempty"`
+	Qty                 int     `json:"qty"`
+	UnitCost            float64 `json:"unitCost"`
+}
+
+type GoodsReceiptDto struct {
+	ID              string                `json:"id"`
+	OrganizationID  string                `json:"-"`
+	SupplierID      string                `json:"supplierId"`
+	Supplier        *SupplierDto          `json:"supplier,omitempty"`
+	PurchaseOrderID string                `json:"purchaseOrderId,omitempty"`
+	Code            string                `json:"code"`
+	Date            string                `json:"date"` // YYYY-MM-DD
+	Reference       string                `json:"reference"`
+	Total           float64               `json:"total"`
+	UpdateCostPrice bool                  `json:"updateCostPrice"`
+	JournalEntryID  string                `json:"journalEntryId,omitempty"`
+	Notes           string                `json:"notes"`
+	CreatedBy       string                `json:"createdBy"`
+	Lines           []GoodsReceiptLineDto `json:"lines"`
+}
+
+func NewGoodsReceiptDtoFromModel(m *model.GoodsReceipt) *GoodsReceiptDto {
+	if m == nil {
+		return nil
+	}
+	d := &GoodsReceiptDto{
+		ID:              m.ID,
+		OrganizationID:  m.OrganizationID,
+		SupplierID:      m.SupplierID,
+		Supplier:        NewSupplierDtoFromModel(m.Supplier),
+		PurchaseOrderID: m.PurchaseOrderID,
+		Code:            m.Code,
+		Date:            m.Date.Format("2006-01-02"),
+		Reference:       m.Reference,
+		Total:           m.Total,
+		UpdateCostPrice: m.UpdateCostPrice,
+		JournalEntryID:  m.JournalEntryID,
+		Notes:           m.Notes,
+		CreatedBy:       m.CreatedBy,
+		Lines:           make([]GoodsReceiptLineDto, 0, len(m.Lines)),
+	}
+	for _, l := range m.Lines {
+		line := GoodsReceiptLineDto{
+			ID:                  l.ID,
+			PurchaseOrderLineID: l.PurchaseOrderLineID,
+			ItemID:              l.ItemID,
+			Qty:                 l.Qty,
+			UnitCost:            l.UnitCost,
+		}
+		if l.Item != nil {
+			line.ItemCode = l.Item.Code
+			line.ItemName = l.Item.Name
+		}
+		d.Lines = append(d.Lines, line)
+	}
+	return d
+}
+
+func (d *GoodsReceiptDto) ToModel() *model.GoodsReceipt {
+	date, _ := time.Parse("2006-01-02", d.Date)
+	m := &model.GoodsReceipt{
+		OrganizationID:  d.OrganizationID,
+		SupplierID:      d.SupplierID,
+		PurchaseOrderID: d.PurchaseOrderID,
+		Code:            d.Code,
+		Date:            date,
+		Reference:       d.Reference,
+		Total:           d.Total,
+		UpdateCostPrice: d.UpdateCostPrice,
+		JournalEntryID:  d.JournalEntryID,
+		Notes:           d.Notes,
+		CreatedBy:       d.CreatedBy,
+	}
+	if d.ID != "" {
+		m.ID = d.ID
+	}
+	for _, l := range d.Lines {
+		m.Lines = append(m.Lines, model.GoodsReceiptLine{
+			PurchaseOrderLineID: l.PurchaseOrderLineID,
+			ItemID:              l.ItemID,
+			Qty:                 l.Qty,
+			UnitCost:            l.UnitCost,
+		})
+	}
+	return m
+}
+
+// GoodsReceiptRequest is the body of POST /api/goods-receipts.
+// With a PurchaseOrderID the supplier comes from the order, every
+// item must be on it and UnitCost defaults to the ordered cost;
+// without one SupplierID and UnitCost are required. Date defaults
+// to today. UpdateCostPrice moves each item's cost price to the
+// weighted average of the stock on hand and the goods received; it
+// is refused for shared items, whose cost every organization uses.
+type GoodsReceiptRequest struct {
+	SupplierID      string                    `json:"supplierId"      validate:"required_without=PurchaseOrderID"`
+	PurchaseOrderID string                    `json:"purchaseOrderId"`
+	Date            string                    `json:"date"            validate:"omitempty,len=10"` // YYYY-MM-DD
+	Reference       string                    `json:"reference"       validate:"max=255"`
+	Notes           string                    `json:"notes"`
+	UpdateCostPrice bool                      `json:"updateCostPrice"`
+	Items           []GoodsReceiptItemRequest `json:"items"           validate:"required,min=1,dive"`
+}
+
+type GoodsReceiptItemRequest struct {
+	ItemID   string   `json:"itemId"   validate:"required"`
+	Qty      int      `json:"qty"      validate:"gt=0"`
+	UnitCost *float64 `json:"unitCost" validate:"omitempty,gte=0"`
+}
+
+// GoodsReceiptFindAllRequest powers GET /api/goods-receipts.
+type GoodsReceiptFindAllRequest struct {
+	FindAllRequest
+	SupplierID      string
+	PurchaseOrderID string
+	From            string // YYYY-MM-DD
+	To              string // YYYY-MM-DD
+}
+
+func (r *GoodsReceiptFindAllRequest) GenerateFilter() {
+	if r.SupplierID != "" {
+		r.Filter = append(r.Filter, pagination.FilterItem{Field: "supplier_id", Op: "eq", Val: r.SupplierID})
+	}
+	if r.PurchaseOrderID != "" {
+		r.Filter = append(r.Filter, pagination.FilterItem{Field: "purchase_order_id", Op: "eq", Val: r.PurchaseOrderID})
+	}
+	if r.From != "" {
+		r.Filter = append(r.Filter, pagination.FilterItem{Field: "date", Op: "gte", Val: r.From})
+	}
+	if r.To != "" {
+		r.Filter = append(r.Filter, pagination.FilterItem{Field: "date", Op: "lte", Val: r.To})
+	}
+}
+
+// MovingAverageCost is the cost price after receiving qty units at
+// unitCost on top of onHand units valued at currentCost. Stock at
+// or below zero carries no value to average with, so the received
+// cost is taken as is.
+func MovingAverageCost(currentCost float64, onHand, qty int, unitCost float64) float64 {
+	if onHand <= 0 {
+		return unitCost
+	}
+	total := onHand + qty
+	if total <= 0 {
+		return unitCost
+	}
+	return (currentCost*float64(onHand) + unitCost*float64(qty)) / float64(total)
+}
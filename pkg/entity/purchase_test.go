@@ -0,0 +1,34 @@
+package entity
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMovingAverageCost(t *testing.T) {
+	tests := []struct {
+		name        string
+		currentCost float64
+		onHand      int
+		qty         int
+		unitCost    float64
+		want        float64
+	}{
+		{"averages with stock on hand", 1000, 10, 10, 2000, 1500},
+		{"weights by quantity", 1000, 30, 10, 2000, 1250},
+		{"same cost stays", 1500, 7, 3, 1500, 1500},
+		{"empty stock takes the received cost", 1000, 0, 5, 1800, 1800},
+		{"negative stock takes the received cost", 1000, -4, 10, 1800, 1800},
+		{"nothing received keeps the cost", 1200, 10, 0, 9999, 1200},
+		{"fractional result", 1000, 3, 1, 1001, 1000.25},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MovingAverageCost(tt.currentCost, tt.onHand, tt.qty, tt.unitCost)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("MovingAverageCost(%v, %d, %d, %v) = %v, want %v",
+					tt.currentCost, tt.onHand, tt.qty, tt.unitCost, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/raymondsugiarto/coffee-api/pkg/model"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/pagination"
+)
+
+type RecipeLineDto struct {
+	ID             string  `json:"id"`
+	IngredientID   string  `json:"ingredientId"   validate:"required"`
+	IngredientCode string  `json:"ingredientCode,omitempty"`
+	IngredientName string  `json:"ingredientName,omitempty"`
+	Qty            float64 `json:"qty"            validate:"gt=0"`
+}
+
+type RecipeDto struct {
+	ID             string          `json:"id"`
+	OrganizationID string          `json:"-"`
+	ItemID         string          `json:"itemId"   validate:"required"`
+	ItemName       string          `json:"itemName,omitempty"`
+	Notes          string          `json:"notes"`
+	IsActive       bool            `json:"isActive"`
+	Lines          []RecipeLineDto `json:"lines"    validate:"required,min=1,dive"`
+}
+
+func NewRecipeDtoFromModel(m *model.Recipe) *RecipeDto {
+	if m == nil {
+		return nil
+	}
+	d := &RecipeDto{
+		ID:             m.ID,
+		OrganizationID: m.OrganizationID,
+		ItemID:         m.ItemID,
+		Notes:          m.Notes,
+		IsActive:       m.IsActive,
+		Lines:          make([]RecipeLineDto, 0, len(m.Lines)),
+	}
+	if m.Item != nil {
+		d.ItemName = m.Item.Name
+	}
+	for _, l := range m.Lines {
+		line := RecipeLineDto{ID: l.ID, IngredientID: l.IngredientID, Qty: l.Qty}
+		if l.Ingredient != nil {
+			line.IngredientCode = l.Ingredient.Code
+			line.IngredientName = l.Ingredient.Name
+		}
+		d.Lines = append(d.Lines, line)
+	}
+	return d
+}
+
+func (d *RecipeDto) ToModel() *model.Recipe {
+	m := &model.Recipe{
+		OrganizationID: d.OrganizationID,
+		ItemID:         d.ItemID,
+		Notes:          d.Notes,
+		IsActive:       d.IsActive,
+	}
+	if d.ID != "" {
+		m.ID = d.ID
+	}
+	for _, l := range d.Lines {
+		m.Lines = append(m.Lines, model.RecipeLine{RecipeID: d.ID, IngredientID: l.IngredientID, Qty: l.Qty})
+	}
+	return m
+}
+
+// RecipeFindAllRequest powers GET /api/recipes.
+type RecipeFindAllRequest struct {
+	FindAllRequest
+	ItemID string
+}
+
+func (r *RecipeFindAllRequest) GenerateFilter() {
+	if r.ItemID != "" {
+		r.Filter = append(r.Filter, pagination.FilterItem{Field: "item_id", Op: "eq", Val: r.ItemID})
+	}
+}
+
+type IngredientUsageDto struct {
+	ID             string  `json:"id"`
+	OrganizationID string  `json:"-"`
+	StockSessionID string  `json:"stockSessionId"`
+	Date           string  `json:"date"` // YYYY-MM-DD
+	ItemID         string  `json:"itemId"`
+	IngredientID   string  `json:"ingredientId"`
+	IngredientName string  `json:"ingredientName,omitempty"`
+	SoldQty        int     `json:"soldQty"`
+	QtyPerUnit     float64 `json:"qtyPerUnit"`
+	Qty            float64 `json:"qty"`
+}
+
+func NewIngredientUsageDtoFromModel(m *model.IngredientUsage) *IngredientUsageDto {
+	if m == nil {
+		return nil
+	}
+	d := &IngredientUsageDto{
+		ID:             m.ID,
+		OrganizationID: m.OrganizationID,
+		StockSessionID: m.StockSessionID,
+		Date:           m.Date.Format("2006-01-02"),
+		ItemID:         m.ItemID,
+		IngredientID:   m.IngredientID,
+		SoldQty:        m.SoldQty,
+		QtyPerUnit:     m.QtyPerUnit,
+		Qty:            m.Qty,
+	}
+	if m.Ingredient != nil {
+		d.IngredientName = m.Ingredient.Name
+	}
+	return d
+}
+
+func (d *IngredientUsageDto) ToModel() *model.IngredientUsage {
+	date, _ := time.Parse("2006-01-02", d.Date)
+	m := &model.IngredientUsage{
+		OrganizationID: d.OrganizationID,
+		StockSessionID: d.StockSessionID,
+		Date:           date,
+		ItemID:         d.ItemID,
+		IngredientID:   d.IngredientID,
+		SoldQty:        d.SoldQty,
+		QtyPerUnit:     d.QtyPerUnit,
+		Qty:            d.Qty,
+	}
+	if d.ID != "" {
+		m.ID = d.ID
+	}
+	return m
+}
+
+// IngredientUsageReportRowDto compares, for one ingredient over the
+// report window, what the recipes say the sales used (Theoretical)
+// with what actually left the depot (Actual: every stock movement
+// except receipts, negated, so session loads net of returns plus
+// opname adjustments). A positive Variance is usage the sales do
+// not explain — waste, spillage or unrecorded sales.
+type IngredientUsageReportRowDto struct {
+	IngredientID   string  `json:"ingredientId"`
+	IngredientCode string  `json:"ingredientCode"`
+	IngredientName string  `json:"ingredientName"`
+	TheoreticalQty float64 `json:"theoreticalQty"`
+	ActualQty      float64 `json:"actualQty"`
+	VarianceQty    float64 `json:"varianceQty"`
+	VariancePct    float64 `json:"variancePct"` // of theoretical; 0 when nothing was expected
+	CostPrice      float64 `json:"costPrice"`
+	VarianceValue  float64 `json:"varianceValue"`
+}
+
+type IngredientUsageReportDto struct {
+	From               string                        `json:"from"`
+	To                 string                        `json:"to"`
+	Rows               []IngredientUsageReportRowDto `json:"rows"`
+	TotalVarianceValue float64                       `json:"totalVarianceValue"`
+}
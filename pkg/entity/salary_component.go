@@ -1,6 +1,8 @@
 package entity
 
 import (
+	"time"
+
 	"github.com/raymondsugiarto/coffee-api/pkg/model"
 	"github.com/raymondsugiarto/coffee-api/pkg/shared/pagination"
 )
@@ -30,6 +32,11 @@ const (
 	ComponentTypeCommission ComponentType = "COMMISSION"
 )
 
+// SalaryComponentDto is one version of a salary band. On create and
+// update EffectiveFrom (YYYY-MM-DD) says from when the values apply,
+// defaulting to today (Asia/Jakarta); an update cannot start before
+// today. EffectiveTo is set by the service when a newer version
+// supersedes this one.
 type SalaryComponentDto struct {
 	ID             string        `json:"id"`
 	OrganizationID string        `json:"-"`
@@ -37,29 +44,45 @@ type SalaryComponentDto struct {
 	ComponentType  ComponentType `json:"componentType" validate:"required,oneof=MEAL_ALLOWANCE ATTENDANCE BONUS_TARGET"`
 	MinimumTarget  float64       `json:"minimumTarget" validate:"gte=0"`
 	Amount         float64       `json:"amount" validate:"gte=0"`
+	EffectiveFrom  string        `json:"effectiveFrom" validate:"omitempty,len=10"`
+	EffectiveTo    string        `json:"effectiveTo"`
+	PreviousID     string        `json:"previousId,omitempty"`
 }
 
 func NewSalaryComponentDtoFromModel(m *model.SalaryComponent) *SalaryComponentDto {
 	if m == nil {
 		return nil
 	}
-	return &SalaryComponentDto{
+	d := &SalaryComponentDto{
 		ID:             m.ID,
 		OrganizationID: m.OrganizationID,
 		CompanyID:      m.CompanyID,
 		ComponentType:  ComponentType(m.ComponentType),
 		MinimumTarget:  m.MinimumTarget,
 		Amount:         m.Amount,
+		EffectiveFrom:  m.EffectiveFrom.Format("2006-01-02"),
+		PreviousID:     m.PreviousID,
+	}
+	if m.EffectiveTo != nil {
+		d.EffectiveTo = m.EffectiveTo.Format("2006-01-02")
 	}
+	return d
 }
 
 func (d *SalaryComponentDto) ToModel() *model.SalaryComponent {
+	effectiveFrom, _ := time.Parse("2006-01-02", d.EffectiveFrom)
 	m := &model.SalaryComponent{
 		OrganizationID: d.OrganizationID,
 		CompanyID:      d.CompanyID,
 		ComponentType:  model.ComponentType(d.ComponentType),
 		MinimumTarget:  d.MinimumTarget,
 		Amount:         d.Amount,
+		EffectiveFrom:  effectiveFrom,
+		PreviousID:     d.PreviousID,
+	}
+	if d.EffectiveTo != "" {
+		effectiveTo, _ := time.Parse("2006-01-02", d.EffectiveTo)
+		m.EffectiveTo = &effectiveTo
 	}
 	if d.ID != "" {
 		m.ID = d.ID
@@ -67,10 +90,51 @@ func (d *SalaryComponentDto) ToModel() *model.SalaryComponent {
 	return m
 }
 
+// EffectiveOn reports whether this version applies on date
+// (YYYY-MM-DD). Dates compare as strings in that layout.
+func (d *SalaryComponentDto) EffectiveOn(date string) bool {
+	return d.EffectiveFrom <= date && (d.EffectiveTo == "" || d.EffectiveTo >= date)
+}
+
+// SalaryComponentsOn keeps the versions of components that apply on
+// date, preserving their order.
+func SalaryComponentsOn(components []SalaryComponentDto, date string) []SalaryComponentDto {
+	out := make([]SalaryComponentDto, 0, len(components))
+	for _, c := range components {
+		if c.EffectiveOn(date) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// ResolveAttendance picks the ATTENDANCE band for a period: the
+// row with the highest minimum_target the driver's days worked
+// still clear. No band cleared resolves to 0.
+func ResolveAttendance(components []SalaryComponentDto, daysWorked int) float64 {
+	var amount float64
+	best := -1.0
+	for _, c := range components {
+		if c.ComponentType != ComponentTypeAttendance {
+			continue
+		}
+		if c.MinimumTarget <= float64(daysWorked) && c.MinimumTarget > best {
+			best = c.MinimumTarget
+			amount = c.Amount
+		}
+	}
+	return amount
+}
+
+// SalaryComponentFindAllRequest lists the current versions by
+// default. On (YYYY-MM-DD) lists the versions valid on that date
+// instead; History lists every version.
 type SalaryComponentFindAllRequest struct {
 	FindAllRequest
 	CompanyID     string
 	ComponentType ComponentType
+	On            string
+	History       bool
 }
 
 func (r *SalaryComponentFindAllRequest) GenerateFilter() {
@@ -0,0 +1,41 @@
+package entity
+
+import "testing"
+
+func TestResolveAttendance(t *testing.T) {
+	bands := []SalaryComponentDto{
+		{ComponentType: ComponentTypeAttendance, MinimumTarget: 20, Amount: 300000},
+		{ComponentType: ComponentTypeAttendance, MinimumTarget: 10, Amount: 100000},
+		{ComponentType: ComponentTypeAttendance, MinimumTarget: 25, Amount: 500000},
+		// Other component types never count as attendance, even
+		// when their target is cleared.
+		{ComponentType: ComponentTypeBonusTarget, MinimumTarget: 0, Amount: 999999},
+		{ComponentType: ComponentTypeMealAllowance, MinimumTarget: 5, Amount: 888888},
+	}
+	tests := []struct {
+		name       string
+		components []SalaryComponentDto
+		daysWorked int
+		want       float64
+	}{
+		{"no band cleared", bands, 9, 0},
+		{"lowest band at its target", bands, 10, 100000},
+		{"between bands", bands, 19, 100000},
+		{"middle band at its target", bands, 20, 300000},
+		{"highest band", bands, 26, 500000},
+		{"no days worked", bands, 0, 0},
+		{"no bands", nil, 30, 0},
+		{
+			"zero target band always applies",
+			[]SalaryComponentDto{{ComponentType: ComponentTypeAttendance, MinimumTarget: 0, Amount: 50000}},
+			0, 50000,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ResolveAttendance(tt.components, tt.daysWorked); got != tt.want {
+				t.Errorf("ResolveAttendance(%d) = %v, want %v", tt.daysWorked, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/raymondsugiarto/coffee-api/pkg/model"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/pagination"
+)
+
+// Stock opname statuses — wire enum.
+const (
+	StockOpnameStatusDraft     = "DRAFT"
+	StockOpnameStatusApproved  = "APPROVED"
+	StockOpnameStatusCancelled = "CANCELLED"
+)
+
+// StockOpnameLineDto is one item of a count. Variance is counted
+// minus system quantity (negative = shrinkage) and VarianceValue
+// its worth at CostPrice; both stay 0 until the item is counted.
+type StockOpnameLineDto struct {
+	ID            string  `json:"id"`
+	ItemID        string  `json:"itemId"`
+	ItemCode      string  `json:"itemCode,omitempty"`
+	ItemName      string  `json:"itemName,omitempty"`
+	SystemQty     int     `json:"systemQty"`
+	CountedQty    *int    `json:"countedQty"`
+	Variance      int     `json:"variance"`
+	CostPrice     float64 `json:"costPrice"`
+	VarianceValue float64 `json:"varianceValue"`
+}
+
+type StockOpnameDto struct {
+	ID             string               `json:"id"`
+	OrganizationID string               `json:"-"`
+	Code           string               `json:"code"`
+	Date           string               `json:"date"` // YYYY-MM-DD
+	Status         string               `json:"status"`
+	Notes          string               `json:"notes"`
+	TotalShrinkage float64              `json:"totalShrinkage"`
+	TotalSurplus   float64              `json:"totalSurplus"`
+	JournalEntryID string               `json:"journalEntryId,omitempty"`
+	CreatedBy      string               `json:"createdBy"`
+	ApprovedBy     string               `json:"approvedBy,omitempty"`
+	ApprovedAt     *time.Time           `json:"approvedAt,omitempty"`
+	Lines          []StockOpnameLineDto `json:"lines"`
+}
+
+func NewStockOpnameDtoFromModel(m *model.StockOpname) *StockOpnameDto {
+	if m == nil {
+		return nil
+	}
+	d := &StockOpnameDto{
+		ID:             m.ID,
+		OrganizationID: m.OrganizationID,
+		Code:           m.Code,
+		Date:           m.Date.Format("2006-01-02"),
+		Status:         m.Status,
+		Notes:          m.Notes,
+		TotalShrinkage: m.TotalShrinkage,
+		TotalSurplus:   m.TotalSurplus,
+		JournalEntryID: m.JournalEntryID,
+		CreatedBy:      m.CreatedBy,
+		ApprovedBy:     m.ApprovedBy,
+		ApprovedAt:     m.ApprovedAt,
+		Lines:          make([]StockOpnameLineDto, 0, len(m.Lines)),
+	}
+	for _, l := range m.Lines {
+		line := StockOpnameLineDto{
+			ID:         l.ID,
+			ItemID:     l.ItemID,
+			SystemQty:  l.SystemQty,
+			CountedQty: l.CountedQty,
+			CostPrice:  l.CostPrice,
+		}
+		if l.Item != nil {
+			line.ItemCode = l.Item.Code
+			line.ItemName = l.Item.Name
+			if m.Status == StockOpnameStatusDraft {
+				line.CostPrice = l.Item.CostPrice
+			}
+		}
+		d.Lines = append(d.Lines, line)
+	}
+	d.ComputeVariances()
+	return d
+}
+
+func (d *StockOpnameDto) ToModel() *model.StockOpname {
+	date, _ := time.Parse("2006-01-02", d.Date)
+	m := &model.StockOpname{
+		OrganizationID: d.OrganizationID,
+		Code:           d.Code,
+		Date:           date,
+		Status:         d.Status,
+		Notes:          d.Notes,
+		TotalShrinkage: d.TotalShrinkage,
+		TotalSurplus:   d.TotalSurplus,
+		JournalEntryID: d.JournalEntryID,
+		CreatedBy:      d.CreatedBy,
+		ApprovedBy:     d.ApprovedBy,
+		ApprovedAt:     d.ApprovedAt,
+	}
+	if d.ID != "" {
+		m.ID = d.ID
+	}
+	for _, l := range d.Lines {
+		m.Lines = append(m.Lines, model.StockOpnameLine{
+			StockOpnameID: d.ID,
+			ItemID:        l.ItemID,
+			SystemQty:     l.SystemQty,
+			CountedQty:    l.CountedQty,
+			CostPrice:     l.CostPrice,
+		})
+	}
+	return m
+}
+
+// ComputeVariances fills each counted line's Variance and
+// VarianceValue and the document's shrinkage / surplus totals. A
+// draft is valued at the items' current cost price, an approved
+// count at the cost price snapshotted on approval.
+func (d *StockOpnameDto) ComputeVariances() {
+	d.TotalShrinkage, d.TotalSurplus = 0, 0
+	for i := range d.Lines {
+		l := &d.Lines[i]
+		l.Variance, l.VarianceValue = 0, 0
+		if l.CountedQty == nil {
+			continue
+		}
+		l.Variance = *l.CountedQty - l.SystemQty
+		l.VarianceValue = float64(l.Variance) * l.CostPrice
+		if l.VarianceValue < 0 {
+			d.TotalShrinkage -= l.VarianceValue
+		} else {
+			d.TotalSurplus += l.VarianceValue
+		}
+	}
+}
+
+// StockOpnameCreateRequest is the body of POST /api/stock-opnames.
+// Date defaults to today. ItemIDs limits the count to those items;
+// empty counts every active item.
+type StockOpnameCreateRequest struct {
+	Date    string   `json:"date"    validate:"omitempty,len=10"` // YYYY-MM-DD
+	Notes   string   `json:"notes"`
+	ItemIDs []string `json:"itemIds" validate:"dive,required"`
+}
+
+// StockOpnameCountRequest is the body of PUT
+// /api/stock-opnames/:id/counts. Items not listed keep their
+// current count.
+type StockOpnameCountRequest struct {
+	Items []StockOpnameCountItemRequest `json:"items" validate:"required,min=1,dive"`
+}
+
+type StockOpnameCountItemRequest struct {
+	ItemID     string `json:"itemId"     validate:"required"`
+	CountedQty int    `json:"countedQty" validate:"gte=0"`
+}
+
+// StockOpnameVarianceDto is the variance list of a count: only the
+// counted lines whose quantity differs from the system, with the
+// totals. Uncounted is how many lines are still waiting for a
+// count.
+type StockOpnameVarianceDto struct {
+	ID             string               `json:"id"`
+	Code           string               `json:"code"`
+	Date           string               `json:"date"`
+	Status         string               `json:"status"`
+	Lines          []StockOpnameLineDto `json:"lines"`
+	Uncounted      int                  `json:"uncounted"`
+	TotalShrinkage float64              `json:"totalShrinkage"`
+	TotalSurplus   float64              `json:"totalSurplus"`
+	NetVariance    float64              `json:"netVariance"`
+}
+
+// NewStockOpnameVarianceDto builds the variance list of d.
+func NewStockOpnameVarianceDto(d *StockOpnameDto) *StockOpnameVarianceDto {
+	v := &StockOpnameVarianceDto{
+		ID:             d.ID,
+		Code:           d.Code,
+		Date:           d.Date,
+		Status:         d.Status,
+		Lines:          make([]StockOpnameLineDto, 0),
+		TotalShrinkage: d.TotalShrinkage,
+		TotalSurplus:   d.TotalSurplus,
+		NetVariance:    d.TotalSurplus - d.TotalShrinkage,
+	}
+	for _, l := range d.Lines {
+		if l.CountedQty == nil {
+			v.Uncounted++
+			continue
+		}
+		if l.Variance != 0 {
+			v.Lines = append(v.Lines, l)
+		}
+	}
+	return v
+}
+
+// StockOpnameFindAllRequest powers GET /api/stock-opnames.
+type StockOpnameFindAllRequest struct {
+	FindAllRequest
+	Status string
+	From   string // YYYY-MM-DD
+	To     string // YYYY-MM-DD
+}
+
+func (r *StockOpnameFindAllRequest) GenerateFilter() {
+	if r.Status != "" {
+		r.Filter = append(r.Filter, pagination.FilterItem{Field: "status", Op: "eq", Val: r.Status})
+	}
+	if r.From != "" {
+		r.Filter = append(r.Filter, pagination.FilterItem{Field: "date", Op: "gte", Val: r.From})
+	}
+	if r.To != "" {
+		r.Filter = append(r.Filter, pagination.FilterItem{Field: "date", Op: "lte", Val: r.To})
+	}
+}
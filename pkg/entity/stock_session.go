@@ -1,6 +1,8 @@
 package entity
 
 import (
+	"encoding/json"
+	"math"
 	"time"
 
 	"github.com/raymondsugiarto/coffee-api/pkg/model"
@@ -10,6 +12,10 @@ import (
 const (
 	StockSessionStatusOpen   = "OPEN"
 	StockSessionStatusClosed = "CLOSED"
+	// StockSessionStatusPendingReview is an OPEN session the
+	// stale-session job found past the cutoff. It still closes,
+	// updates and deletes like an OPEN one.
+	StockSessionStatusPendingReview = "PENDING_REVIEW"
 
 	PaymentMethodCash     = "CASH"
 	PaymentMethodQris     = "QRIS"
@@ -19,9 +25,24 @@ const (
 	CashAdjustmentShortage = "SHORTAGE"
 	CashAdjustmentOverage  = "OVERAGE"
 
+	// Shifts of a driver's day; mirrors model.SessionType.
+	StockSessionShiftMorning = string(model.SessionTypeMorning)
+	StockSessionShiftEvening = string(model.SessionTypeEvening)
+
 	SessionActionOpen   = "OPEN"
 	SessionActionUpdate = "UPDATE"
 	SessionActionClose  = "CLOSE"
+	SessionActionDelete = "DELETE"
+
+	// Stale-session job actions (session_log.action, admin_id empty).
+	SessionActionAutoClose     = "AUTO_CLOSE"
+	SessionActionFlagForReview = "FLAG_FOR_REVIEW"
+
+	// Organization policies for sessions still OPEN past the
+	// cutoff: close them with nothing sold and everything returned,
+	// or flag them PENDING_REVIEW for an admin to close.
+	StaleSessionPolicyAutoClose     = "AUTO_CLOSE"
+	StaleSessionPolicyPendingReview = "PENDING_REVIEW"
 )
 
 // ===== StockSessionItem =====
@@ -204,8 +225,11 @@ type StockSessionItemDto struct {
 	Subtotal             float64 `json:"subtotal"`
 	// CommissionSnapshot is the per-unit commission rate at the
 	// time of the write; CommissionTotal is rate × soldQty.
+	// CommissionPlanID is set when a commission plan priced the row
+	// at close.
 	CommissionSnapshot float64 `json:"commissionSnapshot"`
 	CommissionTotal    float64 `json:"commissionTotal"`
+	CommissionPlanID   string  `json:"commissionPlanId,omitempty"`
 }
 
 func NewStockSessionItemDtoFromModel(m *model.StockSessionItem) *StockSessionItemDto {
@@ -226,6 +250,7 @@ func NewStockSessionItemDtoFromModel(m *model.StockSessionItem) *StockSessionIte
 		Subtotal:             m.Subtotal,
 		CommissionSnapshot:   m.CommissionSnapshot,
 		CommissionTotal:      m.CommissionTotal,
+		CommissionPlanID:     m.CommissionPlanID,
 	}
 	if m.Item != nil {
 		d.Item = NewItemDtoFromModel(m.Item)
@@ -255,6 +280,7 @@ func (d *StockSessionItemDto) ToModel() *model.StockSessionItem {
 		Subtotal:             d.Subtotal,
 		CommissionSnapshot:   d.CommissionSnapshot,
 		CommissionTotal:      d.CommissionTotal,
+		CommissionPlanID:     d.CommissionPlanID,
 	}
 	if d.ID != "" {
 		m.ID = d.ID
@@ -369,13 +395,25 @@ func (d *CashAdjustmentDto) ToModel() *model.CashAdjustment {
 
 // ===== StockSession =====
 
+// OpenStockSessionInputDto opens one shift of a driver's day.
+// Shift defaults to MORNING; a driver has at most one session per
+// (date, shift).
 type OpenStockSessionInputDto struct {
 	EmployeeID string                         `json:"employeeId" validate:"required"`
 	Date       string                         `json:"date" validate:"required"` // YYYY-MM-DD
+	Shift      string                         `json:"shift" validate:"omitempty,oneof=MORNING EVENING"`
 	Notes      string                         `json:"notes"`
 	Items      []OpenStockSessionItemInputDto `json:"items" validate:"required,min=1,dive"`
 }
 
+// CarryOverStockSessionInputDto opens the EVENING session from a
+// closed MORNING one: every unit the driver returned in the morning
+// is loaded again, plus any extra Items taken from the warehouse.
+type CarryOverStockSessionInputDto struct {
+	Notes string                         `json:"notes"`
+	Items []OpenStockSessionItemInputDto `json:"items" validate:"omitempty,dive"`
+}
+
 type CloseStockSessionInputDto struct {
 	Items       []CloseStockSessionItemInputDto `json:"items" validate:"required,min=1,dive"`
 	Payments    []PaymentDetailInputDto         `json:"payments" validate:"required,min=1,dive"`
@@ -389,6 +427,7 @@ type StockSessionDto struct {
 	EmployeeID          string     `json:"employeeId"`
 	Employee            *AdminDto  `json:"employee,omitempty"`
 	Date                string     `json:"date"` // YYYY-MM-DD
+	Shift               string     `json:"shift"`
 	Status              string     `json:"status"`
 	OpenedAt            time.Time  `json:"openedAt"`
 	ClosedAt            *time.Time `json:"closedAt,omitempty"`
@@ -415,6 +454,14 @@ type StockSessionDto struct {
 	Items       []StockSessionItemDto `json:"items"`
 	Payments    []PaymentDetailDto    `json:"payments,omitempty"`
 	Adjustments []CashAdjustmentDto   `json:"adjustments,omitempty"`
+
+	// CommissionPlans snapshots the commission plans applied at
+	// close; empty when every item paid its flat rate.
+	CommissionPlans []StockSessionCommissionPlanDto `json:"commissionPlans,omitempty"`
+
+	// CarriedFromID is the MORNING session whose returns an
+	// EVENING session was loaded with.
+	CarriedFromID string `json:"carriedFromId,omitempty"`
 }
 
 func NewStockSessionDtoFromModel(m *model.StockSession) *StockSessionDto {
@@ -426,6 +473,7 @@ func NewStockSessionDtoFromModel(m *model.StockSession) *StockSessionDto {
 		OrganizationID:  m.OrganizationID,
 		EmployeeID:      m.EmployeeID,
 		Date:            m.Date.Format("2006-01-02"),
+		Shift:           string(m.Shift),
 		Status:          m.Status,
 		OpenedAt:        m.OpenedAt,
 		ClosedAt:        m.ClosedAt,
@@ -444,10 +492,14 @@ func NewStockSessionDtoFromModel(m *model.StockSession) *StockSessionDto {
 		CashDebt:        m.CashDebt,
 		Notes:           m.Notes,
 		CreatedBy:       m.CreatedBy,
+		CarriedFromID:   m.CarriedFromID,
 	}
 	if m.Employee != nil {
 		d.Employee = (&AdminDto{}).FromModel(m.Employee)
 	}
+	if m.CommissionPlans != "" {
+		_ = json.Unmarshal([]byte(m.CommissionPlans), &d.CommissionPlans)
+	}
 	for _, it := range m.Items {
 		d.Items = append(d.Items, *NewStockSessionItemDtoFromModel(&it))
 	}
@@ -464,6 +516,7 @@ func (d *StockSessionDto) ToModel() *model.StockSession {
 	m := &model.StockSession{
 		OrganizationID:      d.OrganizationID,
 		EmployeeID:          d.EmployeeID,
+		Shift:               model.SessionType(d.Shift),
 		Status:              d.Status,
 		OpenedAt:            d.OpenedAt,
 		ClosedAt:            d.ClosedAt,
@@ -483,6 +536,7 @@ func (d *StockSessionDto) ToModel() *model.StockSession {
 		CashDebt:            d.CashDebt,
 		Notes:               d.Notes,
 		CreatedBy:           d.CreatedBy,
+		CarriedFromID:       d.CarriedFromID,
 	}
 	if d.ID != "" {
 		m.ID = d.ID
@@ -492,6 +546,11 @@ func (d *StockSessionDto) ToModel() *model.StockSession {
 			m.Date = t
 		}
 	}
+	if len(d.CommissionPlans) > 0 {
+		if b, err := json.Marshal(d.CommissionPlans); err == nil {
+			m.CommissionPlans = string(b)
+		}
+	}
 	for _, it := range d.Items {
 		m.Items = append(m.Items, *it.ToModel())
 	}
@@ -611,30 +670,26 @@ func (d *StockSessionDto) RecomputeTotals() {
 }
 
 // RecomputeSalary resolves the per-session salary breakdown
-// (meal_allowance, attendance, bonus_target) from a list of salary
-// components that apply to the driver's company.
+// (meal_allowance, bonus_target) from a list of salary components
+// that apply to the driver's company.
 //
 // Resolution rules:
 //
 //   - meal_allowance : sum of every MEAL_ALLOWANCE row in the
 //     company (typically just one row, with minimum_target = 0).
-//   - attendance     : pick the ATTENDANCE row with the highest
-//     minimum_target that the driver still clears. `totalQty`
-//     here is typically the session's TotalItems — the user
-//     can refine later to use a separate days-worked metric.
-//   - bonus_target   : same shape as attendance, applied to
-//     BONUS_TARGET rows.
+//   - bonus_target   : pick the BONUS_TARGET row with the highest
+//     minimum_target that `totalQty` (the session's TotalItems)
+//     still clears.
+//
+// ATTENDANCE bands are evaluated against the days worked over a
+// payroll period (see ResolveAttendance), not per session, so
+// `Attendance` stays 0 here.
 //
-// `totalSalary` is the sum of the three. Components that don't
+// `totalSalary` is the sum of the above. Components that don't
 // match any row resolve to 0 — that's the safe default for new
 // companies that haven't set up their salary bands yet.
 func (d *StockSessionDto) RecomputeSalary(components []SalaryComponentDto, totalQty int) {
-	// Attendance bonus calculation has been removed by product
-	// decision. The `Attendance` column is preserved on the model
-	// and DTO for historical/reporting parity, but is always 0
-	// on every write.
 	var meal, bonus, minTargetCommission float64
-	var attendance = 0.0
 	var bestBonusTarget = -1
 	for _, c := range components {
 		switch c.ComponentType {
@@ -642,12 +697,6 @@ func (d *StockSessionDto) RecomputeSalary(components []SalaryComponentDto, total
 			minTargetCommission = c.MinimumTarget * c.Amount
 		case ComponentTypeMealAllowance:
 			meal += c.Amount
-		case ComponentTypeAttendance:
-			// Intentionally ignored: bonus-hadir removed.
-			// Field kept at 0 for column/UI stability.
-			if c.MinimumTarget <= float64(totalQty) {
-				attendance = c.Amount
-			}
 		case ComponentTypeBonusTarget:
 			if c.MinimumTarget <= float64(totalQty) &&
 				c.MinimumTarget > float64(bestBonusTarget) {
@@ -657,16 +706,101 @@ func (d *StockSessionDto) RecomputeSalary(components []SalaryComponentDto, total
 		}
 	}
 	d.MealAllowance = meal
-	d.Attendance = attendance
+	d.Attendance = 0
 	d.BonusTarget = bonus
-	d.TotalSalary = (d.TotalCommission - minTargetCommission) + meal + bonus + attendance
+	d.TotalSalary = (d.TotalCommission - minTargetCommission) + meal + bonus
 	d.MinTargetCommission = minTargetCommission
 }
 
+// ApplyCommissionPlans re-prices the commission of the rows a plan
+// covers; call it at close, after RecomputeTotals. categoryOf maps
+// item id to its category (a variant's parent category when it has
+// none of its own). Each plan is computed once over all the rows it
+// covers, so tiers see the session's total quantity in scope, and
+// the result is spread back over those rows by quantity (by sales
+// for PERCENTAGE). Rows no plan covers keep the flat item rate.
+//
+// The plans used are snapshotted on CommissionPlans and the row
+// CommissionSnapshot becomes the plan's average rate per unit.
+func (d *StockSessionDto) ApplyCommissionPlans(
+	plans []CommissionPlanDto,
+	companyID string,
+	categoryOf map[string]string,
+) {
+	type group struct {
+		plan  *CommissionPlanDto
+		rows  []int
+		qty   int
+		sales float64
+	}
+	groups := make([]*group, 0)
+	byPlan := make(map[string]*group)
+	for i, it := range d.Items {
+		plan := ResolveCommissionPlan(plans, companyID, categoryOf[it.ItemID])
+		if plan == nil {
+			continue
+		}
+		g, ok := byPlan[plan.ID]
+		if !ok {
+			g = &group{plan: plan}
+			byPlan[plan.ID] = g
+			groups = append(groups, g)
+		}
+		g.rows = append(g.rows, i)
+		g.qty += it.SoldQty
+		g.sales += it.Subtotal
+	}
+
+	d.CommissionPlans = nil
+	for _, g := range groups {
+		amount := math.Round(g.plan.Compute(g.qty, g.sales)*100) / 100
+		remaining := amount
+		for n, i := range g.rows {
+			row := &d.Items[i]
+			share := remaining
+			if n < len(g.rows)-1 {
+				weight := 0.0
+				if g.plan.Method == CommissionPlanMethodPercentage && g.sales > 0 {
+					weight = row.Subtotal / g.sales
+				} else if g.qty > 0 {
+					weight = float64(row.SoldQty) / float64(g.qty)
+				}
+				share = math.Round(amount*weight*100) / 100
+			}
+			remaining -= share
+			row.CommissionTotal = share
+			row.CommissionPlanID = g.plan.ID
+			row.CommissionSnapshot = 0
+			if row.SoldQty > 0 {
+				row.CommissionSnapshot = share / float64(row.SoldQty)
+			}
+		}
+		d.CommissionPlans = append(d.CommissionPlans, StockSessionCommissionPlanDto{
+			PlanID:         g.plan.ID,
+			Name:           g.plan.Name,
+			CompanyID:      g.plan.CompanyID,
+			ItemCategoryID: g.plan.ItemCategoryID,
+			Method:         g.plan.Method,
+			Rate:           g.plan.Rate,
+			Tiers:          g.plan.Tiers,
+			Qty:            g.qty,
+			Sales:          g.sales,
+			Amount:         amount,
+		})
+	}
+
+	var total float64
+	for _, it := range d.Items {
+		total += it.CommissionTotal
+	}
+	d.TotalCommission = total
+}
+
 type StockSessionFindAllRequest struct {
 	FindAllRequest
 	EmployeeID string
 	Date       string // YYYY-MM-DD
+	Shift      string
 	Status     string
 	From       string
 	To         string
@@ -676,6 +810,9 @@ func (r *StockSessionFindAllRequest) GenerateFilter() {
 	if r.EmployeeID != "" {
 		r.Filter = append(r.Filter, pagination.FilterItem{Field: "employee_id", Op: "eq", Val: r.EmployeeID})
 	}
+	if r.Shift != "" {
+		r.Filter = append(r.Filter, pagination.FilterItem{Field: "shift", Op: "eq", Val: r.Shift})
+	}
 	if r.Status != "" {
 		r.Filter = append(r.Filter, pagination.FilterItem{Field: "status", Op: "eq", Val: r.Status})
 	}
@@ -715,6 +852,32 @@ func NewSessionLogDtoFromModel(m *model.SessionLog) *SessionLogDto {
 	}
 }
 
+func (d *SessionLogDto) ToModel() *model.SessionLog {
+	return &model.SessionLog{
+		SessionID: d.SessionID,
+		Action:    d.Action,
+		AdminID:   d.AdminID,
+		Detail:    d.Detail,
+	}
+}
+
+// ===== Stale sessions =====
+
+// StockSessionSettingsDto is the organization's stock-session
+// settings (GET/PUT /api/stock-session/settings).
+type StockSessionSettingsDto struct {
+	StaleSessionPolicy string `json:"staleSessionPolicy" validate:"required,oneof=AUTO_CLOSE PENDING_REVIEW"`
+}
+
+// StaleSessionResultDto summarises one run of the stale-session job.
+// Failed counts sessions neither closed nor flagged; they are tried
+// again on the next run.
+type StaleSessionResultDto struct {
+	Closed  int `json:"closed"`
+	Flagged int `json:"flagged"`
+	Failed  int `json:"failed"`
+}
+
 // ===== Reports =====
 
 type DailyReportDto struct {
@@ -731,6 +894,7 @@ type DailyReportDto struct {
 	TotalBonusTarget   float64                `json:"totalBonusTarget"`
 	TotalSalary        float64                `json:"totalSalary"`
 	ByEmployee         []EmployeeReportRowDto `json:"byEmployee"`
+	ByShift            []ShiftReportRowDto    `json:"byShift"`
 }
 
 type MonthlyReportDto struct {
@@ -747,6 +911,21 @@ type MonthlyReportDto struct {
 	TotalSalary        float64                `json:"totalSalary"`
 	Daily              []DailyReportDto       `json:"daily"`
 	ByEmployee         []EmployeeReportRowDto `json:"byEmployee"`
+	ByShift            []ShiftReportRowDto    `json:"byShift"`
+}
+
+// ShiftReportRowDto splits a report's totals by MORNING / EVENING
+// session.
+type ShiftReportRowDto struct {
+	Shift           string  `json:"shift"`
+	Sessions        int     `json:"sessions"`
+	TotalItems      int     `json:"totalItems"`
+	TotalSales      float64 `json:"totalSales"`
+	TotalCash       float64 `json:"totalCash"`
+	TotalQris       float64 `json:"totalQris"`
+	TotalDiff       float64 `json:"totalDifference"`
+	TotalCommission float64 `json:"totalCommission"`
+	TotalSalary     float64 `json:"totalSalary"`
 }
 
 type EmployeeReportRowDto struct {
@@ -784,6 +963,10 @@ type EmployeePerformanceRowDto struct {
 	MealAllowance float64 `json:"mealAllowance"`
 	BonusTarget   float64 `json:"bonusTarget"`
 	TotalSalary   float64 `json:"totalSalary"`
+
+	// MorningSessions + EveningSessions = Sessions.
+	MorningSessions int `json:"morningSessions"`
+	EveningSessions int `json:"eveningSessions"`
 }
 
 type DashboardSummaryDto struct {
@@ -791,7 +974,13 @@ type DashboardSummaryDto struct {
 	TodayCash         float64 `json:"todayCash"`
 	TodayQris         float64 `json:"todayQris"`
 	TodayTransactions int     `json:"todayTransactions"`
-	OpenSessions      int     `json:"openSessions"`
-	ClosedSessions    int     `json:"closedSessions"`
-	TotalSessions     int     `json:"totalSessions"`
+	// OpenSessions counts today's sessions not closed yet, those
+	// flagged PENDING_REVIEW included.
+	OpenSessions   int `json:"openSessions"`
+	ClosedSessions int `json:"closedSessions"`
+	TotalSessions  int `json:"totalSessions"`
+	// PendingReviewSessions counts every session the stale-session
+	// job flagged that is still waiting to be closed, whatever its
+	// date.
+	PendingReviewSessions int `json:"pendingReviewSessions"`
 }
@@ -0,0 +1,65 @@
+package entity
+
+import (
+	"github.com/raymondsugiarto/coffee-api/pkg/model"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/pagination"
+)
+
+type SupplierDto struct {
+	ID             string `json:"id"`
+	OrganizationID string `json:"-"`
+	Name           string `json:"name"        validate:"required,min=1,max=255"`
+	ContactName    string `json:"contactName" validate:"max=255"`
+	PhoneNumber    string `json:"phoneNumber" validate:"max=64"`
+	Email          string `json:"email"       validate:"omitempty,email,max=255"`
+	Address        string `json:"address"`
+	Notes          string `json:"notes"`
+	IsActive       bool   `json:"isActive"`
+}
+
+func NewSupplierDtoFromModel(m *model.Supplier) *SupplierDto {
+	if m == nil {
+		return nil
+	}
+	return &SupplierDto{
+		ID:             m.ID,
+		OrganizationID: m.OrganizationID,
+		Name:           m.Name,
+		ContactName:    m.ContactName,
+		PhoneNumber:    m.PhoneNumber,
+		Email:          m.Email,
+		Address:        m.Address,
+		Notes:          m.Notes,
+		IsActive:       m.IsActive,
+	}
+}
+
+func (d *SupplierDto) ToModel() *model.Supplier {
+	m := &model.Supplier{
+		OrganizationID: d.OrganizationID,
+		Name:           d.Name,
+		ContactName:    d.ContactName,
+		PhoneNumber:    d.PhoneNumber,
+		Email:          d.Email,
+		Address:        d.Address,
+		Notes:          d.Notes,
+		IsActive:       d.IsActive,
+	}
+	if d.ID != "" {
+		m.ID = d.ID
+	}
+	return m
+}
+
+// SupplierFindAllRequest powers GET /api/suppliers. IsActive is
+// "true" / "false"; empty lists both.
+type SupplierFindAllRequest struct {
+	FindAllRequest
+	IsActive string
+}
+
+func (r *SupplierFindAllRequest) GenerateFilter() {
+	if r.IsActive != "" {
+		r.Filter = append(r.Filter, pagination.FilterItem{Field: "is_active", Op: "eq", Val: r.IsActive == "true"})
+	}
+}
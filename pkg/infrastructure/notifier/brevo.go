@@ -0,0 +1,38 @@
+package notifier
+
+import (
+	"context"
+
+	b "github.com/getbrevo/brevo-go/lib"
+	"github.com/gofiber/fiber/v2/log"
+)
+
+type brevoNotifier struct {
+	client *b.APIClient
+	sender string
+}
+
+// NewBrevoNotifier mails alerts as plain-text transactional emails
+// from sender to each message's recipients. A message without
+// recipients is logged instead.
+func NewBrevoNotifier(client *b.APIClient, sender string) Notifier {
+	return &brevoNotifier{client: client, sender: sender}
+}
+
+func (n *brevoNotifier) Notify(ctx context.Context, msg *Message) error {
+	if len(msg.Recipients) == 0 {
+		log.WithContext(ctx).Warnf("[notifier] no recipients for %q, not mailed", msg.Subject)
+		return nil
+	}
+	to := make([]b.SendSmtpEmailTo, 0, len(msg.Recipients))
+	for _, email := range msg.Recipients {
+		to = append(to, b.SendSmtpEmailTo{Email: email})
+	}
+	_, _, err := n.client.TransactionalEmailsApi.SendTransacEmail(ctx, b.SendSmtpEmail{
+		Sender:      &b.SendSmtpEmailSender{Email: n.sender},
+		To:          to,
+		Subject:     msg.Subject,
+		TextContent: msg.Body,
+	})
+	return err
+}
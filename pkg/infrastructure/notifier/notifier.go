@@ -0,0 +1,46 @@
+package notifier
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/raymondsugiarto/coffee-api/config"
+	"github.com/raymondsugiarto/coffee-api/pkg/infrastructure/brevo"
+)
+
+// Message is one alert. Body is plain text. Recipients are the
+// addresses of the organization the alert is about.
+type Message struct {
+	Recipients []string
+	Subject    string
+	Body       string
+}
+
+// Notifier delivers alerts raised by background jobs.
+type Notifier interface {
+	Notify(ctx context.Context, msg *Message) error
+}
+
+// New returns the backend selected by cron.alert.notifier: "brevo"
+// mails each message's recipients, anything else logs.
+func New(cfg *config.Config) Notifier {
+	switch strings.ToLower(cfg.Cron.Alert.Notifier) {
+	case "brevo":
+		return NewBrevoNotifier(brevo.NewClient(), cfg.Mail.Brevo.Sender)
+	default:
+		return NewLogNotifier()
+	}
+}
+
+type logNotifier struct{}
+
+// NewLogNotifier writes alerts to the application log.
+func NewLogNotifier() Notifier {
+	return &logNotifier{}
+}
+
+func (n *logNotifier) Notify(ctx context.Context, msg *Message) error {
+	log.WithContext(ctx).Warnf("[notifier] to=%s %s\n%s", strings.Join(msg.Recipients, ","), msg.Subject, msg.Body)
+	return nil
+}
@@ -0,0 +1,68 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-co-op/gocron/v2"
+	"gorm.io/gorm"
+)
+
+// ErrLockHeld is returned by Lock while another instance holds the
+// job's lock; gocron then skips the run on this instance.
+var ErrLockHeld = errors.New("scheduler lock is held by another instance")
+
+// dbLocker is a gocron.Locker backed by the scheduler_lock table,
+// one row per job name. Times are the database's, so instances with
+// skewed clocks still agree on who holds a lock.
+//
+// A lock expires after ttl, so a crashed instance cannot block a job
+// forever. On unlock it is kept until minHold after it was taken:
+// an instance whose clock runs a little late then finds the lock
+// still held instead of running the same tick a second time.
+type dbLocker struct {
+	db       *gorm.DB
+	instance string
+	ttl      time.Duration
+	minHold  time.Duration
+}
+
+func newDBLocker(db *gorm.DB, instance string, ttl, minHold time.Duration) gocron.Locker {
+	return &dbLocker{db: db, instance: instance, ttl: ttl, minHold: minHold}
+}
+
+func (l *dbLocker) Lock(ctx context.Context, key string) (gocron.Lock, error) {
+	res := l.db.WithContext(ctx).Exec(`
+		INSERT INTO scheduler_lock (name, locked_by, locked_at, locked_until, created_at, updated_at)
+		VALUES (?, ?, NOW(), NOW() + ? * INTERVAL '1 second', NOW(), NOW())
+		ON CONFLICT (name) DO UPDATE
+		SET locked_by = EXCLUDED.locked_by,
+		    locked_at = EXCLUDED.locked_at,
+		    locked_until = EXCLUDED.locked_until,
+		    updated_at = EXCLUDED.updated_at
+		WHERE scheduler_lock.locked_until < NOW()`,
+		key, l.instance, l.ttl.Seconds(),
+	)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, ErrLockHeld
+	}
+	return &dbLock{locker: l, key: key}, nil
+}
+
+type dbLock struct {
+	locker *dbLocker
+	key    string
+}
+
+func (k *dbLock) Unlock(ctx context.Context) error {
+	return k.locker.db.WithContext(ctx).Exec(`
+		UPDATE scheduler_lock
+		SET locked_until = GREATEST(NOW(), locked_at + ? * INTERVAL '1 second'), updated_at = NOW()
+		WHERE name = ? AND locked_by = ?`,
+		k.locker.minHold.Seconds(), k.key, k.locker.instance,
+	).Error
+}
@@ -0,0 +1,97 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/go-co-op/gocron/v2"
+	"github.com/gofiber/fiber/v2/log"
+	gonanoid "github.com/matoous/go-nanoid/v2"
+	"gorm.io/gorm"
+)
+
+const (
+	// lockTTL bounds how long a job lock outlives a crashed instance.
+	lockTTL = 30 * time.Minute
+	// lockMinHold is how long a finished run keeps its lock, to
+	// absorb clock skew between instances.
+	lockMinHold = time.Minute
+)
+
+// Job is the body of a scheduled job. ctx is cancelled when the
+// scheduler shuts down.
+type Job func(ctx context.Context) error
+
+// Scheduler runs registered jobs on their crontab in Asia/Jakarta.
+// Every API instance runs one; a lock row in the database makes sure
+// each tick of a job runs on a single instance.
+type Scheduler struct {
+	cron gocron.Scheduler
+}
+
+// New builds a scheduler whose jobs lock through db. Jobs only start
+// running after Start.
+func New(db *gorm.DB) (*Scheduler, error) {
+	loc, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		return nil, err
+	}
+	cron, err := gocron.NewScheduler(
+		gocron.WithLocation(loc),
+		gocron.WithDistributedLocker(newDBLocker(db, instanceID(), lockTTL, lockMinHold)),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &Scheduler{cron: cron}, nil
+}
+
+// Register schedules job under name, which is also its lock key.
+// crontab has minute precision; an empty crontab leaves the job
+// disabled. A job never overlaps itself on one instance.
+func (s *Scheduler) Register(name, crontab string, job Job) error {
+	if crontab == "" {
+		log.Infof("[scheduler] %s disabled", name)
+		return nil
+	}
+	_, err := s.cron.NewJob(
+		gocron.CronJob(crontab, false),
+		gocron.NewTask(func(ctx context.Context) {
+			started := time.Now()
+			if err := job(ctx); err != nil {
+				log.WithContext(ctx).Errorf("[scheduler] %s failed after %s: %v", name, time.Since(started), err)
+				return
+			}
+			log.WithContext(ctx).Infof("[scheduler] %s done in %s", name, time.Since(started))
+		}),
+		gocron.WithName(name),
+		gocron.WithSingletonMode(gocron.LimitModeReschedule),
+	)
+	if err != nil {
+		return fmt.Errorf("scheduler: register %s: %w", name, err)
+	}
+	log.Infof("[scheduler] %s scheduled at %q", name, crontab)
+	return nil
+}
+
+// Start begins running the registered jobs in the background.
+func (s *Scheduler) Start() {
+	s.cron.Start()
+}
+
+// Shutdown stops scheduling and waits for running jobs.
+func (s *Scheduler) Shutdown() error {
+	return s.cron.Shutdown()
+}
+
+// instanceID names this process in scheduler_lock.locked_by.
+func instanceID() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "api"
+	}
+	suffix, _ := gonanoid.New(8)
+	return host + "-" + suffix
+}
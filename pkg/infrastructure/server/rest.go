@@ -8,9 +8,11 @@ import (
 	"log"
 	"strconv"
 
+	"github.com/raymondsugiarto/coffee-api/pkg/adapter/jobs"
 	"github.com/raymondsugiarto/coffee-api/pkg/adapter/routes"
 	"github.com/raymondsugiarto/coffee-api/pkg/infrastructure/database"
 	"github.com/raymondsugiarto/coffee-api/pkg/infrastructure/middleware"
+	"github.com/raymondsugiarto/coffee-api/pkg/infrastructure/scheduler"
 	"github.com/raymondsugiarto/coffee-api/pkg/shared/response"
 
 	"github.com/gofiber/fiber/v2"
@@ -42,6 +44,7 @@ func (s *Rest) Initialize() {
 	initDatabase()
 
 	routes.InitRouter(app)
+	initScheduler()
 
 	err := app.Listen(":" + strconv.Itoa(cfg.Server.Rest.Port))
 	if err != nil {
@@ -57,3 +60,16 @@ func initDatabase() {
 	}
 	database.DBConn = sqlConn.GetConn()
 }
+
+// initScheduler starts the background jobs. Every instance runs the
+// scheduler; the database lock lets one of them take each run.
+func initScheduler() {
+	s, err := scheduler.New(database.DBConn)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := jobs.InitJobs(s); err != nil {
+		log.Fatal(err)
+	}
+	s.Start()
+}
@@ -12,9 +12,18 @@ import (
 // `code` is the short identifier printed on reports and used as
 // the seedable key per organization. The (organization_id, code)
 // pair is unique so different orgs can use the same codes.
+//
+// `type` decides how the signed account_mutation.amount reads at
+// report time (see entity.AccountType*). `parent_id` is an optional
+// pointer to a header account of the same type; reports roll child
+// balances up into it. `is_cash` flags the cash and cash-equivalent
+// accounts the cash flow statement is built from.
 type Account struct {
 	concern.CommonWithIDs
 	OrganizationID string
 	Name           string
 	Code           string
+	Type           string
+	ParentID       string
+	IsCash         bool
 }
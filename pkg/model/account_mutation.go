@@ -24,6 +24,9 @@ type AccountMutation struct {
 	RefID          string
 	RefTable       string
 	RefModule      string
+	// JournalEntryID groups the row with the other legs of the
+	// same balanced entry.
+	JournalEntryID string
 	// No Account pointer here — ref counted from the FK only.
 	// Reports join via the column instead of an association to
 	// keep writes fast and predictable.
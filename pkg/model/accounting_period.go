@@ -0,0 +1,37 @@
+package model
+
+import (
+	"time"
+
+	"github.com/raymondsugiarto/coffee-api/pkg/model/concern"
+)
+
+// AccountingPeriod is the lock state of one calendar month for an
+// organization. `period` is "YYYY-MM". A month without a row is
+// OPEN; the row is created the first time the month is closed.
+//
+// While CLOSED, every service that writes dated financial data
+// (stock sessions, cash debts, payroll, ledger mutations) refuses
+// writes dated inside the month.
+type AccountingPeriod struct {
+	concern.CommonWithIDs
+	OrganizationID string
+	Period         string
+	Status         string
+	ClosedAt       *time.Time
+	ClosedBy       string
+	ReopenedAt     *time.Time
+	ReopenedBy     string
+}
+
+// AccountingPeriodLog is the audit trail of period state changes:
+// one append-only row per close / reopen, with who did it and why.
+type AccountingPeriodLog struct {
+	concern.CommonWithIDs
+	OrganizationID     string
+	AccountingPeriodID string
+	Period             string
+	Action             string
+	ActorID            string
+	Reason             string
+}
@@ -15,4 +15,10 @@ type Admin struct {
 	OrganizationID  string
 	CompanyID       string
 	Company         *Company
+
+	// Bank account the driver's pay is transferred to. BankCode is
+	// one of entity.BankCode*.
+	BankCode          string
+	BankAccountNumber string
+	BankAccountName   string
 }
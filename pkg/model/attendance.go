@@ -0,0 +1,26 @@
+package model
+
+import (
+	"time"
+
+	"github.com/raymondsugiarto/coffee-api/pkg/model/concern"
+)
+
+// Attendance is one working day of a driver: when they clocked in
+// and out, and where, when the device shared its position. There is
+// at most one row per driver and Date (the clock-in day in the
+// server's time zone). A row without ClockOutAt is still open.
+type Attendance struct {
+	concern.CommonWithIDs
+	OrganizationID    string
+	AdminIDEmployee   string
+	Date              time.Time
+	ClockInAt         time.Time
+	ClockInLatitude   *float64
+	ClockInLongitude  *float64
+	ClockOutAt        *time.Time
+	ClockOutLatitude  *float64
+	ClockOutLongitude *float64
+	Notes             string
+	CreatedBy         string
+}
@@ -7,9 +7,13 @@ import (
 )
 
 // CashDebt is a driver-issued cash advance / petty-cash entry.
-// One row per advance. Settlement happens out-of-band when the
-// driver hands back receipts or the company deducts from the
-// daily till — so this ledger stays purely additive.
+// One row per advance. Repayments are recorded as
+// CashDebtSettlement rows; `SettledAmount` is their running sum,
+// kept on the advance so the outstanding balance (Amount -
+// SettledAmount) can be filtered and aged without a join.
+// `SettledAt` is set once the advance is fully repaid.
+// JournalEntryID is the ledger posting of the advance itself
+// (CASH_DEBT_CREATED), empty when no rule posts it.
 //
 // We deliberately keep `AdminIDEmployee` as a plain string and
 // do NOT define a `*Admin` relation. GORM requires a `foreignKey`
@@ -25,4 +29,33 @@ type CashDebt struct {
 	Amount          float64
 	PaymentMethod   string // CASH | CASHLESS
 	Notes           string
+	SettledAmount   float64
+	SettledAt       *time.Time
+	JournalEntryID  string
+	Settlements     []CashDebtSettlement
+}
+
+// CashDebtSettlement is one (possibly partial) repayment of a cash
+// advance. Rows are append-only. `Method` is how the driver paid it
+// back: CASH, PAYROLL (deducted from a salary run; RefID points at
+// the employee_salary row) or TILL_OFFSET (netted against a till
+// overage). JournalEntryID is the ledger posting of the repayment.
+// A repayment undone by its source (a voided payroll run) is kept
+// with ReversedAt set, its amount no longer counted in
+// CashDebt.SettledAmount.
+type CashDebtSettlement struct {
+	concern.CommonWithIDs
+	OrganizationID  string
+	CashDebtID      string
+	AdminIDEmployee string
+	Date            time.Time
+	Amount          float64
+	Method          string
+	RefID           string
+	RefTable        string
+	Notes           string
+	JournalEntryID  string
+	CreatedBy       string
+	ReversedAt      *time.Time
+	ReversedBy      string
 }
@@ -0,0 +1,29 @@
+package model
+
+import "github.com/raymondsugiarto/coffee-api/pkg/model/concern"
+
+// CommissionPlan replaces the flat item.commision rate for the
+// items it covers. A plan is scoped to a company, an item category,
+// or both; see entity.ResolveCommissionPlan for which plan wins.
+// Method is TIERED, PROGRESSIVE (both driven by Tiers) or
+// PERCENTAGE (Rate percent of the sales in scope).
+type CommissionPlan struct {
+	concern.CommonWithIDs
+	OrganizationID string
+	Name           string
+	CompanyID      string
+	ItemCategoryID string
+	Method         string
+	Rate           float64
+	IsActive       bool
+	Tiers          []CommissionPlanTier `gorm:"foreignKey:PlanID;constraint:OnDelete:CASCADE"`
+}
+
+// CommissionPlanTier is one band of a tiered plan. It applies to
+// the units sold beyond FromQty, up to the next tier's FromQty.
+type CommissionPlanTier struct {
+	concern.CommonWithIDs
+	PlanID  string
+	FromQty int
+	Rate    float64
+}
@@ -9,11 +9,19 @@ import (
 // EmployeeSalary is the header row for one payroll run for a
 // single employee over a closed date range. The components that
 // rolled into these totals live in EmployeeSalaryComponent.
+//
+// Status walks DRAFT -> APPROVED -> PAID; any of them can be
+// VOIDed. Each transition stamps its actor and time. Approval
+// accrues the pay in the ledger and payment books it out; the
+// journal entries are kept so a void can reverse them. The save
+// itself posts only when the organization has PAYROLL_SAVED rules
+// (SaveJournalEntryID).
 type EmployeeSalary struct {
 	concern.CommonWithIDs
 	OrganizationID     string
+	BatchID            string
 	AdminIDEmployee    string
-	Admin              *Admin
+	Admin              *Admin `gorm:"foreignKey:AdminIDEmployee"`
 	StartDate          time.Time
 	EndDate            time.Time
 	TotalMealAllowance float64
@@ -22,7 +30,23 @@ type EmployeeSalary struct {
 	TotalBonusTarget   float64
 	TotalSalary        float64
 	TotalCashReceipt   float64
-	RemainingSalary    float64
+	// TotalCashDebt is the cash advance recovered by this run; the
+	// CASH_DEBT_DEDUCTION components carry the per-advance split.
+	TotalCashDebt   float64
+	RemainingSalary float64
+
+	Status                string
+	CreatedBy             string
+	ApprovedAt            *time.Time
+	ApprovedBy            string
+	PaidAt                *time.Time
+	PaidBy                string
+	VoidedAt              *time.Time
+	VoidedBy              string
+	VoidReason            string
+	SaveJournalEntryID    string
+	AccrualJournalEntryID string
+	PaymentJournalEntryID string
 }
 
 // EmployeeSalaryComponent is one line on the payroll breakdown.
@@ -30,6 +54,7 @@ type EmployeeSalary struct {
 // COMMISSION:
 //
 //	MEAL_ALLOWANCE | ATTENDANCE | COMMISSION | BONUS_TARGET
+//	CASH_DEBT_DEDUCTION (subtracted, ref_table = cash_debt)
 //
 // ref_id + ref_table + ref_source let the system trace each
 // component row back to its origin (today: stock_session; future:
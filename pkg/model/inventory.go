@@ -0,0 +1,42 @@
+package model
+
+import (
+	"time"
+
+	"github.com/raymondsugiarto/coffee-api/pkg/model/concern"
+)
+
+// ItemStock is the depot's running on-hand quantity of one item.
+// It is only ever moved together with a StockMovement row, so it
+// always equals the sum of the item's movements. ReorderPoint is
+// the on-hand at or below which the item should be ordered again;
+// 0 means it is not watched.
+type ItemStock struct {
+	concern.CommonWithIDs
+	OrganizationID string
+	ItemID         string
+	Item           *Item
+	OnHand         int
+	ReorderPoint   int
+}
+
+// StockMovement is one line of the item stock ledger. Qty is signed:
+// receipts and session returns add to the depot, session loads take
+// from it, and adjustments go either way. BalanceAfter is the item's
+// on-hand right after this line. RefTable / RefID point at the
+// document that caused the move (stock_session, ...).
+type StockMovement struct {
+	concern.CommonWithIDs
+	OrganizationID string
+	ItemID         string
+	Item           *Item
+	Date           time.Time
+	Type           string
+	Qty            int
+	BalanceAfter   int
+	RefID          string
+	RefTable       string
+	Reference      string
+	Notes          string
+	CreatedBy      string
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"github.com/raymondsugiarto/coffee-api/pkg/model/concern"
+)
+
+// JournalEntry is the header of one balanced posting. Its lines are
+// the account_mutation rows carrying its id in journal_entry_id;
+// the service refuses to write an entry whose lines don't sum to
+// zero.
+//
+// Entries are never edited. A wrong entry is undone by a reversal:
+// a mirror entry pointing back through ReversalOfID, with the
+// original stamped ReversedByID so it can't be reversed twice.
+//
+// `source` is MANUAL for operator adjustments, REVERSAL for mirror
+// entries, or the posting event type (STOCK_SESSION_CLOSED, ...)
+// for entries produced by the posting rules. RefTable / RefID point
+// at the upstream record the same way account_mutation does.
+type JournalEntry struct {
+	concern.CommonWithIDs
+	OrganizationID string
+	Description    string
+	Source         string
+	RefID          string
+	RefTable       string
+	RefModule      string
+	ReversalOfID   string
+	ReversedByID   string
+	CreatedBy      string
+}
@@ -20,6 +20,7 @@ type Order struct {
 	TotalQty       int
 	TotalAmount    float64
 	Status         string
+	JournalEntryID string
 	OrderItems     []OrderItem
 	OrderPayments  []OrderPayment
 }
@@ -10,4 +10,8 @@ type Organization struct {
 	Code   string
 	Name   string
 	Origin string
+	// StaleSessionPolicy is what the stale-session job does with a
+	// stock session still OPEN past the cutoff: AUTO_CLOSE or
+	// PENDING_REVIEW (the default).
+	StaleSessionPolicy string
 }
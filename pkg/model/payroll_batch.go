@@ -0,0 +1,20 @@
+package model
+
+import (
+	"time"
+
+	"github.com/raymondsugiarto/coffee-api/pkg/model/concern"
+)
+
+// PayrollBatch groups the payroll runs saved together for every
+// driver of one company over one window. The runs point back at it
+// through EmployeeSalary.BatchID; the batch itself only records
+// what was asked for and by whom.
+type PayrollBatch struct {
+	concern.CommonWithIDs
+	OrganizationID string
+	CompanyID      string
+	StartDate      time.Time
+	EndDate        time.Time
+	CreatedBy      string
+}
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"github.com/raymondsugiarto/coffee-api/pkg/model/concern"
+)
+
+// PostingRule maps one amount of a business event onto a debit and
+// a credit account. When the event fires (a stock session closes, a
+// payroll run is saved, ...) the accounting module looks up every
+// rule for that event and posts `amount` to DebitAccountCode and
+// `-amount` to CreditAccountCode, so each rule is balanced on its
+// own.
+//
+// Accounts are referenced by `code`, not id, for the same reason
+// upstream flows resolve them by code: an organization can replace a
+// seeded account without rewriting its rules. NULL organization_id
+// rows are the global defaults; an org-owned row for the same
+// (event_type, amount_field) overrides them. An inactive rule posts
+// nothing, so an inactive override switches a default off.
+type PostingRule struct {
+	concern.CommonWithIDs
+	OrganizationID    string
+	EventType         string
+	AmountField       string
+	DebitAccountCode  string
+	CreditAccountCode string
+	Description       string
+	IsActive          bool
+}
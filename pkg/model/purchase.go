@@ -0,0 +1,71 @@
+package model
+
+import (
+	"time"
+
+	"github.com/raymondsugiarto/coffee-api/pkg/model/concern"
+)
+
+// PurchaseOrder is what was ordered from a supplier. Status moves
+// OPEN -> PARTIAL -> RECEIVED as goods receipts come in, or to
+// CANCELLED when the rest will not be delivered. Total is the
+// ordered value (sum of qty * unit cost).
+type PurchaseOrder struct {
+	concern.CommonWithIDs
+	OrganizationID string
+	SupplierID     string
+	Supplier       *Supplier
+	Code           string
+	Date           time.Time
+	ExpectedDate   *time.Time
+	Status         string
+	Total          float64
+	Notes          string
+	CreatedBy      string
+	Lines          []PurchaseOrderLine `gorm:"foreignKey:PurchaseOrderID;constraint:OnDelete:CASCADE"`
+}
+
+// PurchaseOrderLine is one item of an order. ReceivedQty is the
+// running sum of the goods-receipt lines booked against it.
+type PurchaseOrderLine struct {
+	concern.CommonWithIDs
+	PurchaseOrderID string
+	ItemID          string
+	Item            *Item
+	Qty             int
+	UnitCost        float64
+	ReceivedQty     int
+}
+
+// GoodsReceipt is a delivery taken into the depot, with or without
+// a purchase order behind it. Its lines are booked as RECEIPT stock
+// movements and its Total as Persediaan against Hutang Usaha
+// (JournalEntryID). Receipts are append-only.
+type GoodsReceipt struct {
+	concern.CommonWithIDs
+	OrganizationID  string
+	SupplierID      string
+	Supplier        *Supplier
+	PurchaseOrderID string
+	Code            string
+	Date            time.Time
+	Reference       string
+	Total           float64
+	UpdateCostPrice bool
+	JournalEntryID  string
+	Notes           string
+	CreatedBy       string
+	Lines           []GoodsReceiptLine `gorm:"foreignKey:GoodsReceiptID;constraint:OnDelete:CASCADE"`
+}
+
+// GoodsReceiptLine is one item received. PurchaseOrderLineID is set
+// when the receipt fills a purchase order.
+type GoodsReceiptLine struct {
+	concern.CommonWithIDs
+	GoodsReceiptID      string
+	PurchaseOrderLineID string
+	ItemID              string
+	Item                *Item
+	Qty                 int
+	UnitCost            float64
+}
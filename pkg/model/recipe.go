@@ -0,0 +1,47 @@
+package model
+
+import (
+	"time"
+
+	"github.com/raymondsugiarto/coffee-api/pkg/model/concern"
+)
+
+// Recipe is the bill of materials of a sellable item: what one unit
+// sold ("Es Kopi Susu", one cup) takes out of the depot. A variant
+// without a recipe of its own uses its parent's.
+type Recipe struct {
+	concern.CommonWithIDs
+	OrganizationID string
+	ItemID         string
+	Item           *Item
+	Notes          string
+	IsActive       bool
+	Lines          []RecipeLine `gorm:"foreignKey:RecipeID;constraint:OnDelete:CASCADE"`
+}
+
+// RecipeLine is one ingredient of a recipe. Qty is per unit of the
+// sellable item, in the ingredient's stock unit (grams of beans,
+// ml of milk, one cup).
+type RecipeLine struct {
+	concern.CommonWithIDs
+	RecipeID     string
+	IngredientID string
+	Ingredient   *Item `gorm:"foreignKey:IngredientID"`
+	Qty          float64
+}
+
+// IngredientUsage is the theoretical consumption of one ingredient
+// by one item sold on a closed stock session: SoldQty units times
+// QtyPerUnit from the recipe in force at close.
+type IngredientUsage struct {
+	concern.CommonWithIDs
+	OrganizationID string
+	StockSessionID string
+	Date           time.Time
+	ItemID         string
+	IngredientID   string
+	Ingredient     *Item `gorm:"foreignKey:IngredientID"`
+	SoldQty        int
+	QtyPerUnit     float64
+	Qty            float64
+}
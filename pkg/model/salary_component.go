@@ -1,6 +1,10 @@
 package model
 
-import "github.com/raymondsugiarto/coffee-api/pkg/model/concern"
+import (
+	"time"
+
+	"github.com/raymondsugiarto/coffee-api/pkg/model/concern"
+)
 
 type ComponentType string
 
@@ -19,4 +23,11 @@ type SalaryComponent struct {
 	ComponentType  ComponentType
 	MinimumTarget  float64
 	Amount         float64
+	// A row is one version of a band, valid from EffectiveFrom to
+	// EffectiveTo inclusive (nil = still current). Editing a band
+	// closes the current version and adds a new one pointing back
+	// through PreviousID, so sessions keep the band of their date.
+	EffectiveFrom time.Time
+	EffectiveTo   *time.Time
+	PreviousID    string
 }
@@ -0,0 +1,42 @@
+package model
+
+import (
+	"time"
+
+	"github.com/raymondsugiarto/coffee-api/pkg/model/concern"
+)
+
+// StockOpname is a physical count of the depot. Starting it
+// snapshots each item's system on-hand (SystemQty); the counted
+// quantities are filled in while it is DRAFT. Approving it books
+// the variances as ADJUSTMENT stock movements and their value at
+// the items' cost price in the ledger (JournalEntryID). A count
+// that is abandoned is CANCELLED.
+type StockOpname struct {
+	concern.CommonWithIDs
+	OrganizationID string
+	Code           string
+	Date           time.Time
+	Status         string
+	Notes          string
+	TotalShrinkage float64
+	TotalSurplus   float64
+	JournalEntryID string
+	CreatedBy      string
+	ApprovedBy     string
+	ApprovedAt     *time.Time
+	Lines          []StockOpnameLine `gorm:"foreignKey:StockOpnameID;constraint:OnDelete:CASCADE"`
+}
+
+// StockOpnameLine is one item of a count. CountedQty stays nil
+// until the item is counted. CostPrice is snapshotted from the item
+// on approval.
+type StockOpnameLine struct {
+	concern.CommonWithIDs
+	StockOpnameID string
+	ItemID        string
+	Item          *Item
+	SystemQty     int
+	CountedQty    *int
+	CostPrice     float64
+}
@@ -19,7 +19,7 @@ type StockSession struct {
 	EmployeeID          string
 	Employee            *Admin
 	Date                time.Time
-	Status              string // OPEN | CLOSED
+	Status              string // OPEN | PENDING_REVIEW | CLOSED
 	OpenedAt            time.Time
 	ClosedAt            *time.Time
 	TotalSales          float64
@@ -51,4 +51,16 @@ type StockSession struct {
 	Payments    []PaymentDetail    `gorm:"foreignKey:SessionID;constraint:OnDelete:CASCADE"`
 	Adjustments []CashAdjustment   `gorm:"foreignKey:SessionID;constraint:OnDelete:CASCADE"`
 	Logs        []SessionLog       `gorm:"foreignKey:SessionID;constraint:OnDelete:CASCADE"`
+
+	// CommissionPlans is the JSON snapshot of the commission plans
+	// applied at close (entity.StockSessionCommissionPlanDto), so a
+	// later edit of a plan never changes a closed session.
+	CommissionPlans string
+
+	// Shift splits a driver's day into a MORNING and an EVENING
+	// session, unique per (employee, date, shift). CarriedFromID is
+	// the MORNING session whose returns an EVENING session was
+	// loaded with.
+	Shift         SessionType
+	CarriedFromID string
 }
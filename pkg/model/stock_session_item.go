@@ -32,4 +32,8 @@ type StockSessionItem struct {
 	// (commissionSnapshot × soldQty). Summed into
 	// stock_session.total_commission.
 	CommissionTotal float64
+	// CommissionPlanID is the commission plan that priced this row
+	// at close; empty when the flat item rate applied. The row's
+	// CommissionSnapshot is then the plan's average rate per unit.
+	CommissionPlanID string
 }
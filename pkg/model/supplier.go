@@ -0,0 +1,17 @@
+package model
+
+import "github.com/raymondsugiarto/coffee-api/pkg/model/concern"
+
+// Supplier is a vendor the organization buys stock from (beans,
+// milk, cups, ...).
+type Supplier struct {
+	concern.CommonWithIDs
+	OrganizationID string
+	Name           string
+	ContactName    string
+	PhoneNumber    string
+	Email          string
+	Address        string
+	Notes          string
+	IsActive       bool
+}
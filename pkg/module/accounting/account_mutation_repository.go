@@ -24,6 +24,14 @@ func NewAccountMutationRepository(db *gorm.DB) AccountMutationRepository {
 	return &accountMutationRepository{db: db}
 }
 
+// WithTx returns a copy of the repository bound to an outer
+// transaction, so a flow that owns the transaction (e.g. the
+// stock-session close) can post its mutations atomically with its
+// own writes.
+func (r *accountMutationRepository) WithTx(tx *gorm.DB) AccountMutationRepository {
+	return &accountMutationRepository{db: tx}
+}
+
 func (r *accountMutationRepository) Create(
 	ctx context.Context,
 	dto *entity.AccountMutationDto,
@@ -57,7 +65,7 @@ func (r *accountMutationRepository) FindAll(
 		QueryField: []string{},
 		Data:       &rows,
 		AllowedFields: []string{
-			"account_id", "ref_id", "ref_table", "ref_module", "amount", "created_at",
+			"account_id", "ref_id", "ref_table", "ref_module", "journal_entry_id", "amount", "created_at",
 		},
 	})
 	if err != nil {
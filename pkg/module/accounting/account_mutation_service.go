@@ -2,16 +2,21 @@ package accounting
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/raymondsugiarto/coffee-api/pkg/entity"
 	shared "github.com/raymondsugiarto/coffee-api/pkg/shared/context"
 	"github.com/raymondsugiarto/coffee-api/pkg/shared/pagination"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/response/status"
+	"gorm.io/gorm"
 )
 
 // AccountMutationRepository is the ledger-side persistence
 // contract. Subset of the full CRUD surface because the ledger
 // is append-only.
 type AccountMutationRepository interface {
+	WithTx(tx *gorm.DB) AccountMutationRepository
 	Create(ctx context.Context, dto *entity.AccountMutationDto) (*entity.AccountMutationDto, error)
 	FindAll(ctx context.Context, req *entity.AccountMutationFindAllRequest) (*pagination.ResultPagination, error)
 }
@@ -21,6 +26,10 @@ type AccountMutationRepository interface {
 // posted, ...) so they can post to the ledger without needing to
 // know the SQL.
 type AccountMutationService interface {
+	// WithTx binds the service to a transaction owned by the
+	// caller. Every Create / Post on the returned service lands in
+	// that transaction and rolls back with it.
+	WithTx(tx *gorm.DB) AccountMutationService
 	Create(ctx context.Context, dto *entity.AccountMutationDto) (*entity.AccountMutationDto, error)
 	// Post writes the same payload but, in addition to the
 	// create, runs the account-existence check against the
@@ -36,6 +45,7 @@ type AccountMutationService interface {
 type accountMutationService struct {
 	repo            AccountMutationRepository
 	accountResolver AccountResolver
+	periodGuard     PeriodGuard
 }
 
 // AccountResolver is a narrow interface the mutation service
@@ -49,17 +59,48 @@ type AccountResolver interface {
 
 // NewAccountMutationService wires the mutation ledger. Pass the
 // full AccountService in — it satisfies AccountResolver via its
-// Get method.
+// Get method. `periodGuard` rejects postings dated in a closed
+// month: a mutation is dated by its CreatedAt (its journal entry's
+// when posted through one, otherwise the insert time) on the
+// Asia/Jakarta calendar.
 func NewAccountMutationService(
 	repo AccountMutationRepository,
 	accountResolver AccountResolver,
+	periodGuard PeriodGuard,
 ) AccountMutationService {
 	return &accountMutationService{
 		repo:            repo,
 		accountResolver: accountResolver,
+		periodGuard:     periodGuard,
 	}
 }
 
+func (s *accountMutationService) WithTx(tx *gorm.DB) AccountMutationService {
+	return &accountMutationService{
+		repo:            s.repo.WithTx(tx),
+		accountResolver: s.accountResolver,
+		periodGuard:     s.periodGuard,
+	}
+}
+
+// jakarta is the business day's time zone, as the scheduler uses.
+var jakarta = func() *time.Location {
+	loc, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		return time.FixedZone("WIB", 7*60*60)
+	}
+	return loc
+}()
+
+// postingDate is the YYYY-MM-DD a posting stamped at `at` falls on;
+// a zero `at` means now.
+func postingDate(at time.Time) string {
+	if at.IsZero() {
+		at = time.Now()
+	}
+	return at.In(jakarta).Format("2006-01-02")
+}
+
 func (s *accountMutationService) Create(
 	ctx context.Context,
 	dto *entity.AccountMutationDto,
@@ -67,6 +108,9 @@ func (s *accountMutationService) Create(
 	if dto.OrganizationID == "" {
 		dto.OrganizationID = shared.GetOrganization(ctx).ID
 	}
+	if err := s.periodGuard.EnsureOpen(ctx, postingDate(dto.CreatedAt)); err != nil {
+		return nil, err
+	}
 	return s.repo.Create(ctx, dto)
 }
 
@@ -78,9 +122,18 @@ func (s *accountMutationService) Post(
 	// account. Returning a 400-ish error from here surfaces a
 	// clean validation message instead of letting the FK fire
 	// later in the SQL.
-	if _, err := s.accountResolver.Get(ctx, dto.AccountID); err != nil {
+	account, err := s.accountResolver.Get(ctx, dto.AccountID)
+	if err != nil {
 		return nil, err
 	}
+	// The account must be global or the posting org's own; another
+	// org's account would leak this org's money into its books.
+	if dto.OrganizationID == "" {
+		dto.OrganizationID = shared.GetOrganization(ctx).ID
+	}
+	if account.OrganizationID != "" && account.OrganizationID != dto.OrganizationID {
+		return nil, status.New(status.BadRequest, fmt.Errorf("account %s not found", dto.AccountID))
+	}
 	return s.Create(ctx, dto)
 }
 
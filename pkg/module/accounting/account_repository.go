@@ -37,6 +37,22 @@ func (r *accountRepository) Get(ctx context.Context, id string) (*entity.Account
 	return entity.NewAccountDtoFromModel(&m), nil
 }
 
+// GetByCode resolves an account by its code for one organization.
+// An org-owned row wins over the global seed row (NULL
+// organization_id) with the same code, so operators can override a
+// default account without touching the seed.
+func (r *accountRepository) GetByCode(ctx context.Context, organizationID, code string) (*entity.AccountDto, error) {
+	var m model.Account
+	if err := r.db.WithContext(ctx).
+		Where("code = ?", code).
+		Where("organization_id IS NULL OR organization_id = ?", organizationID).
+		Order("organization_id IS NULL ASC").
+		First(&m).Error; err != nil {
+		return nil, err
+	}
+	return entity.NewAccountDtoFromModel(&m), nil
+}
+
 func (r *accountRepository) Update(ctx context.Context, dto *entity.AccountDto) (*entity.AccountDto, error) {
 	if err := r.db.WithContext(ctx).Save(dto.ToModel()).Error; err != nil {
 		return nil, err
@@ -75,7 +91,7 @@ func (r *accountRepository) FindAll(
 		Request:       req,
 		QueryField:    []string{},
 		Data:          &rows,
-		AllowedFields: []string{"code", "name"},
+		AllowedFields: []string{"code", "name", "type", "parent_id"},
 	})
 	if err != nil {
 		return nil, err
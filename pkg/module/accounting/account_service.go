@@ -2,10 +2,12 @@ package accounting
 
 import (
 	"context"
+	"errors"
 
 	"github.com/raymondsugiarto/coffee-api/pkg/entity"
 	shared "github.com/raymondsugiarto/coffee-api/pkg/shared/context"
 	"github.com/raymondsugiarto/coffee-api/pkg/shared/pagination"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/response/status"
 )
 
 // AccountRepository is the persistence-side contract the service
@@ -14,6 +16,7 @@ import (
 type AccountRepository interface {
 	Create(ctx context.Context, dto *entity.AccountDto) (*entity.AccountDto, error)
 	Get(ctx context.Context, id string) (*entity.AccountDto, error)
+	GetByCode(ctx context.Context, organizationID, code string) (*entity.AccountDto, error)
 	Update(ctx context.Context, dto *entity.AccountDto) (*entity.AccountDto, error)
 	Delete(ctx context.Context, id string) error
 	FindAll(ctx context.Context, req *entity.AccountFindAllRequest) (*pagination.ResultPagination, error)
@@ -25,6 +28,9 @@ type AccountRepository interface {
 type AccountService interface {
 	Create(ctx context.Context, dto *entity.AccountDto) (*entity.AccountDto, error)
 	Get(ctx context.Context, id string) (*entity.AccountDto, error)
+	// GetByCode resolves an account by code within the caller's
+	// organization, falling back to the global seed row.
+	GetByCode(ctx context.Context, code string) (*entity.AccountDto, error)
 	Update(ctx context.Context, dto *entity.AccountDto) (*entity.AccountDto, error)
 	Delete(ctx context.Context, id string) error
 	FindAll(ctx context.Context, req *entity.AccountFindAllRequest) (*pagination.ResultPagination, error)
@@ -45,6 +51,9 @@ func (s *accountService) Create(ctx context.Context, dto *entity.AccountDto) (*e
 	if dto.OrganizationID == "" {
 		dto.OrganizationID = shared.GetOrganization(ctx).ID
 	}
+	if err := s.validateParent(ctx, dto); err != nil {
+		return nil, err
+	}
 	return s.repo.Create(ctx, dto)
 }
 
@@ -52,13 +61,45 @@ func (s *accountService) Get(ctx context.Context, id string) (*entity.AccountDto
 	return s.repo.Get(ctx, id)
 }
 
+func (s *accountService) GetByCode(ctx context.Context, code string) (*entity.AccountDto, error) {
+	return s.repo.GetByCode(ctx, shared.GetOrganization(ctx).ID, code)
+}
+
 func (s *accountService) Update(ctx context.Context, dto *entity.AccountDto) (*entity.AccountDto, error) {
 	if dto.OrganizationID == "" {
 		dto.OrganizationID = shared.GetOrganization(ctx).ID
 	}
+	if err := s.validateParent(ctx, dto); err != nil {
+		return nil, err
+	}
 	return s.repo.Update(ctx, dto)
 }
 
+// validateParent keeps the hierarchy a tree of like accounts: the
+// parent must exist, share the child's type (so rolled-up balances
+// stay on one normal side), and must not be the account itself or
+// one of its descendants.
+func (s *accountService) validateParent(ctx context.Context, dto *entity.AccountDto) error {
+	if dto.ParentID == "" {
+		return nil
+	}
+	parentID := dto.ParentID
+	for depth := 0; parentID != ""; depth++ {
+		if parentID == dto.ID || depth > 32 {
+			return status.New(status.BadRequest, errors.New("parentId would create a cycle in the account hierarchy"))
+		}
+		parent, err := s.repo.Get(ctx, parentID)
+		if err != nil {
+			return status.New(status.BadRequest, errors.New("parentId does not resolve to an account"))
+		}
+		if depth == 0 && parent.Type != dto.Type {
+			return status.New(status.BadRequest, errors.New("parent account must have the same type"))
+		}
+		parentID = parent.ParentID
+	}
+	return nil
+}
+
 func (s *accountService) Delete(ctx context.Context, id string) error {
 	return s.repo.Delete(ctx, id)
 }
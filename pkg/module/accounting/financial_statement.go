@@ -0,0 +1,328 @@
+package accounting
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	shared "github.com/raymondsugiarto/coffee-api/pkg/shared/context"
+)
+
+// statementWindow is a half-open [from, to) reporting window.
+type statementWindow struct {
+	from time.Time
+	to   time.Time
+}
+
+func (w statementWindow) fromLabel() string { return w.from.Format("2006-01-02") }
+func (w statementWindow) toLabel() string   { return w.to.AddDate(0, 0, -1).Format("2006-01-02") }
+
+// parseStatementWindow resolves the request bounds. Statements
+// always have a start: an empty From falls back to the first day
+// of To's month.
+func parseStatementWindow(req *entity.FinancialStatementRequest) (statementWindow, error) {
+	from, to, err := parseReportWindow(req.From, req.To)
+	if err != nil {
+		return statementWindow{}, err
+	}
+	if from.IsZero() {
+		last := to.AddDate(0, 0, -1)
+		from = time.Date(last.Year(), last.Month(), 1, 0, 0, 0, 0, time.Local)
+	}
+	return statementWindow{from: from, to: to}, nil
+}
+
+// previous returns the window right before w. Whole-month windows
+// shift by calendar months so March compares with February, not
+// with the 31 days before March 1st; anything else shifts by days.
+func (w statementWindow) previous() statementWindow {
+	if w.from.Day() == 1 && w.to.Day() == 1 {
+		months := (w.to.Year()-w.from.Year())*12 + int(w.to.Month()-w.from.Month())
+		return statementWindow{from: w.from.AddDate(0, -months, 0), to: w.from}
+	}
+	days := int(w.to.Sub(w.from).Hours()/24 + 0.5)
+	return statementWindow{from: w.from.AddDate(0, 0, -days), to: w.from}
+}
+
+func changePercent(current, previous float64) float64 {
+	if previous == 0 {
+		return 0
+	}
+	return roundAmount((current - previous) / absAmount(previous) * 100)
+}
+
+func absAmount(v float64) float64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// statementSection accumulates per-account amounts for the current
+// and previous window, then flattens them into a section in code
+// order.
+type statementSection struct {
+	accountType string
+	current     map[string]float64
+	previous    map[string]float64
+}
+
+func newStatementSection(accountType string) *statementSection {
+	return &statementSection{
+		accountType: accountType,
+		current:     make(map[string]float64),
+		previous:    make(map[string]float64),
+	}
+}
+
+func (s *statementSection) build(accounts map[string]*entity.AccountDto, compare bool) entity.FinancialStatementSectionDto {
+	ids := make([]string, 0, len(s.current)+len(s.previous))
+	seen := make(map[string]bool)
+	for id := range s.current {
+		ids, seen[id] = append(ids, id), true
+	}
+	for id := range s.previous {
+		if !seen[id] {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return accounts[ids[i]].Code < accounts[ids[j]].Code
+	})
+
+	section := entity.FinancialStatementSectionDto{
+		Type:  s.accountType,
+		Lines: make([]entity.FinancialStatementLineDto, 0, len(ids)),
+	}
+	for _, id := range ids {
+		a := accounts[id]
+		cur, prev := roundAmount(s.current[id]), roundAmount(s.previous[id])
+		if cur == 0 && prev == 0 {
+			continue
+		}
+		line := entity.FinancialStatementLineDto{
+			AccountID: a.ID,
+			Code:      a.Code,
+			Name:      a.Name,
+			Amount:    cur,
+		}
+		if compare {
+			line.PreviousAmount = prev
+			line.Change = roundAmount(cur - prev)
+			line.ChangePercent = changePercent(cur, prev)
+		}
+		section.Lines = append(section.Lines, line)
+		section.Total += cur
+		section.PreviousTotal += prev
+	}
+	section.Total = roundAmount(section.Total)
+	if compare {
+		section.PreviousTotal = roundAmount(section.PreviousTotal)
+	} else {
+		section.PreviousTotal = 0
+	}
+	return section
+}
+
+func (s *reportService) accountsByID(ctx context.Context, orgID string) (map[string]*entity.AccountDto, error) {
+	accounts, err := s.repo.FindAccounts(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*entity.AccountDto, len(accounts))
+	for _, a := range accounts {
+		byID[a.ID] = a
+	}
+	return byID, nil
+}
+
+func (s *reportService) ProfitLoss(
+	ctx context.Context,
+	req *entity.FinancialStatementRequest,
+) (*entity.ProfitLossDto, error) {
+	window, err := parseStatementWindow(req)
+	if err != nil {
+		return nil, err
+	}
+	orgID := shared.GetOrganization(ctx).ID
+	accounts, err := s.accountsByID(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	revenue := newStatementSection(entity.AccountTypeRevenue)
+	expense := newStatementSection(entity.AccountTypeExpense)
+	collect := func(w statementWindow, pick func(*statementSection) map[string]float64) error {
+		movements, err := s.repo.SumMovements(ctx, orgID, w.from, w.to)
+		if err != nil {
+			return err
+		}
+		for _, m := range movements {
+			a, ok := accounts[m.AccountID]
+			if !ok {
+				continue
+			}
+			amount := entity.AccountBalance(a.Type, m.Debit-m.Credit)
+			switch a.Type {
+			case entity.AccountTypeRevenue:
+				pick(revenue)[a.ID] += amount
+			case entity.AccountTypeExpense:
+				pick(expense)[a.ID] += amount
+			}
+		}
+		return nil
+	}
+	if err := collect(window, func(s *statementSection) map[string]float64 { return s.current }); err != nil {
+		return nil, err
+	}
+
+	report := &entity.ProfitLossDto{
+		From:    window.fromLabel(),
+		To:      window.toLabel(),
+		Compare: req.Compare,
+	}
+	if req.Compare {
+		prev := window.previous()
+		report.PreviousFrom = prev.fromLabel()
+		report.PreviousTo = prev.toLabel()
+		if err := collect(prev, func(s *statementSection) map[string]float64 { return s.previous }); err != nil {
+			return nil, err
+		}
+	}
+
+	report.Revenue = revenue.build(accounts, req.Compare)
+	report.Expense = expense.build(accounts, req.Compare)
+	report.NetIncome = roundAmount(report.Revenue.Total - report.Expense.Total)
+	if req.Compare {
+		report.PreviousNetIncome = roundAmount(report.Revenue.PreviousTotal - report.Expense.PreviousTotal)
+		report.NetIncomeChange = roundAmount(report.NetIncome - report.PreviousNetIncome)
+		report.NetIncomeChangePercent = changePercent(report.NetIncome, report.PreviousNetIncome)
+	}
+	return report, nil
+}
+
+// cashEffects attributes the cash moved by each posting in w to the
+// non-cash accounts on its other side. A balanced posting satisfies
+// cash + non-cash = 0, so each non-cash leg contributes -amount of
+// cash and the contributions add up to the net cash movement.
+// Postings that don't touch a cash account are skipped, as are
+// transfers between two cash accounts (no non-cash leg).
+func (s *reportService) cashEffects(
+	ctx context.Context,
+	orgID string,
+	w statementWindow,
+	accounts map[string]*entity.AccountDto,
+) (map[string]float64, error) {
+	legs, err := s.repo.FindLegs(ctx, orgID, w.from, w.to)
+	if err != nil {
+		return nil, err
+	}
+	byGroup := make(map[string][]*entity.LedgerLegDto)
+	touchesCash := make(map[string]bool)
+	for _, l := range legs {
+		byGroup[l.GroupKey] = append(byGroup[l.GroupKey], l)
+		if a, ok := accounts[l.AccountID]; ok && a.IsCash {
+			touchesCash[l.GroupKey] = true
+		}
+	}
+	effects := make(map[string]float64)
+	for key, group := range byGroup {
+		if !touchesCash[key] {
+			continue
+		}
+		for _, l := range group {
+			a, ok := accounts[l.AccountID]
+			if !ok || a.IsCash {
+				continue
+			}
+			effects[l.AccountID] -= l.Amount
+		}
+	}
+	return effects, nil
+}
+
+func (s *reportService) CashFlow(
+	ctx context.Context,
+	req *entity.FinancialStatementRequest,
+) (*entity.CashFlowDto, error) {
+	window, err := parseStatementWindow(req)
+	if err != nil {
+		return nil, err
+	}
+	orgID := shared.GetOrganization(ctx).ID
+	accounts, err := s.accountsByID(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	types := []string{
+		entity.AccountTypeRevenue,
+		entity.AccountTypeExpense,
+		entity.AccountTypeAsset,
+		entity.AccountTypeLiability,
+		entity.AccountTypeEquity,
+	}
+	sections := make(map[string]*statementSection, len(types))
+	for _, t := range types {
+		sections[t] = newStatementSection(t)
+	}
+	collect := func(w statementWindow, pick func(*statementSection) map[string]float64) error {
+		effects, err := s.cashEffects(ctx, orgID, w, accounts)
+		if err != nil {
+			return err
+		}
+		for id, amount := range effects {
+			if section, ok := sections[accounts[id].Type]; ok {
+				pick(section)[id] += amount
+			}
+		}
+		return nil
+	}
+
+	report := &entity.CashFlowDto{
+		From:    window.fromLabel(),
+		To:      window.toLabel(),
+		Compare: req.Compare,
+	}
+	if err := collect(window, func(s *statementSection) map[string]float64 { return s.current }); err != nil {
+		return nil, err
+	}
+	opening, err := s.repo.SumCashBefore(ctx, orgID, window.from)
+	if err != nil {
+		return nil, err
+	}
+	report.OpeningCash = roundAmount(opening)
+	if req.Compare {
+		prev := window.previous()
+		report.PreviousFrom = prev.fromLabel()
+		report.PreviousTo = prev.toLabel()
+		if err := collect(prev, func(s *statementSection) map[string]float64 { return s.previous }); err != nil {
+			return nil, err
+		}
+		prevOpening, err := s.repo.SumCashBefore(ctx, orgID, prev.from)
+		if err != nil {
+			return nil, err
+		}
+		report.PreviousOpeningCash = roundAmount(prevOpening)
+	}
+
+	report.Sections = make([]entity.FinancialStatementSectionDto, 0, len(types))
+	for _, t := range types {
+		section := sections[t].build(accounts, req.Compare)
+		if len(section.Lines) == 0 {
+			continue
+		}
+		section.Activity = entity.CashFlowActivityOf(t)
+		report.Sections = append(report.Sections, section)
+		report.NetChange += section.Total
+		report.PreviousNetChange += section.PreviousTotal
+	}
+	report.NetChange = roundAmount(report.NetChange)
+	report.ClosingCash = roundAmount(report.OpeningCash + report.NetChange)
+	if req.Compare {
+		report.PreviousNetChange = roundAmount(report.PreviousNetChange)
+		report.PreviousClosingCash = roundAmount(report.PreviousOpeningCash + report.PreviousNetChange)
+	}
+	return report, nil
+}
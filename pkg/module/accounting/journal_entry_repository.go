@@ -0,0 +1,117 @@
+package accounting
+
+import (
+	"context"
+	"errors"
+
+	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	"github.com/raymondsugiarto/coffee-api/pkg/model"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/pagination"
+	"gorm.io/gorm"
+)
+
+// ErrJournalEntryAlreadyReversed is returned by MarkReversed when
+// another reversal got there first.
+var ErrJournalEntryAlreadyReversed = errors.New("journal entry is already reversed")
+
+// journalEntryRepository stores journal entry headers. The lines
+// live in account_mutation and are written through the mutation
+// service; Get reads them back by journal_entry_id.
+type journalEntryRepository struct {
+	db *gorm.DB
+}
+
+func NewJournalEntryRepository(db *gorm.DB) JournalEntryRepository {
+	return &journalEntryRepository{db: db}
+}
+
+func (r *journalEntryRepository) WithTx(tx *gorm.DB) JournalEntryRepository {
+	return &journalEntryRepository{db: tx}
+}
+
+func (r *journalEntryRepository) Create(ctx context.Context, dto *entity.JournalEntryDto) (*entity.JournalEntryDto, error) {
+	m := dto.ToModel()
+	if err := r.db.WithContext(ctx).Create(m).Error; err != nil {
+		return nil, err
+	}
+	return entity.NewJournalEntryDtoFromModel(m), nil
+}
+
+func (r *journalEntryRepository) Get(ctx context.Context, id string) (*entity.JournalEntryDto, error) {
+	var m model.JournalEntry
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&m).Error; err != nil {
+		return nil, err
+	}
+	var lines []model.AccountMutation
+	if err := r.db.WithContext(ctx).
+		Where("journal_entry_id = ?", id).
+		Order("created_at ASC, id ASC").
+		Find(&lines).Error; err != nil {
+		return nil, err
+	}
+	dto := entity.NewJournalEntryDtoFromModel(&m)
+	dto.Lines = make([]*entity.JournalLineDto, 0, len(lines))
+	for _, l := range lines {
+		dto.Lines = append(dto.Lines, &entity.JournalLineDto{
+			ID:          l.ID,
+			AccountID:   l.AccountID,
+			Amount:      l.Amount,
+			Description: l.Description,
+		})
+	}
+	return dto, nil
+}
+
+// MarkReversed stamps the original entry with its reversal. The
+// update only matches an entry that isn't reversed yet, so two
+// concurrent reversals can't both succeed.
+func (r *journalEntryRepository) MarkReversed(ctx context.Context, id, reversedByID string) error {
+	res := r.db.WithContext(ctx).
+		Model(&model.JournalEntry{}).
+		Where("id = ?", id).
+		Where("reversed_by_id IS NULL OR reversed_by_id = ''").
+		Update("reversed_by_id", reversedByID)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrJournalEntryAlreadyReversed
+	}
+	return nil
+}
+
+func (r *journalEntryRepository) FindAll(
+	ctx context.Context,
+	req *entity.JournalEntryFindAllRequest,
+) (*pagination.ResultPagination, error) {
+	var rows []model.JournalEntry = make([]model.JournalEntry, 0)
+	tbl := pagination.NewTable(r.db.WithContext(ctx))
+	dataTable, err := tbl.Pagination(func(i interface{}) *gorm.DB {
+		q := r.db.Model(&model.JournalEntry{})
+		if req.FindAllRequest.OrganizationData.ID != "" {
+			q = q.Where("organization_id = ?", req.FindAllRequest.OrganizationData.ID)
+		}
+		return q
+	}, &pagination.TableRequest{
+		Request:       req,
+		QueryField:    []string{"description"},
+		Data:          &rows,
+		AllowedFields: []string{"source", "ref_id", "ref_table"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := dataTable.(*pagination.ResultPagination)
+	hits := result.Data.(*[]model.JournalEntry)
+	out := make([]*entity.JournalEntryDto, 0, len(*hits))
+	for i := range *hits {
+		out = append(out, entity.NewJournalEntryDtoFromModel(&(*hits)[i]))
+	}
+	return &pagination.ResultPagination{
+		Data:        out,
+		Page:        result.Page,
+		Count:       result.Count,
+		RowsPerPage: result.RowsPerPage,
+		TotalPages:  result.TotalPages,
+	}, nil
+}
@@ -0,0 +1,252 @@
+package accounting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	shared "github.com/raymondsugiarto/coffee-api/pkg/shared/context"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/pagination"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/response/status"
+	"gorm.io/gorm"
+)
+
+// JournalEntryRepository is the persistence contract for journal
+// entry headers.
+type JournalEntryRepository interface {
+	WithTx(tx *gorm.DB) JournalEntryRepository
+	Create(ctx context.Context, dto *entity.JournalEntryDto) (*entity.JournalEntryDto, error)
+	Get(ctx context.Context, id string) (*entity.JournalEntryDto, error)
+	MarkReversed(ctx context.Context, id, reversedByID string) error
+	FindAll(ctx context.Context, req *entity.JournalEntryFindAllRequest) (*pagination.ResultPagination, error)
+}
+
+// JournalEntryService is the balanced write path into the ledger.
+// Every multi-leg posting (manual adjustments, posting-rule events,
+// reversals) goes through Post, which refuses entries whose lines
+// don't sum to zero.
+type JournalEntryService interface {
+	// WithTx binds the service to a transaction owned by the
+	// caller. Without it, Post and Reverse open their own.
+	WithTx(tx *gorm.DB) JournalEntryService
+	Post(ctx context.Context, dto *entity.JournalEntryDto) (*entity.JournalEntryDto, error)
+	// Reverse writes the mirror of an entry (every line negated)
+	// and links the two. An entry can be reversed once.
+	Reverse(ctx context.Context, id string, req *entity.JournalEntryReverseRequest) (*entity.JournalEntryDto, error)
+	Get(ctx context.Context, id string) (*entity.JournalEntryDto, error)
+	FindAll(ctx context.Context, req *entity.JournalEntryFindAllRequest) (*pagination.ResultPagination, error)
+}
+
+type journalEntryService struct {
+	repo                   JournalEntryRepository
+	db                     *gorm.DB
+	tx                     *gorm.DB
+	accountMutationService AccountMutationService
+}
+
+func NewJournalEntryService(
+	repo JournalEntryRepository,
+	db *gorm.DB,
+	accountMutationService AccountMutationService,
+) JournalEntryService {
+	return &journalEntryService{
+		repo:                   repo,
+		db:                     db,
+		accountMutationService: accountMutationService,
+	}
+}
+
+func (s *journalEntryService) WithTx(tx *gorm.DB) JournalEntryService {
+	return &journalEntryService{
+		repo:                   s.repo.WithTx(tx),
+		db:                     s.db,
+		tx:                     tx,
+		accountMutationService: s.accountMutationService.WithTx(tx),
+	}
+}
+
+// inTx runs fn on a transaction-bound copy of the service, reusing
+// the caller's transaction when there is one.
+func (s *journalEntryService) inTx(fn func(svc *journalEntryService) error) error {
+	if s.tx != nil {
+		return fn(s)
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return fn(s.WithTx(tx).(*journalEntryService))
+	})
+}
+
+// validateLines enforces the double-entry invariant: at least two
+// non-zero legs that sum to zero at the numeric(20, 4) precision of
+// the ledger.
+func validateLines(lines []*entity.JournalLineDto) error {
+	if len(lines) < 2 {
+		return status.New(status.BadRequest, errors.New("a journal entry needs at least two lines"))
+	}
+	var sum float64
+	for i, l := range lines {
+		l.Amount = roundAmount(l.Amount)
+		if l.Amount == 0 {
+			return status.New(status.BadRequest, fmt.Errorf("line %d has a zero amount", i+1))
+		}
+		sum += l.Amount
+	}
+	if roundAmount(sum) != 0 {
+		return status.New(status.BadRequest, fmt.Errorf("journal entry is not balanced: lines sum to %.4f", sum))
+	}
+	return nil
+}
+
+func (s *journalEntryService) Post(ctx context.Context, dto *entity.JournalEntryDto) (*entity.JournalEntryDto, error) {
+	if dto.OrganizationID == "" {
+		dto.OrganizationID = shared.GetOrganization(ctx).ID
+	}
+	if dto.Source == "" {
+		dto.Source = entity.JournalEntrySourceManual
+	}
+	if dto.CreatedBy == "" {
+		dto.CreatedBy = actorOf(ctx)
+	}
+	if err := validateLines(dto.Lines); err != nil {
+		return nil, err
+	}
+
+	var result *entity.JournalEntryDto
+	err := s.inTx(func(svc *journalEntryService) error {
+		header, err := svc.repo.Create(ctx, dto)
+		if err != nil {
+			return err
+		}
+		// Lines carry the upstream ref so the account statement can
+		// drill down to the source; an entry without one (a manual
+		// adjustment) refers its lines to itself.
+		refTable, refID, refModule := dto.RefTable, dto.RefID, dto.RefModule
+		if refTable == "" {
+			refTable = entity.AccountMutationRefTableJournalEntry
+			refID = header.ID
+			refModule = entity.AccountMutationRefModuleJournal
+		}
+		header.Lines = make([]*entity.JournalLineDto, 0, len(dto.Lines))
+		for _, l := range dto.Lines {
+			description := l.Description
+			if description == "" {
+				description = dto.Description
+			}
+			posted, err := svc.accountMutationService.Post(ctx, &entity.AccountMutationDto{
+				OrganizationID: dto.OrganizationID,
+				AccountID:      l.AccountID,
+				Amount:         l.Amount,
+				Description:    description,
+				RefID:          refID,
+				RefTable:       refTable,
+				RefModule:      refModule,
+				JournalEntryID: header.ID,
+				CreatedAt:      header.CreatedAt,
+			})
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return status.New(status.BadRequest, fmt.Errorf("account %s not found", l.AccountID))
+				}
+				return err
+			}
+			header.Lines = append(header.Lines, &entity.JournalLineDto{
+				ID:          posted.ID,
+				AccountID:   posted.AccountID,
+				Amount:      posted.Amount,
+				Description: posted.Description,
+			})
+		}
+		result = header
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *journalEntryService) Reverse(
+	ctx context.Context,
+	id string,
+	req *entity.JournalEntryReverseRequest,
+) (*entity.JournalEntryDto, error) {
+	var result *entity.JournalEntryDto
+	err := s.inTx(func(svc *journalEntryService) error {
+		original, err := svc.Get(ctx, id)
+		if err != nil {
+			return err
+		}
+		if original.ReversedByID != "" {
+			return status.New(status.BadRequest, ErrJournalEntryAlreadyReversed)
+		}
+
+		description := req.Description
+		if description == "" {
+			description = "Reversal of " + original.Description
+		}
+		// The mirror keeps the original's upstream ref so both legs
+		// show up when drilling into the source record; a manual
+		// entry's mirror points at the entry it reverses.
+		mirror := &entity.JournalEntryDto{
+			OrganizationID: original.OrganizationID,
+			Description:    description,
+			Source:         entity.JournalEntrySourceReversal,
+			RefID:          original.RefID,
+			RefTable:       original.RefTable,
+			RefModule:      original.RefModule,
+			ReversalOfID:   original.ID,
+			Lines:          make([]*entity.JournalLineDto, 0, len(original.Lines)),
+		}
+		if mirror.RefTable == "" {
+			mirror.RefTable = entity.AccountMutationRefTableJournalEntry
+			mirror.RefID = original.ID
+			mirror.RefModule = entity.AccountMutationRefModuleJournal
+		}
+		for _, l := range original.Lines {
+			mirror.Lines = append(mirror.Lines, &entity.JournalLineDto{
+				AccountID:   l.AccountID,
+				Amount:      -l.Amount,
+				Description: l.Description,
+			})
+		}
+
+		posted, err := svc.Post(ctx, mirror)
+		if err != nil {
+			return err
+		}
+		if err := svc.repo.MarkReversed(ctx, original.ID, posted.ID); err != nil {
+			if errors.Is(err, ErrJournalEntryAlreadyReversed) {
+				return status.New(status.BadRequest, err)
+			}
+			return err
+		}
+		result = posted
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *journalEntryService) Get(ctx context.Context, id string) (*entity.JournalEntryDto, error) {
+	dto, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if dto.OrganizationID != shared.GetOrganization(ctx).ID {
+		return nil, status.New(status.EntityNotFound, errors.New("journal entry not found"))
+	}
+	return dto, nil
+}
+
+func (s *journalEntryService) FindAll(
+	ctx context.Context,
+	req *entity.JournalEntryFindAllRequest,
+) (*pagination.ResultPagination, error) {
+	if req.FindAllRequest.OrganizationData.ID == "" {
+		req.FindAllRequest.OrganizationData.ID = shared.GetOrganization(ctx).ID
+	}
+	return s.repo.FindAll(ctx, req)
+}
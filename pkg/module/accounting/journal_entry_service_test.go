@@ -0,0 +1,104 @@
+package accounting
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	"github.com/raymondsugiarto/coffee-api/pkg/model"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/testdb"
+)
+
+// recordingGuard records the dates it is asked about and refuses
+// the ones in closed.
+type recordingGuard struct {
+	asked  []string
+	closed map[string]bool
+}
+
+func (g *recordingGuard) EnsureOpen(_ context.Context, date string) error {
+	g.asked = append(g.asked, date)
+	if g.closed[date] {
+		return errors.New("accounting period is closed")
+	}
+	return nil
+}
+
+func (g *recordingGuard) EnsureRangeOpen(ctx context.Context, from, to string) error {
+	if err := g.EnsureOpen(ctx, from); err != nil {
+		return err
+	}
+	return g.EnsureOpen(ctx, to)
+}
+
+// orgAccounts resolves any id to an account of org-1 without a
+// query: the test database has one connection, held by the entry's
+// transaction.
+type orgAccounts struct{}
+
+func (orgAccounts) Get(_ context.Context, id string) (*entity.AccountDto, error) {
+	return &entity.AccountDto{ID: id, OrganizationID: "org-1"}, nil
+}
+
+func TestPostingDate(t *testing.T) {
+	tests := []struct {
+		name string
+		at   time.Time
+		want string
+	}{
+		{"before midnight in Jakarta", time.Date(2026, 10, 31, 16, 59, 0, 0, time.UTC), "2026-10-31"},
+		{"after midnight in Jakarta", time.Date(2026, 10, 31, 17, 0, 0, 0, time.UTC), "2026-11-01"},
+		{"zero is now", time.Time{}, time.Now().In(jakarta).Format("2006-01-02")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := postingDate(tt.at); got != tt.want {
+				t.Errorf("postingDate(%v) = %s, want %s", tt.at, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJournalEntryPostChecksTheEntryDate(t *testing.T) {
+	db := testdb.New(t, &model.JournalEntry{}, &model.AccountMutation{})
+	guard := &recordingGuard{}
+	service := NewJournalEntryService(
+		NewJournalEntryRepository(db), db,
+		NewAccountMutationService(NewAccountMutationRepository(db), orgAccounts{}, guard),
+	)
+	ctx := testdb.WithOrganization(context.Background(), "org-1")
+	entry := func() *entity.JournalEntryDto {
+		return &entity.JournalEntryDto{
+			Description: "Cash sale",
+			Lines: []*entity.JournalLineDto{
+				{AccountID: "cash", Amount: 1000},
+				{AccountID: "sales", Amount: -1000},
+			},
+		}
+	}
+
+	posted, err := service.Post(ctx, entry())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Every leg is checked against the entry's own date on the
+	// Jakarta calendar.
+	date := posted.CreatedAt.In(jakarta).Format("2006-01-02")
+	if len(guard.asked) != 2 || guard.asked[0] != date || guard.asked[1] != date {
+		t.Errorf("guard asked about %v, want %s for both legs", guard.asked, date)
+	}
+
+	// In a closed period nothing is written, header included.
+	guard.closed = map[string]bool{date: true}
+	if _, err := service.Post(ctx, entry()); err == nil {
+		t.Fatal("Post in a closed period succeeded")
+	}
+	var headers, lines int64
+	db.Model(&model.JournalEntry{}).Count(&headers)
+	db.Model(&model.AccountMutation{}).Count(&lines)
+	if headers != 1 || lines != 2 {
+		t.Errorf("%d entries / %d lines stored, want only the first entry's 1 / 2", headers, lines)
+	}
+}
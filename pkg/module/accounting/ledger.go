@@ -0,0 +1,127 @@
+package accounting
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	shared "github.com/raymondsugiarto/coffee-api/pkg/shared/context"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/response/status"
+	"github.com/xuri/excelize/v2"
+)
+
+func (s *reportService) AccountLedger(
+	ctx context.Context,
+	accountID string,
+	req *entity.AccountLedgerRequest,
+) (*entity.AccountLedgerDto, error) {
+	from, to, err := parseReportWindow(req.From, req.To)
+	if err != nil {
+		return nil, err
+	}
+	account, err := s.accountService.Get(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	orgID := shared.GetOrganization(ctx).ID
+	if account.OrganizationID != "" && account.OrganizationID != orgID {
+		return nil, status.New(status.EntityNotFound, errors.New("account not found"))
+	}
+
+	var opening float64
+	if !from.IsZero() {
+		if opening, err = s.repo.SumAccountBefore(ctx, orgID, accountID, from); err != nil {
+			return nil, err
+		}
+	}
+	mutations, err := s.repo.FindAccountMutations(ctx, orgID, accountID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	ledger := &entity.AccountLedgerDto{
+		Account:        account,
+		From:           req.From,
+		To:             to.AddDate(0, 0, -1).Format("2006-01-02"),
+		OpeningBalance: roundAmount(entity.AccountBalance(account.Type, opening)),
+		Rows:           make([]entity.AccountLedgerRowDto, 0, len(mutations)),
+	}
+	// Run the balance on the signed (debit-positive) sum and only
+	// flip it to the normal side per row, so rounding never drifts.
+	running := opening
+	for _, m := range mutations {
+		running += m.Amount
+		row := entity.AccountLedgerRowDto{
+			ID:          m.ID,
+			Date:        m.CreatedAt,
+			Description: m.Description,
+			Balance:     roundAmount(entity.AccountBalance(account.Type, running)),
+			RefID:       m.RefID,
+			RefTable:    m.RefTable,
+			RefModule:   m.RefModule,
+			RefLink:     entity.AccountMutationRefLink(m.RefTable, m.RefID),
+		}
+		if m.Amount >= 0 {
+			row.Debit = m.Amount
+			ledger.TotalDebit += m.Amount
+		} else {
+			row.Credit = -m.Amount
+			ledger.TotalCredit -= m.Amount
+		}
+		ledger.Rows = append(ledger.Rows, row)
+	}
+	ledger.TotalDebit = roundAmount(ledger.TotalDebit)
+	ledger.TotalCredit = roundAmount(ledger.TotalCredit)
+	ledger.ClosingBalance = roundAmount(entity.AccountBalance(account.Type, running))
+	return ledger, nil
+}
+
+func (s *reportService) AccountLedgerExcel(
+	ctx context.Context,
+	ledger *entity.AccountLedgerDto,
+) ([]byte, error) {
+	excel := excelize.NewFile()
+	sheetName := "Buku Besar"
+	index, _ := excel.NewSheet(sheetName)
+	excel.DeleteSheet("Sheet1")
+
+	period := ledger.To
+	if ledger.From != "" {
+		period = ledger.From + " s/d " + ledger.To
+	}
+	excel.SetCellValue(sheetName, "A1", fmt.Sprintf("%s - %s", ledger.Account.Code, ledger.Account.Name))
+	excel.SetCellValue(sheetName, "A2", "Periode")
+	excel.SetCellValue(sheetName, "B2", period)
+
+	headers := []string{"Tanggal", "Keterangan", "Referensi", "ID Referensi", "Debit", "Kredit", "Saldo"}
+	for i, header := range headers {
+		cell, _ := excelize.CoordinatesToCellName(i+1, 4)
+		excel.SetCellValue(sheetName, cell, header)
+	}
+
+	setRow := func(row int, values ...interface{}) {
+		for i, v := range values {
+			cell, _ := excelize.CoordinatesToCellName(i+1, row)
+			excel.SetCellValue(sheetName, cell, v)
+		}
+	}
+	row := 5
+	setRow(row, "", "Saldo Awal", "", "", "", "", ledger.OpeningBalance)
+	for _, r := range ledger.Rows {
+		row++
+		setRow(row, r.Date.Format("2006-01-02 15:04"), r.Description, r.RefTable, r.RefID, r.Debit, r.Credit, r.Balance)
+	}
+	row++
+	setRow(row, "", "Saldo Akhir", "", "", ledger.TotalDebit, ledger.TotalCredit, ledger.ClosingBalance)
+
+	excel.SetActiveSheet(index)
+
+	var buf bytes.Buffer
+	if err := excel.Write(&buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
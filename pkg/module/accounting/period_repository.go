@@ -0,0 +1,128 @@
+package accounting
+
+import (
+	"context"
+	"errors"
+
+	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	"github.com/raymondsugiarto/coffee-api/pkg/model"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/pagination"
+	"gorm.io/gorm"
+)
+
+// periodRepository stores accounting_period rows and their audit
+// trail.
+type periodRepository struct {
+	db *gorm.DB
+}
+
+func NewPeriodRepository(db *gorm.DB) PeriodRepository {
+	return &periodRepository{db: db}
+}
+
+// GetByPeriod returns the organization's row for "YYYY-MM", or nil
+// when the month was never closed (and is therefore OPEN).
+func (r *periodRepository) GetByPeriod(ctx context.Context, organizationID, period string) (*entity.AccountingPeriodDto, error) {
+	var m model.AccountingPeriod
+	err := r.db.WithContext(ctx).
+		Where("organization_id = ? AND period = ?", organizationID, period).
+		First(&m).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return entity.NewAccountingPeriodDtoFromModel(&m), nil
+}
+
+// FindClosedIn returns the CLOSED periods among `periods`.
+func (r *periodRepository) FindClosedIn(ctx context.Context, organizationID string, periods []string) ([]string, error) {
+	var closed []string
+	err := r.db.WithContext(ctx).
+		Model(&model.AccountingPeriod{}).
+		Where("organization_id = ? AND status = ?", organizationID, entity.AccountingPeriodStatusClosed).
+		Where("period IN ?", periods).
+		Order("period ASC").
+		Pluck("period", &closed).Error
+	return closed, err
+}
+
+// SaveWithLog upserts the period row and appends its audit row in
+// one transaction, so a state change is never recorded without its
+// trail (or the other way round).
+func (r *periodRepository) SaveWithLog(
+	ctx context.Context,
+	dto *entity.AccountingPeriodDto,
+	logDto *entity.AccountingPeriodLogDto,
+) (*entity.AccountingPeriodDto, error) {
+	var result *entity.AccountingPeriodDto
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		m := dto.ToModel()
+		if dto.ID == "" {
+			if err := tx.Create(m).Error; err != nil {
+				return err
+			}
+		} else if err := tx.Model(m).
+			Select("status", "closed_at", "closed_by", "reopened_at", "reopened_by").
+			Updates(m).Error; err != nil {
+			return err
+		}
+		l := logDto.ToModel()
+		l.AccountingPeriodID = m.ID
+		if err := tx.Create(l).Error; err != nil {
+			return err
+		}
+		result = entity.NewAccountingPeriodDtoFromModel(m)
+		return nil
+	})
+	return result, err
+}
+
+func (r *periodRepository) FindLogs(ctx context.Context, organizationID, period string) ([]*entity.AccountingPeriodLogDto, error) {
+	var rows []model.AccountingPeriodLog
+	if err := r.db.WithContext(ctx).
+		Where("organization_id = ? AND period = ?", organizationID, period).
+		Order("created_at ASC").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]*entity.AccountingPeriodLogDto, 0, len(rows))
+	for i := range rows {
+		out = append(out, entity.NewAccountingPeriodLogDtoFromModel(&rows[i]))
+	}
+	return out, nil
+}
+
+func (r *periodRepository) FindAll(
+	ctx context.Context,
+	req *entity.AccountingPeriodFindAllRequest,
+) (*pagination.ResultPagination, error) {
+	var rows []model.AccountingPeriod = make([]model.AccountingPeriod, 0)
+	tbl := pagination.NewTable(r.db.WithContext(ctx))
+	dataTable, err := tbl.Pagination(func(i interface{}) *gorm.DB {
+		return r.db.Model(&model.AccountingPeriod{}).
+			Where("organization_id = ?", req.FindAllRequest.OrganizationData.ID)
+	}, &pagination.TableRequest{
+		Request:       req,
+		QueryField:    []string{},
+		Data:          &rows,
+		AllowedFields: []string{"period", "status"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := dataTable.(*pagination.ResultPagination)
+	hits := result.Data.(*[]model.AccountingPeriod)
+	out := make([]*entity.AccountingPeriodDto, 0, len(*hits))
+	for i := range *hits {
+		out = append(out, entity.NewAccountingPeriodDtoFromModel(&(*hits)[i]))
+	}
+	return &pagination.ResultPagination{
+		Data:        out,
+		Page:        result.Page,
+		Count:       result.Count,
+		RowsPerPage: result.RowsPerPage,
+		TotalPages:  result.TotalPages,
+	}, nil
+}
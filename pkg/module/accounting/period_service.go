@@ -0,0 +1,251 @@
+package accounting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	shared "github.com/raymondsugiarto/coffee-api/pkg/shared/context"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/pagination"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/response/status"
+)
+
+// PeriodRepository is the persistence contract for accounting
+// periods.
+type PeriodRepository interface {
+	GetByPeriod(ctx context.Context, organizationID, period string) (*entity.AccountingPeriodDto, error)
+	FindClosedIn(ctx context.Context, organizationID string, periods []string) ([]string, error)
+	SaveWithLog(ctx context.Context, dto *entity.AccountingPeriodDto, log *entity.AccountingPeriodLogDto) (*entity.AccountingPeriodDto, error)
+	FindLogs(ctx context.Context, organizationID, period string) ([]*entity.AccountingPeriodLogDto, error)
+	FindAll(ctx context.Context, req *entity.AccountingPeriodFindAllRequest) (*pagination.ResultPagination, error)
+}
+
+// PeriodGuard is the narrow interface write paths depend on to
+// refuse changes inside a closed month. Dates are "YYYY-MM-DD" (any
+// string starting with "YYYY-MM" works).
+type PeriodGuard interface {
+	EnsureOpen(ctx context.Context, date string) error
+	EnsureRangeOpen(ctx context.Context, from, to string) error
+}
+
+// AdminResolver looks up the admin behind a user id. admin.Service
+// satisfies it; the period service uses it to keep reopen
+// admin-only.
+type AdminResolver interface {
+	FindByUserID(ctx context.Context, id string) (*entity.AdminDto, error)
+}
+
+// PeriodService manages the OPEN / CLOSED state of accounting
+// months and is the PeriodGuard every dated write path checks.
+type PeriodService interface {
+	PeriodGuard
+	Get(ctx context.Context, period string) (*entity.AccountingPeriodDto, error)
+	Close(ctx context.Context, period string, req *entity.AccountingPeriodActionRequest) (*entity.AccountingPeriodDto, error)
+	// Reopen is restricted to ADMIN users and requires a reason;
+	// both land in the period's audit trail.
+	Reopen(ctx context.Context, period string, req *entity.AccountingPeriodActionRequest) (*entity.AccountingPeriodDto, error)
+	FindLogs(ctx context.Context, period string) ([]*entity.AccountingPeriodLogDto, error)
+	FindAll(ctx context.Context, req *entity.AccountingPeriodFindAllRequest) (*pagination.ResultPagination, error)
+}
+
+type periodService struct {
+	repo          PeriodRepository
+	adminResolver AdminResolver
+}
+
+func NewPeriodService(repo PeriodRepository, adminResolver AdminResolver) PeriodService {
+	return &periodService{repo: repo, adminResolver: adminResolver}
+}
+
+// parsePeriod validates a "YYYY-MM" period key.
+func parsePeriod(period string) (time.Time, error) {
+	t, err := time.Parse("2006-01", period)
+	if err != nil {
+		return t, status.New(status.BadRequest, fmt.Errorf("invalid period %q, expected YYYY-MM", period))
+	}
+	return t, nil
+}
+
+// periodOf returns the "YYYY-MM" month a dated write falls in.
+func periodOf(date string) (string, error) {
+	if len(date) < 7 {
+		return "", status.New(status.BadRequest, fmt.Errorf("invalid date %q", date))
+	}
+	period := date[:7]
+	if _, err := parsePeriod(period); err != nil {
+		return "", err
+	}
+	return period, nil
+}
+
+func closedPeriodError(periods ...string) error {
+	return status.New(status.BadRequest, fmt.Errorf(
+		"accounting period %s is closed", strings.Join(periods, ", "),
+	))
+}
+
+func (s *periodService) EnsureOpen(ctx context.Context, date string) error {
+	period, err := periodOf(date)
+	if err != nil {
+		return err
+	}
+	p, err := s.repo.GetByPeriod(ctx, shared.GetOrganization(ctx).ID, period)
+	if err != nil {
+		return err
+	}
+	if p != nil && p.Status == entity.AccountingPeriodStatusClosed {
+		return closedPeriodError(period)
+	}
+	return nil
+}
+
+func (s *periodService) EnsureRangeOpen(ctx context.Context, from, to string) error {
+	start, err := periodOf(from)
+	if err != nil {
+		return err
+	}
+	end, err := periodOf(to)
+	if err != nil {
+		return err
+	}
+	t, _ := parsePeriod(start)
+	last, _ := parsePeriod(end)
+	periods := make([]string, 0)
+	for ; !t.After(last); t = t.AddDate(0, 1, 0) {
+		periods = append(periods, t.Format("2006-01"))
+	}
+	if len(periods) == 0 {
+		return nil
+	}
+	closed, err := s.repo.FindClosedIn(ctx, shared.GetOrganization(ctx).ID, periods)
+	if err != nil {
+		return err
+	}
+	if len(closed) > 0 {
+		return closedPeriodError(closed...)
+	}
+	return nil
+}
+
+func (s *periodService) Get(ctx context.Context, period string) (*entity.AccountingPeriodDto, error) {
+	if _, err := parsePeriod(period); err != nil {
+		return nil, err
+	}
+	p, err := s.repo.GetByPeriod(ctx, shared.GetOrganization(ctx).ID, period)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return &entity.AccountingPeriodDto{Period: period, Status: entity.AccountingPeriodStatusOpen}, nil
+	}
+	return p, nil
+}
+
+func (s *periodService) Close(
+	ctx context.Context,
+	period string,
+	req *entity.AccountingPeriodActionRequest,
+) (*entity.AccountingPeriodDto, error) {
+	start, err := parsePeriod(period)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if start.After(now) {
+		return nil, status.New(status.BadRequest, errors.New("cannot close a future period"))
+	}
+	orgID := shared.GetOrganization(ctx).ID
+	p, err := s.repo.GetByPeriod(ctx, orgID, period)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		p = &entity.AccountingPeriodDto{OrganizationID: orgID, Period: period}
+	}
+	if p.Status == entity.AccountingPeriodStatusClosed {
+		return nil, status.New(status.BadRequest, fmt.Errorf("accounting period %s is already closed", period))
+	}
+
+	actorID := actorOf(ctx)
+	p.Status = entity.AccountingPeriodStatusClosed
+	p.ClosedAt = &now
+	p.ClosedBy = actorID
+	return s.repo.SaveWithLog(ctx, p, &entity.AccountingPeriodLogDto{
+		OrganizationID: orgID,
+		Period:         period,
+		Action:         entity.AccountingPeriodActionClose,
+		ActorID:        actorID,
+		Reason:         req.Reason,
+	})
+}
+
+func (s *periodService) Reopen(
+	ctx context.Context,
+	period string,
+	req *entity.AccountingPeriodActionRequest,
+) (*entity.AccountingPeriodDto, error) {
+	if _, err := parsePeriod(period); err != nil {
+		return nil, err
+	}
+	cred := shared.GetUserCredential(ctx)
+	if cred == nil {
+		return nil, status.New(status.Forbidden, errors.New("only admins can reopen an accounting period"))
+	}
+	admin, err := s.adminResolver.FindByUserID(ctx, cred.UserID)
+	if err != nil || admin == nil || admin.AdminType != entity.AdminTypeAdmin {
+		return nil, status.New(status.Forbidden, errors.New("only admins can reopen an accounting period"))
+	}
+	if strings.TrimSpace(req.Reason) == "" {
+		return nil, status.New(status.BadRequest, errors.New("reason is required to reopen a period"))
+	}
+
+	orgID := shared.GetOrganization(ctx).ID
+	p, err := s.repo.GetByPeriod(ctx, orgID, period)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil || p.Status != entity.AccountingPeriodStatusClosed {
+		return nil, status.New(status.BadRequest, fmt.Errorf("accounting period %s is not closed", period))
+	}
+
+	now := time.Now()
+	p.Status = entity.AccountingPeriodStatusOpen
+	p.ReopenedAt = &now
+	p.ReopenedBy = admin.ID
+	return s.repo.SaveWithLog(ctx, p, &entity.AccountingPeriodLogDto{
+		OrganizationID: orgID,
+		Period:         period,
+		Action:         entity.AccountingPeriodActionReopen,
+		ActorID:        admin.ID,
+		Reason:         req.Reason,
+	})
+}
+
+func (s *periodService) FindLogs(ctx context.Context, period string) ([]*entity.AccountingPeriodLogDto, error) {
+	if _, err := parsePeriod(period); err != nil {
+		return nil, err
+	}
+	return s.repo.FindLogs(ctx, shared.GetOrganization(ctx).ID, period)
+}
+
+func (s *periodService) FindAll(
+	ctx context.Context,
+	req *entity.AccountingPeriodFindAllRequest,
+) (*pagination.ResultPagination, error) {
+	if req.FindAllRequest.OrganizationData.ID == "" {
+		req.FindAllRequest.OrganizationData.ID = shared.GetOrganization(ctx).ID
+	}
+	return s.repo.FindAll(ctx, req)
+}
+
+// actorOf returns the admin id of the caller, or "" for requests
+// without a credential (background jobs).
+func actorOf(ctx context.Context) string {
+	if cred := shared.GetUserCredential(ctx); cred != nil {
+		return cred.AdminID
+	}
+	return ""
+}
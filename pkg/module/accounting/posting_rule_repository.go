@@ -0,0 +1,134 @@
+package accounting
+
+import (
+	"context"
+
+	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	"github.com/raymondsugiarto/coffee-api/pkg/model"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/pagination"
+	"gorm.io/gorm"
+)
+
+// postingRuleRepository is the CRUD layer over `posting_rule`. Same
+// shape as accountRepository, plus FindByEvent for the posting path.
+type postingRuleRepository struct {
+	db *gorm.DB
+}
+
+func NewPostingRuleRepository(db *gorm.DB) PostingRuleRepository {
+	return &postingRuleRepository{db: db}
+}
+
+func (r *postingRuleRepository) Create(ctx context.Context, dto *entity.PostingRuleDto) (*entity.PostingRuleDto, error) {
+	m := dto.ToModel()
+	if err := r.db.WithContext(ctx).Create(m).Error; err != nil {
+		return nil, err
+	}
+	return entity.NewPostingRuleDtoFromModel(m), nil
+}
+
+func (r *postingRuleRepository) Get(ctx context.Context, id string) (*entity.PostingRuleDto, error) {
+	var m model.PostingRule
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&m).Error; err != nil {
+		return nil, err
+	}
+	return entity.NewPostingRuleDtoFromModel(&m), nil
+}
+
+func (r *postingRuleRepository) Update(ctx context.Context, dto *entity.PostingRuleDto) (*entity.PostingRuleDto, error) {
+	m := dto.ToModel()
+	res := r.db.WithContext(ctx).Model(m).
+		Where("organization_id = ?", dto.OrganizationID).
+		Select("event_type", "amount_field", "debit_account_code", "credit_account_code", "description", "is_active").
+		Updates(m)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return r.Get(ctx, dto.ID)
+}
+
+func (r *postingRuleRepository) Delete(ctx context.Context, organizationID, id string) error {
+	return r.db.WithContext(ctx).
+		Where("id = ? AND organization_id = ?", id, organizationID).
+		Delete(&model.PostingRule{}).Error
+}
+
+func (r *postingRuleRepository) FindAll(
+	ctx context.Context,
+	req *entity.PostingRuleFindAllRequest,
+) (*pagination.ResultPagination, error) {
+	var rows []model.PostingRule = make([]model.PostingRule, 0)
+	tbl := pagination.NewTable(r.db.WithContext(ctx))
+	dataTable, err := tbl.Pagination(func(i interface{}) *gorm.DB {
+		q := r.db.Model(&model.PostingRule{})
+		if req.FindAllRequest.OrganizationData.ID != "" {
+			q = q.Where(
+				"organization_id IS NULL OR organization_id = ?",
+				req.FindAllRequest.OrganizationData.ID,
+			)
+		}
+		return q
+	}, &pagination.TableRequest{
+		Request:       req,
+		QueryField:    []string{},
+		Data:          &rows,
+		AllowedFields: []string{"event_type", "amount_field", "created_at"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := dataTable.(*pagination.ResultPagination)
+	hits := result.Data.(*[]model.PostingRule)
+	out := make([]*entity.PostingRuleDto, 0, len(*hits))
+	for i := range *hits {
+		out = append(out, entity.NewPostingRuleDtoFromModel(&(*hits)[i]))
+	}
+	return &pagination.ResultPagination{
+		Data:        out,
+		Page:        result.Page,
+		Count:       result.Count,
+		RowsPerPage: result.RowsPerPage,
+		TotalPages:  result.TotalPages,
+	}, nil
+}
+
+// FindByEvent returns the effective rules for one event in one
+// organization. Global rows (NULL organization_id) are the defaults;
+// an org-owned row with the same amount_field replaces the global
+// one instead of posting on top of it. Inactive rows are dropped
+// after that, so an inactive override leaves the amount unposted.
+// Rows come back ordered by amount_field so the resulting ledger
+// legs are deterministic.
+func (r *postingRuleRepository) FindByEvent(
+	ctx context.Context,
+	organizationID, eventType string,
+) ([]*entity.PostingRuleDto, error) {
+	var rows []model.PostingRule
+	if err := r.db.WithContext(ctx).
+		Where("event_type = ?", eventType).
+		Where("organization_id IS NULL OR organization_id = ?", organizationID).
+		Order("amount_field ASC, created_at ASC").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	overridden := make(map[string]bool)
+	for _, m := range rows {
+		if m.OrganizationID != "" {
+			overridden[m.AmountField] = true
+		}
+	}
+	out := make([]*entity.PostingRuleDto, 0, len(rows))
+	for i := range rows {
+		if rows[i].OrganizationID == "" && overridden[rows[i].AmountField] {
+			continue
+		}
+		if !rows[i].IsActive {
+			continue
+		}
+		out = append(out, entity.NewPostingRuleDtoFromModel(&rows[i]))
+	}
+	return out, nil
+}
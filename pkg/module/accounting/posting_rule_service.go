@@ -0,0 +1,146 @@
+package accounting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	shared "github.com/raymondsugiarto/coffee-api/pkg/shared/context"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/pagination"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/response/status"
+	"gorm.io/gorm"
+)
+
+// PostingRuleRepository is the persistence contract for posting
+// rules.
+type PostingRuleRepository interface {
+	Create(ctx context.Context, dto *entity.PostingRuleDto) (*entity.PostingRuleDto, error)
+	Get(ctx context.Context, id string) (*entity.PostingRuleDto, error)
+	// Update and Delete only touch the rule when it is owned by
+	// organizationID (dto.OrganizationID for Update); global rows
+	// are never matched.
+	Update(ctx context.Context, dto *entity.PostingRuleDto) (*entity.PostingRuleDto, error)
+	Delete(ctx context.Context, organizationID, id string) error
+	FindAll(ctx context.Context, req *entity.PostingRuleFindAllRequest) (*pagination.ResultPagination, error)
+	FindByEvent(ctx context.Context, organizationID, eventType string) ([]*entity.PostingRuleDto, error)
+}
+
+// PostingRuleService exposes the CRUD to handlers and the
+// per-event lookup to PostingService.
+type PostingRuleService interface {
+	Create(ctx context.Context, dto *entity.PostingRuleDto) (*entity.PostingRuleDto, error)
+	Get(ctx context.Context, id string) (*entity.PostingRuleDto, error)
+	Update(ctx context.Context, dto *entity.PostingRuleDto) (*entity.PostingRuleDto, error)
+	Delete(ctx context.Context, id string) error
+	FindAll(ctx context.Context, req *entity.PostingRuleFindAllRequest) (*pagination.ResultPagination, error)
+	// FindByEvent returns the effective rules for an event in the
+	// given organization (org overrides merged over global rows).
+	FindByEvent(ctx context.Context, organizationID, eventType string) ([]*entity.PostingRuleDto, error)
+}
+
+type postingRuleService struct {
+	repo           PostingRuleRepository
+	accountService AccountService
+}
+
+// NewPostingRuleService wires the rule CRUD. accountService is used
+// to check that both account codes resolve before a rule is saved,
+// so a bad code fails at configuration time instead of at the next
+// session close.
+func NewPostingRuleService(repo PostingRuleRepository, accountService AccountService) PostingRuleService {
+	return &postingRuleService{repo: repo, accountService: accountService}
+}
+
+func (s *postingRuleService) Create(ctx context.Context, dto *entity.PostingRuleDto) (*entity.PostingRuleDto, error) {
+	if dto.OrganizationID == "" {
+		dto.OrganizationID = shared.GetOrganization(ctx).ID
+	}
+	if err := s.validate(ctx, dto); err != nil {
+		return nil, err
+	}
+	return s.repo.Create(ctx, dto)
+}
+
+// Get returns an org-owned rule or a global default; another org's
+// rule is not found.
+func (s *postingRuleService) Get(ctx context.Context, id string) (*entity.PostingRuleDto, error) {
+	rule, err := s.repo.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, status.New(status.EntityNotFound, err)
+		}
+		return nil, err
+	}
+	if rule.OrganizationID != "" && rule.OrganizationID != shared.GetOrganization(ctx).ID {
+		return nil, status.New(status.EntityNotFound, errors.New("posting rule not found"))
+	}
+	return rule, nil
+}
+
+// getOwned loads a rule the caller's org may change. Global defaults
+// are read-only: an org changes one by creating its own override.
+func (s *postingRuleService) getOwned(ctx context.Context, id string) (*entity.PostingRuleDto, error) {
+	rule, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if rule.OrganizationID != shared.GetOrganization(ctx).ID {
+		return nil, status.New(status.EntityNotFound, errors.New("posting rule not found"))
+	}
+	return rule, nil
+}
+
+func (s *postingRuleService) Update(ctx context.Context, dto *entity.PostingRuleDto) (*entity.PostingRuleDto, error) {
+	existing, err := s.getOwned(ctx, dto.ID)
+	if err != nil {
+		return nil, err
+	}
+	dto.OrganizationID = existing.OrganizationID
+	if err := s.validate(ctx, dto); err != nil {
+		return nil, err
+	}
+	return s.repo.Update(ctx, dto)
+}
+
+func (s *postingRuleService) Delete(ctx context.Context, id string) error {
+	existing, err := s.getOwned(ctx, id)
+	if err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, existing.OrganizationID, id)
+}
+
+func (s *postingRuleService) FindAll(
+	ctx context.Context,
+	req *entity.PostingRuleFindAllRequest,
+) (*pagination.ResultPagination, error) {
+	if req.FindAllRequest.OrganizationData.ID == "" {
+		req.FindAllRequest.OrganizationData.ID = shared.GetOrganization(ctx).ID
+	}
+	return s.repo.FindAll(ctx, req)
+}
+
+func (s *postingRuleService) FindByEvent(
+	ctx context.Context,
+	organizationID, eventType string,
+) ([]*entity.PostingRuleDto, error) {
+	return s.repo.FindByEvent(ctx, organizationID, eventType)
+}
+
+func (s *postingRuleService) validate(ctx context.Context, dto *entity.PostingRuleDto) error {
+	if !entity.IsValidPostingAmountField(dto.EventType, dto.AmountField) {
+		return status.New(status.BadRequest, fmt.Errorf(
+			"amountField %s is not emitted by event %s", dto.AmountField, dto.EventType,
+		))
+	}
+	if dto.DebitAccountCode == dto.CreditAccountCode {
+		return status.New(status.BadRequest, errors.New("debit and credit account must differ"))
+	}
+	for _, code := range []string{dto.DebitAccountCode, dto.CreditAccountCode} {
+		if _, err := s.accountService.GetByCode(ctx, code); err != nil {
+			return status.New(status.BadRequest, fmt.Errorf("account code %s not found", code))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package accounting
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	"github.com/raymondsugiarto/coffee-api/pkg/model"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/response/status"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/testdb"
+	"gorm.io/gorm"
+)
+
+// newPostingRuleFixture seeds two global STOCK_SESSION_CLOSED
+// defaults (TOTAL_CASH, TOTAL_QRIS) plus a switched-off one
+// (TOTAL_OTHER). org-1 overrides TOTAL_CASH and switches TOTAL_QRIS
+// off; org-2 adds a COGS rule of its own.
+func newPostingRuleFixture(t *testing.T) (PostingRuleService, map[string]string) {
+	t.Helper()
+	db := testdb.New(t, &model.PostingRule{}, &model.Account{})
+	for _, code := range []string{"1101", "1102", "1103", "1105", "1301", "4101", "5101"} {
+		if err := db.Create(&model.Account{Code: code, Name: code}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	rules := []struct {
+		key, org, field, debit string
+		active                 bool
+	}{
+		{"global-cash", "", entity.PostingAmountTotalCash, "1101", true},
+		{"global-qris", "", entity.PostingAmountTotalQris, "1102", true},
+		{"global-other", "", entity.PostingAmountTotalOther, "1103", false},
+		{"org1-cash", "org-1", entity.PostingAmountTotalCash, "1105", true},
+		{"org1-qris", "org-1", entity.PostingAmountTotalQris, "1102", false},
+		{"org2-cogs", "org-2", entity.PostingAmountTotalCogs, "5101", true},
+	}
+	ids := make(map[string]string, len(rules))
+	for _, r := range rules {
+		m := &model.PostingRule{
+			OrganizationID:    r.org,
+			EventType:         entity.PostingEventStockSessionClosed,
+			AmountField:       r.field,
+			DebitAccountCode:  r.debit,
+			CreditAccountCode: "4101",
+			IsActive:          r.active,
+		}
+		if r.field == entity.PostingAmountTotalCogs {
+			m.CreditAccountCode = "1301"
+		}
+		if err := db.Create(m).Error; err != nil {
+			t.Fatal(err)
+		}
+		ids[r.key] = m.ID
+	}
+	// Seeded defaults have a NULL organization, as in the migrations.
+	for _, m := range []interface{}{&model.Account{}, &model.PostingRule{}} {
+		if err := db.Model(m).Where("organization_id = ''").
+			Update("organization_id", gorm.Expr("NULL")).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	service := NewPostingRuleService(NewPostingRuleRepository(db), NewAccountService(NewAccountRepository(db)))
+	return service, ids
+}
+
+func TestPostingRuleFindByEvent(t *testing.T) {
+	service, _ := newPostingRuleFixture(t)
+	tests := []struct {
+		org  string
+		want []string // amount_field:debit
+	}{
+		// The override replaces the default; the inactive override
+		// switches TOTAL_QRIS off instead of falling back to it.
+		{"org-1", []string{"TOTAL_CASH:1105"}},
+		{"org-2", []string{"TOTAL_CASH:1101", "TOTAL_COGS:5101", "TOTAL_QRIS:1102"}},
+		{"org-3", []string{"TOTAL_CASH:1101", "TOTAL_QRIS:1102"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.org, func(t *testing.T) {
+			rules, err := service.FindByEvent(context.Background(), tt.org, entity.PostingEventStockSessionClosed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := make([]string, 0, len(rules))
+			for _, r := range rules {
+				got = append(got, r.AmountField+":"+r.DebitAccountCode)
+			}
+			sort.Strings(got)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("rules = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPostingRuleOverrideScope(t *testing.T) {
+	service, ids := newPostingRuleFixture(t)
+	ctx := testdb.WithOrganization(context.Background(), "org-1")
+
+	// A global default is visible but read-only; another org's rule
+	// is not visible at all.
+	if _, err := service.Get(ctx, ids["global-cash"]); err != nil {
+		t.Errorf("Get(global) = %v, want the default", err)
+	}
+	if _, err := service.Get(ctx, ids["org2-cogs"]); err != status.EntityNotFound {
+		t.Errorf("Get(org-2 rule) = %v, want not found", err)
+	}
+	for _, key := range []string{"global-cash", "org2-cogs"} {
+		_, err := service.Update(ctx, &entity.PostingRuleDto{
+			ID:                ids[key],
+			EventType:         entity.PostingEventStockSessionClosed,
+			AmountField:       entity.PostingAmountTotalCash,
+			DebitAccountCode:  "1101",
+			CreditAccountCode: "4101",
+		})
+		if err != status.EntityNotFound {
+			t.Errorf("Update(%s) = %v, want not found", key, err)
+		}
+		if err := service.Delete(ctx, ids[key]); err != status.EntityNotFound {
+			t.Errorf("Delete(%s) = %v, want not found", key, err)
+		}
+	}
+
+	// Switching the org's own TOTAL_QRIS rule back on makes it post
+	// again; isActive defaults to true when omitted.
+	if _, err := service.Update(ctx, &entity.PostingRuleDto{
+		ID:                ids["org1-qris"],
+		EventType:         entity.PostingEventStockSessionClosed,
+		AmountField:       entity.PostingAmountTotalQris,
+		DebitAccountCode:  "1102",
+		CreditAccountCode: "4101",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	rules, err := service.FindByEvent(ctx, "org-1", entity.PostingEventStockSessionClosed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules) != 2 {
+		t.Errorf("%d rules after re-enabling TOTAL_QRIS, want 2", len(rules))
+	}
+}
@@ -0,0 +1,114 @@
+package accounting
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	shared "github.com/raymondsugiarto/coffee-api/pkg/shared/context"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/response/status"
+	"gorm.io/gorm"
+)
+
+// PostingService turns a business event into a journal entry by
+// way of the posting rules. Upstream flows never pick accounts
+// themselves: they describe what happened (event type + amounts)
+// and the rules decide where it lands.
+type PostingService interface {
+	// WithTx binds the posting to a transaction owned by the
+	// caller, so the ledger legs commit or roll back together with
+	// the upstream write that produced them.
+	WithTx(tx *gorm.DB) PostingService
+	// PostEvent posts every rule configured for the event as one
+	// balanced journal entry. Events without rules (or whose
+	// amounts are all zero) post nothing and return nil.
+	PostEvent(ctx context.Context, event *entity.PostingEventDto) (*entity.JournalEntryDto, error)
+}
+
+type postingService struct {
+	ruleService         PostingRuleService
+	accountService      AccountService
+	journalEntryService JournalEntryService
+}
+
+func NewPostingService(
+	ruleService PostingRuleService,
+	accountService AccountService,
+	journalEntryService JournalEntryService,
+) PostingService {
+	return &postingService{
+		ruleService:         ruleService,
+		accountService:      accountService,
+		journalEntryService: journalEntryService,
+	}
+}
+
+func (s *postingService) WithTx(tx *gorm.DB) PostingService {
+	return &postingService{
+		ruleService:         s.ruleService,
+		accountService:      s.accountService,
+		journalEntryService: s.journalEntryService.WithTx(tx),
+	}
+}
+
+func (s *postingService) PostEvent(
+	ctx context.Context,
+	event *entity.PostingEventDto,
+) (*entity.JournalEntryDto, error) {
+	if event.OrganizationID == "" {
+		event.OrganizationID = shared.GetOrganization(ctx).ID
+	}
+	rules, err := s.ruleService.FindByEvent(ctx, event.OrganizationID, event.EventType)
+	if err != nil {
+		return nil, err
+	}
+
+	// Net the legs per account before posting: several rules often
+	// share a side (every payment method credits Penjualan), and
+	// one row per account keeps the ledger readable. Each rule is
+	// balanced on its own, so the netted set is balanced too.
+	legs := make(map[string]float64)
+	order := make([]string, 0)
+	add := func(code string, amount float64) {
+		if _, ok := legs[code]; !ok {
+			order = append(order, code)
+		}
+		legs[code] += amount
+	}
+	for _, r := range rules {
+		amount := event.Amounts[r.AmountField]
+		if amount == 0 {
+			continue
+		}
+		add(r.DebitAccountCode, amount)
+		add(r.CreditAccountCode, -amount)
+	}
+
+	lines := make([]*entity.JournalLineDto, 0, len(order))
+	for _, code := range order {
+		amount := roundAmount(legs[code])
+		if amount == 0 {
+			continue
+		}
+		account, err := s.accountService.GetByCode(ctx, code)
+		if err != nil {
+			return nil, status.New(status.BadRequest, fmt.Errorf("ledger account %s is not configured: %w", code, err))
+		}
+		lines = append(lines, &entity.JournalLineDto{
+			AccountID: account.ID,
+			Amount:    amount,
+		})
+	}
+	if len(lines) == 0 {
+		return nil, nil
+	}
+	return s.journalEntryService.Post(ctx, &entity.JournalEntryDto{
+		OrganizationID: event.OrganizationID,
+		Description:    event.Description,
+		Source:         event.EventType,
+		RefID:          event.RefID,
+		RefTable:       event.RefTable,
+		RefModule:      event.RefModule,
+		Lines:          lines,
+	})
+}
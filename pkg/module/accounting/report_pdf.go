@@ -0,0 +1,145 @@
+package accounting
+
+import (
+	"context"
+
+	"github.com/johnfercher/maroto/v2"
+	"github.com/johnfercher/maroto/v2/pkg/components/col"
+	"github.com/johnfercher/maroto/v2/pkg/components/line"
+	"github.com/johnfercher/maroto/v2/pkg/components/text"
+	"github.com/johnfercher/maroto/v2/pkg/config"
+	"github.com/johnfercher/maroto/v2/pkg/consts/align"
+	"github.com/johnfercher/maroto/v2/pkg/consts/fontstyle"
+	"github.com/johnfercher/maroto/v2/pkg/core"
+	"github.com/johnfercher/maroto/v2/pkg/props"
+	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/utils"
+)
+
+var accountTypeLabels = map[string]string{
+	entity.AccountTypeAsset:     "Aset",
+	entity.AccountTypeLiability: "Kewajiban",
+	entity.AccountTypeEquity:    "Ekuitas",
+	entity.AccountTypeRevenue:   "Pendapatan",
+	entity.AccountTypeExpense:   "Beban",
+}
+
+var cashFlowActivityLabels = map[string]string{
+	entity.CashFlowActivityOperating: "Operasional",
+	entity.CashFlowActivityFinancing: "Pendanaan",
+}
+
+// formatStatementAmount formats with Indonesian separators.
+// FormatIndonesianNumber only handles the unsigned form, so the
+// sign is put back here.
+func formatStatementAmount(v float64) string {
+	if v < 0 {
+		return "(" + utils.FormatIndonesianNumber(-v) + ")"
+	}
+	return utils.FormatIndonesianNumber(v)
+}
+
+// statementPDF lays out a two- or three-column statement: label,
+// amount and, when comparing, the previous period amount.
+type statementPDF struct {
+	m       core.Maroto
+	compare bool
+}
+
+func newStatementPDF(title, period, previousPeriod string, compare bool) *statementPDF {
+	p := &statementPDF{
+		m:       maroto.New(config.NewBuilder().WithPageNumber().Build()),
+		compare: compare,
+	}
+	p.m.AddRows(
+		text.NewRow(8, title, props.Text{Size: 14, Style: fontstyle.Bold, Align: align.Center}),
+		text.NewRow(6, "Periode "+period, props.Text{Size: 9, Align: align.Center}),
+	)
+	if compare {
+		p.m.AddRows(text.NewRow(6, "Pembanding "+previousPeriod, props.Text{Size: 9, Align: align.Center}))
+	}
+	p.m.AddRows(line.NewRow(4))
+	current, previous := "Periode Ini", "Periode Lalu"
+	p.row(fontstyle.Bold, "", &current, &previous)
+	return p
+}
+
+func (p *statementPDF) row(style fontstyle.Type, label string, amount, previous *string) {
+	cell := props.Text{Size: 9, Style: style}
+	right := props.Text{Size: 9, Style: style, Align: align.Right}
+	cols := []core.Col{text.NewCol(6, label, cell)}
+	if amount == nil {
+		cols = append(cols, col.New(6))
+		p.m.AddRow(6, cols...)
+		return
+	}
+	if p.compare {
+		cols = append(cols, text.NewCol(3, *amount, right), text.NewCol(3, *previous, right))
+	} else {
+		cols = append(cols, col.New(3), text.NewCol(3, *amount, right))
+	}
+	p.m.AddRow(6, cols...)
+}
+
+func (p *statementPDF) heading(label string) {
+	p.row(fontstyle.Bold, label, nil, nil)
+}
+
+func (p *statementPDF) amount(style fontstyle.Type, label string, amount, previous float64) {
+	a, b := formatStatementAmount(amount), formatStatementAmount(previous)
+	p.row(style, label, &a, &b)
+}
+
+func (p *statementPDF) section(label string, s entity.FinancialStatementSectionDto) {
+	p.heading(label)
+	for _, l := range s.Lines {
+		p.amount(fontstyle.Normal, "    "+l.Code+" "+l.Name, l.Amount, l.PreviousAmount)
+	}
+	p.amount(fontstyle.Bold, "Total "+label, s.Total, s.PreviousTotal)
+	p.m.AddRows(line.NewRow(4))
+}
+
+func (p *statementPDF) bytes() ([]byte, error) {
+	doc, err := p.m.Generate()
+	if err != nil {
+		return nil, err
+	}
+	return doc.GetBytes(), nil
+}
+
+func (s *reportService) ProfitLossPDF(
+	ctx context.Context,
+	report *entity.ProfitLossDto,
+) ([]byte, error) {
+	p := newStatementPDF(
+		"Laporan Laba Rugi",
+		report.From+" s/d "+report.To,
+		report.PreviousFrom+" s/d "+report.PreviousTo,
+		report.Compare,
+	)
+	p.section(accountTypeLabels[entity.AccountTypeRevenue], report.Revenue)
+	p.section(accountTypeLabels[entity.AccountTypeExpense], report.Expense)
+	p.amount(fontstyle.Bold, "Laba (Rugi) Bersih", report.NetIncome, report.PreviousNetIncome)
+	return p.bytes()
+}
+
+func (s *reportService) CashFlowPDF(
+	ctx context.Context,
+	report *entity.CashFlowDto,
+) ([]byte, error) {
+	p := newStatementPDF(
+		"Laporan Arus Kas",
+		report.From+" s/d "+report.To,
+		report.PreviousFrom+" s/d "+report.PreviousTo,
+		report.Compare,
+	)
+	p.amount(fontstyle.Bold, "Saldo Kas Awal", report.OpeningCash, report.PreviousOpeningCash)
+	p.m.AddRows(line.NewRow(4))
+	for _, section := range report.Sections {
+		label := "Aktivitas " + cashFlowActivityLabels[section.Activity] + " - " + accountTypeLabels[section.Type]
+		p.section(label, section)
+	}
+	p.amount(fontstyle.Bold, "Kenaikan (Penurunan) Kas", report.NetChange, report.PreviousNetChange)
+	p.amount(fontstyle.Bold, "Saldo Kas Akhir", report.ClosingCash, report.PreviousClosingCash)
+	return p.bytes()
+}
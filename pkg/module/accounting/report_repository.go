@@ -0,0 +1,175 @@
+package accounting
+
+import (
+	"context"
+	"time"
+
+	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	"github.com/raymondsugiarto/coffee-api/pkg/model"
+	"gorm.io/gorm"
+)
+
+// reportRepository holds the aggregate reads behind the accounting
+// reports. Unlike the CRUD repositories it never pages: reports need
+// every account and a full SUM over the ledger window.
+type reportRepository struct {
+	db *gorm.DB
+}
+
+func NewReportRepository(db *gorm.DB) ReportRepository {
+	return &reportRepository{db: db}
+}
+
+// FindAccounts returns every account visible to the organization,
+// global seeds included, ordered by code.
+func (r *reportRepository) FindAccounts(ctx context.Context, organizationID string) ([]*entity.AccountDto, error) {
+	var rows []model.Account
+	if err := r.db.WithContext(ctx).
+		Where("organization_id IS NULL OR organization_id = ?", organizationID).
+		Order("code ASC").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]*entity.AccountDto, 0, len(rows))
+	for i := range rows {
+		out = append(out, entity.NewAccountDtoFromModel(&rows[i]))
+	}
+	return out, nil
+}
+
+// SumMovements aggregates the organization's mutations per account:
+// the signed sum before `from` as the opening, and the gross debit /
+// credit in [from, to). A zero `from` means no opening window.
+func (r *reportRepository) SumMovements(
+	ctx context.Context,
+	organizationID string,
+	from, to time.Time,
+) ([]*entity.AccountMovementDto, error) {
+	type aggRow struct {
+		AccountID string
+		Opening   float64
+		Debit     float64
+		Credit    float64
+	}
+	var rows []aggRow
+	err := r.db.WithContext(ctx).
+		Table("account_mutation").
+		Select(`account_id,
+		        COALESCE(SUM(CASE WHEN created_at < ? THEN amount ELSE 0 END), 0) AS opening,
+		        COALESCE(SUM(CASE WHEN created_at >= ? AND amount > 0 THEN amount ELSE 0 END), 0) AS debit,
+		        COALESCE(SUM(CASE WHEN created_at >= ? AND amount < 0 THEN -amount ELSE 0 END), 0) AS credit`,
+			from, from, from).
+		Where("deleted_at IS NULL").
+		Where("organization_id = ?", organizationID).
+		Where("created_at < ?", to).
+		Group("account_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*entity.AccountMovementDto, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, &entity.AccountMovementDto{
+			AccountID: row.AccountID,
+			Opening:   row.Opening,
+			Debit:     row.Debit,
+			Credit:    row.Credit,
+		})
+	}
+	return out, nil
+}
+
+// SumAccountBefore returns the signed sum of the account's
+// mutations posted before `before`: the opening balance of a
+// statement starting there.
+func (r *reportRepository) SumAccountBefore(
+	ctx context.Context,
+	organizationID, accountID string,
+	before time.Time,
+) (float64, error) {
+	var sum float64
+	err := r.db.WithContext(ctx).
+		Model(&model.AccountMutation{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("organization_id = ?", organizationID).
+		Where("account_id = ?", accountID).
+		Where("created_at < ?", before).
+		Scan(&sum).Error
+	return sum, err
+}
+
+// FindAccountMutations returns the account's mutations in
+// [from, to) in posting order. A zero `from` means from the start.
+func (r *reportRepository) FindAccountMutations(
+	ctx context.Context,
+	organizationID, accountID string,
+	from, to time.Time,
+) ([]*entity.AccountMutationDto, error) {
+	var rows []model.AccountMutation
+	q := r.db.WithContext(ctx).
+		Where("organization_id = ?", organizationID).
+		Where("account_id = ?", accountID).
+		Where("created_at < ?", to)
+	if !from.IsZero() {
+		q = q.Where("created_at >= ?", from)
+	}
+	if err := q.Order("created_at ASC, id ASC").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]*entity.AccountMutationDto, 0, len(rows))
+	for i := range rows {
+		out = append(out, entity.NewAccountMutationDtoFromModel(&rows[i]))
+	}
+	return out, nil
+}
+
+// FindLegs returns every mutation of the organization in
+// [from, to), keyed by the posting it belongs to.
+func (r *reportRepository) FindLegs(
+	ctx context.Context,
+	organizationID string,
+	from, to time.Time,
+) ([]*entity.LedgerLegDto, error) {
+	var rows []struct {
+		GroupKey  string
+		AccountID string
+		Amount    float64
+	}
+	err := r.db.WithContext(ctx).
+		Table("account_mutation").
+		Select(`COALESCE(NULLIF(journal_entry_id, ''), ref_table || ':' || ref_id) AS group_key,
+		        account_id,
+		        amount`).
+		Where("deleted_at IS NULL").
+		Where("organization_id = ?", organizationID).
+		Where("created_at >= ? AND created_at < ?", from, to).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*entity.LedgerLegDto, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, &entity.LedgerLegDto{
+			GroupKey:  row.GroupKey,
+			AccountID: row.AccountID,
+			Amount:    row.Amount,
+		})
+	}
+	return out, nil
+}
+
+// SumCashBefore returns the combined balance of the organization's
+// cash accounts (is_cash) before `before`.
+func (r *reportRepository) SumCashBefore(ctx context.Context, organizationID string, before time.Time) (float64, error) {
+	var sum float64
+	err := r.db.WithContext(ctx).
+		Table("account_mutation m").
+		Select("COALESCE(SUM(m.amount), 0)").
+		Joins("JOIN account a ON a.id = m.account_id").
+		Where("m.deleted_at IS NULL").
+		Where("m.organization_id = ?", organizationID).
+		Where("a.is_cash = ?", true).
+		Where("m.created_at < ?", before).
+		Scan(&sum).Error
+	return sum, err
+}
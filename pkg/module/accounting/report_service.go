@@ -0,0 +1,182 @@
+package accounting
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	shared "github.com/raymondsugiarto/coffee-api/pkg/shared/context"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/response/status"
+)
+
+// ReportRepository is the aggregate read side of the ledger.
+type ReportRepository interface {
+	FindAccounts(ctx context.Context, organizationID string) ([]*entity.AccountDto, error)
+	SumMovements(ctx context.Context, organizationID string, from, to time.Time) ([]*entity.AccountMovementDto, error)
+	SumAccountBefore(ctx context.Context, organizationID, accountID string, before time.Time) (float64, error)
+	FindAccountMutations(ctx context.Context, organizationID, accountID string, from, to time.Time) ([]*entity.AccountMutationDto, error)
+	FindLegs(ctx context.Context, organizationID string, from, to time.Time) ([]*entity.LedgerLegDto, error)
+	SumCashBefore(ctx context.Context, organizationID string, before time.Time) (float64, error)
+}
+
+// ReportService builds the accounting reports on top of
+// account_mutation. Account types decide how the signed amounts are
+// read; see entity.AccountBalance.
+type ReportService interface {
+	TrialBalance(ctx context.Context, req *entity.TrialBalanceRequest) (*entity.TrialBalanceDto, error)
+	// AccountLedger is the statement of one account: every mutation
+	// in the window with a running balance.
+	AccountLedger(ctx context.Context, accountID string, req *entity.AccountLedgerRequest) (*entity.AccountLedgerDto, error)
+	// AccountLedgerExcel renders a statement as an XLSX workbook.
+	AccountLedgerExcel(ctx context.Context, ledger *entity.AccountLedgerDto) ([]byte, error)
+	ProfitLoss(ctx context.Context, req *entity.FinancialStatementRequest) (*entity.ProfitLossDto, error)
+	ProfitLossPDF(ctx context.Context, report *entity.ProfitLossDto) ([]byte, error)
+	CashFlow(ctx context.Context, req *entity.FinancialStatementRequest) (*entity.CashFlowDto, error)
+	CashFlowPDF(ctx context.Context, report *entity.CashFlowDto) ([]byte, error)
+}
+
+type reportService struct {
+	repo           ReportRepository
+	accountService AccountService
+}
+
+func NewReportService(repo ReportRepository, accountService AccountService) ReportService {
+	return &reportService{repo: repo, accountService: accountService}
+}
+
+// parseReportWindow turns inclusive YYYY-MM-DD bounds into a
+// half-open [from, to) time window in server local time. An empty
+// `to` means today.
+func parseReportWindow(from, to string) (time.Time, time.Time, error) {
+	var start, end time.Time
+	if from != "" {
+		t, err := time.ParseInLocation("2006-01-02", from, time.Local)
+		if err != nil {
+			return start, end, status.New(status.BadRequest, errors.New("from must be YYYY-MM-DD"))
+		}
+		start = t
+	}
+	if to == "" {
+		to = time.Now().Format("2006-01-02")
+	}
+	t, err := time.ParseInLocation("2006-01-02", to, time.Local)
+	if err != nil {
+		return start, end, status.New(status.BadRequest, errors.New("to must be YYYY-MM-DD"))
+	}
+	end = t.AddDate(0, 0, 1)
+	if !start.IsZero() && !start.Before(end) {
+		return start, end, status.New(status.BadRequest, errors.New("from must not be after to"))
+	}
+	return start, end, nil
+}
+
+func roundAmount(v float64) float64 {
+	return math.Round(v*10000) / 10000
+}
+
+func (s *reportService) TrialBalance(
+	ctx context.Context,
+	req *entity.TrialBalanceRequest,
+) (*entity.TrialBalanceDto, error) {
+	from, to, err := parseReportWindow(req.From, req.To)
+	if err != nil {
+		return nil, err
+	}
+	orgID := shared.GetOrganization(ctx).ID
+	accounts, err := s.repo.FindAccounts(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	movements, err := s.repo.SumMovements(ctx, orgID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	byAccount := make(map[string]*entity.AccountMovementDto, len(movements))
+	for _, m := range movements {
+		byAccount[m.AccountID] = m
+	}
+
+	report := &entity.TrialBalanceDto{
+		From: req.From,
+		To:   to.AddDate(0, 0, -1).Format("2006-01-02"),
+		Rows: make([]entity.TrialBalanceRowDto, 0, len(accounts)),
+	}
+
+	// Accounts arrive sorted by code, so walking children in slice
+	// order keeps every level in code order. An account whose parent
+	// isn't visible to this organization is treated as a root.
+	visible := make(map[string]bool, len(accounts))
+	for _, a := range accounts {
+		visible[a.ID] = true
+	}
+	children := make(map[string][]*entity.AccountDto)
+	roots := make([]*entity.AccountDto, 0)
+	for _, a := range accounts {
+		if a.ParentID != "" && visible[a.ParentID] {
+			children[a.ParentID] = append(children[a.ParentID], a)
+			continue
+		}
+		roots = append(roots, a)
+	}
+
+	// walk appends the account's row, then its subtree, and returns
+	// the subtree's signed (debit-positive) opening, debit and
+	// credit so the parent row can include them.
+	var walk func(a *entity.AccountDto, level int) (float64, float64, float64)
+	walk = func(a *entity.AccountDto, level int) (float64, float64, float64) {
+		var own entity.AccountMovementDto
+		if m, ok := byAccount[a.ID]; ok {
+			own = *m
+		}
+		ownClosing := own.Opening + own.Debit - own.Credit
+		if ownClosing > 0 {
+			report.ClosingDebit += ownClosing
+		} else {
+			report.ClosingCredit -= ownClosing
+		}
+		report.TotalDebit += own.Debit
+		report.TotalCredit += own.Credit
+
+		idx := len(report.Rows)
+		report.Rows = append(report.Rows, entity.TrialBalanceRowDto{
+			AccountID:     a.ID,
+			Code:          a.Code,
+			Name:          a.Name,
+			Type:          a.Type,
+			NormalBalance: entity.AccountNormalBalance(a.Type),
+			ParentID:      a.ParentID,
+			Level:         level,
+			HasChildren:   len(children[a.ID]) > 0,
+			OwnOpening:    roundAmount(entity.AccountBalance(a.Type, own.Opening)),
+			OwnDebit:      roundAmount(own.Debit),
+			OwnCredit:     roundAmount(own.Credit),
+			OwnClosing:    roundAmount(entity.AccountBalance(a.Type, ownClosing)),
+		})
+
+		opening, debit, credit := own.Opening, own.Debit, own.Credit
+		for _, c := range children[a.ID] {
+			o, d, cr := walk(c, level+1)
+			opening += o
+			debit += d
+			credit += cr
+		}
+		row := &report.Rows[idx]
+		row.OpeningBalance = roundAmount(entity.AccountBalance(a.Type, opening))
+		row.Debit = roundAmount(debit)
+		row.Credit = roundAmount(credit)
+		row.ClosingBalance = roundAmount(entity.AccountBalance(a.Type, opening+debit-credit))
+		return opening, debit, credit
+	}
+	for _, r := range roots {
+		walk(r, 0)
+	}
+
+	report.TotalDebit = roundAmount(report.TotalDebit)
+	report.TotalCredit = roundAmount(report.TotalCredit)
+	report.ClosingDebit = roundAmount(report.ClosingDebit)
+	report.ClosingCredit = roundAmount(report.ClosingCredit)
+	report.Balanced = report.TotalDebit == report.TotalCredit && report.ClosingDebit == report.ClosingCredit
+	return report, nil
+}
@@ -16,6 +16,9 @@ type Repository interface {
 	FindByUserID(ctx context.Context, id string) (*entity.AdminDto, error)
 	CreateAdminCompany(ctx context.Context, dto *entity.CreateAdminCompany, cb func(tx *gorm.DB) error) (*entity.CreateAdminCompany, error)
 	FindAllByCompanyID(ctx context.Context, companyID string, req *entity.FindAllRequest) (*pagination.ResultPagination, error)
+	// FindEmailsByOrganization lists the e-mail addresses of the
+	// organization's admins of the given type.
+	FindEmailsByOrganization(ctx context.Context, organizationID, adminType string) ([]string, error)
 }
 
 type repository struct {
@@ -145,3 +148,16 @@ func (r *repository) FindAllByCompanyID(ctx context.Context, companyID string, r
 		TotalPages:  result.TotalPages,
 	}, nil
 }
+
+func (r *repository) FindEmailsByOrganization(ctx context.Context, organizationID, adminType string) ([]string, error) {
+	emails := make([]string, 0)
+	err := r.db.WithContext(ctx).Model(&model.Admin{}).
+		Where("organization_id = ? AND admin_type = ? AND email <> ''", organizationID, adminType).
+		Order("email ASC").
+		Distinct().
+		Pluck("email", &emails).Error
+	if err != nil {
+		return nil, err
+	}
+	return emails, nil
+}
@@ -21,6 +21,9 @@ type Service interface {
 	UpdateName(ctx context.Context, id string, admin *entity.AdminDto) (*entity.AdminDto, error)
 	CreateAdminCompany(ctx context.Context, admin *entity.CreateAdminCompany) (*entity.CreateAdminCompany, error)
 	FindAllByCompanyID(ctx context.Context, companyID string, req *entity.FindAllRequest) (*pagination.ResultPagination, error)
+	// FindAlertRecipients returns where an organization's alerts go:
+	// the e-mail addresses of its ADMIN users.
+	FindAlertRecipients(ctx context.Context, organizationID string) ([]string, error)
 }
 
 type service struct {
@@ -135,3 +138,7 @@ func (s *service) CreateAdminCompany(ctx context.Context, admin *entity.CreateAd
 func (s *service) FindAllByCompanyID(ctx context.Context, companyID string, req *entity.FindAllRequest) (*pagination.ResultPagination, error) {
 	return s.repo.FindAllByCompanyID(ctx, companyID, req)
 }
+
+func (s *service) FindAlertRecipients(ctx context.Context, organizationID string) ([]string, error) {
+	return s.repo.FindEmailsByOrganization(ctx, organizationID, entity.AdminTypeAdmin)
+}
@@ -0,0 +1,184 @@
+package attendance
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	"github.com/raymondsugiarto/coffee-api/pkg/model"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/pagination"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrAttendanceAlreadyClockedIn is returned by ClockIn when the
+// driver already has a record for that day.
+var ErrAttendanceAlreadyClockedIn = errors.New("employee already clocked in on this date")
+
+// ErrAttendanceAlreadyClockedOut is returned by ClockOut when the
+// record is already closed.
+var ErrAttendanceAlreadyClockedOut = errors.New("attendance is already clocked out")
+
+// ErrAttendanceClockOutBeforeIn is returned by ClockOut when the
+// clock-out time is not after the clock-in.
+var ErrAttendanceClockOutBeforeIn = errors.New("clock-out must be after clock-in")
+
+type Repository interface {
+	// ClockIn creates the day's record, serialised per driver so
+	// two concurrent clock-ins cannot both land.
+	ClockIn(ctx context.Context, dto *entity.AttendanceDto) (*entity.AttendanceDto, error)
+	// ClockOut closes an open record with the row locked.
+	ClockOut(ctx context.Context, id string, req *entity.AttendanceClockOutRequest, at time.Time) (*entity.AttendanceDto, error)
+	Get(ctx context.Context, id string) (*entity.AttendanceDto, error)
+	FindAll(ctx context.Context, req *entity.AttendanceFindAllRequest) (*pagination.ResultPagination, error)
+	// FindRange lists a driver's records dated between from and to
+	// (inclusive), oldest first.
+	FindRange(ctx context.Context, organizationID, adminIDEmployee string, from, to time.Time) ([]*entity.AttendanceDto, error)
+	// CountDaysWorked counts the distinct days between from and to
+	// (inclusive) on which the driver clocked in.
+	CountDaysWorked(ctx context.Context, adminIDEmployee string, from, to time.Time) (int, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) ClockIn(ctx context.Context, dto *entity.AttendanceDto) (*entity.AttendanceDto, error) {
+	m := dto.ToModel()
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "attendance:"+m.AdminIDEmployee).Error; err != nil {
+			return err
+		}
+		var existing int64
+		if err := tx.Model(&model.Attendance{}).
+			Where("admin_id_employee = ? AND date = ?", m.AdminIDEmployee, m.Date).
+			Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return ErrAttendanceAlreadyClockedIn
+		}
+		return tx.Create(m).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entity.NewAttendanceDtoFromModel(m), nil
+}
+
+func (r *repository) ClockOut(
+	ctx context.Context,
+	id string,
+	req *entity.AttendanceClockOutRequest,
+	at time.Time,
+) (*entity.AttendanceDto, error) {
+	var m model.Attendance
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", id).
+			First(&m).Error; err != nil {
+			return err
+		}
+		if m.ClockOutAt != nil {
+			return ErrAttendanceAlreadyClockedOut
+		}
+		if !at.After(m.ClockInAt) {
+			return ErrAttendanceClockOutBeforeIn
+		}
+		m.ClockOutAt = &at
+		m.ClockOutLatitude = req.Latitude
+		m.ClockOutLongitude = req.Longitude
+		cols := []string{"clock_out_at", "clock_out_latitude", "clock_out_longitude", "updated_at"}
+		if req.Notes != "" {
+			m.Notes = req.Notes
+			cols = append(cols, "notes")
+		}
+		return tx.Model(&m).Select(cols).Updates(&m).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entity.NewAttendanceDtoFromModel(&m), nil
+}
+
+func (r *repository) Get(ctx context.Context, id string) (*entity.AttendanceDto, error) {
+	var m model.Attendance
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&m).Error; err != nil {
+		return nil, err
+	}
+	return entity.NewAttendanceDtoFromModel(&m), nil
+}
+
+func (r *repository) FindAll(
+	ctx context.Context,
+	req *entity.AttendanceFindAllRequest,
+) (*pagination.ResultPagination, error) {
+	var rows []model.Attendance = make([]model.Attendance, 0)
+	tbl := pagination.NewTable(r.db)
+	dataTable, err := tbl.Pagination(func(i interface{}) *gorm.DB {
+		return r.db.WithContext(ctx).Model(&model.Attendance{}).
+			Where("organization_id = ?", req.FindAllRequest.OrganizationData.ID)
+	}, &pagination.TableRequest{
+		Request:       req,
+		QueryField:    []string{},
+		Data:          &rows,
+		AllowedFields: []string{"admin_id_employee", "date", "clock_in_at", "clock_out_at"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := dataTable.(*pagination.ResultPagination)
+	hits := result.Data.(*[]model.Attendance)
+	out := make([]*entity.AttendanceDto, 0, len(*hits))
+	for i := range *hits {
+		out = append(out, entity.NewAttendanceDtoFromModel(&(*hits)[i]))
+	}
+	return &pagination.ResultPagination{
+		Data:        out,
+		Page:        result.Page,
+		Count:       result.Count,
+		RowsPerPage: result.RowsPerPage,
+		TotalPages:  result.TotalPages,
+	}, nil
+}
+
+func (r *repository) FindRange(
+	ctx context.Context,
+	organizationID, adminIDEmployee string,
+	from, to time.Time,
+) ([]*entity.AttendanceDto, error) {
+	var rows []model.Attendance
+	if err := r.db.WithContext(ctx).
+		Where("organization_id = ? AND admin_id_employee = ?", organizationID, adminIDEmployee).
+		Where("date >= ? AND date <= ?", from, to).
+		Order("date ASC").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]*entity.AttendanceDto, 0, len(rows))
+	for i := range rows {
+		out = append(out, entity.NewAttendanceDtoFromModel(&rows[i]))
+	}
+	return out, nil
+}
+
+func (r *repository) CountDaysWorked(
+	ctx context.Context,
+	adminIDEmployee string,
+	from, to time.Time,
+) (int, error) {
+	var days int64
+	if err := r.db.WithContext(ctx).
+		Model(&model.Attendance{}).
+		Where("admin_id_employee = ? AND date >= ? AND date <= ?", adminIDEmployee, from, to).
+		Distinct("date").
+		Count(&days).Error; err != nil {
+		return 0, err
+	}
+	return int(days), nil
+}
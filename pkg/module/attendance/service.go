@@ -0,0 +1,170 @@
+package attendance
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	shared "github.com/raymondsugiarto/coffee-api/pkg/shared/context"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/pagination"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/response/status"
+	"gorm.io/gorm"
+)
+
+type Service interface {
+	// ClockIn opens the driver's record for the day of req.At
+	// (default now). A second clock-in on the same day is refused.
+	ClockIn(ctx context.Context, req *entity.AttendanceClockInRequest) (*entity.AttendanceDto, error)
+	// ClockOut closes an open record.
+	ClockOut(ctx context.Context, id string, req *entity.AttendanceClockOutRequest) (*entity.AttendanceDto, error)
+	Get(ctx context.Context, id string) (*entity.AttendanceDto, error)
+	FindAll(ctx context.Context, req *entity.AttendanceFindAllRequest) (*pagination.ResultPagination, error)
+	// Summary rolls a driver's records up over a period.
+	Summary(ctx context.Context, adminIDEmployee string, req *entity.AttendanceSummaryRequest) (*entity.AttendanceSummaryDto, error)
+	// DaysWorked counts the days between from and to (inclusive) on
+	// which the driver clocked in; payroll evaluates the ATTENDANCE
+	// bands against it.
+	DaysWorked(ctx context.Context, adminIDEmployee string, from, to time.Time) (int, error)
+}
+
+type service struct {
+	repo Repository
+}
+
+func NewService(repo Repository) Service {
+	return &service{repo: repo}
+}
+
+func (s *service) ClockIn(
+	ctx context.Context,
+	req *entity.AttendanceClockInRequest,
+) (*entity.AttendanceDto, error) {
+	at := time.Now()
+	if req.At != nil {
+		at = req.At.In(time.Local)
+	}
+	result, err := s.repo.ClockIn(ctx, &entity.AttendanceDto{
+		OrganizationID:   shared.GetOrganization(ctx).ID,
+		AdminIDEmployee:  req.AdminIDEmployee,
+		Date:             at.Format("2006-01-02"),
+		ClockInAt:        at,
+		ClockInLatitude:  req.Latitude,
+		ClockInLongitude: req.Longitude,
+		Notes:            req.Notes,
+		CreatedBy:        actorOf(ctx),
+	})
+	if err != nil {
+		if errors.Is(err, ErrAttendanceAlreadyClockedIn) {
+			return nil, status.New(status.EntityConflict, err)
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *service) ClockOut(
+	ctx context.Context,
+	id string,
+	req *entity.AttendanceClockOutRequest,
+) (*entity.AttendanceDto, error) {
+	if _, err := s.Get(ctx, id); err != nil {
+		return nil, err
+	}
+	at := time.Now()
+	if req.At != nil {
+		at = req.At.In(time.Local)
+	}
+	result, err := s.repo.ClockOut(ctx, id, req, at)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrAttendanceAlreadyClockedOut):
+			return nil, status.New(status.EntityConflict, err)
+		case errors.Is(err, ErrAttendanceClockOutBeforeIn):
+			return nil, status.New(status.BadRequest, err)
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *service) Get(ctx context.Context, id string) (*entity.AttendanceDto, error) {
+	result, err := s.repo.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, status.New(status.EntityNotFound, err)
+		}
+		return nil, err
+	}
+	if result.OrganizationID != shared.GetOrganization(ctx).ID {
+		return nil, status.New(status.EntityNotFound, errors.New("attendance not found"))
+	}
+	return result, nil
+}
+
+func (s *service) FindAll(
+	ctx context.Context,
+	req *entity.AttendanceFindAllRequest,
+) (*pagination.ResultPagination, error) {
+	if req.FindAllRequest.OrganizationData.ID == "" {
+		req.FindAllRequest.OrganizationData.ID = shared.GetOrganization(ctx).ID
+	}
+	return s.repo.FindAll(ctx, req)
+}
+
+func (s *service) Summary(
+	ctx context.Context,
+	adminIDEmployee string,
+	req *entity.AttendanceSummaryRequest,
+) (*entity.AttendanceSummaryDto, error) {
+	from, err := time.Parse("2006-01-02", req.From)
+	if err != nil {
+		return nil, status.New(status.BadRequest, err)
+	}
+	to, err := time.Parse("2006-01-02", req.To)
+	if err != nil {
+		return nil, status.New(status.BadRequest, err)
+	}
+	if to.Before(from) {
+		return nil, status.New(status.BadRequest, errors.New("to is before from"))
+	}
+	records, err := s.repo.FindRange(ctx, shared.GetOrganization(ctx).ID, adminIDEmployee, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &entity.AttendanceSummaryDto{
+		AdminIDEmployee: adminIDEmployee,
+		From:            req.From,
+		To:              req.To,
+		Records:         make([]entity.AttendanceDto, 0, len(records)),
+	}
+	// One record per day is enforced at clock-in, so every record
+	// is a day worked.
+	for _, r := range records {
+		summary.DaysWorked++
+		if r.ClockOutAt == nil {
+			summary.OpenDays++
+		}
+		summary.TotalHours += r.Hours
+		summary.Records = append(summary.Records, *r)
+	}
+	summary.TotalHours = math.Round(summary.TotalHours*100) / 100
+	return summary, nil
+}
+
+func (s *service) DaysWorked(
+	ctx context.Context,
+	adminIDEmployee string,
+	from, to time.Time,
+) (int, error) {
+	return s.repo.CountDaysWorked(ctx, adminIDEmployee, from, to)
+}
+
+func actorOf(ctx context.Context) string {
+	if cred := shared.GetUserCredential(ctx); cred != nil {
+		return cred.AdminID
+	}
+	return ""
+}
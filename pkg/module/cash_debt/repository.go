@@ -2,21 +2,65 @@ package cashdebt
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/raymondsugiarto/coffee-api/pkg/entity"
 	"github.com/raymondsugiarto/coffee-api/pkg/model"
 	"github.com/raymondsugiarto/coffee-api/pkg/shared/pagination"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type Repository interface {
-	Create(ctx context.Context, dto *entity.CashDebtDto) (*entity.CashDebtDto, error)
+	// WithTx returns a repository bound to the caller's transaction.
+	WithTx(tx *gorm.DB) Repository
+	// Create, Update and Delete run afterSave inside their
+	// transaction; an error rolls the write back. Create and Update
+	// store the saved.JournalEntryID afterSave leaves behind.
+	Create(
+		ctx context.Context,
+		dto *entity.CashDebtDto,
+		afterSave func(tx *gorm.DB, saved *entity.CashDebtDto) error,
+	) (*entity.CashDebtDto, error)
 	Get(ctx context.Context, id string) (*entity.CashDebtDto, error)
-	Update(ctx context.Context, dto *entity.CashDebtDto) (*entity.CashDebtDto, error)
-	Delete(ctx context.Context, id string) error
+	Update(
+		ctx context.Context,
+		dto *entity.CashDebtDto,
+		afterSave func(tx *gorm.DB, saved *entity.CashDebtDto) error,
+	) (*entity.CashDebtDto, error)
+	Delete(ctx context.Context, id string, afterSave func(tx *gorm.DB) error) error
 	FindAll(ctx context.Context, req *entity.CashDebtFindAllRequest) (*pagination.ResultPagination, error)
+	// Settle records a repayment and bumps the advance's
+	// settled_amount in one transaction, with the advance row
+	// locked so concurrent repayments cannot overpay it. afterSave
+	// runs inside the same transaction; an error rolls both back.
+	// afterSave may set saved.JournalEntryID; it is stored on the
+	// settlement row.
+	Settle(
+		ctx context.Context,
+		dto *entity.CashDebtSettlementDto,
+		afterSave func(tx *gorm.DB, saved *entity.CashDebtSettlementDto) error,
+	) (*entity.CashDebtSettlementDto, error)
+	// ReverseSettlements marks every live settlement made by the
+	// given source record as reversed and takes their amounts back
+	// off the advances. afterSave receives the reversed rows inside
+	// the same transaction.
+	ReverseSettlements(
+		ctx context.Context,
+		refTable, refID, actor string,
+		afterSave func(tx *gorm.DB, reversed []*entity.CashDebtSettlementDto) error,
+	) ([]*entity.CashDebtSettlementDto, error)
+	// FindOutstanding lists an employee's advances that are not
+	// fully repaid, oldest first. A non-empty `until` (YYYY-MM-DD)
+	// keeps advances dated on or before it.
+	FindOutstanding(ctx context.Context, organizationID, adminIDEmployee, until string) ([]*entity.CashDebtDto, error)
 }
 
+// ErrCashDebtOverSettled is returned by Settle when the repayment
+// exceeds what is still owed.
+var ErrCashDebtOverSettled = errors.New("settlement exceeds the outstanding cash debt")
+
 type repository struct {
 	db *gorm.DB
 }
@@ -25,31 +69,101 @@ func NewRepository(db *gorm.DB) Repository {
 	return &repository{db: db}
 }
 
-func (r *repository) Create(ctx context.Context, dto *entity.CashDebtDto) (*entity.CashDebtDto, error) {
-	m := dto.ToModel()
-	if err := r.db.WithContext(ctx).Create(m).Error; err != nil {
+func (r *repository) WithTx(tx *gorm.DB) Repository {
+	return &repository{db: tx}
+}
+
+func (r *repository) Create(
+	ctx context.Context,
+	dto *entity.CashDebtDto,
+	afterSave func(tx *gorm.DB, saved *entity.CashDebtDto) error,
+) (*entity.CashDebtDto, error) {
+	var result *entity.CashDebtDto
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		m := dto.ToModel()
+		if err := tx.Create(m).Error; err != nil {
+			return err
+		}
+		result = entity.NewCashDebtDtoFromModel(m)
+		if afterSave != nil {
+			if err := afterSave(tx, result); err != nil {
+				return err
+			}
+			if result.JournalEntryID != "" {
+				return tx.Model(m).Update("journal_entry_id", result.JournalEntryID).Error
+			}
+		}
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
-	return entity.NewCashDebtDtoFromModel(m), nil
+	return result, nil
 }
 
 func (r *repository) Get(ctx context.Context, id string) (*entity.CashDebtDto, error) {
 	var m model.CashDebt
-	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&m).Error; err != nil {
+	if err := r.db.WithContext(ctx).
+		Preload("Settlements", func(db *gorm.DB) *gorm.DB {
+			return db.Order("date ASC, created_at ASC")
+		}).
+		Where("id = ?", id).
+		First(&m).Error; err != nil {
 		return nil, err
 	}
 	return entity.NewCashDebtDtoFromModel(&m), nil
 }
 
-func (r *repository) Update(ctx context.Context, dto *entity.CashDebtDto) (*entity.CashDebtDto, error) {
-	if err := r.db.WithContext(ctx).Save(dto.ToModel()).Error; err != nil {
+func (r *repository) Update(
+	ctx context.Context,
+	dto *entity.CashDebtDto,
+	afterSave func(tx *gorm.DB, saved *entity.CashDebtDto) error,
+) (*entity.CashDebtDto, error) {
+	// settled_amount belongs to the settlement flow and is left
+	// alone here; settled_at follows the new amount so raising an
+	// already repaid advance reopens it.
+	m := dto.ToModel()
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(m).
+			Select("admin_id_employee", "date", "amount", "payment_method", "notes", "updated_at").
+			Updates(m).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.CashDebt{}).Where("id = ?", m.ID).
+			Update("settled_at", gorm.Expr(
+				"CASE WHEN amount - settled_amount < 0.005 THEN COALESCE(settled_at, ?) ELSE NULL END", time.Now(),
+			)).Error; err != nil {
+			return err
+		}
+		if afterSave == nil {
+			return nil
+		}
+		saved, err := r.WithTx(tx).Get(ctx, m.ID)
+		if err != nil {
+			return err
+		}
+		if err := afterSave(tx, saved); err != nil {
+			return err
+		}
+		return tx.Model(&model.CashDebt{}).Where("id = ?", m.ID).
+			Update("journal_entry_id", saved.JournalEntryID).Error
+	})
+	if err != nil {
 		return nil, err
 	}
-	return dto, nil
+	return r.Get(ctx, dto.ID)
 }
 
-func (r *repository) Delete(ctx context.Context, id string) error {
-	return r.db.WithContext(ctx).Where("id = ?", id).Delete(&model.CashDebt{}).Error
+func (r *repository) Delete(ctx context.Context, id string, afterSave func(tx *gorm.DB) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ?", id).Delete(&model.CashDebt{}).Error; err != nil {
+			return err
+		}
+		if afterSave != nil {
+			return afterSave(tx)
+		}
+		return nil
+	})
 }
 
 func (r *repository) FindAll(
@@ -84,3 +198,114 @@ func (r *repository) FindAll(
 		TotalPages:  result.TotalPages,
 	}, nil
 }
+
+func (r *repository) Settle(
+	ctx context.Context,
+	dto *entity.CashDebtSettlementDto,
+	afterSave func(tx *gorm.DB, saved *entity.CashDebtSettlementDto) error,
+) (*entity.CashDebtSettlementDto, error) {
+	var result *entity.CashDebtSettlementDto
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var debt model.CashDebt
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", dto.CashDebtID).
+			First(&debt).Error; err != nil {
+			return err
+		}
+		outstanding := entity.CashDebtOutstanding(debt.Amount, debt.SettledAmount)
+		if dto.Amount > outstanding {
+			return ErrCashDebtOverSettled
+		}
+
+		m := dto.ToModel()
+		if err := tx.Create(m).Error; err != nil {
+			return err
+		}
+		updates := map[string]interface{}{
+			"settled_amount": gorm.Expr("settled_amount + ?", dto.Amount),
+			"updated_at":     time.Now(),
+		}
+		if entity.CashDebtOutstanding(debt.Amount, debt.SettledAmount+dto.Amount) == 0 {
+			updates["settled_at"] = time.Now()
+		}
+		if err := tx.Model(&model.CashDebt{}).Where("id = ?", debt.ID).Updates(updates).Error; err != nil {
+			return err
+		}
+		result = entity.NewCashDebtSettlementDtoFromModel(m)
+		if afterSave != nil {
+			if err := afterSave(tx, result); err != nil {
+				return err
+			}
+			if result.JournalEntryID != "" {
+				return tx.Model(m).Update("journal_entry_id", result.JournalEntryID).Error
+			}
+		}
+		return nil
+	})
+	return result, err
+}
+
+func (r *repository) ReverseSettlements(
+	ctx context.Context,
+	refTable, refID, actor string,
+	afterSave func(tx *gorm.DB, reversed []*entity.CashDebtSettlementDto) error,
+) ([]*entity.CashDebtSettlementDto, error) {
+	var result []*entity.CashDebtSettlementDto
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var rows []model.CashDebtSettlement
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("ref_table = ? AND ref_id = ? AND reversed_at IS NULL", refTable, refID).
+			Order("created_at ASC").
+			Find(&rows).Error; err != nil {
+			return err
+		}
+		now := time.Now()
+		for i := range rows {
+			m := &rows[i]
+			m.ReversedAt = &now
+			m.ReversedBy = actor
+			if err := tx.Model(m).Select("reversed_at", "reversed_by").Updates(m).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&model.CashDebt{}).Where("id = ?", m.CashDebtID).
+				Updates(map[string]interface{}{
+					"settled_amount": gorm.Expr("settled_amount - ?", m.Amount),
+					"settled_at":     nil,
+					"updated_at":     now,
+				}).Error; err != nil {
+				return err
+			}
+			result = append(result, entity.NewCashDebtSettlementDtoFromModel(m))
+		}
+		if afterSave != nil {
+			return afterSave(tx, result)
+		}
+		return nil
+	})
+	return result, err
+}
+
+func (r *repository) FindOutstanding(
+	ctx context.Context,
+	organizationID, adminIDEmployee, until string,
+) ([]*entity.CashDebtDto, error) {
+	var rows []model.CashDebt
+	q := r.db.WithContext(ctx).
+		Where("organization_id = ? AND admin_id_employee = ?", organizationID, adminIDEmployee).
+		Where("amount - settled_amount >= 0.005")
+	if until != "" {
+		q = q.Where("date <= ?", until)
+	}
+	if err := q.Order("date ASC, created_at ASC").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]*entity.CashDebtDto, 0, len(rows))
+	for i := range rows {
+		d := entity.NewCashDebtDtoFromModel(&rows[i])
+		if d.Outstanding == 0 {
+			continue
+		}
+		out = append(out, d)
+	}
+	return out, nil
+}
@@ -2,33 +2,110 @@ package cashdebt
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
 
 	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	accounting "github.com/raymondsugiarto/coffee-api/pkg/module/accounting"
 	shared "github.com/raymondsugiarto/coffee-api/pkg/shared/context"
 	"github.com/raymondsugiarto/coffee-api/pkg/shared/pagination"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/response/status"
+	"gorm.io/gorm"
 )
 
 type Service interface {
+	// WithTx binds the service to a transaction owned by the caller,
+	// so a repayment commits or rolls back together with the write
+	// that produced it (e.g. a payroll save).
+	WithTx(tx *gorm.DB) Service
 	Create(ctx context.Context, dto *entity.CashDebtDto) (*entity.CashDebtDto, error)
 	Get(ctx context.Context, id string) (*entity.CashDebtDto, error)
 	Update(ctx context.Context, dto *entity.CashDebtDto) (*entity.CashDebtDto, error)
 	Delete(ctx context.Context, id string) error
 	FindAll(ctx context.Context, req *entity.CashDebtFindAllRequest) (*pagination.ResultPagination, error)
+	// Settle records a full or partial repayment of an advance and
+	// posts it to the ledger (CASH_DEBT_SETTLED).
+	Settle(ctx context.Context, id string, req *entity.CashDebtSettleRequest) (*entity.CashDebtDto, error)
+	// SettleByPayroll records the PAYROLL repayment a saved salary
+	// run deducts, dated at the run's end date and linked to it so
+	// ReverseSettlements can undo it when the run is voided.
+	SettleByPayroll(ctx context.Context, id string, run *entity.EmployeeSalaryDto, amount float64) (*entity.CashDebtDto, error)
+	// Balance returns what an employee still owes, aged by advance
+	// date as of today.
+	Balance(ctx context.Context, adminIDEmployee string) (*entity.CashDebtBalanceDto, error)
+	// FindOutstanding lists the employee's unpaid advances dated on
+	// or before `until` (YYYY-MM-DD, empty for no bound), oldest
+	// first.
+	FindOutstanding(ctx context.Context, adminIDEmployee, until string) ([]*entity.CashDebtDto, error)
+	// ReverseSettlements undoes every repayment the source record
+	// made (e.g. a voided payroll run): the advances owe that money
+	// again and each repayment's ledger entry is reversed.
+	ReverseSettlements(ctx context.Context, refTable, refID, reason string) error
 }
 
 type service struct {
-	repo Repository
+	repo                Repository
+	periodGuard         accounting.PeriodGuard
+	postingService      accounting.PostingService
+	journalEntryService accounting.JournalEntryService
 }
 
-func NewService(repo Repository) Service {
-	return &service{repo: repo}
+// NewService wires the cash-debt ledger. `periodGuard` keeps
+// advances and repayments dated inside a closed accounting period
+// read-only; `postingService` books advances (when the organization
+// has CASH_DEBT_CREATED rules) and repayments against Piutang Kas
+// Bon, and `journalEntryService` reverses them.
+func NewService(
+	repo Repository,
+	periodGuard accounting.PeriodGuard,
+	postingService accounting.PostingService,
+	journalEntryService accounting.JournalEntryService,
+) Service {
+	return &service{
+		repo:                repo,
+		periodGuard:         periodGuard,
+		postingService:      postingService,
+		journalEntryService: journalEntryService,
+	}
+}
+
+func (s *service) WithTx(tx *gorm.DB) Service {
+	return &service{
+		repo:                s.repo.WithTx(tx),
+		periodGuard:         s.periodGuard,
+		postingService:      s.postingService.WithTx(tx),
+		journalEntryService: s.journalEntryService.WithTx(tx),
+	}
+}
+
+// settlementAmountFields maps a settlement method onto the
+// CASH_DEBT_SETTLED amount field its posting rule reads.
+var settlementAmountFields = map[string]string{
+	entity.CashDebtSettlementMethodCash:       entity.PostingAmountSettledCash,
+	entity.CashDebtSettlementMethodPayroll:    entity.PostingAmountSettledPayroll,
+	entity.CashDebtSettlementMethodTillOffset: entity.PostingAmountSettledTillOffset,
+}
+
+// agingBuckets are the bands of GET /employees/:id/cash-debt-balance,
+// in days since the advance. MaxDays 0 is open-ended.
+var agingBuckets = []entity.CashDebtAgingBucketDto{
+	{Label: "0-7", MinDays: 0, MaxDays: 7},
+	{Label: "8-30", MinDays: 8, MaxDays: 30},
+	{Label: ">30", MinDays: 31},
 }
 
 func (s *service) Create(ctx context.Context, dto *entity.CashDebtDto) (*entity.CashDebtDto, error) {
 	if dto.OrganizationID == "" {
 		dto.OrganizationID = shared.GetOrganization(ctx).ID
 	}
-	return s.repo.Create(ctx, dto)
+	if err := s.periodGuard.EnsureOpen(ctx, dto.Date); err != nil {
+		return nil, err
+	}
+	return s.repo.Create(ctx, dto, func(tx *gorm.DB, saved *entity.CashDebtDto) error {
+		return s.postAdvance(ctx, tx, saved)
+	})
 }
 
 func (s *service) Get(ctx context.Context, id string) (*entity.CashDebtDto, error) {
@@ -39,11 +116,91 @@ func (s *service) Update(ctx context.Context, dto *entity.CashDebtDto) (*entity.
 	if dto.OrganizationID == "" {
 		dto.OrganizationID = shared.GetOrganization(ctx).ID
 	}
-	return s.repo.Update(ctx, dto)
+	// Both the old and the new date must be in open periods: moving
+	// an advance out of a closed month changes it as much as
+	// editing it in place.
+	existing, err := s.repo.Get(ctx, dto.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.periodGuard.EnsureOpen(ctx, existing.Date); err != nil {
+		return nil, err
+	}
+	if err := s.periodGuard.EnsureOpen(ctx, dto.Date); err != nil {
+		return nil, err
+	}
+	if dto.Amount < existing.SettledAmount {
+		return nil, status.New(status.BadRequest, fmt.Errorf(
+			"amount cannot be lower than the %.2f already repaid", existing.SettledAmount,
+		))
+	}
+	return s.repo.Update(ctx, dto, func(tx *gorm.DB, saved *entity.CashDebtDto) error {
+		if saved.Amount == existing.Amount && saved.Date == existing.Date {
+			return nil
+		}
+		// The advance moved: its posting is reversed and the new
+		// amount and date posted in its place.
+		if err := s.reverseAdvance(ctx, tx, saved, "Cash debt changed"); err != nil {
+			return err
+		}
+		return s.postAdvance(ctx, tx, saved)
+	})
 }
 
 func (s *service) Delete(ctx context.Context, id string) error {
-	return s.repo.Delete(ctx, id)
+	existing, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.periodGuard.EnsureOpen(ctx, existing.Date); err != nil {
+		return err
+	}
+	// Repayments are already in the ledger; deleting the advance
+	// would leave them pointing at nothing.
+	if existing.SettledAmount > 0 {
+		return status.New(status.BadRequest, errors.New("cash debt has settlements and cannot be deleted"))
+	}
+	return s.repo.Delete(ctx, id, func(tx *gorm.DB) error {
+		return s.reverseAdvance(ctx, tx, existing, "Cash debt deleted")
+	})
+}
+
+// postAdvance fires CASH_DEBT_CREATED inside the write transaction.
+// No default rule is seeded: the advance is already booked by the
+// session close it was taken at, so only an organization that books
+// advances here configures one.
+func (s *service) postAdvance(ctx context.Context, tx *gorm.DB, d *entity.CashDebtDto) error {
+	entry, err := s.postingService.WithTx(tx).PostEvent(ctx, &entity.PostingEventDto{
+		OrganizationID: d.OrganizationID,
+		EventType:      entity.PostingEventCashDebtCreated,
+		RefID:          d.ID,
+		RefTable:       entity.AccountMutationRefTableCashDebt,
+		RefModule:      entity.AccountMutationRefModuleCashDebt,
+		Description:    fmt.Sprintf("Cash debt %s", d.Date),
+		Amounts: map[string]float64{
+			entity.PostingAmountAmount: d.Amount,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	d.JournalEntryID = ""
+	if entry != nil {
+		d.JournalEntryID = entry.ID
+	}
+	return nil
+}
+
+// reverseAdvance undoes the CASH_DEBT_CREATED posting of an advance,
+// if it had one.
+func (s *service) reverseAdvance(ctx context.Context, tx *gorm.DB, d *entity.CashDebtDto, reason string) error {
+	if d.JournalEntryID == "" {
+		return nil
+	}
+	_, err := s.journalEntryService.WithTx(tx).Reverse(ctx, d.JournalEntryID, &entity.JournalEntryReverseRequest{
+		Description: reason,
+	})
+	return err
 }
 
 func (s *service) FindAll(
@@ -55,3 +212,188 @@ func (s *service) FindAll(
 	}
 	return s.repo.FindAll(ctx, req)
 }
+
+func (s *service) Settle(
+	ctx context.Context,
+	id string,
+	req *entity.CashDebtSettleRequest,
+) (*entity.CashDebtDto, error) {
+	if req.Method != entity.CashDebtSettlementMethodCash && req.Method != entity.CashDebtSettlementMethodTillOffset {
+		return nil, status.New(status.BadRequest, fmt.Errorf("settlement method %q is not allowed", req.Method))
+	}
+	date := req.Date
+	if date == "" {
+		date = time.Now().Format("2006-01-02")
+	}
+	return s.settle(ctx, id, &entity.CashDebtSettlementDto{
+		Date:   date,
+		Amount: req.Amount,
+		Method: req.Method,
+		Notes:  req.Notes,
+	})
+}
+
+func (s *service) SettleByPayroll(
+	ctx context.Context,
+	id string,
+	run *entity.EmployeeSalaryDto,
+	amount float64,
+) (*entity.CashDebtDto, error) {
+	return s.settle(ctx, id, &entity.CashDebtSettlementDto{
+		Date:     run.EndDate,
+		Amount:   amount,
+		Method:   entity.CashDebtSettlementMethodPayroll,
+		RefID:    run.ID,
+		RefTable: entity.CashDebtSettlementRefTableEmployeeSalary,
+		Notes:    fmt.Sprintf("Payroll %s - %s", run.StartDate, run.EndDate),
+	})
+}
+
+// settle books `settlement` (Date, Amount, Method, Ref*, Notes)
+// against the advance `id` of the caller's organization.
+func (s *service) settle(
+	ctx context.Context,
+	id string,
+	settlement *entity.CashDebtSettlementDto,
+) (*entity.CashDebtDto, error) {
+	orgID := shared.GetOrganization(ctx).ID
+	debt, err := s.repo.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, status.New(status.EntityNotFound, err)
+		}
+		return nil, err
+	}
+	if debt.OrganizationID != orgID {
+		return nil, status.New(status.EntityNotFound, errors.New("cash debt not found"))
+	}
+
+	if settlement.Date < debt.Date {
+		return nil, status.New(status.BadRequest, errors.New("settlement date is before the cash debt date"))
+	}
+	if err := s.periodGuard.EnsureOpen(ctx, settlement.Date); err != nil {
+		return nil, err
+	}
+
+	settlement.OrganizationID = orgID
+	settlement.CashDebtID = debt.ID
+	settlement.AdminIDEmployee = debt.AdminIDEmployee
+	settlement.CreatedBy = actorOf(ctx)
+	_, err = s.repo.Settle(ctx, settlement, func(tx *gorm.DB, saved *entity.CashDebtSettlementDto) error {
+		return s.postSettlement(ctx, tx, saved)
+	})
+	if err != nil {
+		if errors.Is(err, ErrCashDebtOverSettled) {
+			return nil, status.New(status.BadRequest, fmt.Errorf(
+				"%w: %.2f outstanding", err, debt.Outstanding,
+			))
+		}
+		return nil, err
+	}
+	return s.repo.Get(ctx, id)
+}
+
+// postSettlement fires CASH_DEBT_SETTLED inside the settlement
+// transaction. The default rules (migration 000022) credit Piutang
+// Kas Bon against Kas (cash), Hutang Gaji (payroll deduction) or
+// Piutang Driver (till offset).
+func (s *service) postSettlement(ctx context.Context, tx *gorm.DB, d *entity.CashDebtSettlementDto) error {
+	entry, err := s.postingService.WithTx(tx).PostEvent(ctx, &entity.PostingEventDto{
+		OrganizationID: d.OrganizationID,
+		EventType:      entity.PostingEventCashDebtSettled,
+		RefID:          d.CashDebtID,
+		RefTable:       entity.AccountMutationRefTableCashDebt,
+		RefModule:      entity.AccountMutationRefModuleCashDebt,
+		Description:    fmt.Sprintf("Cash debt settlement (%s) %s", d.Method, d.Date),
+		Amounts: map[string]float64{
+			settlementAmountFields[d.Method]: d.Amount,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if entry != nil {
+		d.JournalEntryID = entry.ID
+	}
+	return nil
+}
+
+func (s *service) ReverseSettlements(ctx context.Context, refTable, refID, reason string) error {
+	_, err := s.repo.ReverseSettlements(ctx, refTable, refID, actorOf(ctx),
+		func(tx *gorm.DB, reversed []*entity.CashDebtSettlementDto) error {
+			journalEntryService := s.journalEntryService.WithTx(tx)
+			for _, d := range reversed {
+				if d.JournalEntryID == "" {
+					continue
+				}
+				if _, err := journalEntryService.Reverse(ctx, d.JournalEntryID, &entity.JournalEntryReverseRequest{
+					Description: fmt.Sprintf("Cash debt settlement reversed: %s", reason),
+				}); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	return err
+}
+
+func (s *service) Balance(ctx context.Context, adminIDEmployee string) (*entity.CashDebtBalanceDto, error) {
+	debts, err := s.repo.FindOutstanding(ctx, shared.GetOrganization(ctx).ID, adminIDEmployee, "")
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	balance := &entity.CashDebtBalanceDto{
+		AdminIDEmployee: adminIDEmployee,
+		AsOf:            today.Format("2006-01-02"),
+		Aging:           make([]entity.CashDebtAgingBucketDto, len(agingBuckets)),
+		Debts:           debts,
+	}
+	copy(balance.Aging, agingBuckets)
+	for _, d := range debts {
+		balance.TotalAmount += d.Amount
+		balance.TotalSettled += d.SettledAmount
+		balance.Outstanding += d.Outstanding
+
+		date, _ := time.Parse("2006-01-02", d.Date)
+		days := int(today.Sub(date).Hours() / 24)
+		if days < 0 {
+			days = 0
+		}
+		for i := range balance.Aging {
+			b := &balance.Aging[i]
+			if days >= b.MinDays && (b.MaxDays == 0 || days <= b.MaxDays) {
+				b.Count++
+				b.Outstanding += d.Outstanding
+				break
+			}
+		}
+	}
+	balance.TotalAmount = roundCents(balance.TotalAmount)
+	balance.TotalSettled = roundCents(balance.TotalSettled)
+	balance.Outstanding = roundCents(balance.Outstanding)
+	for i := range balance.Aging {
+		balance.Aging[i].Outstanding = roundCents(balance.Aging[i].Outstanding)
+	}
+	return balance, nil
+}
+
+func (s *service) FindOutstanding(
+	ctx context.Context,
+	adminIDEmployee, until string,
+) ([]*entity.CashDebtDto, error) {
+	return s.repo.FindOutstanding(ctx, shared.GetOrganization(ctx).ID, adminIDEmployee, until)
+}
+
+func roundCents(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+func actorOf(ctx context.Context) string {
+	if cred := shared.GetUserCredential(ctx); cred != nil {
+		return cred.AdminID
+	}
+	return ""
+}
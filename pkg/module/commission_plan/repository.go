@@ -0,0 +1,140 @@
+package commissionplan
+
+import (
+	"context"
+
+	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	"github.com/raymondsugiarto/coffee-api/pkg/model"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/pagination"
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	Create(ctx context.Context, dto *entity.CommissionPlanDto) (*entity.CommissionPlanDto, error)
+	Get(ctx context.Context, id string) (*entity.CommissionPlanDto, error)
+	// Update rewrites the plan header and replaces its tiers.
+	Update(ctx context.Context, dto *entity.CommissionPlanDto) (*entity.CommissionPlanDto, error)
+	Delete(ctx context.Context, id string) error
+	FindAll(ctx context.Context, req *entity.CommissionPlanFindAllRequest) (*pagination.ResultPagination, error)
+	// FindActive returns every active plan of the organization with
+	// its tiers, oldest first.
+	FindActive(ctx context.Context, organizationID string) ([]entity.CommissionPlanDto, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) Create(ctx context.Context, dto *entity.CommissionPlanDto) (*entity.CommissionPlanDto, error) {
+	m := dto.ToModel()
+	if err := r.db.WithContext(ctx).Create(m).Error; err != nil {
+		return nil, err
+	}
+	return entity.NewCommissionPlanDtoFromModel(m), nil
+}
+
+func (r *repository) Get(ctx context.Context, id string) (*entity.CommissionPlanDto, error) {
+	var m model.CommissionPlan
+	if err := r.db.WithContext(ctx).Preload("Tiers").Where("id = ?", id).First(&m).Error; err != nil {
+		return nil, err
+	}
+	return entity.NewCommissionPlanDtoFromModel(&m), nil
+}
+
+func (r *repository) Update(ctx context.Context, dto *entity.CommissionPlanDto) (*entity.CommissionPlanDto, error) {
+	m := dto.ToModel()
+	tiers := m.Tiers
+	m.Tiers = nil
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(m).
+			Select("name", "company_id", "item_category_id", "method", "rate", "is_active").
+			Updates(m).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("plan_id = ?", m.ID).Delete(&model.CommissionPlanTier{}).Error; err != nil {
+			return err
+		}
+		for i := range tiers {
+			tiers[i].PlanID = m.ID
+		}
+		if len(tiers) > 0 {
+			if err := tx.Create(&tiers).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.Get(ctx, m.ID)
+}
+
+func (r *repository) Delete(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("plan_id = ?", id).Delete(&model.CommissionPlanTier{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).Delete(&model.CommissionPlan{}).Error
+	})
+}
+
+func (r *repository) FindAll(
+	ctx context.Context,
+	req *entity.CommissionPlanFindAllRequest,
+) (*pagination.ResultPagination, error) {
+	var rows []model.CommissionPlan = make([]model.CommissionPlan, 0)
+	tbl := pagination.NewTable(r.db)
+	dataTable, err := tbl.Pagination(func(i interface{}) *gorm.DB {
+		q := r.db.WithContext(ctx).Model(&model.CommissionPlan{}).Preload("Tiers").
+			Where("organization_id = ?", req.FindAllRequest.OrganizationData.ID)
+		if req.CompanyID != "" {
+			q = q.Where("company_id = ?", req.CompanyID)
+		}
+		if req.ItemCategoryID != "" {
+			q = q.Where("item_category_id = ?", req.ItemCategoryID)
+		}
+		return q
+	}, &pagination.TableRequest{
+		Request:       req,
+		QueryField:    []string{"name"},
+		Data:          &rows,
+		AllowedFields: []string{"name", "method", "created_at"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := dataTable.(*pagination.ResultPagination)
+	hits := result.Data.(*[]model.CommissionPlan)
+	out := make([]*entity.CommissionPlanDto, 0, len(*hits))
+	for i := range *hits {
+		out = append(out, entity.NewCommissionPlanDtoFromModel(&(*hits)[i]))
+	}
+	return &pagination.ResultPagination{
+		Data:        out,
+		Page:        result.Page,
+		Count:       result.Count,
+		RowsPerPage: result.RowsPerPage,
+		TotalPages:  result.TotalPages,
+	}, nil
+}
+
+func (r *repository) FindActive(ctx context.Context, organizationID string) ([]entity.CommissionPlanDto, error) {
+	var rows []model.CommissionPlan
+	if err := r.db.WithContext(ctx).
+		Preload("Tiers").
+		Where("organization_id = ? AND is_active = ?", organizationID, true).
+		Order("created_at ASC").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]entity.CommissionPlanDto, 0, len(rows))
+	for i := range rows {
+		out = append(out, *entity.NewCommissionPlanDtoFromModel(&rows[i]))
+	}
+	return out, nil
+}
@@ -0,0 +1,111 @@
+package commissionplan
+
+import (
+	"context"
+	"errors"
+
+	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	shared "github.com/raymondsugiarto/coffee-api/pkg/shared/context"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/pagination"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/response/status"
+	"gorm.io/gorm"
+)
+
+// Service manages commission plans. Closing a stock session reads
+// the active plans through FindActive and snapshots the ones it
+// used, so editing or deleting a plan only affects later closes.
+type Service interface {
+	Create(ctx context.Context, dto *entity.CommissionPlanDto) (*entity.CommissionPlanDto, error)
+	Get(ctx context.Context, id string) (*entity.CommissionPlanDto, error)
+	Update(ctx context.Context, dto *entity.CommissionPlanDto) (*entity.CommissionPlanDto, error)
+	Delete(ctx context.Context, id string) error
+	FindAll(ctx context.Context, req *entity.CommissionPlanFindAllRequest) (*pagination.ResultPagination, error)
+	FindActive(ctx context.Context, organizationID string) ([]entity.CommissionPlanDto, error)
+}
+
+type service struct {
+	repo Repository
+}
+
+func NewService(repo Repository) Service {
+	return &service{repo: repo}
+}
+
+func (s *service) Create(ctx context.Context, dto *entity.CommissionPlanDto) (*entity.CommissionPlanDto, error) {
+	if err := validatePlan(dto); err != nil {
+		return nil, err
+	}
+	dto.OrganizationID = shared.GetOrganization(ctx).ID
+	return s.repo.Create(ctx, dto)
+}
+
+func (s *service) Get(ctx context.Context, id string) (*entity.CommissionPlanDto, error) {
+	plan, err := s.repo.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, status.New(status.EntityNotFound, err)
+		}
+		return nil, err
+	}
+	if plan.OrganizationID != shared.GetOrganization(ctx).ID {
+		return nil, status.New(status.EntityNotFound, errors.New("commission plan not found"))
+	}
+	return plan, nil
+}
+
+func (s *service) Update(ctx context.Context, dto *entity.CommissionPlanDto) (*entity.CommissionPlanDto, error) {
+	if _, err := s.Get(ctx, dto.ID); err != nil {
+		return nil, err
+	}
+	if err := validatePlan(dto); err != nil {
+		return nil, err
+	}
+	dto.OrganizationID = shared.GetOrganization(ctx).ID
+	return s.repo.Update(ctx, dto)
+}
+
+func (s *service) Delete(ctx context.Context, id string) error {
+	if _, err := s.Get(ctx, id); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *service) FindAll(
+	ctx context.Context,
+	req *entity.CommissionPlanFindAllRequest,
+) (*pagination.ResultPagination, error) {
+	req.FindAllRequest.OrganizationData.ID = shared.GetOrganization(ctx).ID
+	return s.repo.FindAll(ctx, req)
+}
+
+func (s *service) FindActive(ctx context.Context, organizationID string) ([]entity.CommissionPlanDto, error) {
+	return s.repo.FindActive(ctx, organizationID)
+}
+
+// validatePlan checks what the struct tags cannot: tiered plans need
+// tiers starting at 0 with distinct thresholds, percentage plans a
+// rate.
+func validatePlan(dto *entity.CommissionPlanDto) error {
+	if dto.Method == entity.CommissionPlanMethodPercentage {
+		if dto.Rate <= 0 {
+			return status.New(status.BadRequest, errors.New("a percentage plan needs a rate above 0"))
+		}
+		dto.Tiers = nil
+		return nil
+	}
+	if len(dto.Tiers) == 0 {
+		return status.New(status.BadRequest, errors.New("a tiered plan needs at least one tier"))
+	}
+	dto.SortTiers()
+	if dto.Tiers[0].FromQty != 0 {
+		return status.New(status.BadRequest, errors.New("the first tier must start at 0"))
+	}
+	for i := 1; i < len(dto.Tiers); i++ {
+		if dto.Tiers[i].FromQty == dto.Tiers[i-1].FromQty {
+			return status.New(status.BadRequest, errors.New("tiers must start at distinct quantities"))
+		}
+	}
+	dto.Rate = 0
+	return nil
+}
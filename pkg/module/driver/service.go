@@ -2,15 +2,24 @@ package driver
 
 import (
 	"context"
+	"errors"
 
 	"github.com/raymondsugiarto/coffee-api/pkg/entity"
 	"github.com/raymondsugiarto/coffee-api/pkg/model"
+	shared "github.com/raymondsugiarto/coffee-api/pkg/shared/context"
 	"github.com/raymondsugiarto/coffee-api/pkg/shared/pagination"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/response/status"
 	"gorm.io/gorm"
 )
 
 type Service interface {
 	FindAllDrivers(ctx context.Context, req *entity.DriverFindAllRequest) (*pagination.ResultPagination, error)
+	// GetBankAccount returns the account the driver's pay is
+	// transferred to, with the number masked.
+	GetBankAccount(ctx context.Context, id string) (*entity.DriverBankAccountDto, error)
+	// UpdateBankAccount sets the account the driver's pay is
+	// transferred to.
+	UpdateBankAccount(ctx context.Context, id string, req *entity.DriverBankAccountRequest) (*entity.DriverBankAccountDto, error)
 }
 
 type service struct {
@@ -56,3 +65,45 @@ func (s *service) FindAllDrivers(ctx context.Context, req *entity.DriverFindAllR
 		TotalPages:  result.TotalPages,
 	}, nil
 }
+
+// findDriver loads an employee of the caller's organization.
+func (s *service) findDriver(ctx context.Context, id string) (*model.Admin, error) {
+	var m model.Admin
+	if err := s.db.WithContext(ctx).
+		Where("id = ? AND admin_type = ? AND organization_id = ?", id, entity.AdminTypeEmployee, shared.GetOrganization(ctx).ID).
+		First(&m).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, status.New(status.EntityNotFound, errors.New("driver not found"))
+		}
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (s *service) GetBankAccount(ctx context.Context, id string) (*entity.DriverBankAccountDto, error) {
+	m, err := s.findDriver(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return new(entity.DriverBankAccountDto).FromModel(m), nil
+}
+
+func (s *service) UpdateBankAccount(
+	ctx context.Context,
+	id string,
+	req *entity.DriverBankAccountRequest,
+) (*entity.DriverBankAccountDto, error) {
+	m, err := s.findDriver(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	m.BankCode = req.BankCode
+	m.BankAccountNumber = req.BankAccountNumber
+	m.BankAccountName = req.BankAccountName
+	if err := s.db.WithContext(ctx).Model(m).
+		Select("bank_code", "bank_account_number", "bank_account_name").
+		Updates(m).Error; err != nil {
+		return nil, err
+	}
+	return new(entity.DriverBankAccountDto).FromModel(m), nil
+}
@@ -0,0 +1,320 @@
+package inventory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	"github.com/raymondsugiarto/coffee-api/pkg/model"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/pagination"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository interface {
+	// WithTx returns a repository bound to the caller's transaction.
+	WithTx(tx *gorm.DB) Repository
+	// Post writes the movements and moves each item's on-hand by
+	// its Qty, with the item_stock rows locked so concurrent posts
+	// cannot lose an update. The movements are returned with
+	// BalanceAfter filled in.
+	Post(ctx context.Context, movements []*entity.StockMovementDto) ([]*entity.StockMovementDto, error)
+	// NetByRef sums, per item, the movements of one type already
+	// posted for a document.
+	NetByRef(ctx context.Context, organizationID, refTable, refID, movementType string) (map[string]int, error)
+	// GetStock returns an item's on-hand; an item that never moved
+	// has 0.
+	GetStock(ctx context.Context, organizationID, itemID string) (*entity.ItemStockDto, error)
+	FindStock(ctx context.Context, req *entity.ItemStockFindAllRequest) (*pagination.ResultPagination, error)
+	FindMovements(ctx context.Context, req *entity.StockMovementFindAllRequest) (*pagination.ResultPagination, error)
+	// SetReorderPoint stores an item's reorder point, creating its
+	// item_stock row (on-hand 0) if it never moved.
+	SetReorderPoint(ctx context.Context, organizationID, itemID string, reorderPoint int) error
+	// FindLowStock returns, for every organization, the watched
+	// items at or below their reorder point. Organizations with
+	// nothing low are left out.
+	FindLowStock(ctx context.Context) ([]*entity.LowStockDto, error)
+	// MissingItems returns the ids that are not items of the
+	// organization (or global items).
+	MissingItems(ctx context.Context, organizationID string, ids []string) ([]string, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) WithTx(tx *gorm.DB) Repository {
+	return &repository{db: tx}
+}
+
+func (r *repository) Post(
+	ctx context.Context,
+	movements []*entity.StockMovementDto,
+) ([]*entity.StockMovementDto, error) {
+	if len(movements) == 0 {
+		return nil, nil
+	}
+	out := make([]*entity.StockMovementDto, 0, len(movements))
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		stocks, err := lockStocks(tx, movements[0].OrganizationID, itemIDsOf(movements))
+		if err != nil {
+			return err
+		}
+		for _, d := range movements {
+			saved, err := postLine(tx, stocks[d.ItemID], d)
+			if err != nil {
+				return err
+			}
+			out = append(out, saved)
+		}
+		return saveStocks(tx, stocks)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// lockStocks makes sure every item has an item_stock row, then locks
+// them in item order so two posts touching the same items cannot
+// deadlock.
+func lockStocks(tx *gorm.DB, organizationID string, itemIDs []string) (map[string]*model.ItemStock, error) {
+	for _, id := range itemIDs {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&model.ItemStock{OrganizationID: organizationID, ItemID: id}).Error; err != nil {
+			return nil, err
+		}
+	}
+	var rows []model.ItemStock
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("organization_id = ? AND item_id IN ?", organizationID, itemIDs).
+		Order("item_id ASC").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	stocks := make(map[string]*model.ItemStock, len(rows))
+	for i := range rows {
+		stocks[rows[i].ItemID] = &rows[i]
+	}
+	return stocks, nil
+}
+
+// postLine moves stock by d.Qty and writes the ledger line.
+func postLine(tx *gorm.DB, stock *model.ItemStock, d *entity.StockMovementDto) (*entity.StockMovementDto, error) {
+	stock.OnHand += d.Qty
+	d.BalanceAfter = stock.OnHand
+	m := d.ToModel()
+	if err := tx.Create(m).Error; err != nil {
+		return nil, err
+	}
+	return entity.NewStockMovementDtoFromModel(m), nil
+}
+
+func saveStocks(tx *gorm.DB, stocks map[string]*model.ItemStock) error {
+	for _, s := range stocks {
+		if err := tx.Model(s).Select("on_hand", "updated_at").Updates(s).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func itemIDsOf(movements []*entity.StockMovementDto) []string {
+	seen := make(map[string]bool, len(movements))
+	ids := make([]string, 0, len(movements))
+	for _, d := range movements {
+		if !seen[d.ItemID] {
+			seen[d.ItemID] = true
+			ids = append(ids, d.ItemID)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func (r *repository) NetByRef(
+	ctx context.Context,
+	organizationID, refTable, refID, movementType string,
+) (map[string]int, error) {
+	var rows []struct {
+		ItemID string
+		Qty    int
+	}
+	if err := r.db.WithContext(ctx).
+		Model(&model.StockMovement{}).
+		Select("item_id, SUM(qty) AS qty").
+		Where("organization_id = ? AND ref_table = ? AND ref_id = ? AND type = ?",
+			organizationID, refTable, refID, movementType).
+		Group("item_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make(map[string]int, len(rows))
+	for _, row := range rows {
+		out[row.ItemID] = row.Qty
+	}
+	return out, nil
+}
+
+func (r *repository) GetStock(ctx context.Context, organizationID, itemID string) (*entity.ItemStockDto, error) {
+	var item model.Item
+	if err := r.db.WithContext(ctx).Where("id = ?", itemID).First(&item).Error; err != nil {
+		return nil, err
+	}
+	var stock model.ItemStock
+	err := r.db.WithContext(ctx).
+		Where("organization_id = ? AND item_id = ?", organizationID, itemID).
+		Limit(1).
+		Find(&stock).Error
+	if err != nil {
+		return nil, err
+	}
+	stock.ItemID = itemID
+	stock.Item = &item
+	return entity.NewItemStockDtoFromModel(&stock), nil
+}
+
+func (r *repository) FindStock(
+	ctx context.Context,
+	req *entity.ItemStockFindAllRequest,
+) (*pagination.ResultPagination, error) {
+	var rows []model.ItemStock = make([]model.ItemStock, 0)
+	tbl := pagination.NewTable(r.db)
+	dataTable, err := tbl.Pagination(func(i interface{}) *gorm.DB {
+		q := r.db.WithContext(ctx).Model(&model.ItemStock{}).
+			Preload("Item").
+			Where("organization_id = ?", req.FindAllRequest.OrganizationData.ID)
+		if req.LowStock {
+			q = q.Where("reorder_point > 0 AND on_hand <= reorder_point")
+		}
+		return q
+	}, &pagination.TableRequest{
+		Request:       req,
+		QueryField:    []string{},
+		Data:          &rows,
+		AllowedFields: []string{"item_id", "on_hand", "reorder_point", "updated_at"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := dataTable.(*pagination.ResultPagination)
+	hits := result.Data.(*[]model.ItemStock)
+	out := make([]*entity.ItemStockDto, 0, len(*hits))
+	for i := range *hits {
+		out = append(out, entity.NewItemStockDtoFromModel(&(*hits)[i]))
+	}
+	return &pagination.ResultPagination{
+		Data:        out,
+		Page:        result.Page,
+		Count:       result.Count,
+		RowsPerPage: result.RowsPerPage,
+		TotalPages:  result.TotalPages,
+	}, nil
+}
+
+func (r *repository) FindMovements(
+	ctx context.Context,
+	req *entity.StockMovementFindAllRequest,
+) (*pagination.ResultPagination, error) {
+	var rows []model.StockMovement = make([]model.StockMovement, 0)
+	tbl := pagination.NewTable(r.db)
+	dataTable, err := tbl.Pagination(func(i interface{}) *gorm.DB {
+		return r.db.WithContext(ctx).Model(&model.StockMovement{}).
+			Preload("Item").
+			Where("organization_id = ?", req.FindAllRequest.OrganizationData.ID)
+	}, &pagination.TableRequest{
+		Request:       req,
+		QueryField:    []string{},
+		Data:          &rows,
+		AllowedFields: []string{"item_id", "type", "ref_id", "ref_table", "date", "qty"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := dataTable.(*pagination.ResultPagination)
+	hits := result.Data.(*[]model.StockMovement)
+	out := make([]*entity.StockMovementDto, 0, len(*hits))
+	for i := range *hits {
+		out = append(out, entity.NewStockMovementDtoFromModel(&(*hits)[i]))
+	}
+	return &pagination.ResultPagination{
+		Data:        out,
+		Page:        result.Page,
+		Count:       result.Count,
+		RowsPerPage: result.RowsPerPage,
+		TotalPages:  result.TotalPages,
+	}, nil
+}
+
+func (r *repository) SetReorderPoint(ctx context.Context, organizationID, itemID string, reorderPoint int) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&model.ItemStock{OrganizationID: organizationID, ItemID: itemID}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.ItemStock{}).
+			Where("organization_id = ? AND item_id = ?", organizationID, itemID).
+			Update("reorder_point", reorderPoint).Error
+	})
+}
+
+func (r *repository) FindLowStock(ctx context.Context) ([]*entity.LowStockDto, error) {
+	var rows []model.ItemStock
+	if err := r.db.WithContext(ctx).
+		Preload("Item").
+		Where("reorder_point > 0 AND on_hand <= reorder_point").
+		Order("organization_id ASC, item_id ASC").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]*entity.LowStockDto, 0)
+	byOrg := make(map[string]*entity.LowStockDto)
+	orgIDs := make([]string, 0)
+	for i := range rows {
+		group, ok := byOrg[rows[i].OrganizationID]
+		if !ok {
+			group = &entity.LowStockDto{OrganizationID: rows[i].OrganizationID}
+			byOrg[group.OrganizationID] = group
+			orgIDs = append(orgIDs, group.OrganizationID)
+			out = append(out, group)
+		}
+		group.Items = append(group.Items, entity.NewItemStockDtoFromModel(&rows[i]))
+	}
+	if len(orgIDs) == 0 {
+		return out, nil
+	}
+	var orgs []model.Organization
+	if err := r.db.WithContext(ctx).Where("id IN ?", orgIDs).Find(&orgs).Error; err != nil {
+		return nil, err
+	}
+	for _, o := range orgs {
+		byOrg[o.ID].OrganizationName = o.Name
+	}
+	return out, nil
+}
+
+func (r *repository) MissingItems(ctx context.Context, organizationID string, ids []string) ([]string, error) {
+	var found []string
+	if err := r.db.WithContext(ctx).
+		Model(&model.Item{}).
+		Where("id IN ?", ids).
+		Where("organization_id IS NULL OR organization_id = '' OR organization_id = ?", organizationID).
+		Pluck("id", &found).Error; err != nil {
+		return nil, err
+	}
+	have := make(map[string]bool, len(found))
+	for _, id := range found {
+		have[id] = true
+	}
+	missing := make([]string, 0)
+	for _, id := range ids {
+		if !have[id] {
+			missing = append(missing, id)
+		}
+	}
+	return missing, nil
+}
@@ -0,0 +1,242 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	shared "github.com/raymondsugiarto/coffee-api/pkg/shared/context"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/pagination"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/response/status"
+	"gorm.io/gorm"
+)
+
+// Service keeps the depot's item stock ledger. Every change of
+// on-hand is a StockMovement line; nothing edits item_stock
+// directly.
+type Service interface {
+	// WithTx binds the service to a transaction owned by the caller,
+	// so the stock moves commit or roll back together with the
+	// document that caused them (e.g. a stock session close).
+	WithTx(tx *gorm.DB) Service
+	// Receive books goods delivered to the depot (RECEIPT). It is
+	// called by goods receipts, which post the ledger and guard the
+	// accounting period; it is not exposed on its own.
+	Receive(ctx context.Context, req *entity.StockReceiptRequest) ([]*entity.StockMovementDto, error)
+	// SyncSession brings the ledger in line with a stock session:
+	// SESSION_OUT for what the driver loaded and, once the session
+	// is CLOSED, SESSION_RETURN for what came back. Only the change
+	// since the last sync is posted, so it is called on every open,
+	// update and close; a deleted session syncs with no items, which
+	// puts its load back.
+	SyncSession(ctx context.Context, session *entity.StockSessionDto) error
+	// Post books movements prepared by another module's document
+	// (e.g. the ADJUSTMENT lines of an approved stock opname). Qty
+	// is the signed change; the lines come back with BalanceAfter.
+	Post(ctx context.Context, movements []*entity.StockMovementDto) ([]*entity.StockMovementDto, error)
+	GetStock(ctx context.Context, itemID string) (*entity.ItemStockDto, error)
+	FindStock(ctx context.Context, req *entity.ItemStockFindAllRequest) (*pagination.ResultPagination, error)
+	FindMovements(ctx context.Context, req *entity.StockMovementFindAllRequest) (*pagination.ResultPagination, error)
+	// SetReorderPoint sets the on-hand at or below which an item is
+	// reported as low stock; 0 stops watching it.
+	SetReorderPoint(ctx context.Context, itemID string, req *entity.ReorderPointRequest) (*entity.ItemStockDto, error)
+	// FindLowStock lists the low items of every organization. It is
+	// meant for background jobs and does not read the organization
+	// from ctx.
+	FindLowStock(ctx context.Context) ([]*entity.LowStockDto, error)
+	// EnsureItems refuses item ids that are not in the caller's
+	// organization catalog (BadRequest listing the missing ids).
+	EnsureItems(ctx context.Context, ids []string) error
+}
+
+type service struct {
+	repo Repository
+}
+
+func NewService(repo Repository) Service {
+	return &service{repo: repo}
+}
+
+func (s *service) WithTx(tx *gorm.DB) Service {
+	return &service{repo: s.repo.WithTx(tx)}
+}
+
+func (s *service) Receive(
+	ctx context.Context,
+	req *entity.StockReceiptRequest,
+) ([]*entity.StockMovementDto, error) {
+	orgID := shared.GetOrganization(ctx).ID
+	ids := make([]string, 0, len(req.Items))
+	for _, it := range req.Items {
+		ids = append(ids, it.ItemID)
+	}
+	if err := s.EnsureItems(ctx, ids); err != nil {
+		return nil, err
+	}
+	date := req.Date
+	if date == "" {
+		date = time.Now().Format("2006-01-02")
+	}
+	movements := make([]*entity.StockMovementDto, 0, len(req.Items))
+	for _, it := range req.Items {
+		movements = append(movements, &entity.StockMovementDto{
+			OrganizationID: orgID,
+			ItemID:         it.ItemID,
+			Date:           date,
+			Type:           entity.StockMovementTypeReceipt,
+			Qty:            it.Qty,
+			RefID:          req.RefID,
+			RefTable:       req.RefTable,
+			Reference:      req.Reference,
+			Notes:          req.Notes,
+			CreatedBy:      actorOf(ctx),
+		})
+	}
+	return s.repo.Post(ctx, movements)
+}
+
+func (s *service) SyncSession(ctx context.Context, session *entity.StockSessionDto) error {
+	loaded := make(map[string]int)
+	returned := make(map[string]int)
+	for _, it := range session.Items {
+		loaded[it.ItemID] -= it.OutQty
+		if session.Status == entity.StockSessionStatusClosed {
+			returned[it.ItemID] += it.ReturnQty
+		}
+	}
+
+	notes := strings.TrimSpace(fmt.Sprintf("Stock session %s %s", session.Date, session.Shift))
+	movements := make([]*entity.StockMovementDto, 0)
+	for _, target := range []struct {
+		movementType string
+		want         map[string]int
+	}{
+		{entity.StockMovementTypeSessionOut, loaded},
+		{entity.StockMovementTypeSessionReturn, returned},
+	} {
+		posted, err := s.repo.NetByRef(
+			ctx, session.OrganizationID,
+			entity.AccountMutationRefTableStockSession, session.ID, target.movementType,
+		)
+		if err != nil {
+			return err
+		}
+		ids := make([]string, 0, len(target.want)+len(posted))
+		for id := range target.want {
+			ids = append(ids, id)
+		}
+		for id := range posted {
+			if _, ok := target.want[id]; !ok {
+				ids = append(ids, id)
+			}
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			delta := target.want[id] - posted[id]
+			if delta == 0 {
+				continue
+			}
+			movements = append(movements, &entity.StockMovementDto{
+				OrganizationID: session.OrganizationID,
+				ItemID:         id,
+				Date:           session.Date,
+				Type:           target.movementType,
+				Qty:            delta,
+				RefID:          session.ID,
+				RefTable:       entity.AccountMutationRefTableStockSession,
+				Notes:          notes,
+				CreatedBy:      actorOf(ctx),
+			})
+		}
+	}
+	_, err := s.repo.Post(ctx, movements)
+	return err
+}
+
+func (s *service) Post(
+	ctx context.Context,
+	movements []*entity.StockMovementDto,
+) ([]*entity.StockMovementDto, error) {
+	return s.repo.Post(ctx, movements)
+}
+
+func (s *service) GetStock(ctx context.Context, itemID string) (*entity.ItemStockDto, error) {
+	orgID := shared.GetOrganization(ctx).ID
+	// An item outside the catalog is not found, not a zero balance.
+	missing, err := s.repo.MissingItems(ctx, orgID, []string{itemID})
+	if err != nil {
+		return nil, err
+	}
+	if len(missing) > 0 {
+		return nil, status.New(status.EntityNotFound, fmt.Errorf("item not found: %s", itemID))
+	}
+	result, err := s.repo.GetStock(ctx, orgID, itemID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, status.New(status.EntityNotFound, err)
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *service) FindStock(
+	ctx context.Context,
+	req *entity.ItemStockFindAllRequest,
+) (*pagination.ResultPagination, error) {
+	if req.FindAllRequest.OrganizationData.ID == "" {
+		req.FindAllRequest.OrganizationData.ID = shared.GetOrganization(ctx).ID
+	}
+	return s.repo.FindStock(ctx, req)
+}
+
+func (s *service) FindMovements(
+	ctx context.Context,
+	req *entity.StockMovementFindAllRequest,
+) (*pagination.ResultPagination, error) {
+	if req.FindAllRequest.OrganizationData.ID == "" {
+		req.FindAllRequest.OrganizationData.ID = shared.GetOrganization(ctx).ID
+	}
+	return s.repo.FindMovements(ctx, req)
+}
+
+func (s *service) SetReorderPoint(
+	ctx context.Context,
+	itemID string,
+	req *entity.ReorderPointRequest,
+) (*entity.ItemStockDto, error) {
+	if err := s.EnsureItems(ctx, []string{itemID}); err != nil {
+		return nil, err
+	}
+	orgID := shared.GetOrganization(ctx).ID
+	if err := s.repo.SetReorderPoint(ctx, orgID, itemID, req.ReorderPoint); err != nil {
+		return nil, err
+	}
+	return s.repo.GetStock(ctx, orgID, itemID)
+}
+
+func (s *service) FindLowStock(ctx context.Context) ([]*entity.LowStockDto, error) {
+	return s.repo.FindLowStock(ctx)
+}
+
+func (s *service) EnsureItems(ctx context.Context, ids []string) error {
+	missing, err := s.repo.MissingItems(ctx, shared.GetOrganization(ctx).ID, ids)
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return status.New(status.BadRequest, fmt.Errorf("item not found: %s", strings.Join(missing, ", ")))
+	}
+	return nil
+}
+
+func actorOf(ctx context.Context) string {
+	if cred := shared.GetUserCredential(ctx); cred != nil {
+		return cred.AdminID
+	}
+	return ""
+}
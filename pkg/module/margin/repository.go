@@ -0,0 +1,59 @@
+package margin
+
+import (
+	"context"
+
+	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	FindLines(ctx context.Context, orgID, from, to string) ([]*entity.MarginLineDto, error)
+}
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+// FindLines aggregates closed-session items per day, driver and
+// root item. A variant (item.parent_id set) is reported under its
+// parent, including the parent's category, so a drink sold in
+// three sizes shows up as one product. Revenue is the row subtotal
+// and COGS values the units sold at the cost snapshotted on the
+// session item, the same figure the close posts to HPP.
+func (r *repository) FindLines(ctx context.Context, orgID, from, to string) ([]*entity.MarginLineDto, error) {
+	rows := make([]*entity.MarginLineDto, 0)
+	q := r.db.WithContext(ctx).
+		Table("stock_session_item ssi").
+		Select(`ss.date AS date,
+		        ss.employee_id AS employee_id,
+		        TRIM(COALESCE(a.first_name, '') || ' ' || COALESCE(a.last_name, '')) AS employee_name,
+		        root.id AS item_id,
+		        root.code AS item_code,
+		        root.name AS item_name,
+		        COALESCE(root.category_id, '') AS category_id,
+		        COALESCE(ic.name, '') AS category_name,
+		        COALESCE(SUM(ssi.sold_qty), 0) AS sold_qty,
+		        COALESCE(SUM(ssi.subtotal), 0) AS revenue,
+		        COALESCE(SUM(ssi.sold_qty * ssi.cost_price_snapshot), 0) AS cogs`).
+		Joins("JOIN stock_session ss ON ss.id = ssi.session_id AND ss.deleted_at IS NULL").
+		Joins("JOIN item i ON i.id = ssi.item_id").
+		Joins("JOIN item root ON root.id = COALESCE(NULLIF(i.parent_id, ''), i.id)").
+		Joins("LEFT JOIN item_category ic ON ic.id = root.category_id").
+		Joins("LEFT JOIN admin a ON a.id = ss.employee_id").
+		Where("ssi.deleted_at IS NULL").
+		Where("ss.status = ?", "CLOSED").
+		Where("ss.date >= ? AND ss.date <= ?", from, to).
+		Where("ss.organization_id = ?", orgID)
+	err := q.
+		Group("ss.date, ss.employee_id, a.first_name, a.last_name, root.id, root.code, root.name, root.category_id, ic.name").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
@@ -0,0 +1,145 @@
+package margin
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	shared "github.com/raymondsugiarto/coffee-api/pkg/shared/context"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/response/status"
+)
+
+// Service reports gross margin (revenue - COGS) on closed stock
+// sessions. Only CLOSED sessions count: an open session has no
+// sold quantities yet and nothing has been posted to HPP.
+type Service interface {
+	Report(ctx context.Context, req *entity.MarginReportRequest) (*entity.MarginReportDto, error)
+}
+
+type service struct {
+	repo Repository
+}
+
+func NewService(repo Repository) Service {
+	return &service{repo: repo}
+}
+
+// jakarta is the business day's time zone, as the scheduler uses.
+var jakarta = func() *time.Location {
+	loc, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		return time.FixedZone("WIB", 7*60*60)
+	}
+	return loc
+}()
+
+func (s *service) Report(ctx context.Context, req *entity.MarginReportRequest) (*entity.MarginReportDto, error) {
+	to := time.Now().In(jakarta)
+	if req.To != "" {
+		t, err := time.ParseInLocation("2006-01-02", req.To, jakarta)
+		if err != nil {
+			return nil, status.New(status.BadRequest, fmt.Errorf("invalid to date %q", req.To))
+		}
+		to = t
+	}
+	from := to.AddDate(0, 0, -30)
+	if req.From != "" {
+		f, err := time.ParseInLocation("2006-01-02", req.From, jakarta)
+		if err != nil {
+			return nil, status.New(status.BadRequest, fmt.Errorf("invalid from date %q", req.From))
+		}
+		from = f
+	}
+	if from.After(to) {
+		return nil, status.New(status.BadRequest, fmt.Errorf("from must not be after to"))
+	}
+
+	report := &entity.MarginReportDto{
+		From: from.Format("2006-01-02"),
+		To:   to.Format("2006-01-02"),
+	}
+	// The report is always the caller's organization's.
+	lines, err := s.repo.FindLines(ctx, shared.GetOrganization(ctx).ID, report.From, report.To)
+	if err != nil {
+		return nil, err
+	}
+
+	byItem := newBuckets()
+	byCategory := newBuckets()
+	byDriver := newBuckets()
+	byDay := newBuckets()
+	for _, l := range lines {
+		day := l.Date.Format("2006-01-02")
+		byItem.add(l.ItemID, l.ItemCode, l.ItemName, l)
+		byCategory.add(l.CategoryID, "", l.CategoryName, l)
+		byDriver.add(l.EmployeeID, "", l.EmployeeName, l)
+		byDay.add(day, "", day, l)
+		addLine(&report.Total, l)
+	}
+	finish(&report.Total)
+	report.Total.Name = "Total"
+
+	report.ByItem = byItem.rows(byRevenue)
+	report.ByCategory = byCategory.rows(byRevenue)
+	report.ByDriver = byDriver.rows(byRevenue)
+	report.ByDay = byDay.rows(func(a, b entity.MarginRowDto) bool { return a.ID < b.ID })
+	return report, nil
+}
+
+type buckets struct {
+	byID map[string]*entity.MarginRowDto
+}
+
+func newBuckets() *buckets {
+	return &buckets{byID: make(map[string]*entity.MarginRowDto)}
+}
+
+func (b *buckets) add(id, code, name string, l *entity.MarginLineDto) {
+	row, ok := b.byID[id]
+	if !ok {
+		row = &entity.MarginRowDto{ID: id, Code: code, Name: name}
+		b.byID[id] = row
+	}
+	addLine(row, l)
+}
+
+func (b *buckets) rows(less func(a, b entity.MarginRowDto) bool) []entity.MarginRowDto {
+	out := make([]entity.MarginRowDto, 0, len(b.byID))
+	for _, r := range b.byID {
+		finish(r)
+		out = append(out, *r)
+	}
+	sort.Slice(out, func(i, j int) bool { return less(out[i], out[j]) })
+	return out
+}
+
+func byRevenue(a, b entity.MarginRowDto) bool {
+	if a.Revenue != b.Revenue {
+		return a.Revenue > b.Revenue
+	}
+	return a.Name < b.Name
+}
+
+func addLine(row *entity.MarginRowDto, l *entity.MarginLineDto) {
+	row.SoldQty += l.SoldQty
+	row.Revenue += l.Revenue
+	row.Cogs += l.Cogs
+}
+
+// finish rounds the sums and derives the margin. Margin % is
+// against revenue and left at 0 when nothing was sold.
+func finish(row *entity.MarginRowDto) {
+	row.Revenue = round(row.Revenue)
+	row.Cogs = round(row.Cogs)
+	row.GrossMargin = round(row.Revenue - row.Cogs)
+	if row.Revenue != 0 {
+		row.MarginPercent = round(row.GrossMargin / row.Revenue * 100)
+	}
+}
+
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -2,6 +2,7 @@ package order
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/raymondsugiarto/coffee-api/pkg/entity"
@@ -11,10 +12,17 @@ import (
 )
 
 type Repository interface {
-	Create(ctx context.Context, role *entity.OrderDto) (*entity.OrderDto, error)
+	// Create and Delete run afterSave inside their transaction with
+	// the order written or removed; an error rolls the write back.
+	// Create stores the saved.JournalEntryID afterSave leaves behind.
+	Create(
+		ctx context.Context,
+		role *entity.OrderDto,
+		afterSave func(tx *gorm.DB, saved *entity.OrderDto) error,
+	) (*entity.OrderDto, error)
 	Get(ctx context.Context, id string) (*entity.OrderDto, error)
 	Update(ctx context.Context, role *entity.OrderDto) (*entity.OrderDto, error)
-	Delete(ctx context.Context, id string) error
+	Delete(ctx context.Context, id string, afterSave func(tx *gorm.DB, deleted *entity.OrderDto) error) error
 	FindAll(ctx context.Context, req *entity.OrderFindAllRequest) (*pagination.ResultPagination, error)
 	Count(ctx context.Context, req *entity.OrderFindAllRequest) (*entity.OrderCountDto, error)
 }
@@ -27,13 +35,32 @@ func NewRepository(db *gorm.DB) Repository {
 	return &repository{db}
 }
 
-func (r *repository) Create(ctx context.Context, role *entity.OrderDto) (*entity.OrderDto, error) {
-	m := role.ToModel()
-	err := r.db.Create(m).Error
+func (r *repository) Create(
+	ctx context.Context,
+	role *entity.OrderDto,
+	afterSave func(tx *gorm.DB, saved *entity.OrderDto) error,
+) (*entity.OrderDto, error) {
+	var result *entity.OrderDto
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		m := role.ToModel()
+		if err := tx.Create(m).Error; err != nil {
+			return err
+		}
+		result = entity.NewOrderDtoFromModel(m)
+		if afterSave != nil {
+			if err := afterSave(tx, result); err != nil {
+				return err
+			}
+			if result.JournalEntryID != "" {
+				return tx.Model(m).Update("journal_entry_id", result.JournalEntryID).Error
+			}
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
-	return entity.NewOrderDtoFromModel(m), nil
+	return result, nil
 }
 
 func (r *repository) Get(ctx context.Context, id string) (*entity.OrderDto, error) {
@@ -46,19 +73,35 @@ func (r *repository) Get(ctx context.Context, id string) (*entity.OrderDto, erro
 }
 
 func (r *repository) Update(ctx context.Context, role *entity.OrderDto) (*entity.OrderDto, error) {
-	err := r.db.Save(role.ToModel()).Error
+	// journal_entry_id belongs to the posting made at create.
+	err := r.db.Omit("journal_entry_id").Save(role.ToModel()).Error
 	if err != nil {
 		return nil, err
 	}
 	return role, nil
 }
 
-func (r *repository) Delete(ctx context.Context, id string) error {
-	err := r.db.Where("id = ?", id).Delete(&model.Order{}).Error
-	if err != nil {
-		return err
-	}
-	return nil
+func (r *repository) Delete(
+	ctx context.Context,
+	id string,
+	afterSave func(tx *gorm.DB, deleted *entity.OrderDto) error,
+) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var m model.Order
+		if err := tx.Where("id = ?", id).First(&m).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+		if err := tx.Delete(&m).Error; err != nil {
+			return err
+		}
+		if afterSave != nil {
+			return afterSave(tx, entity.NewOrderDtoFromModel(&m))
+		}
+		return nil
+	})
 }
 
 func (r *repository) FindAll(ctx context.Context, req *entity.OrderFindAllRequest) (*pagination.ResultPagination, error) {
@@ -2,14 +2,17 @@ package order
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/gofiber/fiber/v2/log"
 	gonanoid "github.com/matoous/go-nanoid/v2"
 	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	accounting "github.com/raymondsugiarto/coffee-api/pkg/module/accounting"
 	"github.com/raymondsugiarto/coffee-api/pkg/module/company"
 	shared "github.com/raymondsugiarto/coffee-api/pkg/shared/context"
 	"github.com/raymondsugiarto/coffee-api/pkg/shared/pagination"
+	"gorm.io/gorm"
 )
 
 type Service interface {
@@ -22,12 +25,27 @@ type Service interface {
 }
 
 type service struct {
-	repo           Repository
-	companyService company.Service
+	repo                Repository
+	companyService      company.Service
+	postingService      accounting.PostingService
+	journalEntryService accounting.JournalEntryService
 }
 
-func NewService(repo Repository, companyService company.Service) Service {
-	return &service{repo, companyService}
+// NewService wires the order module. An order posts ORDER_CREATED
+// through `postingService` when it is created; deleting it reverses
+// that entry through `journalEntryService`.
+func NewService(
+	repo Repository,
+	companyService company.Service,
+	postingService accounting.PostingService,
+	journalEntryService accounting.JournalEntryService,
+) Service {
+	return &service{
+		repo:                repo,
+		companyService:      companyService,
+		postingService:      postingService,
+		journalEntryService: journalEntryService,
+	}
 }
 
 func (s *service) Create(ctx context.Context, dto *entity.OrderDto) (*entity.OrderDto, error) {
@@ -40,7 +58,33 @@ func (s *service) Create(ctx context.Context, dto *entity.OrderDto) (*entity.Ord
 	code, _ := gonanoid.Generate("ABCDEFGHIJKLMNOPQRSTUVWXYZ", 4)
 	dto.Code = time.Now().Format("20060102") + "/" + code
 	dto.CompanyID = company.ID
-	return s.repo.Create(ctx, dto)
+	return s.repo.Create(ctx, dto, func(tx *gorm.DB, saved *entity.OrderDto) error {
+		return s.postOrder(ctx, tx, saved)
+	})
+}
+
+// postOrder fires ORDER_CREATED inside the create transaction. No
+// default rule is seeded, so an order only reaches the ledger for an
+// organization that configures one.
+func (s *service) postOrder(ctx context.Context, tx *gorm.DB, d *entity.OrderDto) error {
+	entry, err := s.postingService.WithTx(tx).PostEvent(ctx, &entity.PostingEventDto{
+		OrganizationID: d.OrganizationID,
+		EventType:      entity.PostingEventOrderCreated,
+		RefID:          d.ID,
+		RefTable:       entity.AccountMutationRefTableOrder,
+		RefModule:      entity.AccountMutationRefModuleOrder,
+		Description:    fmt.Sprintf("Order %s", d.Code),
+		Amounts: map[string]float64{
+			entity.PostingAmountTotalAmount: d.TotalAmount,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if entry != nil {
+		d.JournalEntryID = entry.ID
+	}
+	return nil
 }
 
 func (s *service) FindByID(ctx context.Context, id string) (*entity.OrderDto, error) {
@@ -53,7 +97,15 @@ func (s *service) Update(ctx context.Context, dto *entity.OrderDto) (*entity.Ord
 }
 
 func (s *service) Delete(ctx context.Context, id string) error {
-	return s.repo.Delete(ctx, id)
+	return s.repo.Delete(ctx, id, func(tx *gorm.DB, deleted *entity.OrderDto) error {
+		if deleted.JournalEntryID == "" {
+			return nil
+		}
+		_, err := s.journalEntryService.WithTx(tx).Reverse(ctx, deleted.JournalEntryID, &entity.JournalEntryReverseRequest{
+			Description: fmt.Sprintf("Order %s deleted", deleted.Code),
+		})
+		return err
+	})
 }
 
 func (s *service) FindAll(ctx context.Context, req *entity.OrderFindAllRequest) (*pagination.ResultPagination, error) {
@@ -0,0 +1,184 @@
+package payroll
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	shared "github.com/raymondsugiarto/coffee-api/pkg/shared/context"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/response/status"
+	"gorm.io/gorm"
+)
+
+func (s *service) SimulateBatch(
+	ctx context.Context,
+	req *entity.PayrollBatchRequest,
+) (*entity.PayrollBatchSimulateResultDto, error) {
+	employees, err := s.repo.FindCompanyEmployees(ctx, shared.GetOrganization(ctx).ID, req.CompanyID)
+	if err != nil {
+		return nil, err
+	}
+	if len(employees) == 0 {
+		return nil, status.New(status.BadRequest, errors.New("company has no drivers"))
+	}
+
+	out := &entity.PayrollBatchSimulateResultDto{
+		CompanyID: req.CompanyID,
+		StartDate: req.StartDate,
+		EndDate:   req.EndDate,
+		Employees: make([]entity.PayrollBatchEmployeeDto, 0, len(employees)),
+	}
+	for _, e := range employees {
+		sim, err := s.Simulate(ctx, &entity.SimulatePayrollRequest{
+			AdminIDEmployee:      e.ID,
+			StartDate:            req.StartDate,
+			EndDate:              req.EndDate,
+			TotalCashReceipt:     req.TotalCashReceipts[e.ID],
+			CashDebtDeductionCap: req.CashDebtDeductionCap,
+		})
+		if err != nil {
+			return nil, err
+		}
+		out.Employees = append(out.Employees, entity.PayrollBatchEmployeeDto{
+			EmployeeName:             strings.TrimSpace(e.FirstName + " " + e.LastName),
+			SimulatePayrollResultDto: *sim,
+		})
+		out.TotalSalary += sim.TotalSalary
+		out.TotalCashReceipt += sim.TotalCashReceipt
+		out.CashDebtDeduction += sim.CashDebtDeduction
+		out.RemainingSalary += sim.RemainingSalary
+	}
+	out.TotalSalary = roundCents(out.TotalSalary)
+	out.TotalCashReceipt = roundCents(out.TotalCashReceipt)
+	out.CashDebtDeduction = roundCents(out.CashDebtDeduction)
+	out.RemainingSalary = roundCents(out.RemainingSalary)
+	return out, nil
+}
+
+// SaveBatch saves exactly what SimulateBatch shows. Drivers without
+// a session in the window get no run. A driver who already has an
+// overlapping run fails the batch rather than being skipped, so the
+// operator sees the conflict instead of a silently short batch.
+func (s *service) SaveBatch(
+	ctx context.Context,
+	req *entity.PayrollBatchRequest,
+) (*entity.PayrollBatchDto, error) {
+	preview, err := s.SimulateBatch(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	runs := make([]*entity.SavePayrollRequest, 0, len(preview.Employees))
+	for i := range preview.Employees {
+		sim := &preview.Employees[i].SimulatePayrollResultDto
+		if sim.SessionCount == 0 {
+			continue
+		}
+		runs = append(runs, saveRequestFromSimulation(sim))
+	}
+	if len(runs) == 0 {
+		return nil, status.New(status.BadRequest, errors.New("no driver of the company has a session in the window"))
+	}
+
+	var batchID string
+	err = s.repo.Transaction(ctx, func(tx *gorm.DB) error {
+		repo := s.repo.WithTx(tx)
+		batch, err := repo.CreateBatch(ctx, &entity.PayrollBatchDto{
+			OrganizationID: shared.GetOrganization(ctx).ID,
+			CompanyID:      req.CompanyID,
+			StartDate:      req.StartDate,
+			EndDate:        req.EndDate,
+			CreatedBy:      actorOf(ctx),
+		})
+		if err != nil {
+			return err
+		}
+		for _, run := range runs {
+			if _, err := s.save(ctx, repo, run, batch.ID); err != nil {
+				return err
+			}
+		}
+		batchID = batch.ID
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetBatch(ctx, batchID)
+}
+
+func (s *service) GetBatch(ctx context.Context, id string) (*entity.PayrollBatchDto, error) {
+	batch, err := s.repo.GetBatch(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, status.New(status.EntityNotFound, err)
+		}
+		return nil, err
+	}
+	if batch.OrganizationID != shared.GetOrganization(ctx).ID {
+		return nil, status.New(status.EntityNotFound, errors.New("payroll batch not found"))
+	}
+	for _, r := range batch.Runs {
+		batch.TotalSalary += r.TotalSalary
+		batch.TotalCashReceipt += r.TotalCashReceipt
+		batch.TotalCashDebt += r.TotalCashDebt
+		batch.RemainingSalary += r.RemainingSalary
+	}
+	batch.TotalSalary = roundCents(batch.TotalSalary)
+	batch.TotalCashReceipt = roundCents(batch.TotalCashReceipt)
+	batch.TotalCashDebt = roundCents(batch.TotalCashDebt)
+	batch.RemainingSalary = roundCents(batch.RemainingSalary)
+	return batch, nil
+}
+
+// saveRequestFromSimulation builds the save payload the frontend
+// would send for a single run: one component per session and
+// non-zero component type, referencing the session, plus one
+// ATTENDANCE component for the window.
+func saveRequestFromSimulation(sim *entity.SimulatePayrollResultDto) *entity.SavePayrollRequest {
+	req := &entity.SavePayrollRequest{
+		AdminIDEmployee:      sim.AdminIDEmployee,
+		StartDate:            sim.StartDate,
+		EndDate:              sim.EndDate,
+		TotalMealAllowance:   sim.TotalMealAllowance,
+		TotalAttendance:      sim.TotalAttendance,
+		TotalCommission:      sim.TotalCommission,
+		TotalBonusTarget:     sim.TotalBonusTarget,
+		TotalSalary:          sim.TotalSalary,
+		TotalCashReceipt:     sim.TotalCashReceipt,
+		CashDebtDeductionCap: sim.CashDebtDeductionCap,
+		CashDebtDeductions:   sim.CashDebtDeductions,
+	}
+	for _, ss := range sim.Sessions {
+		// A slice, not a map, so every save lists a session's lines
+		// in the same order.
+		for _, c := range []struct {
+			componentType string
+			amount        float64
+		}{
+			{entity.EmployeeSalaryComponentTypeCommission, ss.Commission},
+			{entity.EmployeeSalaryComponentTypeMealAllowance, ss.MealAllowance},
+			{entity.EmployeeSalaryComponentTypeBonusTarget, ss.BonusTarget},
+		} {
+			if c.amount == 0 {
+				continue
+			}
+			req.Components = append(req.Components, entity.EmployeeSalaryComponentDto{
+				ComponentType: c.componentType,
+				Amount:        c.amount,
+				RefID:         ss.SessionID,
+				RefTable:      entity.AccountMutationRefTableStockSession,
+				RefSource:     entity.EmployeeSalaryRefSourceSales,
+			})
+		}
+	}
+	if sim.TotalAttendance != 0 {
+		req.Components = append(req.Components, entity.EmployeeSalaryComponentDto{
+			ComponentType: entity.EmployeeSalaryComponentTypeAttendance,
+			Amount:        sim.TotalAttendance,
+			RefTable:      entity.AttendanceRefTable,
+			RefSource:     entity.EmployeeSalaryRefSourceAttendance,
+		})
+	}
+	return req
+}
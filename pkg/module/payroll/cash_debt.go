@@ -0,0 +1,110 @@
+package payroll
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/response/status"
+	"gorm.io/gorm"
+)
+
+// proposeCashDebtDeductions spreads what the run may recover over
+// the unpaid advances, oldest first. The budget is the pay left
+// after the cash receipt, lowered to the operator cap when one is
+// set; the last advance it reaches may be recovered only in part.
+func proposeCashDebtDeductions(
+	debts []*entity.CashDebtDto,
+	payable float64,
+	limit *float64,
+) []entity.PayrollCashDebtDeductionDto {
+	budget := math.Max(payable, 0)
+	if limit != nil && *limit < budget {
+		budget = *limit
+	}
+	out := make([]entity.PayrollCashDebtDeductionDto, 0, len(debts))
+	for _, d := range debts {
+		if budget < 0.005 {
+			break
+		}
+		deduction := roundCents(math.Min(d.Outstanding, budget))
+		if deduction <= 0 {
+			continue
+		}
+		out = append(out, entity.PayrollCashDebtDeductionDto{
+			CashDebtID:  d.ID,
+			Date:        d.Date,
+			Amount:      d.Amount,
+			Outstanding: d.Outstanding,
+			Deduction:   deduction,
+		})
+		budget -= deduction
+	}
+	return out
+}
+
+// validateCashDebtDeductions re-checks the submitted deduction list
+// against the operator cap and the pay. Whose each advance is and
+// whether it still owes that much are checked by settleCashDebts.
+func validateCashDebtDeductions(req *entity.SavePayrollRequest) (float64, error) {
+	var total float64
+	seen := make(map[string]bool, len(req.CashDebtDeductions))
+	for _, d := range req.CashDebtDeductions {
+		if seen[d.CashDebtID] {
+			return 0, status.New(status.BadRequest, fmt.Errorf("cash debt %s is deducted twice", d.CashDebtID))
+		}
+		seen[d.CashDebtID] = true
+		total += d.Deduction
+	}
+	total = roundCents(total)
+	if req.CashDebtDeductionCap != nil && total > roundCents(*req.CashDebtDeductionCap) {
+		return 0, status.New(status.BadRequest, fmt.Errorf(
+			"cash debt deduction %.2f exceeds the cap of %.2f", total, *req.CashDebtDeductionCap,
+		))
+	}
+	if total > roundCents(req.TotalSalary-req.TotalCashReceipt) {
+		return 0, status.New(status.BadRequest, errors.New("cash debt deduction exceeds the salary left after the cash receipt"))
+	}
+	return total, nil
+}
+
+// settleCashDebts marks every deducted advance as repaid through
+// payroll, linked back to the saved employee_salary row. Runs in the
+// save transaction. Each advance must be the paid employee's own and
+// still owe money; one of another employee would otherwise be
+// repaid from this salary.
+func (s *service) settleCashDebts(
+	ctx context.Context,
+	tx *gorm.DB,
+	saved *entity.EmployeeSalaryDto,
+	deductions []entity.PayrollCashDebtDeductionDto,
+) error {
+	cashDebtService := s.cashDebtService.WithTx(tx)
+	for _, d := range deductions {
+		debt, err := cashDebtService.Get(ctx, d.CashDebtID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return status.New(status.BadRequest, fmt.Errorf("cash debt %s not found", d.CashDebtID))
+			}
+			return err
+		}
+		if debt.OrganizationID != saved.OrganizationID || debt.AdminIDEmployee != saved.AdminIDEmployee {
+			return status.New(status.BadRequest, fmt.Errorf(
+				"cash debt %s does not belong to this employee", d.CashDebtID,
+			))
+		}
+		if debt.SettledAt != nil || debt.Outstanding <= 0 {
+			return status.New(status.BadRequest, fmt.Errorf("cash debt %s is already settled", d.CashDebtID))
+		}
+		if _, err := cashDebtService.SettleByPayroll(ctx, d.CashDebtID, saved, d.Deduction); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func roundCents(v float64) float64 {
+	return math.Round(v*100) / 100
+}
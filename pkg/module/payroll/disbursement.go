@@ -0,0 +1,175 @@
+package payroll
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	shared "github.com/raymondsugiarto/coffee-api/pkg/shared/context"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/response/status"
+)
+
+// disbursementBanks maps admin.bank_code to the 3-digit bank code
+// and name the interbank columns ask for.
+var disbursementBanks = map[string]struct{ code, name string }{
+	entity.BankCodeBCA:     {"014", "BANK CENTRAL ASIA"},
+	entity.BankCodeMandiri: {"008", "BANK MANDIRI"},
+	entity.BankCodeBNI:     {"009", "BANK NEGARA INDONESIA"},
+	entity.BankCodeBRI:     {"002", "BANK RAKYAT INDONESIA"},
+}
+
+func (s *service) DisbursementCSV(
+	ctx context.Context,
+	req *entity.PayrollDisbursementRequest,
+) ([]byte, error) {
+	if req.TransferDate == "" {
+		req.TransferDate = time.Now().Format("2006-01-02")
+	}
+	transferDate, err := time.Parse("2006-01-02", req.TransferDate)
+	if err != nil {
+		return nil, status.New(status.BadRequest, err)
+	}
+
+	lines, err := s.repo.FindDisbursementLines(ctx, shared.GetOrganization(ctx).ID, req.BatchID, req.EmployeeSalaryIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return nil, status.New(status.EntityNotFound, errors.New("no payroll run matches the selection"))
+	}
+	if len(req.EmployeeSalaryIDs) > 0 && len(lines) != len(req.EmployeeSalaryIDs) {
+		return nil, status.New(status.BadRequest, errors.New("some payroll runs were not found"))
+	}
+
+	// Only runs that were approved are paid out; a run paid earlier
+	// can be exported again. Runs with nothing left to pay need no
+	// transfer and are left out.
+	var missingBank, notApproved []string
+	transfers := make([]entity.PayrollDisbursementLineDto, 0, len(lines))
+	for _, l := range lines {
+		if l.Status != entity.EmployeeSalaryStatusApproved && l.Status != entity.EmployeeSalaryStatusPaid {
+			notApproved = append(notApproved, l.EmployeeName+" ("+l.Status+")")
+			continue
+		}
+		if l.Amount <= 0 {
+			continue
+		}
+		if _, ok := disbursementBanks[l.BankCode]; !ok || l.BankAccountNumber == "" || l.BankAccountName == "" {
+			missingBank = append(missingBank, l.EmployeeName)
+			continue
+		}
+		transfers = append(transfers, l)
+	}
+	if len(notApproved) > 0 {
+		return nil, status.New(status.BadRequest, fmt.Errorf("payroll runs are not approved: %s", strings.Join(notApproved, ", ")))
+	}
+	if len(missingBank) > 0 {
+		return nil, status.New(status.BadRequest, fmt.Errorf("bank account details are missing for: %s", strings.Join(missingBank, ", ")))
+	}
+	if len(transfers) == 0 {
+		return nil, status.New(status.BadRequest, errors.New("no remaining salary to transfer"))
+	}
+
+	var records [][]string
+	switch req.Format {
+	case entity.PayrollDisbursementFormatBCA:
+		records = bcaDisbursementRecords(transfers)
+	case entity.PayrollDisbursementFormatMandiri:
+		records = mandiriDisbursementRecords(req.SourceAccountNumber, transferDate, transfers)
+	default:
+		return nil, status.New(status.BadRequest, fmt.Errorf("unknown disbursement format %s", req.Format))
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.WriteAll(records); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// disbursementRemark is the transfer note the driver sees on the
+// statement. Banks cut it short, so it only carries the period end.
+func disbursementRemark(l entity.PayrollDisbursementLineDto) string {
+	return "GAJI " + strings.ReplaceAll(l.EndDate, "-", "")
+}
+
+// bcaDisbursementRecords follows the KlikBCA Bisnis transfer massal
+// template: a header row, then one row per transfer. Transfers to a
+// BCA account go in-house (BCA), the rest through LLG with the
+// destination bank code.
+func bcaDisbursementRecords(lines []entity.PayrollDisbursementLineDto) [][]string {
+	records := [][]string{{
+		"No", "Transfer Type", "Credited Account", "Receiver Name", "Amount",
+		"Remark", "Receiver Bank Code", "Receiver Cust Type", "Receiver Cust Residence",
+	}}
+	for i, l := range lines {
+		transferType, bankCode, custType, residence := "BCA", "", "", ""
+		if l.BankCode != entity.BankCodeBCA {
+			// LLG needs the beneficiary type (1 = individual) and
+			// residence (1 = resident).
+			transferType, bankCode, custType, residence = "LLG", disbursementBanks[l.BankCode].code, "1", "1"
+		}
+		records = append(records, []string{
+			fmt.Sprint(i + 1),
+			transferType,
+			l.BankAccountNumber,
+			l.BankAccountName,
+			fmt.Sprintf("%.2f", l.Amount),
+			disbursementRemark(l),
+			bankCode,
+			custType,
+			residence,
+		})
+	}
+	return records
+}
+
+// mandiriDisbursementRecords follows the Mandiri Cash Management
+// bulk upload layout: a P header with the execution date, debit
+// account, record count and total, then one row per transfer.
+// Mandiri accounts are paid in-house (IBU), other banks online
+// (LBU) with the destination bank code and name.
+func mandiriDisbursementRecords(
+	sourceAccount string,
+	transferDate time.Time,
+	lines []entity.PayrollDisbursementLineDto,
+) [][]string {
+	var total float64
+	for _, l := range lines {
+		total += l.Amount
+	}
+	records := [][]string{{
+		"P",
+		transferDate.Format("20060102"),
+		sourceAccount,
+		fmt.Sprint(len(lines)),
+		fmt.Sprintf("%.2f", roundCents(total)),
+	}}
+	for _, l := range lines {
+		method, bankCode, bankName := "IBU", "", ""
+		if l.BankCode != entity.BankCodeMandiri {
+			bank := disbursementBanks[l.BankCode]
+			method, bankCode, bankName = "LBU", bank.code, bank.name
+		}
+		records = append(records, []string{
+			l.BankAccountNumber,
+			l.BankAccountName,
+			"", "", "", // beneficiary address lines
+			"IDR",
+			fmt.Sprintf("%.2f", l.Amount),
+			disbursementRemark(l),
+			l.EmployeeSalaryID,
+			method,
+			bankCode,
+			bankName,
+			"OUR", // transfer charges borne by the company
+		})
+	}
+	return records
+}
@@ -0,0 +1,171 @@
+package payroll
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	shared "github.com/raymondsugiarto/coffee-api/pkg/shared/context"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/response/status"
+	"gorm.io/gorm"
+)
+
+// The ledger side of the lifecycle, with the default rules of
+// migration 000024:
+//
+//	APPROVED  Dr Hutang Komisi / Beban Gaji   Cr Hutang Gaji
+//	PAID      Dr Hutang Gaji                  Cr Kas / Bank
+//	VOID      mirror of whatever was posted, plus the reversal of
+//	          the PAYROLL cash debt settlements made at save.
+//
+// A save posts PAYROLL_SAVED only for an organization that set up
+// rules for it; a void reverses that entry too. Like the save, the
+// approval and the payment are refused while the run's window
+// touches a closed accounting period.
+
+func (s *service) Approve(ctx context.Context, id string) (*entity.EmployeeSalaryDto, error) {
+	return s.transition(ctx, id, []string{entity.EmployeeSalaryStatusDraft},
+		func(tx *gorm.DB, run *entity.EmployeeSalaryDto) error {
+			if err := s.periodGuard.EnsureRangeOpen(ctx, run.StartDate, run.EndDate); err != nil {
+				return err
+			}
+			entry, err := s.postRun(ctx, tx, run, entity.PostingEventPayrollApproved, map[string]float64{
+				entity.PostingAmountTotalSalary:        run.TotalSalary,
+				entity.PostingAmountTotalCommission:    run.TotalCommission,
+				entity.PostingAmountTotalMealAllowance: run.TotalMealAllowance,
+				entity.PostingAmountTotalAttendance:    run.TotalAttendance,
+				entity.PostingAmountTotalBonusTarget:   run.TotalBonusTarget,
+			})
+			if err != nil {
+				return err
+			}
+			now := time.Now()
+			run.Status = entity.EmployeeSalaryStatusApproved
+			run.ApprovedAt = &now
+			run.ApprovedBy = actorOf(ctx)
+			run.AccrualJournalEntryID = entry
+			return nil
+		})
+}
+
+func (s *service) Pay(ctx context.Context, id string) (*entity.EmployeeSalaryDto, error) {
+	return s.transition(ctx, id, []string{entity.EmployeeSalaryStatusApproved},
+		func(tx *gorm.DB, run *entity.EmployeeSalaryDto) error {
+			if err := s.periodGuard.EnsureRangeOpen(ctx, run.StartDate, run.EndDate); err != nil {
+				return err
+			}
+			entry, err := s.postRun(ctx, tx, run, entity.PostingEventPayrollPaid, map[string]float64{
+				entity.PostingAmountTotalCashReceipt: run.TotalCashReceipt,
+				entity.PostingAmountRemainingSalary:  run.RemainingSalary,
+			})
+			if err != nil {
+				return err
+			}
+			now := time.Now()
+			run.Status = entity.EmployeeSalaryStatusPaid
+			run.PaidAt = &now
+			run.PaidBy = actorOf(ctx)
+			run.PaymentJournalEntryID = entry
+			return nil
+		})
+}
+
+func (s *service) Void(
+	ctx context.Context,
+	id string,
+	req *entity.PayrollVoidRequest,
+) (*entity.EmployeeSalaryDto, error) {
+	from := []string{
+		entity.EmployeeSalaryStatusDraft,
+		entity.EmployeeSalaryStatusApproved,
+		entity.EmployeeSalaryStatusPaid,
+	}
+	return s.transition(ctx, id, from, func(tx *gorm.DB, run *entity.EmployeeSalaryDto) error {
+		journalEntryService := s.journalEntryService.WithTx(tx)
+		description := fmt.Sprintf("Payroll %s - %s voided: %s", run.StartDate, run.EndDate, req.Reason)
+		// Undo in the reverse order of posting.
+		for _, entryID := range []string{run.PaymentJournalEntryID, run.AccrualJournalEntryID, run.SaveJournalEntryID} {
+			if entryID == "" {
+				continue
+			}
+			if _, err := journalEntryService.Reverse(ctx, entryID, &entity.JournalEntryReverseRequest{
+				Description: description,
+			}); err != nil {
+				return err
+			}
+		}
+		if err := s.cashDebtService.WithTx(tx).ReverseSettlements(
+			ctx, entity.CashDebtSettlementRefTableEmployeeSalary, run.ID, req.Reason,
+		); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		run.Status = entity.EmployeeSalaryStatusVoid
+		run.VoidedAt = &now
+		run.VoidedBy = actorOf(ctx)
+		run.VoidReason = req.Reason
+		return nil
+	})
+}
+
+// transition runs one lifecycle step on a run of the caller's
+// organization and maps the repository errors onto API statuses.
+func (s *service) transition(
+	ctx context.Context,
+	id string,
+	from []string,
+	apply func(tx *gorm.DB, run *entity.EmployeeSalaryDto) error,
+) (*entity.EmployeeSalaryDto, error) {
+	orgID := shared.GetOrganization(ctx).ID
+	result, err := s.repo.Transition(ctx, id, from, func(tx *gorm.DB, run *entity.EmployeeSalaryDto) error {
+		if run.OrganizationID != orgID {
+			return gorm.ErrRecordNotFound
+		}
+		return apply(tx, run)
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, status.New(status.EntityNotFound, errors.New("payroll run not found"))
+		case errors.Is(err, ErrPayrollInvalidTransition):
+			return nil, status.New(status.BadRequest, err)
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+// postRun fires a payroll event inside the transition transaction
+// and returns the id of the journal entry it produced ("" when the
+// rules post nothing).
+func (s *service) postRun(
+	ctx context.Context,
+	tx *gorm.DB,
+	run *entity.EmployeeSalaryDto,
+	eventType string,
+	amounts map[string]float64,
+) (string, error) {
+	entry, err := s.postingService.WithTx(tx).PostEvent(ctx, &entity.PostingEventDto{
+		OrganizationID: run.OrganizationID,
+		EventType:      eventType,
+		RefID:          run.ID,
+		RefTable:       entity.AccountMutationRefTablePayroll,
+		RefModule:      entity.AccountMutationRefModulePayroll,
+		Description:    fmt.Sprintf("Payroll %s - %s", run.StartDate, run.EndDate),
+		Amounts:        amounts,
+	})
+	if err != nil || entry == nil {
+		return "", err
+	}
+	return entry.ID, nil
+}
+
+func actorOf(ctx context.Context) string {
+	if cred := shared.GetUserCredential(ctx); cred != nil {
+		return cred.AdminID
+	}
+	return ""
+}
@@ -0,0 +1,152 @@
+package payroll
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/johnfercher/maroto/v2"
+	"github.com/johnfercher/maroto/v2/pkg/components/col"
+	"github.com/johnfercher/maroto/v2/pkg/components/line"
+	"github.com/johnfercher/maroto/v2/pkg/components/text"
+	"github.com/johnfercher/maroto/v2/pkg/config"
+	"github.com/johnfercher/maroto/v2/pkg/consts/align"
+	"github.com/johnfercher/maroto/v2/pkg/consts/fontstyle"
+	"github.com/johnfercher/maroto/v2/pkg/core"
+	"github.com/johnfercher/maroto/v2/pkg/props"
+	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	shared "github.com/raymondsugiarto/coffee-api/pkg/shared/context"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/response/status"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/utils"
+	"gorm.io/gorm"
+)
+
+var payslipComponentLabels = map[string]string{
+	entity.EmployeeSalaryComponentTypeCommission:        "Komisi",
+	entity.EmployeeSalaryComponentTypeMealAllowance:     "Uang Makan",
+	entity.EmployeeSalaryComponentTypeAttendance:        "Kehadiran",
+	entity.EmployeeSalaryComponentTypeBonusTarget:       "Bonus Target",
+	entity.EmployeeSalaryComponentTypeCashDebtDeduction: "Potongan Kas Bon",
+}
+
+var payslipShiftLabels = map[string]string{
+	entity.StockSessionShiftMorning: "Pagi",
+	entity.StockSessionShiftEvening: "Sore",
+}
+
+// formatPayslipAmount formats with Indonesian separators; deductions
+// are shown in parentheses.
+func formatPayslipAmount(v float64) string {
+	if v < 0 {
+		return "(" + utils.FormatIndonesianNumber(-v) + ")"
+	}
+	return utils.FormatIndonesianNumber(v)
+}
+
+func (s *service) PayslipPDF(ctx context.Context, id string) ([]byte, error) {
+	slip, err := s.repo.FindPayslip(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, status.New(status.EntityNotFound, err)
+		}
+		return nil, err
+	}
+	if slip.Run.OrganizationID != shared.GetOrganization(ctx).ID {
+		return nil, status.New(status.EntityNotFound, errors.New("payroll run not found"))
+	}
+	run := slip.Run
+
+	m := maroto.New(config.NewBuilder().WithPageNumber().Build())
+	m.AddRows(
+		text.NewRow(8, "Slip Gaji", props.Text{Size: 14, Style: fontstyle.Bold, Align: align.Center}),
+		text.NewRow(6, "Periode "+run.StartDate+" s/d "+run.EndDate, props.Text{Size: 9, Align: align.Center}),
+		line.NewRow(4),
+	)
+	label := props.Text{Size: 9}
+	m.AddRow(6, text.NewCol(3, "Nama", label), text.NewCol(9, ": "+slip.EmployeeName, label))
+	m.AddRow(6, text.NewCol(3, "Status", label), text.NewCol(9, ": "+run.Status, label))
+	m.AddRows(line.NewRow(4))
+
+	// Components as saved, with where each came from. Session
+	// components are referenced by the session date.
+	sessionDates := make(map[string]string, len(slip.Sessions))
+	for _, ss := range slip.Sessions {
+		sessionDates[ss.SessionID] = ss.Date
+	}
+	m.AddRows(text.NewRow(6, "Rincian Komponen", props.Text{Size: 10, Style: fontstyle.Bold}))
+	payslipTableRow(m, fontstyle.Bold, []int{4, 3, 2, 3}, 3, "Komponen", "Sumber", "Referensi", "Jumlah")
+	for _, c := range run.Components {
+		amount := c.Amount
+		if c.ComponentType == entity.EmployeeSalaryComponentTypeCashDebtDeduction {
+			amount = -amount
+		}
+		ref := sessionDates[c.RefID]
+		if ref == "" {
+			ref = c.RefTable
+		}
+		name := payslipComponentLabels[c.ComponentType]
+		if name == "" {
+			name = c.ComponentType
+		}
+		payslipTableRow(m, fontstyle.Normal, []int{4, 3, 2, 3}, 3, name, c.RefSource, ref, formatPayslipAmount(amount))
+	}
+	m.AddRows(line.NewRow(4))
+
+	for _, t := range []struct {
+		style  fontstyle.Type
+		label  string
+		amount float64
+	}{
+		{fontstyle.Normal, "Total Komisi", run.TotalCommission},
+		{fontstyle.Normal, "Total Uang Makan", run.TotalMealAllowance},
+		{fontstyle.Normal, "Total Kehadiran", run.TotalAttendance},
+		{fontstyle.Normal, "Total Bonus Target", run.TotalBonusTarget},
+		{fontstyle.Bold, "Total Gaji", run.TotalSalary},
+		{fontstyle.Normal, "Potongan Setoran Tunai", -run.TotalCashReceipt},
+		{fontstyle.Normal, "Potongan Kas Bon", -run.TotalCashDebt},
+		{fontstyle.Bold, "Sisa Gaji Dibayar", run.RemainingSalary},
+	} {
+		m.AddRow(6,
+			text.NewCol(6, t.label, props.Text{Size: 9, Style: t.style}),
+			col.New(3),
+			text.NewCol(3, formatPayslipAmount(t.amount), props.Text{Size: 9, Style: t.style, Align: align.Right}),
+		)
+	}
+
+	if len(slip.Sessions) > 0 {
+		m.AddRows(line.NewRow(4), text.NewRow(6, "Rincian per Sesi", props.Text{Size: 10, Style: fontstyle.Bold}))
+		widths := []int{2, 2, 2, 2, 1, 1, 2}
+		payslipTableRow(m, fontstyle.Bold, widths, 1, "Tanggal", "Penjualan", "Komisi", "Uang Makan", "Hadir", "Bonus", "Total")
+		for _, ss := range slip.Sessions {
+			payslipTableRow(m, fontstyle.Normal, widths, 1,
+				strings.TrimSpace(ss.Date+" "+payslipShiftLabels[ss.Shift]),
+				formatPayslipAmount(ss.TotalSales),
+				formatPayslipAmount(ss.Commission),
+				formatPayslipAmount(ss.MealAllowance),
+				formatPayslipAmount(ss.Attendance),
+				formatPayslipAmount(ss.BonusTarget),
+				formatPayslipAmount(ss.TotalSalary),
+			)
+		}
+	}
+
+	doc, err := m.Generate()
+	if err != nil {
+		return nil, err
+	}
+	return doc.GetBytes(), nil
+}
+
+// payslipTableRow adds one table row. Cells from index rightFrom on
+// hold amounts and are right aligned.
+func payslipTableRow(m core.Maroto, style fontstyle.Type, widths []int, rightFrom int, cells ...string) {
+	cols := make([]core.Col, 0, len(cells))
+	for i, v := range cells {
+		p := props.Text{Size: 8, Style: style}
+		if i >= rightFrom {
+			p.Align = align.Right
+		}
+		cols = append(cols, text.NewCol(widths[i], v, p))
+	}
+	m.AddRow(6, cols...)
+}
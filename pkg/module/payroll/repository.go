@@ -2,31 +2,88 @@ package payroll
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/raymondsugiarto/coffee-api/pkg/entity"
 	"github.com/raymondsugiarto/coffee-api/pkg/model"
 	"github.com/raymondsugiarto/coffee-api/pkg/shared/pagination"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ErrPayrollOverlap is returned by Save when the employee already
+// has a live (non-VOID) run whose window overlaps the new one.
+var ErrPayrollOverlap = errors.New("payroll window overlaps an existing run for this employee")
+
+// ErrPayrollInvalidTransition is returned by Transition when the
+// run is not in one of the states the action starts from.
+var ErrPayrollInvalidTransition = errors.New("payroll run cannot make this transition")
+
 type Repository interface {
+	// WithTx returns a repository bound to the caller's transaction.
+	WithTx(tx *gorm.DB) Repository
+	// Transaction runs fn in one transaction; repositories bound
+	// with WithTx(tx) inside it commit or roll back together.
+	Transaction(ctx context.Context, fn func(tx *gorm.DB) error) error
+
 	// Simulate returns the per-session evidence + rolled totals for
-	// the given employee/date-range. Read-only.
+	// the given employee/date-range. Read-only. daysWorked is the
+	// attendance count the ATTENDANCE bands are evaluated against.
 	Simulate(
 		ctx context.Context,
 		adminID string,
 		startDate, endDate time.Time,
+		daysWorked int,
 	) (*entity.SimulatePayrollResultDto, error)
 
-	// Save persists the header + components atomically.
-	Save(ctx context.Context, dto *entity.EmployeeSalaryDto) (*entity.EmployeeSalaryDto, error)
+	// Save persists the header + components atomically. afterSave
+	// runs inside the same transaction with the saved run; an error
+	// from it rolls the whole save back. afterSave may set
+	// saved.SaveJournalEntryID; it is stored on the run.
+	Save(
+		ctx context.Context,
+		dto *entity.EmployeeSalaryDto,
+		afterSave func(tx *gorm.DB, saved *entity.EmployeeSalaryDto) error,
+	) (*entity.EmployeeSalaryDto, error)
 
 	// FindAll paginates saved payroll runs.
 	FindAll(ctx context.Context, req *entity.EmployeeSalaryFindAllRequest) (*pagination.ResultPagination, error)
 
 	// FindOne returns a single payroll run with its components.
 	FindOne(ctx context.Context, id string) (*entity.EmployeeSalaryDto, error)
+
+	// Transition moves a run between states. The row is locked and
+	// must be in one of `from`; apply then updates the lifecycle
+	// fields of `run` (and may post to the ledger on tx), which are
+	// written back before commit.
+	Transition(
+		ctx context.Context,
+		id string,
+		from []string,
+		apply func(tx *gorm.DB, run *entity.EmployeeSalaryDto) error,
+	) (*entity.EmployeeSalaryDto, error)
+
+	// FindCompanyEmployees lists the drivers bound to a company
+	// through admin_company.
+	FindCompanyEmployees(ctx context.Context, organizationID, companyID string) ([]*entity.AdminDto, error)
+	CreateBatch(ctx context.Context, dto *entity.PayrollBatchDto) (*entity.PayrollBatchDto, error)
+	// GetBatch returns the batch header with its runs' totals.
+	GetBatch(ctx context.Context, id string) (*entity.PayrollBatchDto, error)
+
+	// FindPayslip returns a run with the driver's name and its
+	// per-session breakdown, rebuilt from the saved components.
+	FindPayslip(ctx context.Context, id string) (*entity.PayslipDto, error)
+
+	// FindDisbursementLines returns the runs of a batch (or the
+	// listed runs) with the driver's bank details, ordered by name.
+	FindDisbursementLines(
+		ctx context.Context,
+		organizationID, batchID string,
+		ids []string,
+	) ([]entity.PayrollDisbursementLineDto, error)
 }
 
 type repository struct {
@@ -37,25 +94,35 @@ func NewRepository(db *gorm.DB) Repository {
 	return &repository{db: db}
 }
 
+func (r *repository) WithTx(tx *gorm.DB) Repository {
+	return &repository{db: tx}
+}
+
+func (r *repository) Transaction(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	return r.db.WithContext(ctx).Transaction(fn)
+}
+
 func (r *repository) Simulate(
 	ctx context.Context,
 	adminID string,
 	startDate, endDate time.Time,
+	daysWorked int,
 ) (*entity.SimulatePayrollResultDto, error) {
 	var sessions []model.StockSession
 	if err := r.db.WithContext(ctx).
 		Where("employee_id = ? AND date >= ? AND date <= ?", adminID, startDate, endDate).
-		Order("date ASC").
+		Order("date ASC, opened_at ASC").
 		Find(&sessions).Error; err != nil {
 		return nil, err
 	}
 
-	// Resolve the employee's company + the salary_component rows
-	// once for this run. The per-session breakdown is then derived
-	// live from the SAME rule that RecomputeSalary would have
-	// computed at close time, so a historic session whose
-	// persisted snapshot is stale (e.g. salary_component was added
-	// later) still reports the right number on a fresh simulate.
+	// Resolve the employee's company + every salary_component
+	// version overlapping the window once for this run. Each
+	// session's breakdown is then derived live with the SAME rule
+	// RecomputeSalary applies at close time, using the versions
+	// valid on the session date, so a historic session whose
+	// persisted snapshot is stale still reports what a fresh close
+	// would produce, and a band edited later does not leak into it.
 	//
 	// Failure paths (no company binding / no salary_component
 	// rows) silently fall back to zero — same as the runtime close
@@ -74,6 +141,8 @@ func (r *repository) Simulate(
 		var rows []model.SalaryComponent
 		if err := r.db.WithContext(ctx).
 			Where("company_id = ?", companyID).
+			Where("effective_from <= ? AND (effective_to IS NULL OR effective_to >= ?)", endDate, startDate).
+			Order("minimum_target ASC, component_type ASC").
 			Find(&rows).Error; err == nil {
 			for i := range rows {
 				components = append(components, *entity.NewSalaryComponentDtoFromModel(&rows[i]))
@@ -108,6 +177,24 @@ func (r *repository) Simulate(
 		}
 	}
 
+	// Meal allowance, bonus target and the minimum-target commission
+	// are earned per working day, not per shift: a day's sessions
+	// are added up and the components valid on that date applied
+	// once to the sum.
+	type day struct {
+		items      int
+		commission float64
+	}
+	days := make(map[string]*day)
+	for _, ss := range sessions {
+		sessionDate := ss.Date.Format("2006-01-02")
+		if days[sessionDate] == nil {
+			days[sessionDate] = &day{}
+		}
+		days[sessionDate].items += ss.TotalItems
+		days[sessionDate].commission += ss.TotalCommission
+	}
+
 	out := &entity.SimulatePayrollResultDto{
 		AdminIDEmployee: adminID,
 		StartDate:       startDate.Format("2006-01-02"),
@@ -117,11 +204,14 @@ func (r *repository) Simulate(
 	}
 	for _, ss := range sessions {
 		// Attach every cash_debt row whose date matches this
-		// session's date. A session that happens on a date with no
+		// session's date to the day's first shift; the advances
+		// are taken off the map so an EVENING session on the same
+		// day does not count them again. A session without
 		// advances still gets an empty (non-nil) slice so the
 		// frontend can render an empty state without null-checks.
 		sessionDate := ss.Date.Format("2006-01-02")
 		debtsForSession := cashDebtByDate[sessionDate]
+		delete(cashDebtByDate, sessionDate)
 		debtDtos := make([]entity.SimulatePayrollSessionCashDebtDto, 0, len(debtsForSession))
 		var sessionDebtTotal float64
 		for _, d := range debtsForSession {
@@ -129,39 +219,60 @@ func (r *repository) Simulate(
 			sessionDebtTotal += d.Amount
 		}
 
+		// The day's components land on its first shift, the same
+		// way its advances do; a later shift only adds its own
+		// commission.
+		salary := &entity.StockSessionDto{TotalCommission: ss.TotalCommission}
+		if d, first := days[sessionDate]; first {
+			delete(days, sessionDate)
+			daily := &entity.StockSessionDto{TotalCommission: d.commission}
+			daily.RecomputeSalary(entity.SalaryComponentsOn(components, sessionDate), d.items)
+			salary.MinTargetCommission = daily.MinTargetCommission
+			salary.MealAllowance = daily.MealAllowance
+			salary.BonusTarget = daily.BonusTarget
+		}
+		salary.TotalSalary = ss.TotalCommission - salary.MinTargetCommission + salary.MealAllowance + salary.BonusTarget
+
 		out.Sessions = append(out.Sessions, entity.SimulatePayrollSessionDto{
-			SessionID:     ss.ID,
-			Date:          sessionDate,
-			Status:        ss.Status,
-			TotalSales:    ss.TotalSales,
-			Attendance:    ss.Attendance,
-			Commission:    ss.TotalCommission - ss.MinTargetCommission,
-			MealAllowance: ss.MealAllowance,
-			BonusTarget:   ss.BonusTarget,
-			TotalSalary:   ss.TotalSalary,
-			CashDebts:     debtDtos,
+			SessionID:           ss.ID,
+			Date:                sessionDate,
+			Shift:               string(ss.Shift),
+			Status:              ss.Status,
+			TotalSales:          ss.TotalSales,
+			Attendance:          salary.Attendance,
+			MinTargetCommission: salary.MinTargetCommission,
+			Commission:          ss.TotalCommission - salary.MinTargetCommission,
+			MealAllowance:       salary.MealAllowance,
+			BonusTarget:         salary.BonusTarget,
+			TotalSalary:         salary.TotalSalary,
+			CashDebts:           debtDtos,
 		})
-		out.TotalCommission += ss.TotalCommission - ss.MinTargetCommission
-		out.TotalMealAllowance += ss.MealAllowance
-		out.TotalBonusTarget += ss.BonusTarget
-		out.TotalAttendance += ss.Attendance
-		out.TotalSalary += ss.TotalSalary
-
-		// If the same cash_debt row falls on a date that has two
-		// sessions (rare: half-day split), it would be counted twice
-		// here. We accept the duplication as the conservative choice
-		// so the operator can see the row attached to BOTH closes.
+		out.TotalCommission += ss.TotalCommission - salary.MinTargetCommission
+		out.TotalMealAllowance += salary.MealAllowance
+		out.TotalBonusTarget += salary.BonusTarget
+		out.TotalAttendance += salary.Attendance
+		out.TotalSalary += salary.TotalSalary
 		out.TotalCashDebt += sessionDebtTotal
-		out.RemainingSalary += ss.TotalSalary - sessionDebtTotal
+		out.RemainingSalary += salary.TotalSalary - sessionDebtTotal
 	}
+
+	// Attendance is earned over the window, not per session: the
+	// days worked clear the ATTENDANCE band valid on the end date.
+	out.DaysWorked = daysWorked
+	out.TotalAttendance = entity.ResolveAttendance(
+		entity.SalaryComponentsOn(components, out.EndDate), daysWorked,
+	)
+	out.TotalSalary += out.TotalAttendance
+	out.RemainingSalary += out.TotalAttendance
 	return out, nil
 }
 
 func (r *repository) Save(
 	ctx context.Context,
 	dto *entity.EmployeeSalaryDto,
+	afterSave func(tx *gorm.DB, saved *entity.EmployeeSalaryDto) error,
 ) (*entity.EmployeeSalaryDto, error) {
-	return r.saveTx(ctx, dto)
+	return r.saveTx(ctx, dto, afterSave)
 }
 
 // saveTx wraps the header + components in a transaction so the
@@ -169,9 +280,26 @@ func (r *repository) Save(
 func (r *repository) saveTx(
 	ctx context.Context,
 	dto *entity.EmployeeSalaryDto,
+	afterSave func(tx *gorm.DB, saved *entity.EmployeeSalaryDto) error,
 ) (*entity.EmployeeSalaryDto, error) {
 	var out *entity.EmployeeSalaryDto
 	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Serialise saves per employee so two concurrent runs cannot
+		// both pass the overlap check.
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "payroll:"+dto.AdminIDEmployee).Error; err != nil {
+			return err
+		}
+		var overlapping int64
+		if err := tx.Model(&model.EmployeeSalary{}).
+			Where("admin_id_employee = ? AND status <> ?", dto.AdminIDEmployee, entity.EmployeeSalaryStatusVoid).
+			Where("start_date <= ? AND end_date >= ?", dto.EndDate, dto.StartDate).
+			Count(&overlapping).Error; err != nil {
+			return err
+		}
+		if overlapping > 0 {
+			return ErrPayrollOverlap
+		}
+
 		header := dto.ToModel()
 		if err := tx.Create(header).Error; err != nil {
 			return err
@@ -192,6 +320,14 @@ func (r *repository) saveTx(
 		for i := range components {
 			out.Components = append(out.Components, *entity.NewEmployeeSalaryComponentDtoFromModel(&components[i]))
 		}
+		if afterSave != nil {
+			if err := afterSave(tx, out); err != nil {
+				return err
+			}
+			if out.SaveJournalEntryID != "" {
+				return tx.Model(header).Update("save_journal_entry_id", out.SaveJournalEntryID).Error
+			}
+		}
 		return nil
 	})
 	if err != nil {
@@ -230,6 +366,12 @@ func (r *repository) FindAll(
 		if req.AdminIDEmployee != "" {
 			q = q.Where("admin_id_employee = ?", req.AdminIDEmployee)
 		}
+		if req.Status != "" {
+			q = q.Where("status = ?", req.Status)
+		}
+		if req.BatchID != "" {
+			q = q.Where("batch_id = ?", req.BatchID)
+		}
 		return q
 	}, &pagination.TableRequest{
 		Request:       req,
@@ -254,3 +396,236 @@ func (r *repository) FindAll(
 		TotalPages:  result.TotalPages,
 	}, nil
 }
+
+func (r *repository) Transition(
+	ctx context.Context,
+	id string,
+	from []string,
+	apply func(tx *gorm.DB, run *entity.EmployeeSalaryDto) error,
+) (*entity.EmployeeSalaryDto, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var m model.EmployeeSalary
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", id).
+			First(&m).Error; err != nil {
+			return err
+		}
+		allowed := false
+		for _, st := range from {
+			if m.Status == st {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("%w: run is %s", ErrPayrollInvalidTransition, m.Status)
+		}
+
+		run := entity.NewEmployeeSalaryDtoFromModel(&m)
+		if err := apply(tx, run); err != nil {
+			return err
+		}
+		updated := run.ToModel()
+		return tx.Model(updated).
+			Select(
+				"status", "approved_at", "approved_by", "paid_at", "paid_by",
+				"voided_at", "voided_by", "void_reason",
+				"accrual_journal_entry_id", "payment_journal_entry_id", "updated_at",
+			).
+			Updates(updated).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.FindOne(ctx, id)
+}
+
+func (r *repository) FindCompanyEmployees(
+	ctx context.Context,
+	organizationID, companyID string,
+) ([]*entity.AdminDto, error) {
+	var rows []model.Admin
+	if err := r.db.WithContext(ctx).
+		Model(&model.Admin{}).
+		Joins("JOIN admin_company ac ON ac.admin_id = admin.id AND ac.deleted_at IS NULL").
+		Where("ac.company_id = ? AND ac.organization_id = ?", companyID, organizationID).
+		Where("admin.admin_type = ?", entity.AdminTypeEmployee).
+		Order("admin.first_name ASC, admin.last_name ASC").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]*entity.AdminDto, 0, len(rows))
+	for i := range rows {
+		out = append(out, new(entity.AdminDto).FromModel(&rows[i]))
+	}
+	return out, nil
+}
+
+func (r *repository) CreateBatch(ctx context.Context, dto *entity.PayrollBatchDto) (*entity.PayrollBatchDto, error) {
+	m := dto.ToModel()
+	if err := r.db.WithContext(ctx).Create(m).Error; err != nil {
+		return nil, err
+	}
+	return entity.NewPayrollBatchDtoFromModel(m), nil
+}
+
+func (r *repository) GetBatch(ctx context.Context, id string) (*entity.PayrollBatchDto, error) {
+	var m model.PayrollBatch
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&m).Error; err != nil {
+		return nil, err
+	}
+	out := entity.NewPayrollBatchDtoFromModel(&m)
+
+	type runRow struct {
+		ID               string
+		AdminIDEmployee  string
+		FirstName        string
+		LastName         string
+		Status           string
+		TotalSalary      float64
+		TotalCashReceipt float64
+		TotalCashDebt    float64
+		RemainingSalary  float64
+	}
+	var rows []runRow
+	if err := r.db.WithContext(ctx).
+		Table("employee_salary es").
+		Select(`es.id, es.admin_id_employee, a.first_name, a.last_name, es.status,
+		        es.total_salary, es.total_cash_receipt, es.total_cash_debt, es.remaining_salary`).
+		Joins("LEFT JOIN admin a ON a.id = es.admin_id_employee").
+		Where("es.batch_id = ? AND es.deleted_at IS NULL", id).
+		Order("a.first_name ASC, a.last_name ASC").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	out.Runs = make([]entity.PayrollBatchRunDto, 0, len(rows))
+	for _, row := range rows {
+		out.Runs = append(out.Runs, entity.PayrollBatchRunDto{
+			EmployeeSalaryID: row.ID,
+			AdminIDEmployee:  row.AdminIDEmployee,
+			EmployeeName:     strings.TrimSpace(row.FirstName + " " + row.LastName),
+			Status:           row.Status,
+			TotalSalary:      row.TotalSalary,
+			TotalCashReceipt: row.TotalCashReceipt,
+			TotalCashDebt:    row.TotalCashDebt,
+			RemainingSalary:  row.RemainingSalary,
+		})
+	}
+	return out, nil
+}
+
+func (r *repository) FindPayslip(ctx context.Context, id string) (*entity.PayslipDto, error) {
+	run, err := r.FindOne(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	out := &entity.PayslipDto{Run: run}
+
+	var admin model.Admin
+	if err := r.db.WithContext(ctx).Where("id = ?", run.AdminIDEmployee).First(&admin).Error; err == nil {
+		out.EmployeeName = strings.TrimSpace(admin.FirstName + " " + admin.LastName)
+	}
+
+	// Amounts come from the components rather than the session row:
+	// the payslip shows what this run paid, not what a fresh close
+	// would compute today.
+	type sessionRow struct {
+		SessionID     string
+		Date          time.Time
+		Shift         string
+		Status        string
+		TotalSales    float64
+		Commission    float64
+		MealAllowance float64
+		Attendance    float64
+		BonusTarget   float64
+	}
+	var rows []sessionRow
+	if err := r.db.WithContext(ctx).
+		Table("employee_salary_component c").
+		Select(`ss.id AS session_id, ss.date, ss.shift, ss.status, ss.total_sales,
+		        SUM(CASE WHEN c.component_type = ? THEN c.amount ELSE 0 END) AS commission,
+		        SUM(CASE WHEN c.component_type = ? THEN c.amount ELSE 0 END) AS meal_allowance,
+		        SUM(CASE WHEN c.component_type = ? THEN c.amount ELSE 0 END) AS attendance,
+		        SUM(CASE WHEN c.component_type = ? THEN c.amount ELSE 0 END) AS bonus_target`,
+			entity.EmployeeSalaryComponentTypeCommission,
+			entity.EmployeeSalaryComponentTypeMealAllowance,
+			entity.EmployeeSalaryComponentTypeAttendance,
+			entity.EmployeeSalaryComponentTypeBonusTarget).
+		Joins("JOIN stock_session ss ON ss.id = c.ref_id").
+		Where("c.employee_salary_id = ? AND c.ref_table = ? AND c.deleted_at IS NULL",
+			id, entity.AccountMutationRefTableStockSession).
+		Group("ss.id, ss.date, ss.shift, ss.status, ss.total_sales, ss.opened_at").
+		Order("ss.date ASC, ss.opened_at ASC").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	out.Sessions = make([]entity.SimulatePayrollSessionDto, 0, len(rows))
+	for _, row := range rows {
+		out.Sessions = append(out.Sessions, entity.SimulatePayrollSessionDto{
+			SessionID:     row.SessionID,
+			Date:          row.Date.Format("2006-01-02"),
+			Shift:         row.Shift,
+			Status:        row.Status,
+			TotalSales:    row.TotalSales,
+			Commission:    row.Commission,
+			MealAllowance: row.MealAllowance,
+			Attendance:    row.Attendance,
+			BonusTarget:   row.BonusTarget,
+			TotalSalary:   row.Commission + row.MealAllowance + row.Attendance + row.BonusTarget,
+		})
+	}
+	return out, nil
+}
+
+func (r *repository) FindDisbursementLines(
+	ctx context.Context,
+	organizationID, batchID string,
+	ids []string,
+) ([]entity.PayrollDisbursementLineDto, error) {
+	type lineRow struct {
+		ID                string
+		Status            string
+		StartDate         time.Time
+		EndDate           time.Time
+		AdminIDEmployee   string
+		FirstName         string
+		LastName          string
+		BankCode          string
+		BankAccountNumber string
+		BankAccountName   string
+		RemainingSalary   float64
+	}
+	q := r.db.WithContext(ctx).
+		Table("employee_salary es").
+		Select(`es.id, es.status, es.start_date, es.end_date, es.admin_id_employee, es.remaining_salary,
+		        a.first_name, a.last_name, a.bank_code, a.bank_account_number, a.bank_account_name`).
+		Joins("LEFT JOIN admin a ON a.id = es.admin_id_employee").
+		Where("es.organization_id = ? AND es.deleted_at IS NULL", organizationID)
+	if batchID != "" {
+		q = q.Where("es.batch_id = ?", batchID)
+	}
+	if len(ids) > 0 {
+		q = q.Where("es.id IN ?", ids)
+	}
+	var rows []lineRow
+	if err := q.Order("a.first_name ASC, a.last_name ASC, es.start_date ASC").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]entity.PayrollDisbursementLineDto, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, entity.PayrollDisbursementLineDto{
+			EmployeeSalaryID:  row.ID,
+			Status:            row.Status,
+			StartDate:         row.StartDate.Format("2006-01-02"),
+			EndDate:           row.EndDate.Format("2006-01-02"),
+			AdminIDEmployee:   row.AdminIDEmployee,
+			EmployeeName:      strings.TrimSpace(row.FirstName + " " + row.LastName),
+			BankCode:          row.BankCode,
+			BankAccountNumber: row.BankAccountNumber,
+			BankAccountName:   row.BankAccountName,
+			Amount:            row.RemainingSalary,
+		})
+	}
+	return out, nil
+}
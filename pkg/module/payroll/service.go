@@ -2,11 +2,17 @@ package payroll
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	accounting "github.com/raymondsugiarto/coffee-api/pkg/module/accounting"
+	"github.com/raymondsugiarto/coffee-api/pkg/module/attendance"
+	cashdebt "github.com/raymondsugiarto/coffee-api/pkg/module/cash_debt"
 	shared "github.com/raymondsugiarto/coffee-api/pkg/shared/context"
 	"github.com/raymondsugiarto/coffee-api/pkg/shared/pagination"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/response/status"
+	"gorm.io/gorm"
 )
 
 type Service interface {
@@ -14,44 +20,181 @@ type Service interface {
 	Save(ctx context.Context, req *entity.SavePayrollRequest) (*entity.EmployeeSalaryDto, error)
 	FindAll(ctx context.Context, req *entity.EmployeeSalaryFindAllRequest) (*pagination.ResultPagination, error)
 	FindOne(ctx context.Context, id string) (*entity.EmployeeSalaryDto, error)
+	// Approve moves a DRAFT run to APPROVED and accrues it in the
+	// ledger (PAYROLL_APPROVED).
+	Approve(ctx context.Context, id string) (*entity.EmployeeSalaryDto, error)
+	// Pay moves an APPROVED run to PAID and books the payout
+	// (PAYROLL_PAID).
+	Pay(ctx context.Context, id string) (*entity.EmployeeSalaryDto, error)
+	// Void cancels a run in any live state: its ledger entries are
+	// reversed and the cash debt it recovered is owed again.
+	Void(ctx context.Context, id string, req *entity.PayrollVoidRequest) (*entity.EmployeeSalaryDto, error)
+
+	// SimulateBatch runs Simulate for every driver of a company.
+	SimulateBatch(ctx context.Context, req *entity.PayrollBatchRequest) (*entity.PayrollBatchSimulateResultDto, error)
+	// SaveBatch saves one DRAFT run per driver with sessions in the
+	// window, all in one transaction: any failing run rolls the
+	// whole batch back.
+	SaveBatch(ctx context.Context, req *entity.PayrollBatchRequest) (*entity.PayrollBatchDto, error)
+	// GetBatch returns a batch with its per-driver totals.
+	GetBatch(ctx context.Context, id string) (*entity.PayrollBatchDto, error)
+
+	// PayslipPDF renders the printable payslip of one run.
+	PayslipPDF(ctx context.Context, id string) ([]byte, error)
+
+	// DisbursementCSV builds the bank bulk-transfer file paying the
+	// remaining salary of the selected runs.
+	DisbursementCSV(ctx context.Context, req *entity.PayrollDisbursementRequest) ([]byte, error)
 }
 
 type service struct {
-	repo Repository
+	repo                Repository
+	periodGuard         accounting.PeriodGuard
+	cashDebtService     cashdebt.Service
+	attendanceService   attendance.Service
+	postingService      accounting.PostingService
+	journalEntryService accounting.JournalEntryService
 }
 
-func NewService(repo Repository) Service {
-	return &service{repo: repo}
+// NewService wires the payroll module. `periodGuard` refuses a
+// save, approval or payment whose window touches a closed
+// accounting period; `cashDebtService` supplies the unpaid advances
+// a run deducts and settles them; `attendanceService` supplies the
+// days worked the ATTENDANCE bands are evaluated against. Approval
+// and payment post through `postingService`; a void reverses those
+// entries through `journalEntryService`.
+func NewService(
+	repo Repository,
+	periodGuard accounting.PeriodGuard,
+	cashDebtService cashdebt.Service,
+	attendanceService attendance.Service,
+	postingService accounting.PostingService,
+	journalEntryService accounting.JournalEntryService,
+) Service {
+	return &service{
+		repo:                repo,
+		periodGuard:         periodGuard,
+		cashDebtService:     cashDebtService,
+		attendanceService:   attendanceService,
+		postingService:      postingService,
+		journalEntryService: journalEntryService,
+	}
 }
 
 func (s *service) Simulate(
 	ctx context.Context,
 	req *entity.SimulatePayrollRequest,
 ) (*entity.SimulatePayrollResultDto, error) {
-	start, err := time.Parse("2006-01-02", req.StartDate)
+	result, err := s.earnings(ctx, s.repo, req.AdminIDEmployee, req.StartDate, req.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	debts, err := s.cashDebtService.FindOutstanding(ctx, req.AdminIDEmployee, req.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range debts {
+		result.CashDebtOutstanding += d.Outstanding
+	}
+	result.CashDebtOutstanding = roundCents(result.CashDebtOutstanding)
+	result.TotalCashReceipt = req.TotalCashReceipt
+	result.CashDebtDeductionCap = req.CashDebtDeductionCap
+	result.CashDebtDeductions = proposeCashDebtDeductions(
+		debts, result.TotalSalary-req.TotalCashReceipt, req.CashDebtDeductionCap,
+	)
+	for _, d := range result.CashDebtDeductions {
+		result.CashDebtDeduction += d.Deduction
+	}
+	result.CashDebtDeduction = roundCents(result.CashDebtDeduction)
+	result.RemainingSalary = roundCents(result.TotalSalary - req.TotalCashReceipt - result.CashDebtDeduction)
+	return result, nil
+}
+
+// earnings works out what the employee earned over the window: the
+// sessions and the attendance. Simulate and save both go through it,
+// so a saved (and later approved) run carries the figures the
+// operator was shown.
+func (s *service) earnings(
+	ctx context.Context,
+	repo Repository,
+	adminID, startDate, endDate string,
+) (*entity.SimulatePayrollResultDto, error) {
+	start, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return nil, err
+	}
+	end, err := time.Parse("2006-01-02", endDate)
 	if err != nil {
 		return nil, err
 	}
-	end, err := time.Parse("2006-01-02", req.EndDate)
+	daysWorked, err := s.attendanceService.DaysWorked(ctx, adminID, start, end)
 	if err != nil {
 		return nil, err
 	}
-	return s.repo.Simulate(ctx, req.AdminIDEmployee, start, end)
+	return repo.Simulate(ctx, adminID, start, end, daysWorked)
 }
 
 // Save turns the operator-approved simulation into one persisted
 // employee_salary header row + N employee_salary_component rows.
+// The run starts as DRAFT and is refused when the employee already
+// has a live run overlapping the window.
 // The frontend submits the same SimulatePayrollResultDto it just
 // rendered (minus the session evidence), plus the operator-entered
-// TotalCashReceipt, and the service flattens it into the schema.
+// TotalCashReceipt. The earnings are worked out again from the
+// sessions rather than taken from the request, and flattened into
+// the schema.
+// Cash debt deductions settle the advances they cover inside the
+// same transaction, and the run posts PAYROLL_SAVED, which has no
+// default rules: the ledger normally sees a run on approval.
 func (s *service) Save(
 	ctx context.Context,
 	req *entity.SavePayrollRequest,
+) (*entity.EmployeeSalaryDto, error) {
+	return s.save(ctx, s.repo, req, "")
+}
+
+// save is Save against a given repository, so a batch can run it
+// inside its own transaction and tag the run with the batch id.
+func (s *service) save(
+	ctx context.Context,
+	repo Repository,
+	req *entity.SavePayrollRequest,
+	batchID string,
 ) (*entity.EmployeeSalaryDto, error) {
 	orgID := shared.GetOrganization(ctx).ID
+	if err := s.periodGuard.EnsureRangeOpen(ctx, req.StartDate, req.EndDate); err != nil {
+		return nil, err
+	}
+	earned, err := s.earnings(ctx, repo, req.AdminIDEmployee, req.StartDate, req.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	operator := req
+	req = saveRequestFromSimulation(earned)
+	req.TotalCashReceipt = operator.TotalCashReceipt
+	req.CashDebtDeductionCap = operator.CashDebtDeductionCap
+	req.CashDebtDeductions = operator.CashDebtDeductions
+
+	cashDebt, err := validateCashDebtDeductions(req)
+	if err != nil {
+		return nil, err
+	}
+
+	components := req.Components
+	for _, d := range req.CashDebtDeductions {
+		components = append(components, entity.EmployeeSalaryComponentDto{
+			ComponentType: entity.EmployeeSalaryComponentTypeCashDebtDeduction,
+			Amount:        d.Deduction,
+			RefID:         d.CashDebtID,
+			RefTable:      entity.AccountMutationRefTableCashDebt,
+			RefSource:     entity.EmployeeSalaryRefSourceCashDebt,
+		})
+	}
 
 	header := &entity.EmployeeSalaryDto{
 		OrganizationID:     orgID,
+		BatchID:            batchID,
 		AdminIDEmployee:    req.AdminIDEmployee,
 		StartDate:          req.StartDate,
 		EndDate:            req.EndDate,
@@ -61,10 +204,39 @@ func (s *service) Save(
 		TotalBonusTarget:   req.TotalBonusTarget,
 		TotalSalary:        req.TotalSalary,
 		TotalCashReceipt:   req.TotalCashReceipt,
-		RemainingSalary:    req.TotalSalary - req.TotalCashReceipt,
-		Components:         req.Components,
+		TotalCashDebt:      cashDebt,
+		RemainingSalary:    req.TotalSalary - req.TotalCashReceipt - cashDebt,
+		Components:         components,
+		Status:             entity.EmployeeSalaryStatusDraft,
+		CreatedBy:          actorOf(ctx),
+	}
+	result, err := repo.Save(ctx, header, func(tx *gorm.DB, saved *entity.EmployeeSalaryDto) error {
+		if err := s.settleCashDebts(ctx, tx, saved, req.CashDebtDeductions); err != nil {
+			return err
+		}
+		entry, err := s.postRun(ctx, tx, saved, entity.PostingEventPayrollSaved, map[string]float64{
+			entity.PostingAmountTotalSalary:        saved.TotalSalary,
+			entity.PostingAmountTotalCommission:    saved.TotalCommission,
+			entity.PostingAmountTotalMealAllowance: saved.TotalMealAllowance,
+			entity.PostingAmountTotalAttendance:    saved.TotalAttendance,
+			entity.PostingAmountTotalBonusTarget:   saved.TotalBonusTarget,
+			entity.PostingAmountTotalCashReceipt:   saved.TotalCashReceipt,
+			entity.PostingAmountTotalCashDebt:      saved.TotalCashDebt,
+			entity.PostingAmountRemainingSalary:    saved.RemainingSalary,
+		})
+		if err != nil {
+			return err
+		}
+		saved.SaveJournalEntryID = entry
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, ErrPayrollOverlap) {
+			return nil, status.New(status.EntityConflict, err)
+		}
+		return nil, err
 	}
-	return s.repo.Save(ctx, header)
+	return result, nil
 }
 
 func (s *service) FindAll(
@@ -0,0 +1,245 @@
+package payroll
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	"github.com/raymondsugiarto/coffee-api/pkg/model"
+	accounting "github.com/raymondsugiarto/coffee-api/pkg/module/accounting"
+	"github.com/raymondsugiarto/coffee-api/pkg/module/attendance"
+	cashdebt "github.com/raymondsugiarto/coffee-api/pkg/module/cash_debt"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/testdb"
+	"gorm.io/gorm"
+)
+
+type stubPeriodGuard struct{}
+
+func (stubPeriodGuard) EnsureOpen(context.Context, string) error              { return nil }
+func (stubPeriodGuard) EnsureRangeOpen(context.Context, string, string) error { return nil }
+
+// closedPeriodGuard refuses every window, as a closed accounting
+// period would.
+type closedPeriodGuard struct{}
+
+func (closedPeriodGuard) EnsureOpen(context.Context, string) error {
+	return errors.New("accounting period is closed")
+}
+
+func (closedPeriodGuard) EnsureRangeOpen(context.Context, string, string) error {
+	return errors.New("accounting period is closed")
+}
+
+type stubAttendance struct {
+	attendance.Service
+}
+
+func (stubAttendance) DaysWorked(context.Context, string, time.Time, time.Time) (int, error) {
+	return 0, nil
+}
+
+type stubCashDebt struct {
+	cashdebt.Service
+}
+
+func (s stubCashDebt) WithTx(*gorm.DB) cashdebt.Service { return s }
+
+func (stubCashDebt) FindOutstanding(context.Context, string, string) ([]*entity.CashDebtDto, error) {
+	return nil, nil
+}
+
+// stubPosting records the events instead of posting them.
+type stubPosting struct {
+	events *[]*entity.PostingEventDto
+}
+
+func (s stubPosting) WithTx(*gorm.DB) accounting.PostingService { return s }
+
+func (s stubPosting) PostEvent(_ context.Context, event *entity.PostingEventDto) (*entity.JournalEntryDto, error) {
+	*s.events = append(*s.events, event)
+	return &entity.JournalEntryDto{ID: "entry"}, nil
+}
+
+// newPayrollFixture seeds one driver of company c1 with two shifts
+// on 1 Oct and one on 2 Oct. The company pays a 15.000 meal
+// allowance a day, a 20.000 bonus from 50 cups a day, and its
+// commission only counts above 10 cups at 1.000.
+func newPayrollFixture(t *testing.T) (*service, *[]*entity.PostingEventDto) {
+	t.Helper()
+	db := testdb.New(t,
+		&model.StockSession{}, &model.AdminCompany{}, &model.SalaryComponent{}, &model.CashDebt{},
+		&model.EmployeeSalary{}, &model.EmployeeSalaryComponent{},
+	)
+	day := func(d int) time.Time { return time.Date(2026, 10, d, 0, 0, 0, 0, time.UTC) }
+	seed := []interface{}{
+		&model.AdminCompany{OrganizationID: "org-1", CompanyID: "c1", AdminID: "driver"},
+		&model.SalaryComponent{CompanyID: "c1", ComponentType: model.ComponentTypeMealAllowance, Amount: 15000, EffectiveFrom: day(1)},
+		&model.SalaryComponent{CompanyID: "c1", ComponentType: model.ComponentTypeBonusTarget, MinimumTarget: 50, Amount: 20000, EffectiveFrom: day(1)},
+		&model.SalaryComponent{CompanyID: "c1", ComponentType: model.ComponentTypeCommission, MinimumTarget: 10, Amount: 1000, EffectiveFrom: day(1)},
+		&model.StockSession{OrganizationID: "org-1", EmployeeID: "driver", Date: day(1), OpenedAt: day(1).Add(7 * time.Hour),
+			Shift: model.SessionTypeMorning, TotalItems: 30, TotalCommission: 30000},
+		&model.StockSession{OrganizationID: "org-1", EmployeeID: "driver", Date: day(1), OpenedAt: day(1).Add(15 * time.Hour),
+			Shift: model.SessionTypeEvening, TotalItems: 30, TotalCommission: 30000},
+		&model.StockSession{OrganizationID: "org-1", EmployeeID: "driver", Date: day(2), OpenedAt: day(2).Add(7 * time.Hour),
+			Shift: model.SessionTypeMorning, TotalItems: 20, TotalCommission: 20000},
+	}
+	for _, m := range seed {
+		if err := db.Create(m).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	events := new([]*entity.PostingEventDto)
+	s := NewService(NewRepository(db), stubPeriodGuard{}, stubCashDebt{}, stubAttendance{}, stubPosting{events}, nil)
+	return s.(*service), events
+}
+
+// Day 1 sells 60 cups over two shifts: one meal allowance, the bonus
+// on the day's 60 cups and one minimum target. Day 2 sells 20 cups.
+const (
+	wantMealAllowance = 2 * 15000
+	wantBonusTarget   = 20000
+	wantCommission    = (60000 - 10000) + (20000 - 10000)
+	wantTotalSalary   = wantMealAllowance + wantBonusTarget + wantCommission
+)
+
+func TestSimulateAppliesDailyComponentsOncePerDate(t *testing.T) {
+	s, _ := newPayrollFixture(t)
+	ctx := testdb.WithOrganization(context.Background(), "org-1")
+
+	got, err := s.Simulate(ctx, &entity.SimulatePayrollRequest{
+		AdminIDEmployee: "driver", StartDate: "2026-10-01", EndDate: "2026-10-31",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.SessionCount != 3 {
+		t.Fatalf("SessionCount = %d, want 3", got.SessionCount)
+	}
+	for _, c := range []struct {
+		name      string
+		got, want float64
+	}{
+		{"TotalMealAllowance", got.TotalMealAllowance, wantMealAllowance},
+		{"TotalBonusTarget", got.TotalBonusTarget, wantBonusTarget},
+		{"TotalCommission", got.TotalCommission, wantCommission},
+		{"TotalSalary", got.TotalSalary, wantTotalSalary},
+	} {
+		if math.Abs(c.got-c.want) > 1e-9 {
+			t.Errorf("%s = %v, want %v", c.name, c.got, c.want)
+		}
+	}
+
+	// The day's components sit on its first shift only.
+	evening := got.Sessions[1]
+	if evening.Shift != string(model.SessionTypeEvening) {
+		t.Fatalf("second session is %s, want the evening shift of day 1", evening.Shift)
+	}
+	if evening.MealAllowance != 0 || evening.BonusTarget != 0 || evening.MinTargetCommission != 0 {
+		t.Errorf("evening shift carries day components: %+v", evening)
+	}
+	if evening.TotalSalary != 30000 {
+		t.Errorf("evening shift TotalSalary = %v, want its own commission 30000", evening.TotalSalary)
+	}
+	var sum float64
+	for _, ss := range got.Sessions {
+		sum += ss.TotalSalary
+	}
+	if math.Abs(sum-got.TotalSalary) > 1e-9 {
+		t.Errorf("sessions add up to %v, total is %v", sum, got.TotalSalary)
+	}
+}
+
+func TestSaveAndApproveUseTheSimulatedFigures(t *testing.T) {
+	s, events := newPayrollFixture(t)
+	ctx := testdb.WithOrganization(context.Background(), "org-1")
+
+	// The request claims one meal allowance per shift; the run
+	// must carry what the sessions earned instead.
+	saved, err := s.Save(ctx, &entity.SavePayrollRequest{
+		AdminIDEmployee:    "driver",
+		StartDate:          "2026-10-01",
+		EndDate:            "2026-10-31",
+		TotalMealAllowance: 3 * 15000,
+		TotalSalary:        wantTotalSalary + 15000,
+		TotalCashReceipt:   5000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.TotalMealAllowance != wantMealAllowance || saved.TotalSalary != wantTotalSalary {
+		t.Errorf("saved meal %v / salary %v, want %v / %v",
+			saved.TotalMealAllowance, saved.TotalSalary, wantMealAllowance, wantTotalSalary)
+	}
+	if saved.RemainingSalary != wantTotalSalary-5000 {
+		t.Errorf("RemainingSalary = %v, want %v", saved.RemainingSalary, wantTotalSalary-5000)
+	}
+	var meals float64
+	for _, c := range saved.Components {
+		if c.ComponentType == entity.EmployeeSalaryComponentTypeMealAllowance {
+			meals += c.Amount
+		}
+	}
+	if meals != wantMealAllowance {
+		t.Errorf("MEAL_ALLOWANCE components add up to %v, want %v", meals, wantMealAllowance)
+	}
+
+	if _, err := s.Approve(ctx, saved.ID); err != nil {
+		t.Fatal(err)
+	}
+	// The save and the approval both post the run as saved.
+	wantEvents := []string{entity.PostingEventPayrollSaved, entity.PostingEventPayrollApproved}
+	if len(*events) != len(wantEvents) {
+		t.Fatalf("%d events posted, want %d", len(*events), len(wantEvents))
+	}
+	for i, event := range *events {
+		if event.EventType != wantEvents[i] {
+			t.Errorf("event %d is %s, want %s", i, event.EventType, wantEvents[i])
+		}
+		if got := event.Amounts[entity.PostingAmountTotalMealAllowance]; got != wantMealAllowance {
+			t.Errorf("%s meal allowance %v, want %v", event.EventType, got, wantMealAllowance)
+		}
+		if got := event.Amounts[entity.PostingAmountTotalSalary]; got != wantTotalSalary {
+			t.Errorf("%s salary %v, want %v", event.EventType, got, wantTotalSalary)
+		}
+	}
+}
+
+func TestApproveAndPayRefuseAClosedPeriod(t *testing.T) {
+	s, events := newPayrollFixture(t)
+	ctx := testdb.WithOrganization(context.Background(), "org-1")
+	saved, err := s.Save(ctx, &entity.SavePayrollRequest{
+		AdminIDEmployee: "driver", StartDate: "2026-10-01", EndDate: "2026-10-31",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	posted := len(*events)
+
+	s.periodGuard = closedPeriodGuard{}
+	if _, err := s.Approve(ctx, saved.ID); err == nil {
+		t.Error("Approve in a closed period succeeded")
+	}
+	s.periodGuard = stubPeriodGuard{}
+	if _, err := s.Approve(ctx, saved.ID); err != nil {
+		t.Fatalf("Approve after the refusal: %v", err)
+	}
+	posted++
+
+	s.periodGuard = closedPeriodGuard{}
+	if _, err := s.Pay(ctx, saved.ID); err == nil {
+		t.Error("Pay in a closed period succeeded")
+	}
+	var run model.EmployeeSalary
+	if err := s.repo.(*repository).db.First(&run, "id = ?", saved.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if run.Status != entity.EmployeeSalaryStatusApproved {
+		t.Errorf("status = %s, want %s", run.Status, entity.EmployeeSalaryStatusApproved)
+	}
+	if len(*events) != posted {
+		t.Errorf("%d events posted, want %d: the refused steps must post nothing", len(*events), posted)
+	}
+}
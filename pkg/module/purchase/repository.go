@@ -0,0 +1,333 @@
+package purchase
+
+import (
+	"context"
+	"errors"
+
+	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	"github.com/raymondsugiarto/coffee-api/pkg/model"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/pagination"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository interface {
+	// WithTx returns a repository bound to the caller's transaction.
+	WithTx(tx *gorm.DB) Repository
+	CreateOrder(ctx context.Context, dto *entity.PurchaseOrderDto) (*entity.PurchaseOrderDto, error)
+	GetOrder(ctx context.Context, id string) (*entity.PurchaseOrderDto, error)
+	// UpdateOrder rewrites the order header and replaces its lines.
+	// Only an OPEN order nothing was received against can change
+	// (ErrPurchaseOrderLocked).
+	UpdateOrder(ctx context.Context, dto *entity.PurchaseOrderDto) (*entity.PurchaseOrderDto, error)
+	// CancelOrder marks an OPEN or PARTIAL order CANCELLED; what
+	// was already received stays booked.
+	CancelOrder(ctx context.Context, id string) (*entity.PurchaseOrderDto, error)
+	FindOrders(ctx context.Context, req *entity.PurchaseOrderFindAllRequest) (*pagination.ResultPagination, error)
+	// CreateReceipt saves a goods receipt and, when it fills a
+	// purchase order, bumps the lines' received_qty and the order
+	// status, with the order locked so two receipts cannot
+	// over-receive it (ErrReceiptExceedsOrder). afterSave runs
+	// inside the same transaction; an error rolls everything back.
+	// afterSave may set saved.JournalEntryID; it is stored on the
+	// receipt.
+	CreateReceipt(
+		ctx context.Context,
+		dto *entity.GoodsReceiptDto,
+		afterSave func(tx *gorm.DB, saved *entity.GoodsReceiptDto) error,
+	) (*entity.GoodsReceiptDto, error)
+	GetReceipt(ctx context.Context, id string) (*entity.GoodsReceiptDto, error)
+	FindReceipts(ctx context.Context, req *entity.GoodsReceiptFindAllRequest) (*pagination.ResultPagination, error)
+	// ApplyMovingAverage moves the item's cost price to the weighted
+	// average of onHand units at the current cost and qty units at
+	// unitCost (entity.MovingAverageCost), with the item row locked.
+	// Only an item the organization owns moves; a shared item's cost
+	// belongs to every organization (ErrSharedItemCost).
+	ApplyMovingAverage(ctx context.Context, organizationID, itemID string, onHand, qty int, unitCost float64) error
+}
+
+var (
+	// ErrPurchaseOrderLocked is returned by UpdateOrder once the
+	// order is no longer OPEN or has receipts against it.
+	ErrPurchaseOrderLocked = errors.New("purchase order can no longer be edited")
+	// ErrPurchaseOrderClosed is returned when receiving against, or
+	// cancelling, an order that is RECEIVED or CANCELLED.
+	ErrPurchaseOrderClosed = errors.New("purchase order is already received or cancelled")
+	// ErrReceiptExceedsOrder is returned by CreateReceipt when a
+	// line receives more than is still outstanding on the order.
+	ErrReceiptExceedsOrder = errors.New("received quantity exceeds the quantity still on order")
+	// ErrSharedItemCost is returned by ApplyMovingAverage for an item
+	// the organization does not own.
+	ErrSharedItemCost = errors.New("cost price of a shared item cannot be updated by a goods receipt")
+)
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) WithTx(tx *gorm.DB) Repository {
+	return &repository{db: tx}
+}
+
+func (r *repository) CreateOrder(ctx context.Context, dto *entity.PurchaseOrderDto) (*entity.PurchaseOrderDto, error) {
+	m := dto.ToModel()
+	if err := r.db.WithContext(ctx).Create(m).Error; err != nil {
+		return nil, err
+	}
+	return r.GetOrder(ctx, m.ID)
+}
+
+func (r *repository) GetOrder(ctx context.Context, id string) (*entity.PurchaseOrderDto, error) {
+	var m model.PurchaseOrder
+	if err := r.db.WithContext(ctx).
+		Preload("Supplier").
+		Preload("Lines", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC") }).
+		Preload("Lines.Item").
+		Where("id = ?", id).
+		First(&m).Error; err != nil {
+		return nil, err
+	}
+	return entity.NewPurchaseOrderDtoFromModel(&m), nil
+}
+
+func (r *repository) UpdateOrder(ctx context.Context, dto *entity.PurchaseOrderDto) (*entity.PurchaseOrderDto, error) {
+	m := dto.ToModel()
+	lines := m.Lines
+	m.Lines = nil
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		existing, err := lockOrder(tx, m.ID)
+		if err != nil {
+			return err
+		}
+		if existing.Status != entity.PurchaseOrderStatusOpen {
+			return ErrPurchaseOrderLocked
+		}
+		for _, l := range existing.Lines {
+			if l.ReceivedQty > 0 {
+				return ErrPurchaseOrderLocked
+			}
+		}
+		if err := tx.Model(m).
+			Select("supplier_id", "date", "expected_date", "total", "notes").
+			Updates(m).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("purchase_order_id = ?", m.ID).Delete(&model.PurchaseOrderLine{}).Error; err != nil {
+			return err
+		}
+		for i := range lines {
+			lines[i].PurchaseOrderID = m.ID
+		}
+		return tx.Create(&lines).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetOrder(ctx, m.ID)
+}
+
+func (r *repository) CancelOrder(ctx context.Context, id string) (*entity.PurchaseOrderDto, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		existing, err := lockOrder(tx, id)
+		if err != nil {
+			return err
+		}
+		if existing.Status == entity.PurchaseOrderStatusReceived ||
+			existing.Status == entity.PurchaseOrderStatusCancelled {
+			return ErrPurchaseOrderClosed
+		}
+		return tx.Model(existing).Update("status", entity.PurchaseOrderStatusCancelled).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetOrder(ctx, id)
+}
+
+func (r *repository) FindOrders(
+	ctx context.Context,
+	req *entity.PurchaseOrderFindAllRequest,
+) (*pagination.ResultPagination, error) {
+	var rows []model.PurchaseOrder = make([]model.PurchaseOrder, 0)
+	tbl := pagination.NewTable(r.db)
+	dataTable, err := tbl.Pagination(func(i interface{}) *gorm.DB {
+		return r.db.WithContext(ctx).Model(&model.PurchaseOrder{}).
+			Preload("Supplier").
+			Preload("Lines").
+			Preload("Lines.Item").
+			Where("organization_id = ?", req.FindAllRequest.OrganizationData.ID)
+	}, &pagination.TableRequest{
+		Request:       req,
+		QueryField:    []string{"code", "notes"},
+		Data:          &rows,
+		AllowedFields: []string{"code", "date", "status", "supplier_id", "total", "created_at"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := dataTable.(*pagination.ResultPagination)
+	hits := result.Data.(*[]model.PurchaseOrder)
+	out := make([]*entity.PurchaseOrderDto, 0, len(*hits))
+	for i := range *hits {
+		out = append(out, entity.NewPurchaseOrderDtoFromModel(&(*hits)[i]))
+	}
+	return &pagination.ResultPagination{
+		Data:        out,
+		Page:        result.Page,
+		Count:       result.Count,
+		RowsPerPage: result.RowsPerPage,
+		TotalPages:  result.TotalPages,
+	}, nil
+}
+
+func (r *repository) CreateReceipt(
+	ctx context.Context,
+	dto *entity.GoodsReceiptDto,
+	afterSave func(tx *gorm.DB, saved *entity.GoodsReceiptDto) error,
+) (*entity.GoodsReceiptDto, error) {
+	var id string
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if dto.PurchaseOrderID != "" {
+			if err := receiveOnOrder(tx, dto); err != nil {
+				return err
+			}
+		}
+		m := dto.ToModel()
+		if err := tx.Create(m).Error; err != nil {
+			return err
+		}
+		id = m.ID
+		saved := entity.NewGoodsReceiptDtoFromModel(m)
+		if afterSave != nil {
+			if err := afterSave(tx, saved); err != nil {
+				return err
+			}
+			if saved.JournalEntryID != "" {
+				return tx.Model(m).Update("journal_entry_id", saved.JournalEntryID).Error
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.GetReceipt(ctx, id)
+}
+
+// receiveOnOrder books the receipt lines against the locked order
+// and moves it to PARTIAL or RECEIVED.
+func receiveOnOrder(tx *gorm.DB, dto *entity.GoodsReceiptDto) error {
+	order, err := lockOrder(tx, dto.PurchaseOrderID)
+	if err != nil {
+		return err
+	}
+	if order.Status != entity.PurchaseOrderStatusOpen && order.Status != entity.PurchaseOrderStatusPartial {
+		return ErrPurchaseOrderClosed
+	}
+	lines := make(map[string]*model.PurchaseOrderLine, len(order.Lines))
+	for i := range order.Lines {
+		lines[order.Lines[i].ID] = &order.Lines[i]
+	}
+	for _, l := range dto.Lines {
+		line, ok := lines[l.PurchaseOrderLineID]
+		if !ok || line.ReceivedQty+l.Qty > line.Qty {
+			return ErrReceiptExceedsOrder
+		}
+		line.ReceivedQty += l.Qty
+		if err := tx.Model(line).Update("received_qty", line.ReceivedQty).Error; err != nil {
+			return err
+		}
+	}
+	status := entity.PurchaseOrderStatusReceived
+	for _, l := range order.Lines {
+		if l.ReceivedQty < l.Qty {
+			status = entity.PurchaseOrderStatusPartial
+			break
+		}
+	}
+	return tx.Model(order).Update("status", status).Error
+}
+
+func (r *repository) GetReceipt(ctx context.Context, id string) (*entity.GoodsReceiptDto, error) {
+	var m model.GoodsReceipt
+	if err := r.db.WithContext(ctx).
+		Preload("Supplier").
+		Preload("Lines", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC") }).
+		Preload("Lines.Item").
+		Where("id = ?", id).
+		First(&m).Error; err != nil {
+		return nil, err
+	}
+	return entity.NewGoodsReceiptDtoFromModel(&m), nil
+}
+
+func (r *repository) FindReceipts(
+	ctx context.Context,
+	req *entity.GoodsReceiptFindAllRequest,
+) (*pagination.ResultPagination, error) {
+	var rows []model.GoodsReceipt = make([]model.GoodsReceipt, 0)
+	tbl := pagination.NewTable(r.db)
+	dataTable, err := tbl.Pagination(func(i interface{}) *gorm.DB {
+		return r.db.WithContext(ctx).Model(&model.GoodsReceipt{}).
+			Preload("Supplier").
+			Preload("Lines").
+			Preload("Lines.Item").
+			Where("organization_id = ?", req.FindAllRequest.OrganizationData.ID)
+	}, &pagination.TableRequest{
+		Request:       req,
+		QueryField:    []string{"code", "reference", "notes"},
+		Data:          &rows,
+		AllowedFields: []string{"code", "date", "supplier_id", "purchase_order_id", "total", "created_at"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := dataTable.(*pagination.ResultPagination)
+	hits := result.Data.(*[]model.GoodsReceipt)
+	out := make([]*entity.GoodsReceiptDto, 0, len(*hits))
+	for i := range *hits {
+		out = append(out, entity.NewGoodsReceiptDtoFromModel(&(*hits)[i]))
+	}
+	return &pagination.ResultPagination{
+		Data:        out,
+		Page:        result.Page,
+		Count:       result.Count,
+		RowsPerPage: result.RowsPerPage,
+		TotalPages:  result.TotalPages,
+	}, nil
+}
+
+func (r *repository) ApplyMovingAverage(ctx context.Context, organizationID, itemID string, onHand, qty int, unitCost float64) error {
+	var item model.Item
+	err := r.db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND organization_id = ?", itemID, organizationID).
+		First(&item).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrSharedItemCost
+	}
+	if err != nil {
+		return err
+	}
+	cost := entity.MovingAverageCost(item.CostPrice, onHand, qty, unitCost)
+	return r.db.WithContext(ctx).Model(&model.Item{}).
+		Where("id = ? AND organization_id = ?", itemID, organizationID).
+		Update("cost_price", cost).Error
+}
+
+func lockOrder(tx *gorm.DB, id string) (*model.PurchaseOrder, error) {
+	var order model.PurchaseOrder
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", id).
+		First(&order).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Where("purchase_order_id = ?", id).Find(&order.Lines).Error; err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
@@ -0,0 +1,54 @@
+package purchase
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/raymondsugiarto/coffee-api/pkg/model"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/testdb"
+)
+
+func TestApplyMovingAverage(t *testing.T) {
+	db := testdb.New(t, &model.Item{})
+	items := []*model.Item{
+		{OrganizationID: "org-1", Code: "OWN", CostPrice: 10000},
+		{OrganizationID: "", Code: "SHARED", CostPrice: 10000},
+		{OrganizationID: "org-2", Code: "OTHER", CostPrice: 10000},
+	}
+	for _, it := range items {
+		if err := db.Create(it).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	own, shared, other := items[0].ID, items[1].ID, items[2].ID
+	repo := NewRepository(db)
+	ctx := context.Background()
+
+	costOf := func(id string) float64 {
+		var it model.Item
+		if err := db.First(&it, "id = ?", id).Error; err != nil {
+			t.Fatal(err)
+		}
+		return it.CostPrice
+	}
+
+	// 10 on hand at 10.000 and 30 received at 12.000 average to 11.500.
+	if err := repo.ApplyMovingAverage(ctx, "org-1", own, 10, 30, 12000); err != nil {
+		t.Fatalf("own item: %v", err)
+	}
+	if got := costOf(own); math.Abs(got-11500) > 1e-9 {
+		t.Errorf("own item cost = %v, want 11500", got)
+	}
+
+	for name, id := range map[string]string{"shared item": shared, "another organization's item": other} {
+		err := repo.ApplyMovingAverage(ctx, "org-1", id, 10, 30, 12000)
+		if !errors.Is(err, ErrSharedItemCost) {
+			t.Errorf("%s: err = %v, want ErrSharedItemCost", name, err)
+		}
+		if got := costOf(id); got != 10000 {
+			t.Errorf("%s cost = %v, want it untouched at 10000", name, got)
+		}
+	}
+}
@@ -0,0 +1,322 @@
+package purchase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	gonanoid "github.com/matoous/go-nanoid/v2"
+	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	accounting "github.com/raymondsugiarto/coffee-api/pkg/module/accounting"
+	"github.com/raymondsugiarto/coffee-api/pkg/module/inventory"
+	"github.com/raymondsugiarto/coffee-api/pkg/module/supplier"
+	shared "github.com/raymondsugiarto/coffee-api/pkg/shared/context"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/pagination"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/response/status"
+	"gorm.io/gorm"
+)
+
+// Service handles buying stock: purchase orders raised with a
+// supplier and the goods receipts that bring the goods into the
+// depot.
+type Service interface {
+	CreateOrder(ctx context.Context, dto *entity.PurchaseOrderDto) (*entity.PurchaseOrderDto, error)
+	GetOrder(ctx context.Context, id string) (*entity.PurchaseOrderDto, error)
+	UpdateOrder(ctx context.Context, dto *entity.PurchaseOrderDto) (*entity.PurchaseOrderDto, error)
+	CancelOrder(ctx context.Context, id string) (*entity.PurchaseOrderDto, error)
+	FindOrders(ctx context.Context, req *entity.PurchaseOrderFindAllRequest) (*pagination.ResultPagination, error)
+	// Receive books a goods receipt in one transaction: RECEIPT
+	// stock movements, the order's received quantities, the cost
+	// price update when asked for, and the GOODS_RECEIVED posting.
+	Receive(ctx context.Context, req *entity.GoodsReceiptRequest) (*entity.GoodsReceiptDto, error)
+	GetReceipt(ctx context.Context, id string) (*entity.GoodsReceiptDto, error)
+	FindReceipts(ctx context.Context, req *entity.GoodsReceiptFindAllRequest) (*pagination.ResultPagination, error)
+}
+
+type service struct {
+	repo             Repository
+	supplierService  supplier.Service
+	inventoryService inventory.Service
+	postingService   accounting.PostingService
+	periodGuard      accounting.PeriodGuard
+}
+
+// NewService wires purchasing. Receipts move depot stock through
+// `inventoryService` and book Persediaan against Hutang Usaha
+// through `postingService`; `periodGuard` keeps receipts out of
+// closed accounting periods.
+func NewService(
+	repo Repository,
+	supplierService supplier.Service,
+	inventoryService inventory.Service,
+	postingService accounting.PostingService,
+	periodGuard accounting.PeriodGuard,
+) Service {
+	return &service{
+		repo:             repo,
+		supplierService:  supplierService,
+		inventoryService: inventoryService,
+		postingService:   postingService,
+		periodGuard:      periodGuard,
+	}
+}
+
+func (s *service) CreateOrder(ctx context.Context, dto *entity.PurchaseOrderDto) (*entity.PurchaseOrderDto, error) {
+	if err := s.prepareOrder(ctx, dto); err != nil {
+		return nil, err
+	}
+	dto.ID = ""
+	dto.Code = documentCode("PO", dto.Date)
+	dto.Status = entity.PurchaseOrderStatusOpen
+	dto.CreatedBy = actorOf(ctx)
+	return s.repo.CreateOrder(ctx, dto)
+}
+
+func (s *service) GetOrder(ctx context.Context, id string) (*entity.PurchaseOrderDto, error) {
+	order, err := s.repo.GetOrder(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, status.New(status.EntityNotFound, err)
+		}
+		return nil, err
+	}
+	if order.OrganizationID != shared.GetOrganization(ctx).ID {
+		return nil, status.New(status.EntityNotFound, errors.New("purchase order not found"))
+	}
+	return order, nil
+}
+
+func (s *service) UpdateOrder(ctx context.Context, dto *entity.PurchaseOrderDto) (*entity.PurchaseOrderDto, error) {
+	if _, err := s.GetOrder(ctx, dto.ID); err != nil {
+		return nil, err
+	}
+	if err := s.prepareOrder(ctx, dto); err != nil {
+		return nil, err
+	}
+	result, err := s.repo.UpdateOrder(ctx, dto)
+	if err != nil {
+		if errors.Is(err, ErrPurchaseOrderLocked) {
+			return nil, status.New(status.EntityConflict, err)
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *service) CancelOrder(ctx context.Context, id string) (*entity.PurchaseOrderDto, error) {
+	if _, err := s.GetOrder(ctx, id); err != nil {
+		return nil, err
+	}
+	result, err := s.repo.CancelOrder(ctx, id)
+	if err != nil {
+		if errors.Is(err, ErrPurchaseOrderClosed) {
+			return nil, status.New(status.EntityConflict, err)
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *service) FindOrders(
+	ctx context.Context,
+	req *entity.PurchaseOrderFindAllRequest,
+) (*pagination.ResultPagination, error) {
+	req.FindAllRequest.OrganizationData.ID = shared.GetOrganization(ctx).ID
+	return s.repo.FindOrders(ctx, req)
+}
+
+// prepareOrder checks the supplier and items and fills the fields
+// the client does not send.
+func (s *service) prepareOrder(ctx context.Context, dto *entity.PurchaseOrderDto) error {
+	if _, err := s.supplierService.Get(ctx, dto.SupplierID); err != nil {
+		return err
+	}
+	seen := make(map[string]bool, len(dto.Lines))
+	ids := make([]string, 0, len(dto.Lines))
+	for _, l := range dto.Lines {
+		if seen[l.ItemID] {
+			return status.New(status.BadRequest, fmt.Errorf("item %s is ordered twice", l.ItemID))
+		}
+		seen[l.ItemID] = true
+		ids = append(ids, l.ItemID)
+	}
+	if err := s.inventoryService.EnsureItems(ctx, ids); err != nil {
+		return err
+	}
+	if dto.Date == "" {
+		dto.Date = time.Now().Format("2006-01-02")
+	}
+	if dto.ExpectedDate != "" && dto.ExpectedDate < dto.Date {
+		return status.New(status.BadRequest, errors.New("expected date is before the order date"))
+	}
+	dto.OrganizationID = shared.GetOrganization(ctx).ID
+	dto.ComputeTotal()
+	return nil
+}
+
+func (s *service) Receive(ctx context.Context, req *entity.GoodsReceiptRequest) (*entity.GoodsReceiptDto, error) {
+	receipt := &entity.GoodsReceiptDto{
+		OrganizationID:  shared.GetOrganization(ctx).ID,
+		SupplierID:      req.SupplierID,
+		PurchaseOrderID: req.PurchaseOrderID,
+		Date:            req.Date,
+		Reference:       req.Reference,
+		UpdateCostPrice: req.UpdateCostPrice,
+		Notes:           req.Notes,
+		CreatedBy:       actorOf(ctx),
+	}
+	if receipt.Date == "" {
+		receipt.Date = time.Now().Format("2006-01-02")
+	}
+	if err := s.periodGuard.EnsureOpen(ctx, receipt.Date); err != nil {
+		return nil, err
+	}
+
+	var orderLines map[string]entity.PurchaseOrderLineDto
+	if req.PurchaseOrderID != "" {
+		order, err := s.GetOrder(ctx, req.PurchaseOrderID)
+		if err != nil {
+			return nil, err
+		}
+		if req.SupplierID != "" && req.SupplierID != order.SupplierID {
+			return nil, status.New(status.BadRequest, errors.New("supplier does not match the purchase order"))
+		}
+		receipt.SupplierID = order.SupplierID
+		orderLines = make(map[string]entity.PurchaseOrderLineDto, len(order.Lines))
+		for _, l := range order.Lines {
+			orderLines[l.ItemID] = l
+		}
+	} else if _, err := s.supplierService.Get(ctx, req.SupplierID); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(req.Items))
+	for _, it := range req.Items {
+		if seen[it.ItemID] {
+			return nil, status.New(status.BadRequest, fmt.Errorf("item %s is received twice", it.ItemID))
+		}
+		seen[it.ItemID] = true
+		line := entity.GoodsReceiptLineDto{ItemID: it.ItemID, Qty: it.Qty}
+		if orderLines != nil {
+			ordered, ok := orderLines[it.ItemID]
+			if !ok {
+				return nil, status.New(status.BadRequest, fmt.Errorf("item %s is not on the purchase order", it.ItemID))
+			}
+			line.PurchaseOrderLineID = ordered.ID
+			line.UnitCost = ordered.UnitCost
+		} else if it.UnitCost == nil {
+			return nil, status.New(status.BadRequest, fmt.Errorf("item %s needs a unit cost", it.ItemID))
+		}
+		if it.UnitCost != nil {
+			line.UnitCost = *it.UnitCost
+		}
+		receipt.Total += float64(line.Qty) * line.UnitCost
+		receipt.Lines = append(receipt.Lines, line)
+	}
+	receipt.Code = documentCode("GR", receipt.Date)
+
+	result, err := s.repo.CreateReceipt(ctx, receipt, func(tx *gorm.DB, saved *entity.GoodsReceiptDto) error {
+		return s.bookReceipt(ctx, tx, saved)
+	})
+	if err != nil {
+		if errors.Is(err, ErrPurchaseOrderClosed) || errors.Is(err, ErrReceiptExceedsOrder) ||
+			errors.Is(err, ErrSharedItemCost) {
+			return nil, status.New(status.BadRequest, err)
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+// bookReceipt runs inside the receipt transaction: the goods go on
+// hand, the cost prices move when asked for, and the receipt lands
+// in the ledger. The default rule (migration 000032) posts:
+//
+//	Dr 1301 Persediaan       total_received
+//	    Cr 2103 Hutang Usaha total_received
+func (s *service) bookReceipt(ctx context.Context, tx *gorm.DB, d *entity.GoodsReceiptDto) error {
+	stock := &entity.StockReceiptRequest{
+		Date:      d.Date,
+		Reference: d.Code,
+		Notes:     d.Notes,
+		RefID:     d.ID,
+		RefTable:  entity.AccountMutationRefTableGoodsReceipt,
+	}
+	for _, l := range d.Lines {
+		stock.Items = append(stock.Items, entity.StockReceiptItemRequest{ItemID: l.ItemID, Qty: l.Qty})
+	}
+	movements, err := s.inventoryService.WithTx(tx).Receive(ctx, stock)
+	if err != nil {
+		return err
+	}
+
+	if d.UpdateCostPrice {
+		balances := make(map[string]int, len(movements))
+		for _, m := range movements {
+			balances[m.ItemID] = m.BalanceAfter
+		}
+		repo := s.repo.WithTx(tx)
+		for _, l := range d.Lines {
+			if err := repo.ApplyMovingAverage(ctx, d.OrganizationID, l.ItemID, balances[l.ItemID]-l.Qty, l.Qty, l.UnitCost); err != nil {
+				return err
+			}
+		}
+	}
+
+	entry, err := s.postingService.WithTx(tx).PostEvent(ctx, &entity.PostingEventDto{
+		OrganizationID: d.OrganizationID,
+		EventType:      entity.PostingEventGoodsReceived,
+		RefID:          d.ID,
+		RefTable:       entity.AccountMutationRefTableGoodsReceipt,
+		RefModule:      entity.AccountMutationRefModulePurchase,
+		Description:    fmt.Sprintf("Goods receipt %s", d.Code),
+		Amounts: map[string]float64{
+			entity.PostingAmountTotalReceived: d.Total,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if entry != nil {
+		d.JournalEntryID = entry.ID
+	}
+	return nil
+}
+
+func (s *service) GetReceipt(ctx context.Context, id string) (*entity.GoodsReceiptDto, error) {
+	receipt, err := s.repo.GetReceipt(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, status.New(status.EntityNotFound, err)
+		}
+		return nil, err
+	}
+	if receipt.OrganizationID != shared.GetOrganization(ctx).ID {
+		return nil, status.New(status.EntityNotFound, errors.New("goods receipt not found"))
+	}
+	return receipt, nil
+}
+
+func (s *service) FindReceipts(
+	ctx context.Context,
+	req *entity.GoodsReceiptFindAllRequest,
+) (*pagination.ResultPagination, error) {
+	req.FindAllRequest.OrganizationData.ID = shared.GetOrganization(ctx).ID
+	return s.repo.FindReceipts(ctx, req)
+}
+
+// documentCode numbers purchase documents the way orders are
+// numbered: prefix, date and a random suffix (PO/20260314/KQZD).
+func documentCode(prefix, date string) string {
+	suffix, _ := gonanoid.Generate("ABCDEFGHIJKLMNOPQRSTUVWXYZ", 4)
+	return prefix + "/" + strings.ReplaceAll(date, "-", "") + "/" + suffix
+}
+
+func actorOf(ctx context.Context) string {
+	if cred := shared.GetUserCredential(ctx); cred != nil {
+		return cred.AdminID
+	}
+	return ""
+}
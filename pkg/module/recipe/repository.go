@@ -0,0 +1,313 @@
+package recipe
+
+import (
+	"context"
+	"errors"
+
+	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	"github.com/raymondsugiarto/coffee-api/pkg/model"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/pagination"
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	// WithTx returns a repository bound to the caller's transaction.
+	WithTx(tx *gorm.DB) Repository
+	// Create refuses a second recipe for the same item
+	// (ErrRecipeExists).
+	Create(ctx context.Context, dto *entity.RecipeDto) (*entity.RecipeDto, error)
+	Get(ctx context.Context, id string) (*entity.RecipeDto, error)
+	// Update rewrites the recipe header and replaces its lines.
+	Update(ctx context.Context, dto *entity.RecipeDto) (*entity.RecipeDto, error)
+	Delete(ctx context.Context, id string) error
+	FindAll(ctx context.Context, req *entity.RecipeFindAllRequest) (*pagination.ResultPagination, error)
+	// FindForItems returns the active recipe each item sells with,
+	// keyed by item id: its own or, for a variant without one, its
+	// parent's. Items without a recipe are left out.
+	FindForItems(ctx context.Context, organizationID string, itemIDs []string) (map[string]*entity.RecipeDto, error)
+	// SaveUsage replaces the theoretical usage recorded for a
+	// session.
+	SaveUsage(ctx context.Context, sessionID string, rows []*entity.IngredientUsageDto) error
+	FindUsageBySession(ctx context.Context, organizationID, sessionID string) ([]*entity.IngredientUsageDto, error)
+	// TheoreticalUsage sums the recorded usage per ingredient over
+	// [from, to].
+	TheoreticalUsage(ctx context.Context, organizationID, from, to string) (map[string]float64, error)
+	// ActualUsage sums, per item, what left the depot over
+	// [from, to]: every stock movement except receipts, negated.
+	ActualUsage(ctx context.Context, organizationID, from, to string, itemIDs []string) (map[string]float64, error)
+	// IngredientIDs lists the items used as an ingredient by any
+	// of the organization's recipes.
+	IngredientIDs(ctx context.Context, organizationID string) ([]string, error)
+	// FindItems loads items by id.
+	FindItems(ctx context.Context, ids []string) (map[string]*model.Item, error)
+}
+
+// ErrRecipeExists is returned by Create when the item already has
+// a recipe.
+var ErrRecipeExists = errors.New("item already has a recipe")
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) WithTx(tx *gorm.DB) Repository {
+	return &repository{db: tx}
+}
+
+func (r *repository) Create(ctx context.Context, dto *entity.RecipeDto) (*entity.RecipeDto, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&model.Recipe{}).
+		Where("organization_id = ? AND item_id = ?", dto.OrganizationID, dto.ItemID).
+		Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, ErrRecipeExists
+	}
+	m := dto.ToModel()
+	if err := r.db.WithContext(ctx).Create(m).Error; err != nil {
+		return nil, err
+	}
+	return r.Get(ctx, m.ID)
+}
+
+func (r *repository) Get(ctx context.Context, id string) (*entity.RecipeDto, error) {
+	var m model.Recipe
+	if err := r.db.WithContext(ctx).
+		Preload("Item").
+		Preload("Lines", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC") }).
+		Preload("Lines.Ingredient").
+		Where("id = ?", id).
+		First(&m).Error; err != nil {
+		return nil, err
+	}
+	return entity.NewRecipeDtoFromModel(&m), nil
+}
+
+func (r *repository) Update(ctx context.Context, dto *entity.RecipeDto) (*entity.RecipeDto, error) {
+	m := dto.ToModel()
+	lines := m.Lines
+	m.Lines = nil
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(m).Select("notes", "is_active").Updates(m).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("recipe_id = ?", m.ID).Delete(&model.RecipeLine{}).Error; err != nil {
+			return err
+		}
+		for i := range lines {
+			lines[i].RecipeID = m.ID
+		}
+		return tx.Create(&lines).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.Get(ctx, m.ID)
+}
+
+func (r *repository) Delete(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("recipe_id = ?", id).Delete(&model.RecipeLine{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).Delete(&model.Recipe{}).Error
+	})
+}
+
+func (r *repository) FindAll(
+	ctx context.Context,
+	req *entity.RecipeFindAllRequest,
+) (*pagination.ResultPagination, error) {
+	var rows []model.Recipe = make([]model.Recipe, 0)
+	tbl := pagination.NewTable(r.db)
+	dataTable, err := tbl.Pagination(func(i interface{}) *gorm.DB {
+		return r.db.WithContext(ctx).Model(&model.Recipe{}).
+			Preload("Item").
+			Preload("Lines").
+			Preload("Lines.Ingredient").
+			Where("organization_id = ?", req.FindAllRequest.OrganizationData.ID)
+	}, &pagination.TableRequest{
+		Request:       req,
+		QueryField:    []string{"notes"},
+		Data:          &rows,
+		AllowedFields: []string{"item_id", "is_active", "created_at"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := dataTable.(*pagination.ResultPagination)
+	hits := result.Data.(*[]model.Recipe)
+	out := make([]*entity.RecipeDto, 0, len(*hits))
+	for i := range *hits {
+		out = append(out, entity.NewRecipeDtoFromModel(&(*hits)[i]))
+	}
+	return &pagination.ResultPagination{
+		Data:        out,
+		Page:        result.Page,
+		Count:       result.Count,
+		RowsPerPage: result.RowsPerPage,
+		TotalPages:  result.TotalPages,
+	}, nil
+}
+
+func (r *repository) FindForItems(
+	ctx context.Context,
+	organizationID string,
+	itemIDs []string,
+) (map[string]*entity.RecipeDto, error) {
+	out := make(map[string]*entity.RecipeDto)
+	if len(itemIDs) == 0 {
+		return out, nil
+	}
+	items, err := r.FindItems(ctx, itemIDs)
+	if err != nil {
+		return nil, err
+	}
+	lookup := make([]string, 0, len(itemIDs)*2)
+	for _, id := range itemIDs {
+		lookup = append(lookup, id)
+		if it, ok := items[id]; ok && it.ParentID != "" {
+			lookup = append(lookup, it.ParentID)
+		}
+	}
+	var rows []model.Recipe
+	if err := r.db.WithContext(ctx).
+		Preload("Lines").
+		Where("organization_id = ? AND is_active = ? AND item_id IN ?", organizationID, true, lookup).
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	byItem := make(map[string]*entity.RecipeDto, len(rows))
+	for i := range rows {
+		byItem[rows[i].ItemID] = entity.NewRecipeDtoFromModel(&rows[i])
+	}
+	for _, id := range itemIDs {
+		if rec, ok := byItem[id]; ok {
+			out[id] = rec
+			continue
+		}
+		if it, ok := items[id]; ok && it.ParentID != "" {
+			if rec, ok := byItem[it.ParentID]; ok {
+				out[id] = rec
+			}
+		}
+	}
+	return out, nil
+}
+
+func (r *repository) SaveUsage(ctx context.Context, sessionID string, rows []*entity.IngredientUsageDto) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("stock_session_id = ?", sessionID).Delete(&model.IngredientUsage{}).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		models := make([]*model.IngredientUsage, 0, len(rows))
+		for _, d := range rows {
+			models = append(models, d.ToModel())
+		}
+		return tx.Create(&models).Error
+	})
+}
+
+func (r *repository) FindUsageBySession(
+	ctx context.Context,
+	organizationID, sessionID string,
+) ([]*entity.IngredientUsageDto, error) {
+	var rows []model.IngredientUsage
+	if err := r.db.WithContext(ctx).
+		Preload("Ingredient").
+		Where("organization_id = ? AND stock_session_id = ?", organizationID, sessionID).
+		Order("created_at ASC").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]*entity.IngredientUsageDto, 0, len(rows))
+	for i := range rows {
+		out = append(out, entity.NewIngredientUsageDtoFromModel(&rows[i]))
+	}
+	return out, nil
+}
+
+func (r *repository) TheoreticalUsage(ctx context.Context, organizationID, from, to string) (map[string]float64, error) {
+	var rows []struct {
+		IngredientID string
+		Qty          float64
+	}
+	if err := r.db.WithContext(ctx).
+		Model(&model.IngredientUsage{}).
+		Select("ingredient_id, SUM(qty) AS qty").
+		Where("organization_id = ? AND date BETWEEN ? AND ?", organizationID, from, to).
+		Group("ingredient_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make(map[string]float64, len(rows))
+	for _, row := range rows {
+		out[row.IngredientID] = row.Qty
+	}
+	return out, nil
+}
+
+func (r *repository) ActualUsage(
+	ctx context.Context,
+	organizationID, from, to string,
+	itemIDs []string,
+) (map[string]float64, error) {
+	out := make(map[string]float64)
+	if len(itemIDs) == 0 {
+		return out, nil
+	}
+	var rows []struct {
+		ItemID string
+		Qty    float64
+	}
+	if err := r.db.WithContext(ctx).
+		Model(&model.StockMovement{}).
+		Select("item_id, -SUM(qty) AS qty").
+		Where("organization_id = ? AND date BETWEEN ? AND ?", organizationID, from, to).
+		Where("type <> ?", entity.StockMovementTypeReceipt).
+		Where("item_id IN ?", itemIDs).
+		Group("item_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		out[row.ItemID] = row.Qty
+	}
+	return out, nil
+}
+
+func (r *repository) IngredientIDs(ctx context.Context, organizationID string) ([]string, error) {
+	var ids []string
+	if err := r.db.WithContext(ctx).
+		Model(&model.RecipeLine{}).
+		Joins("JOIN recipe ON recipe.id = recipe_line.recipe_id AND recipe.deleted_at IS NULL").
+		Where("recipe.organization_id = ?", organizationID).
+		Distinct("recipe_line.ingredient_id").
+		Pluck("recipe_line.ingredient_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (r *repository) FindItems(ctx context.Context, ids []string) (map[string]*model.Item, error) {
+	out := make(map[string]*model.Item, len(ids))
+	if len(ids) == 0 {
+		return out, nil
+	}
+	var rows []model.Item
+	if err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for i := range rows {
+		out[rows[i].ID] = &rows[i]
+	}
+	return out, nil
+}
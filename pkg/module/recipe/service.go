@@ -0,0 +1,238 @@
+package recipe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	"github.com/raymondsugiarto/coffee-api/pkg/module/inventory"
+	shared "github.com/raymondsugiarto/coffee-api/pkg/shared/context"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/pagination"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/response/status"
+	"gorm.io/gorm"
+)
+
+// Service manages recipes (bills of materials) and the ingredient
+// usage they imply. Closing a stock session explodes the units sold
+// into theoretical usage through RecordSessionUsage; the usage
+// report sets that against what actually left the depot.
+type Service interface {
+	// WithTx binds the service to a transaction owned by the caller,
+	// so the usage commits or rolls back with the session close.
+	WithTx(tx *gorm.DB) Service
+	Create(ctx context.Context, dto *entity.RecipeDto) (*entity.RecipeDto, error)
+	Get(ctx context.Context, id string) (*entity.RecipeDto, error)
+	Update(ctx context.Context, dto *entity.RecipeDto) (*entity.RecipeDto, error)
+	Delete(ctx context.Context, id string) error
+	FindAll(ctx context.Context, req *entity.RecipeFindAllRequest) (*pagination.ResultPagination, error)
+	// RecordSessionUsage stores the theoretical ingredient usage of
+	// a closed session: SoldQty of each item times its recipe.
+	// Items without a recipe use nothing.
+	RecordSessionUsage(ctx context.Context, session *entity.StockSessionDto) error
+	// FindSessionUsage lists the usage recorded when the session
+	// closed; empty for an open session.
+	FindSessionUsage(ctx context.Context, sessionID string) ([]*entity.IngredientUsageDto, error)
+	// UsageReport compares theoretical with actual ingredient usage
+	// over [from, to] (YYYY-MM-DD), largest variance value first.
+	UsageReport(ctx context.Context, from, to string) (*entity.IngredientUsageReportDto, error)
+}
+
+type service struct {
+	repo             Repository
+	inventoryService inventory.Service
+}
+
+// NewService wires recipes. `inventoryService` validates the items
+// a recipe refers to.
+func NewService(repo Repository, inventoryService inventory.Service) Service {
+	return &service{repo: repo, inventoryService: inventoryService}
+}
+
+func (s *service) WithTx(tx *gorm.DB) Service {
+	return &service{repo: s.repo.WithTx(tx), inventoryService: s.inventoryService}
+}
+
+func (s *service) Create(ctx context.Context, dto *entity.RecipeDto) (*entity.RecipeDto, error) {
+	if err := s.validateRecipe(ctx, dto); err != nil {
+		return nil, err
+	}
+	dto.OrganizationID = shared.GetOrganization(ctx).ID
+	result, err := s.repo.Create(ctx, dto)
+	if err != nil {
+		if errors.Is(err, ErrRecipeExists) {
+			return nil, status.New(status.EntityConflict, err)
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *service) Get(ctx context.Context, id string) (*entity.RecipeDto, error) {
+	recipe, err := s.repo.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, status.New(status.EntityNotFound, err)
+		}
+		return nil, err
+	}
+	if recipe.OrganizationID != shared.GetOrganization(ctx).ID {
+		return nil, status.New(status.EntityNotFound, errors.New("recipe not found"))
+	}
+	return recipe, nil
+}
+
+func (s *service) Update(ctx context.Context, dto *entity.RecipeDto) (*entity.RecipeDto, error) {
+	existing, err := s.Get(ctx, dto.ID)
+	if err != nil {
+		return nil, err
+	}
+	// The recipe belongs to its item; moving it to another item is
+	// a delete and a create.
+	dto.ItemID = existing.ItemID
+	if err := s.validateRecipe(ctx, dto); err != nil {
+		return nil, err
+	}
+	dto.OrganizationID = existing.OrganizationID
+	return s.repo.Update(ctx, dto)
+}
+
+func (s *service) Delete(ctx context.Context, id string) error {
+	if _, err := s.Get(ctx, id); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *service) FindAll(
+	ctx context.Context,
+	req *entity.RecipeFindAllRequest,
+) (*pagination.ResultPagination, error) {
+	req.FindAllRequest.OrganizationData.ID = shared.GetOrganization(ctx).ID
+	return s.repo.FindAll(ctx, req)
+}
+
+// validateRecipe checks what the struct tags cannot: the items
+// exist, an ingredient appears once and is not the item itself.
+func (s *service) validateRecipe(ctx context.Context, dto *entity.RecipeDto) error {
+	ids := []string{dto.ItemID}
+	seen := make(map[string]bool, len(dto.Lines))
+	for _, l := range dto.Lines {
+		if l.IngredientID == dto.ItemID {
+			return status.New(status.BadRequest, errors.New("an item cannot be an ingredient of itself"))
+		}
+		if seen[l.IngredientID] {
+			return status.New(status.BadRequest, fmt.Errorf("ingredient %s is listed twice", l.IngredientID))
+		}
+		seen[l.IngredientID] = true
+		ids = append(ids, l.IngredientID)
+	}
+	return s.inventoryService.EnsureItems(ctx, ids)
+}
+
+func (s *service) RecordSessionUsage(ctx context.Context, session *entity.StockSessionDto) error {
+	itemIDs := make([]string, 0, len(session.Items))
+	for _, it := range session.Items {
+		if it.SoldQty > 0 {
+			itemIDs = append(itemIDs, it.ItemID)
+		}
+	}
+	recipes, err := s.repo.FindForItems(ctx, session.OrganizationID, itemIDs)
+	if err != nil {
+		return err
+	}
+	rows := make([]*entity.IngredientUsageDto, 0)
+	for _, it := range session.Items {
+		recipe, ok := recipes[it.ItemID]
+		if !ok || it.SoldQty <= 0 {
+			continue
+		}
+		for _, l := range recipe.Lines {
+			rows = append(rows, &entity.IngredientUsageDto{
+				OrganizationID: session.OrganizationID,
+				StockSessionID: session.ID,
+				Date:           session.Date,
+				ItemID:         it.ItemID,
+				IngredientID:   l.IngredientID,
+				SoldQty:        it.SoldQty,
+				QtyPerUnit:     l.Qty,
+				Qty:            float64(it.SoldQty) * l.Qty,
+			})
+		}
+	}
+	return s.repo.SaveUsage(ctx, session.ID, rows)
+}
+
+func (s *service) FindSessionUsage(ctx context.Context, sessionID string) ([]*entity.IngredientUsageDto, error) {
+	return s.repo.FindUsageBySession(ctx, shared.GetOrganization(ctx).ID, sessionID)
+}
+
+func (s *service) UsageReport(ctx context.Context, from, to string) (*entity.IngredientUsageReportDto, error) {
+	if from == "" || to == "" {
+		return nil, status.New(status.BadRequest, errors.New("from and to are required"))
+	}
+	if from > to {
+		return nil, status.New(status.BadRequest, errors.New("from is after to"))
+	}
+	orgID := shared.GetOrganization(ctx).ID
+	theoretical, err := s.repo.TheoreticalUsage(ctx, orgID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	ingredientIDs, err := s.repo.IngredientIDs(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]bool, len(ingredientIDs))
+	for _, id := range ingredientIDs {
+		known[id] = true
+	}
+	for id := range theoretical {
+		if !known[id] {
+			known[id] = true
+			ingredientIDs = append(ingredientIDs, id)
+		}
+	}
+	actual, err := s.repo.ActualUsage(ctx, orgID, from, to, ingredientIDs)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.repo.FindItems(ctx, ingredientIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &entity.IngredientUsageReportDto{
+		From: from,
+		To:   to,
+		Rows: make([]entity.IngredientUsageReportRowDto, 0, len(ingredientIDs)),
+	}
+	for _, id := range ingredientIDs {
+		if theoretical[id] == 0 && actual[id] == 0 {
+			continue
+		}
+		row := entity.IngredientUsageReportRowDto{
+			IngredientID:   id,
+			TheoreticalQty: theoretical[id],
+			ActualQty:      actual[id],
+		}
+		if it, ok := items[id]; ok {
+			row.IngredientCode = it.Code
+			row.IngredientName = it.Name
+			row.CostPrice = it.CostPrice
+		}
+		row.VarianceQty = row.ActualQty - row.TheoreticalQty
+		if row.TheoreticalQty != 0 {
+			row.VariancePct = math.Round(row.VarianceQty/row.TheoreticalQty*10000) / 100
+		}
+		row.VarianceValue = row.VarianceQty * row.CostPrice
+		report.TotalVarianceValue += row.VarianceValue
+		report.Rows = append(report.Rows, row)
+	}
+	sort.SliceStable(report.Rows, func(i, j int) bool {
+		return math.Abs(report.Rows[i].VarianceValue) > math.Abs(report.Rows[j].VarianceValue)
+	})
+	return report, nil
+}
@@ -2,27 +2,42 @@ package salarycomponent
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/raymondsugiarto/coffee-api/pkg/entity"
 	"github.com/raymondsugiarto/coffee-api/pkg/model"
 	"github.com/raymondsugiarto/coffee-api/pkg/shared/pagination"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ErrSalaryComponentSuperseded is returned when editing or ending a
+// version that a newer one already replaced.
+var ErrSalaryComponentSuperseded = errors.New("salary component version is no longer current")
+
+// ErrSalaryComponentEffectiveFrom is returned when a new version
+// would start before the version it replaces.
+var ErrSalaryComponentEffectiveFrom = errors.New("effective date is before the current version")
+
 type Repository interface {
 	Create(ctx context.Context, dto *entity.SalaryComponentDto) (*entity.SalaryComponentDto, error)
 	Get(ctx context.Context, id string) (*entity.SalaryComponentDto, error)
-	Update(ctx context.Context, dto *entity.SalaryComponentDto) (*entity.SalaryComponentDto, error)
-	Delete(ctx context.Context, id string) error
+	// Supersede closes the current version `id` the day before
+	// next.EffectiveFrom and inserts next as its successor.
+	Supersede(ctx context.Context, id string, next *entity.SalaryComponentDto) (*entity.SalaryComponentDto, error)
+	// End closes the current version `id` on effectiveTo, leaving
+	// the band without a successor.
+	End(ctx context.Context, id string, effectiveTo time.Time) error
 	FindAll(ctx context.Context, req *entity.SalaryComponentFindAllRequest) (*pagination.ResultPagination, error)
-	// FindByCompany returns every salary_component row scoped to
-	// the given company, ordered by minimum_target ASC. Used by the
-	// stock-session close path to resolve the per-session salary
-	// breakdown; the ascending order matches the picking logic in
-	// entity.StockSessionDto.RecomputeSalary (the highest
-	// minimum_target the driver still clears wins, so we want to
-	// walk the list from low to high).
-	FindByCompany(ctx context.Context, companyID string) ([]*entity.SalaryComponentDto, error)
+	// FindByCompany returns the salary_component versions of the
+	// given company valid on `on`, ordered by minimum_target ASC.
+	// Used by the stock-session close path to resolve the
+	// per-session salary breakdown; the ascending order matches the
+	// picking logic in entity.StockSessionDto.RecomputeSalary (the
+	// highest minimum_target the driver still clears wins, so we
+	// want to walk the list from low to high).
+	FindByCompany(ctx context.Context, companyID string, on time.Time) ([]*entity.SalaryComponentDto, error)
 }
 
 type repository struct {
@@ -49,15 +64,61 @@ func (r *repository) Get(ctx context.Context, id string) (*entity.SalaryComponen
 	return entity.NewSalaryComponentDtoFromModel(&m), nil
 }
 
-func (r *repository) Update(ctx context.Context, dto *entity.SalaryComponentDto) (*entity.SalaryComponentDto, error) {
-	if err := r.db.Save(dto.ToModel()).Error; err != nil {
+func (r *repository) Supersede(
+	ctx context.Context,
+	id string,
+	next *entity.SalaryComponentDto,
+) (*entity.SalaryComponentDto, error) {
+	m := next.ToModel()
+	m.ID = ""
+	m.PreviousID = id
+	m.EffectiveTo = nil
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		current, err := lockCurrent(tx, id)
+		if err != nil {
+			return err
+		}
+		if m.EffectiveFrom.Before(current.EffectiveFrom) {
+			return ErrSalaryComponentEffectiveFrom
+		}
+		// A version replaced on its first day ends before it
+		// starts; it never applied and stays only as history.
+		end := m.EffectiveFrom.AddDate(0, 0, -1)
+		current.EffectiveTo = &end
+		if err := tx.Model(current).Select("effective_to").Updates(current).Error; err != nil {
+			return err
+		}
+		return tx.Create(m).Error
+	})
+	if err != nil {
 		return nil, err
 	}
-	return dto, nil
+	return entity.NewSalaryComponentDtoFromModel(m), nil
 }
 
-func (r *repository) Delete(ctx context.Context, id string) error {
-	return r.db.Where("id = ?", id).Delete(&model.SalaryComponent{}).Error
+func (r *repository) End(ctx context.Context, id string, effectiveTo time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		current, err := lockCurrent(tx, id)
+		if err != nil {
+			return err
+		}
+		current.EffectiveTo = &effectiveTo
+		return tx.Model(current).Select("effective_to").Updates(current).Error
+	})
+}
+
+// lockCurrent locks version `id` and checks nothing superseded it.
+func lockCurrent(tx *gorm.DB, id string) (*model.SalaryComponent, error) {
+	var m model.SalaryComponent
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", id).
+		First(&m).Error; err != nil {
+		return nil, err
+	}
+	if m.EffectiveTo != nil {
+		return nil, ErrSalaryComponentSuperseded
+	}
+	return &m, nil
 }
 
 func (r *repository) FindAll(
@@ -76,12 +137,19 @@ func (r *repository) FindAll(
 				req.FindAllRequest.OrganizationData.ID,
 			)
 		}
+		switch {
+		case req.History:
+		case req.On != "":
+			q = q.Where("effective_from <= ? AND (effective_to IS NULL OR effective_to >= ?)", req.On, req.On)
+		default:
+			q = q.Where("effective_to IS NULL")
+		}
 		return q
 	}, &pagination.TableRequest{
 		Request:       req,
 		QueryField:    []string{},
 		Data:          &rows,
-		AllowedFields: []string{"company_id", "component_type", "minimum_target", "amount"},
+		AllowedFields: []string{"company_id", "component_type", "minimum_target", "amount", "effective_from"},
 	})
 	if err != nil {
 		return nil, err
@@ -101,9 +169,9 @@ func (r *repository) FindAll(
 	}, nil
 }
 
-// findByCompany returns every salary_component row for a company,
-// ordered by minimum_target ASC then component_type ASC for a
-// stable tie-break.
+// findByCompany returns the salary_component versions of a company
+// valid on one date, ordered by minimum_target ASC then
+// component_type ASC for a stable tie-break.
 //
 // Why ordered:
 //   - The stock-session close path picks the highest
@@ -119,6 +187,7 @@ func (r *repository) FindAll(
 func (r *repository) FindByCompany(
 	ctx context.Context,
 	companyID string,
+	on time.Time,
 ) ([]*entity.SalaryComponentDto, error) {
 	if companyID == "" {
 		return []*entity.SalaryComponentDto{}, nil
@@ -126,6 +195,7 @@ func (r *repository) FindByCompany(
 	var rows []model.SalaryComponent
 	if err := r.db.WithContext(ctx).
 		Where("company_id = ?", companyID).
+		Where("effective_from <= ? AND (effective_to IS NULL OR effective_to >= ?)", on, on).
 		Order("minimum_target ASC, component_type ASC").
 		Find(&rows).Error; err != nil {
 		return nil, err
@@ -2,22 +2,32 @@ package salarycomponent
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/raymondsugiarto/coffee-api/pkg/entity"
 	shared "github.com/raymondsugiarto/coffee-api/pkg/shared/context"
 	"github.com/raymondsugiarto/coffee-api/pkg/shared/pagination"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/response/status"
+	"gorm.io/gorm"
 )
 
 type Service interface {
 	Create(ctx context.Context, dto *entity.SalaryComponentDto) (*entity.SalaryComponentDto, error)
 	Get(ctx context.Context, id string) (*entity.SalaryComponentDto, error)
+	// Update never rewrites a band: it closes the current version
+	// and returns the new one, effective from dto.EffectiveFrom
+	// (today or later; defaults to today).
 	Update(ctx context.Context, dto *entity.SalaryComponentDto) (*entity.SalaryComponentDto, error)
+	// Delete ends the current version yesterday, so the band no
+	// longer applies from today while past sessions keep it.
 	Delete(ctx context.Context, id string) error
 	FindAll(ctx context.Context, req *entity.SalaryComponentFindAllRequest) (*pagination.ResultPagination, error)
-	// FindByCompany returns the salary bands for one company,
-	// ordered by minimum_target ASC. Used by the stock-session
-	// close path so the picking logic can walk the list directly.
-	FindByCompany(ctx context.Context, companyID string) ([]*entity.SalaryComponentDto, error)
+	// FindByCompany returns the salary bands for one company valid
+	// on `on`, ordered by minimum_target ASC. Used by the
+	// stock-session close path so the picking logic can walk the
+	// list directly.
+	FindByCompany(ctx context.Context, companyID string, on time.Time) ([]*entity.SalaryComponentDto, error)
 }
 
 type service struct {
@@ -28,10 +38,29 @@ func NewService(repo Repository) Service {
 	return &service{repo: repo}
 }
 
+// jakarta is the business day's time zone, as the scheduler uses.
+var jakarta = func() *time.Location {
+	loc, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		return time.FixedZone("WIB", 7*60*60)
+	}
+	return loc
+}()
+
+func today() time.Time {
+	y, m, d := time.Now().In(jakarta).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, jakarta)
+}
+
 func (s *service) Create(ctx context.Context, dto *entity.SalaryComponentDto) (*entity.SalaryComponentDto, error) {
 	if dto.OrganizationID == "" {
 		dto.OrganizationID = shared.GetOrganization(ctx).ID
 	}
+	if dto.EffectiveFrom == "" {
+		dto.EffectiveFrom = today().Format("2006-01-02")
+	}
+	dto.EffectiveTo = ""
+	dto.PreviousID = ""
 	return s.repo.Create(ctx, dto)
 }
 
@@ -40,14 +69,53 @@ func (s *service) Get(ctx context.Context, id string) (*entity.SalaryComponentDt
 }
 
 func (s *service) Update(ctx context.Context, dto *entity.SalaryComponentDto) (*entity.SalaryComponentDto, error) {
+	current, err := s.repo.Get(ctx, dto.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, status.New(status.EntityNotFound, err)
+		}
+		return nil, err
+	}
+	// A version changes the values of a band, not which band it is.
+	if dto.CompanyID != current.CompanyID || dto.ComponentType != current.ComponentType {
+		return nil, status.New(status.BadRequest, errors.New("company and component type of a salary component cannot change"))
+	}
+	dto.OrganizationID = current.OrganizationID
 	if dto.OrganizationID == "" {
 		dto.OrganizationID = shared.GetOrganization(ctx).ID
 	}
-	return s.repo.Update(ctx, dto)
+	if dto.EffectiveFrom == "" {
+		dto.EffectiveFrom = today().Format("2006-01-02")
+	}
+	// Payroll recomputes a session's pay from the versions valid on
+	// its date, so a backdated version would rewrite pay already
+	// earned.
+	if dto.EffectiveFrom < today().Format("2006-01-02") {
+		return nil, status.New(status.BadRequest, errors.New("effective date cannot be before today"))
+	}
+	result, err := s.repo.Supersede(ctx, dto.ID, dto)
+	if err != nil {
+		return nil, versionError(err)
+	}
+	return result, nil
 }
 
 func (s *service) Delete(ctx context.Context, id string) error {
-	return s.repo.Delete(ctx, id)
+	return versionError(s.repo.End(ctx, id, today().AddDate(0, 0, -1)))
+}
+
+func versionError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return status.New(status.EntityNotFound, err)
+	case errors.Is(err, ErrSalaryComponentSuperseded):
+		return status.New(status.EntityConflict, err)
+	case errors.Is(err, ErrSalaryComponentEffectiveFrom):
+		return status.New(status.BadRequest, err)
+	}
+	return err
 }
 
 func (s *service) FindAll(
@@ -75,6 +143,7 @@ func (s *service) FindAll(
 func (s *service) FindByCompany(
 	ctx context.Context,
 	companyID string,
+	on time.Time,
 ) ([]*entity.SalaryComponentDto, error) {
-	return s.repo.FindByCompany(ctx, companyID)
+	return s.repo.FindByCompany(ctx, companyID, on)
 }
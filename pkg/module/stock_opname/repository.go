@@ -0,0 +1,265 @@
+package stockopname
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	"github.com/raymondsugiarto/coffee-api/pkg/model"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/pagination"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Repository interface {
+	Create(ctx context.Context, dto *entity.StockOpnameDto) (*entity.StockOpnameDto, error)
+	Get(ctx context.Context, id string) (*entity.StockOpnameDto, error)
+	// SaveCounts records counted quantities on a DRAFT count,
+	// keyed by item id. Items not on the count are refused
+	// (ErrStockOpnameUnknownItem).
+	SaveCounts(ctx context.Context, id string, counts map[string]int) (*entity.StockOpnameDto, error)
+	// Approve locks a DRAFT count whose lines are all counted,
+	// snapshots the items' cost prices, stores the totals and marks
+	// it APPROVED. afterSave runs inside the same transaction (to
+	// move the stock and post the ledger); an error rolls the
+	// approval back. afterSave may set saved.JournalEntryID; it is
+	// stored on the count.
+	Approve(
+		ctx context.Context,
+		id, actor string,
+		afterSave func(tx *gorm.DB, saved *entity.StockOpnameDto) error,
+	) (*entity.StockOpnameDto, error)
+	// Cancel marks a DRAFT count CANCELLED.
+	Cancel(ctx context.Context, id string) (*entity.StockOpnameDto, error)
+	FindAll(ctx context.Context, req *entity.StockOpnameFindAllRequest) (*pagination.ResultPagination, error)
+	// Snapshot returns one uncounted line per item with its current
+	// depot on-hand: the given items, or every active item of the
+	// organization when itemIDs is empty.
+	Snapshot(ctx context.Context, organizationID string, itemIDs []string) ([]entity.StockOpnameLineDto, error)
+}
+
+var (
+	// ErrStockOpnameNotDraft is returned when counting, approving
+	// or cancelling a count that is already APPROVED or CANCELLED.
+	ErrStockOpnameNotDraft = errors.New("stock opname is no longer a draft")
+	// ErrStockOpnameUnknownItem is returned by SaveCounts for an
+	// item that is not on the count.
+	ErrStockOpnameUnknownItem = errors.New("item is not on this stock opname")
+	// ErrStockOpnameUncounted is returned by Approve while some
+	// lines have no counted quantity.
+	ErrStockOpnameUncounted = errors.New("stock opname has items that are not counted yet")
+)
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) Create(ctx context.Context, dto *entity.StockOpnameDto) (*entity.StockOpnameDto, error) {
+	m := dto.ToModel()
+	if err := r.db.WithContext(ctx).Create(m).Error; err != nil {
+		return nil, err
+	}
+	return r.Get(ctx, m.ID)
+}
+
+func (r *repository) Get(ctx context.Context, id string) (*entity.StockOpnameDto, error) {
+	var m model.StockOpname
+	if err := r.db.WithContext(ctx).
+		Preload("Lines", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC") }).
+		Preload("Lines.Item").
+		Where("id = ?", id).
+		First(&m).Error; err != nil {
+		return nil, err
+	}
+	return entity.NewStockOpnameDtoFromModel(&m), nil
+}
+
+func (r *repository) SaveCounts(ctx context.Context, id string, counts map[string]int) (*entity.StockOpnameDto, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		opname, err := lockDraft(tx, id)
+		if err != nil {
+			return err
+		}
+		lines := make(map[string]*model.StockOpnameLine, len(opname.Lines))
+		for i := range opname.Lines {
+			lines[opname.Lines[i].ItemID] = &opname.Lines[i]
+		}
+		for itemID, qty := range counts {
+			line, ok := lines[itemID]
+			if !ok {
+				return ErrStockOpnameUnknownItem
+			}
+			if err := tx.Model(line).Update("counted_qty", qty).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.Get(ctx, id)
+}
+
+func (r *repository) Approve(
+	ctx context.Context,
+	id, actor string,
+	afterSave func(tx *gorm.DB, saved *entity.StockOpnameDto) error,
+) (*entity.StockOpnameDto, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		opname, err := lockDraft(tx, id)
+		if err != nil {
+			return err
+		}
+		itemIDs := make([]string, 0, len(opname.Lines))
+		for _, l := range opname.Lines {
+			if l.CountedQty == nil {
+				return ErrStockOpnameUncounted
+			}
+			itemIDs = append(itemIDs, l.ItemID)
+		}
+		var items []model.Item
+		if err := tx.Where("id IN ?", itemIDs).Find(&items).Error; err != nil {
+			return err
+		}
+		costs := make(map[string]float64, len(items))
+		for _, it := range items {
+			costs[it.ID] = it.CostPrice
+		}
+		for i := range opname.Lines {
+			line := &opname.Lines[i]
+			line.CostPrice = costs[line.ItemID]
+			if err := tx.Model(line).Update("cost_price", line.CostPrice).Error; err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		opname.Status = entity.StockOpnameStatusApproved
+		opname.ApprovedBy = actor
+		opname.ApprovedAt = &now
+		saved := entity.NewStockOpnameDtoFromModel(opname)
+		opname.TotalShrinkage = saved.TotalShrinkage
+		opname.TotalSurplus = saved.TotalSurplus
+		if err := tx.Model(opname).
+			Select("status", "approved_by", "approved_at", "total_shrinkage", "total_surplus").
+			Updates(opname).Error; err != nil {
+			return err
+		}
+		if afterSave != nil {
+			if err := afterSave(tx, saved); err != nil {
+				return err
+			}
+			if saved.JournalEntryID != "" {
+				return tx.Model(opname).Update("journal_entry_id", saved.JournalEntryID).Error
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.Get(ctx, id)
+}
+
+func (r *repository) Cancel(ctx context.Context, id string) (*entity.StockOpnameDto, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		opname, err := lockDraft(tx, id)
+		if err != nil {
+			return err
+		}
+		return tx.Model(opname).Update("status", entity.StockOpnameStatusCancelled).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.Get(ctx, id)
+}
+
+func (r *repository) FindAll(
+	ctx context.Context,
+	req *entity.StockOpnameFindAllRequest,
+) (*pagination.ResultPagination, error) {
+	var rows []model.StockOpname = make([]model.StockOpname, 0)
+	tbl := pagination.NewTable(r.db)
+	dataTable, err := tbl.Pagination(func(i interface{}) *gorm.DB {
+		return r.db.WithContext(ctx).Model(&model.StockOpname{}).
+			Preload("Lines").
+			Preload("Lines.Item").
+			Where("organization_id = ?", req.FindAllRequest.OrganizationData.ID)
+	}, &pagination.TableRequest{
+		Request:       req,
+		QueryField:    []string{"code", "notes"},
+		Data:          &rows,
+		AllowedFields: []string{"code", "date", "status", "created_at"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := dataTable.(*pagination.ResultPagination)
+	hits := result.Data.(*[]model.StockOpname)
+	out := make([]*entity.StockOpnameDto, 0, len(*hits))
+	for i := range *hits {
+		out = append(out, entity.NewStockOpnameDtoFromModel(&(*hits)[i]))
+	}
+	return &pagination.ResultPagination{
+		Data:        out,
+		Page:        result.Page,
+		Count:       result.Count,
+		RowsPerPage: result.RowsPerPage,
+		TotalPages:  result.TotalPages,
+	}, nil
+}
+
+func (r *repository) Snapshot(
+	ctx context.Context,
+	organizationID string,
+	itemIDs []string,
+) ([]entity.StockOpnameLineDto, error) {
+	var rows []struct {
+		ItemID string
+		OnHand int
+	}
+	q := r.db.WithContext(ctx).
+		Table("item").
+		Select("item.id AS item_id, COALESCE(item_stock.on_hand, 0) AS on_hand").
+		Joins("LEFT JOIN item_stock ON item_stock.item_id = item.id AND item_stock.organization_id = ? AND item_stock.deleted_at IS NULL", organizationID).
+		Where("item.deleted_at IS NULL").
+		Where("item.organization_id IS NULL OR item.organization_id = '' OR item.organization_id = ?", organizationID)
+	if len(itemIDs) > 0 {
+		q = q.Where("item.id IN ?", itemIDs)
+	} else {
+		q = q.Where("item.is_active = ?", true)
+	}
+	if err := q.Order("item.name ASC").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]entity.StockOpnameLineDto, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, entity.StockOpnameLineDto{ItemID: row.ItemID, SystemQty: row.OnHand})
+	}
+	return out, nil
+}
+
+// lockDraft loads a count with its lines, the header row locked,
+// and refuses anything but a DRAFT.
+func lockDraft(tx *gorm.DB, id string) (*model.StockOpname, error) {
+	var opname model.StockOpname
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", id).
+		First(&opname).Error; err != nil {
+		return nil, err
+	}
+	if opname.Status != entity.StockOpnameStatusDraft {
+		return nil, ErrStockOpnameNotDraft
+	}
+	if err := tx.Where("stock_opname_id = ?", id).Find(&opname.Lines).Error; err != nil {
+		return nil, err
+	}
+	return &opname, nil
+}
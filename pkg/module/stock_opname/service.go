@@ -0,0 +1,245 @@
+package stockopname
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	gonanoid "github.com/matoous/go-nanoid/v2"
+	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	accounting "github.com/raymondsugiarto/coffee-api/pkg/module/accounting"
+	"github.com/raymondsugiarto/coffee-api/pkg/module/inventory"
+	shared "github.com/raymondsugiarto/coffee-api/pkg/shared/context"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/pagination"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/response/status"
+	"gorm.io/gorm"
+)
+
+// Service runs physical stock counts of the depot. The variance of
+// each item is measured against the system quantity snapshotted
+// when the count was started, so stock moved while the count is
+// open (session loads, receipts) is not mistaken for shrinkage.
+type Service interface {
+	// Start opens a DRAFT count with the system quantities of the
+	// requested items (every active item by default).
+	Start(ctx context.Context, req *entity.StockOpnameCreateRequest) (*entity.StockOpnameDto, error)
+	Get(ctx context.Context, id string) (*entity.StockOpnameDto, error)
+	// Count records counted quantities on a DRAFT count.
+	Count(ctx context.Context, id string, req *entity.StockOpnameCountRequest) (*entity.StockOpnameDto, error)
+	// Variances returns the lines whose count differs from the
+	// system quantity.
+	Variances(ctx context.Context, id string) (*entity.StockOpnameVarianceDto, error)
+	// Approve books every variance as an ADJUSTMENT stock movement
+	// and posts its value (STOCK_OPNAME_APPROVED) in one
+	// transaction.
+	Approve(ctx context.Context, id string) (*entity.StockOpnameDto, error)
+	Cancel(ctx context.Context, id string) (*entity.StockOpnameDto, error)
+	FindAll(ctx context.Context, req *entity.StockOpnameFindAllRequest) (*pagination.ResultPagination, error)
+}
+
+type service struct {
+	repo             Repository
+	inventoryService inventory.Service
+	postingService   accounting.PostingService
+	periodGuard      accounting.PeriodGuard
+}
+
+// NewService wires stock counts. Approval adjusts the depot through
+// `inventoryService` and books the variance value through
+// `postingService`; `periodGuard` keeps counts dated in a closed
+// accounting period from being approved.
+func NewService(
+	repo Repository,
+	inventoryService inventory.Service,
+	postingService accounting.PostingService,
+	periodGuard accounting.PeriodGuard,
+) Service {
+	return &service{
+		repo:             repo,
+		inventoryService: inventoryService,
+		postingService:   postingService,
+		periodGuard:      periodGuard,
+	}
+}
+
+func (s *service) Start(ctx context.Context, req *entity.StockOpnameCreateRequest) (*entity.StockOpnameDto, error) {
+	orgID := shared.GetOrganization(ctx).ID
+	if len(req.ItemIDs) > 0 {
+		if err := s.inventoryService.EnsureItems(ctx, req.ItemIDs); err != nil {
+			return nil, err
+		}
+	}
+	lines, err := s.repo.Snapshot(ctx, orgID, req.ItemIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return nil, status.New(status.BadRequest, errors.New("there are no items to count"))
+	}
+	date := req.Date
+	if date == "" {
+		date = time.Now().Format("2006-01-02")
+	}
+	suffix, _ := gonanoid.Generate("ABCDEFGHIJKLMNOPQRSTUVWXYZ", 4)
+	return s.repo.Create(ctx, &entity.StockOpnameDto{
+		OrganizationID: orgID,
+		Code:           "SO/" + strings.ReplaceAll(date, "-", "") + "/" + suffix,
+		Date:           date,
+		Status:         entity.StockOpnameStatusDraft,
+		Notes:          req.Notes,
+		CreatedBy:      actorOf(ctx),
+		Lines:          lines,
+	})
+}
+
+func (s *service) Get(ctx context.Context, id string) (*entity.StockOpnameDto, error) {
+	opname, err := s.repo.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, status.New(status.EntityNotFound, err)
+		}
+		return nil, err
+	}
+	if opname.OrganizationID != shared.GetOrganization(ctx).ID {
+		return nil, status.New(status.EntityNotFound, errors.New("stock opname not found"))
+	}
+	return opname, nil
+}
+
+func (s *service) Count(
+	ctx context.Context,
+	id string,
+	req *entity.StockOpnameCountRequest,
+) (*entity.StockOpnameDto, error) {
+	if _, err := s.Get(ctx, id); err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int, len(req.Items))
+	for _, it := range req.Items {
+		if _, dup := counts[it.ItemID]; dup {
+			return nil, status.New(status.BadRequest, fmt.Errorf("item %s is counted twice", it.ItemID))
+		}
+		counts[it.ItemID] = it.CountedQty
+	}
+	result, err := s.repo.SaveCounts(ctx, id, counts)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return result, nil
+}
+
+func (s *service) Variances(ctx context.Context, id string) (*entity.StockOpnameVarianceDto, error) {
+	opname, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return entity.NewStockOpnameVarianceDto(opname), nil
+}
+
+func (s *service) Approve(ctx context.Context, id string) (*entity.StockOpnameDto, error) {
+	opname, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.periodGuard.EnsureOpen(ctx, opname.Date); err != nil {
+		return nil, err
+	}
+	result, err := s.repo.Approve(ctx, id, actorOf(ctx), func(tx *gorm.DB, saved *entity.StockOpnameDto) error {
+		return s.bookVariances(ctx, tx, saved)
+	})
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return result, nil
+}
+
+// bookVariances runs inside the approval transaction. Each line
+// moves the depot by its variance, so whatever moved since the
+// count started stays on top of the counted quantity. The default
+// rules (migration 000033) post:
+//
+//	Dr 6103 Beban Selisih Persediaan total_shrinkage
+//	    Cr 1301 Persediaan           total_shrinkage
+//	Dr 1301 Persediaan               total_surplus
+//	    Cr 6103 Beban Selisih Persediaan total_surplus
+func (s *service) bookVariances(ctx context.Context, tx *gorm.DB, d *entity.StockOpnameDto) error {
+	movements := make([]*entity.StockMovementDto, 0)
+	for _, l := range d.Lines {
+		if l.Variance == 0 {
+			continue
+		}
+		movements = append(movements, &entity.StockMovementDto{
+			OrganizationID: d.OrganizationID,
+			ItemID:         l.ItemID,
+			Date:           d.Date,
+			Type:           entity.StockMovementTypeAdjustment,
+			Qty:            l.Variance,
+			RefID:          d.ID,
+			RefTable:       entity.AccountMutationRefTableStockOpname,
+			Reference:      d.Code,
+			Notes:          d.Notes,
+			CreatedBy:      d.ApprovedBy,
+		})
+	}
+	if _, err := s.inventoryService.WithTx(tx).Post(ctx, movements); err != nil {
+		return err
+	}
+
+	entry, err := s.postingService.WithTx(tx).PostEvent(ctx, &entity.PostingEventDto{
+		OrganizationID: d.OrganizationID,
+		EventType:      entity.PostingEventOpnameApproved,
+		RefID:          d.ID,
+		RefTable:       entity.AccountMutationRefTableStockOpname,
+		RefModule:      entity.AccountMutationRefModuleInventory,
+		Description:    fmt.Sprintf("Stock opname %s", d.Code),
+		Amounts: map[string]float64{
+			entity.PostingAmountTotalShrinkage: d.TotalShrinkage,
+			entity.PostingAmountTotalSurplus:   d.TotalSurplus,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if entry != nil {
+		d.JournalEntryID = entry.ID
+	}
+	return nil
+}
+
+func (s *service) Cancel(ctx context.Context, id string) (*entity.StockOpnameDto, error) {
+	if _, err := s.Get(ctx, id); err != nil {
+		return nil, err
+	}
+	result, err := s.repo.Cancel(ctx, id)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return result, nil
+}
+
+func (s *service) FindAll(
+	ctx context.Context,
+	req *entity.StockOpnameFindAllRequest,
+) (*pagination.ResultPagination, error) {
+	req.FindAllRequest.OrganizationData.ID = shared.GetOrganization(ctx).ID
+	return s.repo.FindAll(ctx, req)
+}
+
+func mapError(err error) error {
+	switch {
+	case errors.Is(err, ErrStockOpnameNotDraft):
+		return status.New(status.EntityConflict, err)
+	case errors.Is(err, ErrStockOpnameUnknownItem), errors.Is(err, ErrStockOpnameUncounted):
+		return status.New(status.BadRequest, err)
+	}
+	return err
+}
+
+func actorOf(ctx context.Context) string {
+	if cred := shared.GetUserCredential(ctx); cred != nil {
+		return cred.AdminID
+	}
+	return ""
+}
@@ -0,0 +1,159 @@
+package stocksession
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+
+	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	shared "github.com/raymondsugiarto/coffee-api/pkg/shared/context"
+	"gorm.io/gorm"
+)
+
+// logDetail is the JSON kept in session_log.detail: the session
+// fields and item quantities a write changed, keyed by their wire
+// names (items by item id), plus what triggered an automatic
+// action.
+type logDetail struct {
+	Changes map[string]fieldChange            `json:"changes,omitempty"`
+	Items   map[string]map[string]fieldChange `json:"items,omitempty"`
+	Cutoff  string                            `json:"cutoff,omitempty"`
+	Reason  string                            `json:"reason,omitempty"`
+}
+
+// fieldChange is one changed value; From is null on open, To is
+// null on delete.
+type fieldChange struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+func (d *logDetail) String() string {
+	b, _ := json.Marshal(d)
+	return string(b)
+}
+
+// sessionFields are the audited session columns.
+func sessionFields(d *entity.StockSessionDto) map[string]interface{} {
+	if d == nil {
+		return nil
+	}
+	return map[string]interface{}{
+		"employeeId":      d.EmployeeID,
+		"date":            d.Date,
+		"shift":           d.Shift,
+		"status":          d.Status,
+		"notes":           d.Notes,
+		"cashDebt":        d.CashDebt,
+		"totalSales":      d.TotalSales,
+		"totalCash":       d.TotalCash,
+		"totalQris":       d.TotalQris,
+		"totalOther":      d.TotalOther,
+		"totalPayment":    d.TotalPayment,
+		"difference":      d.Difference,
+		"totalItems":      d.TotalItems,
+		"totalCommission": d.TotalCommission,
+		"totalSalary":     d.TotalSalary,
+	}
+}
+
+// itemFields are the audited quantities of each item, by item id.
+func itemFields(d *entity.StockSessionDto) map[string]map[string]interface{} {
+	out := make(map[string]map[string]interface{})
+	if d == nil {
+		return out
+	}
+	for _, it := range d.Items {
+		out[it.ItemID] = map[string]interface{}{
+			"outQty":          it.OutQty,
+			"returnQty":       it.ReturnQty,
+			"cashSoldQty":     it.CashSoldQty,
+			"cashlessSoldQty": it.CashlessSoldQty,
+		}
+	}
+	return out
+}
+
+// diffFields compares two field sets; a nil set stands for a
+// session (or item) that does not exist on that side, whose zero
+// values are left out.
+func diffFields(before, after map[string]interface{}) map[string]fieldChange {
+	out := make(map[string]fieldChange)
+	for key := range union(before, after) {
+		from, hadFrom := before[key]
+		to, hadTo := after[key]
+		switch {
+		case !hadFrom && isZero(to), !hadTo && isZero(from):
+			continue
+		case hadFrom && hadTo && from == to:
+			continue
+		}
+		out[key] = fieldChange{From: from, To: to}
+	}
+	return out
+}
+
+func union(a, b map[string]interface{}) map[string]bool {
+	keys := make(map[string]bool, len(a)+len(b))
+	for k := range a {
+		keys[k] = true
+	}
+	for k := range b {
+		keys[k] = true
+	}
+	return keys
+}
+
+func isZero(v interface{}) bool {
+	return v == nil || reflect.ValueOf(v).IsZero()
+}
+
+// sessionDiff fills d (a fresh detail when nil) with what changed
+// between before and after; either may be nil on open and delete.
+func sessionDiff(d *logDetail, before, after *entity.StockSessionDto) *logDetail {
+	if d == nil {
+		d = &logDetail{}
+	}
+	if changes := diffFields(sessionFields(before), sessionFields(after)); len(changes) > 0 {
+		d.Changes = changes
+	}
+	beforeItems, afterItems := itemFields(before), itemFields(after)
+	ids := make(map[string]bool, len(beforeItems)+len(afterItems))
+	for id := range beforeItems {
+		ids[id] = true
+	}
+	for id := range afterItems {
+		ids[id] = true
+	}
+	for id := range ids {
+		changes := diffFields(beforeItems[id], afterItems[id])
+		if len(changes) == 0 {
+			continue
+		}
+		if d.Items == nil {
+			d.Items = make(map[string]map[string]fieldChange)
+		}
+		d.Items[id] = changes
+	}
+	return d
+}
+
+// appendLog writes the audit row of a session write inside the
+// write's transaction, so a rolled-back write leaves no trace.
+func (s *service) appendLog(
+	ctx context.Context,
+	tx *gorm.DB,
+	sessionID, actorID, action string,
+	detail *logDetail,
+) error {
+	return s.repo.WithTx(tx).AppendLog(ctx, &entity.SessionLogDto{
+		SessionID: sessionID,
+		Action:    action,
+		AdminID:   actorID,
+		Detail:    detail.String(),
+	})
+}
+
+func (s *service) FindLogs(ctx context.Context, id string) ([]*entity.SessionLogDto, error) {
+	return s.repo.FindLogs(ctx, shared.GetOrganization(ctx).ID, id)
+}
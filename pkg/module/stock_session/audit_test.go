@@ -0,0 +1,72 @@
+package stocksession
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		before map[string]interface{}
+		after  map[string]interface{}
+		want   map[string]fieldChange
+	}{
+		{
+			name:   "nothing changed",
+			before: map[string]interface{}{"status": "OPEN", "cashDebt": 1000.0},
+			after:  map[string]interface{}{"status": "OPEN", "cashDebt": 1000.0},
+			want:   map[string]fieldChange{},
+		},
+		{
+			name:   "changed values only",
+			before: map[string]interface{}{"status": "OPEN", "notes": "a", "outQty": 10},
+			after:  map[string]interface{}{"status": "CLOSED", "notes": "a", "outQty": 12},
+			want: map[string]fieldChange{
+				"status": {From: "OPEN", To: "CLOSED"},
+				"outQty": {From: 10, To: 12},
+			},
+		},
+		{
+			name:   "change to a zero value is kept",
+			before: map[string]interface{}{"returnQty": 3, "notes": "late"},
+			after:  map[string]interface{}{"returnQty": 0, "notes": ""},
+			want: map[string]fieldChange{
+				"returnQty": {From: 3, To: 0},
+				"notes":     {From: "late", To: ""},
+			},
+		},
+		{
+			name:   "open leaves out zero values",
+			before: nil,
+			after:  map[string]interface{}{"status": "OPEN", "notes": "", "outQty": 5, "returnQty": 0},
+			want: map[string]fieldChange{
+				"status": {From: nil, To: "OPEN"},
+				"outQty": {From: nil, To: 5},
+			},
+		},
+		{
+			name:   "delete leaves out zero values",
+			before: map[string]interface{}{"status": "OPEN", "totalSales": 0.0, "outQty": 5},
+			after:  nil,
+			want: map[string]fieldChange{
+				"status": {From: "OPEN", To: nil},
+				"outQty": {From: 5, To: nil},
+			},
+		},
+		{
+			name:   "both sides missing",
+			before: nil,
+			after:  nil,
+			want:   map[string]fieldChange{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := diffFields(tt.before, tt.after)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diffFields() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package stocksession
+
+import (
+	"context"
+
+	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	"github.com/raymondsugiarto/coffee-api/pkg/model"
+)
+
+// applyCommissionPlans re-prices the session's commission with the
+// organization's active commission plans. It runs at close only:
+// open sessions show the flat item rate as an estimate, and the
+// close snapshots the plans it used on the session, so closed
+// sessions do not move when a plan is edited later.
+//
+// Unlike the salary lookup this fails the close on error: a
+// commission silently falling back to the flat rate would be paid
+// out wrongly.
+func (s *service) applyCommissionPlans(ctx context.Context, dto *entity.StockSessionDto) error {
+	plans, err := s.commissionPlanService.FindActive(ctx, dto.OrganizationID)
+	if err != nil {
+		return err
+	}
+	if len(plans) == 0 {
+		return nil
+	}
+	companyID, err := s.companyOf(ctx, dto.EmployeeID)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]string, 0, len(dto.Items))
+	for _, it := range dto.Items {
+		ids = append(ids, it.ItemID)
+	}
+	// A variant without its own category is priced as its parent.
+	var rows []struct {
+		ID         string
+		CategoryID string
+	}
+	if err := s.db.WithContext(ctx).
+		Table("item i").
+		Select("i.id, COALESCE(NULLIF(i.category_id, ''), p.category_id) AS category_id").
+		Joins("LEFT JOIN item p ON p.id = NULLIF(i.parent_id, '')").
+		Where("i.id IN ?", ids).
+		Scan(&rows).Error; err != nil {
+		return err
+	}
+	categoryOf := make(map[string]string, len(rows))
+	for _, r := range rows {
+		categoryOf[r.ID] = r.CategoryID
+	}
+
+	dto.ApplyCommissionPlans(plans, companyID, categoryOf)
+	return nil
+}
+
+// companyOf returns the company the driver is bound to through
+// admin_company, or "" when there is none.
+func (s *service) companyOf(ctx context.Context, employeeID string) (string, error) {
+	var companyID string
+	err := s.db.WithContext(ctx).
+		Model(&model.AdminCompany{}).
+		Select("company_id").
+		Where("admin_id = ?", employeeID).
+		Scan(&companyID).Error
+	return companyID, err
+}
@@ -0,0 +1,67 @@
+package stocksession
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	"gorm.io/gorm"
+)
+
+// closePostingAmounts exposes the closed session totals as the
+// amount fields of the STOCK_SESSION_CLOSED event. Which accounts
+// they hit is decided by the posting rules; the default rules
+// (migration 000017) produce:
+//
+//	Dr Kas               total_cash        (net of cash_debt)
+//	Dr Kliring QRIS      total_qris
+//	Dr Bank              total_other       (TRANSFER / OTHER)
+//	Dr Piutang Kas Bon   cash_debt
+//	Dr Piutang Driver    driver_shortage   (credit when overage)
+//	    Cr Penjualan     total_sales
+//	Dr Beban Komisi      total_commission
+//	    Cr Hutang Komisi total_commission
+//	Dr HPP               total_cogs        (migration 000021)
+//	    Cr Persediaan    total_cogs
+//
+// DRIVER_SHORTAGE is the till shortage not already explained by the
+// cash advance. With unclamped totals it is exactly
+// -(difference + cash_debt); deriving it from total_sales instead
+// keeps the entry balanced even when RecomputeTotals clamps
+// total_cash / total_payment at 0.
+//
+// TOTAL_COGS values the units sold at the cost price snapshotted
+// on each session item.
+func closePostingAmounts(d *entity.StockSessionDto) map[string]float64 {
+	shortage := d.TotalSales - d.TotalCash - d.TotalQris - d.TotalOther - d.CashDebt
+	var cogs float64
+	for _, it := range d.Items {
+		cogs += float64(it.SoldQty) * it.CostPriceSnapshot
+	}
+	return map[string]float64{
+		entity.PostingAmountTotalCash:       d.TotalCash,
+		entity.PostingAmountTotalQris:       d.TotalQris,
+		entity.PostingAmountTotalOther:      d.TotalOther,
+		entity.PostingAmountTotalSales:      d.TotalSales,
+		entity.PostingAmountCashDebt:        d.CashDebt,
+		entity.PostingAmountDriverShortage:  shortage,
+		entity.PostingAmountTotalCommission: d.TotalCommission,
+		entity.PostingAmountTotalCogs:       cogs,
+	}
+}
+
+// postCloseToLedger fires STOCK_SESSION_CLOSED inside the close
+// transaction. A rule pointing at a missing account fails the close
+// rather than leaving a session whose totals the ledger never saw.
+func (s *service) postCloseToLedger(ctx context.Context, tx *gorm.DB, d *entity.StockSessionDto) error {
+	_, err := s.postingService.WithTx(tx).PostEvent(ctx, &entity.PostingEventDto{
+		OrganizationID: d.OrganizationID,
+		EventType:      entity.PostingEventStockSessionClosed,
+		RefID:          d.ID,
+		RefTable:       entity.AccountMutationRefTableStockSession,
+		RefModule:      entity.AccountMutationRefModuleStockSession,
+		Description:    fmt.Sprintf("Stock session close %s", d.Date),
+		Amounts:        closePostingAmounts(d),
+	})
+	return err
+}
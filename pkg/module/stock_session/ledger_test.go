@@ -0,0 +1,105 @@
+package stocksession
+
+import (
+	"math"
+	"testing"
+
+	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+)
+
+func TestClosePostingAmounts(t *testing.T) {
+	tests := []struct {
+		name    string
+		session entity.StockSessionDto
+		want    map[string]float64
+	}{
+		{
+			name: "till matches sales",
+			session: entity.StockSessionDto{
+				TotalSales: 100000,
+				TotalCash:  60000,
+				TotalQris:  40000,
+			},
+			want: map[string]float64{
+				entity.PostingAmountTotalCash:      60000,
+				entity.PostingAmountTotalQris:      40000,
+				entity.PostingAmountTotalSales:     100000,
+				entity.PostingAmountDriverShortage: 0,
+			},
+		},
+		{
+			name: "shortage net of cash advance",
+			session: entity.StockSessionDto{
+				TotalSales: 100000,
+				TotalCash:  50000,
+				TotalQris:  30000,
+				TotalOther: 5000,
+				CashDebt:   10000,
+			},
+			want: map[string]float64{
+				entity.PostingAmountTotalCash:      50000,
+				entity.PostingAmountTotalQris:      30000,
+				entity.PostingAmountTotalOther:     5000,
+				entity.PostingAmountTotalSales:     100000,
+				entity.PostingAmountCashDebt:       10000,
+				entity.PostingAmountDriverShortage: 5000,
+			},
+		},
+		{
+			name: "overage is a negative shortage",
+			session: entity.StockSessionDto{
+				TotalSales: 100000,
+				TotalCash:  70000,
+				TotalQris:  35000,
+			},
+			want: map[string]float64{
+				entity.PostingAmountTotalCash:      70000,
+				entity.PostingAmountTotalQris:      35000,
+				entity.PostingAmountTotalSales:     100000,
+				entity.PostingAmountDriverShortage: -5000,
+			},
+		},
+		{
+			name: "cogs at snapshotted cost and commission",
+			session: entity.StockSessionDto{
+				TotalSales:      90000,
+				TotalCash:       90000,
+				TotalCommission: 7500,
+				Items: []entity.StockSessionItemDto{
+					{ItemID: "a", SoldQty: 10, CostPriceSnapshot: 4000},
+					{ItemID: "b", SoldQty: 5, CostPriceSnapshot: 2500.5},
+					{ItemID: "c", SoldQty: 0, CostPriceSnapshot: 9999},
+				},
+			},
+			want: map[string]float64{
+				entity.PostingAmountTotalCash:       90000,
+				entity.PostingAmountTotalSales:      90000,
+				entity.PostingAmountTotalCommission: 7500,
+				entity.PostingAmountTotalCogs:       52502.5,
+			},
+		},
+		{
+			name:    "empty session posts nothing",
+			session: entity.StockSessionDto{},
+			want:    map[string]float64{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := closePostingAmounts(&tt.session)
+			for _, field := range entity.PostingRuleAmountFields[entity.PostingEventStockSessionClosed] {
+				if math.Abs(got[field]-tt.want[field]) > 1e-9 {
+					t.Errorf("%s = %v, want %v", field, got[field], tt.want[field])
+				}
+			}
+			// Whatever the till says, the entry balances: the
+			// receipts, the advance and the shortage make up the sales.
+			sum := got[entity.PostingAmountTotalCash] + got[entity.PostingAmountTotalQris] +
+				got[entity.PostingAmountTotalOther] + got[entity.PostingAmountCashDebt] +
+				got[entity.PostingAmountDriverShortage]
+			if math.Abs(sum-got[entity.PostingAmountTotalSales]) > 1e-9 {
+				t.Errorf("debits %v do not balance sales %v", sum, got[entity.PostingAmountTotalSales])
+			}
+		})
+	}
+}
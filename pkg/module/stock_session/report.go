@@ -6,11 +6,13 @@ import (
 
 	"github.com/raymondsugiarto/coffee-api/pkg/entity"
 	"github.com/raymondsugiarto/coffee-api/pkg/model"
+	shared "github.com/raymondsugiarto/coffee-api/pkg/shared/context"
 	"gorm.io/gorm"
 )
 
 func (s *service) GetDashboard(ctx context.Context) (*entity.DashboardSummaryDto, error) {
 	today := time.Now().Format("2006-01-02")
+	orgID := shared.GetOrganization(ctx).ID
 
 	type aggRow struct {
 		TotalSales     float64
@@ -22,6 +24,8 @@ func (s *service) GetDashboard(ctx context.Context) (*entity.DashboardSummaryDto
 		TotalSessions  int64
 	}
 
+	// A session flagged for review is still open: it has not been
+	// closed yet, so it counts as open until someone closes it.
 	var row aggRow
 	err := s.db.Raw(`
 		SELECT
@@ -29,12 +33,16 @@ func (s *service) GetDashboard(ctx context.Context) (*entity.DashboardSummaryDto
 			COALESCE(SUM(total_cash), 0) AS total_cash,
 			COALESCE(SUM(total_qris), 0) AS total_qris,
 			COUNT(*) AS transactions,
-			SUM(CASE WHEN status = 'OPEN' THEN 1 ELSE 0 END) AS open_sessions,
-			SUM(CASE WHEN status = 'CLOSED' THEN 1 ELSE 0 END) AS closed_sessions,
+			COALESCE(SUM(CASE WHEN status IN (?, ?) THEN 1 ELSE 0 END), 0) AS open_sessions,
+			COALESCE(SUM(CASE WHEN status = ? THEN 1 ELSE 0 END), 0) AS closed_sessions,
 			COUNT(*) AS total_sessions
 		FROM stock_session
-		WHERE date = ?
-	`, today).Scan(&row).Error
+		WHERE date = ? AND organization_id = ?
+	`,
+		entity.StockSessionStatusOpen, entity.StockSessionStatusPendingReview,
+		entity.StockSessionStatusClosed,
+		today, orgID,
+	).Scan(&row).Error
 	if err != nil {
 		return nil, err
 	}
@@ -51,8 +59,17 @@ func (s *service) GetDashboard(ctx context.Context) (*entity.DashboardSummaryDto
 			COALESCE(SUM(CASE WHEN pd.payment_method = 'QRIS' THEN pd.amount ELSE 0 END), 0) AS total_qris
 		FROM payment_detail pd
 		JOIN stock_session ss ON ss.id = pd.session_id
-		WHERE ss.date = ?
-	`, today).Scan(&p)
+		WHERE ss.date = ? AND ss.organization_id = ?
+	`, today, orgID).Scan(&p)
+
+	// Flagged sessions stay on the dashboard until someone closes
+	// them, not just on their own date.
+	var pendingReview int64
+	if err := s.db.Model(&model.StockSession{}).
+		Where("organization_id = ? AND status = ?", orgID, entity.StockSessionStatusPendingReview).
+		Count(&pendingReview).Error; err != nil {
+		return nil, err
+	}
 
 	return &entity.DashboardSummaryDto{
 		TodaySales:        row.TotalSales,
@@ -62,6 +79,8 @@ func (s *service) GetDashboard(ctx context.Context) (*entity.DashboardSummaryDto
 		OpenSessions:      int(row.OpenSessions),
 		ClosedSessions:    int(row.ClosedSessions),
 		TotalSessions:     int(row.TotalSessions),
+
+		PendingReviewSessions: int(pendingReview),
 	}, nil
 }
 
@@ -77,7 +96,9 @@ func (s *service) GetDailyReport(ctx context.Context, date string) (*entity.Dail
 	}
 
 	byEmployee := make(map[string]*entity.EmployeeReportRowDto)
+	byShift := make(map[string]*entity.ShiftReportRowDto)
 	for _, ss := range sessions {
+		addShiftRow(byShift, &ss)
 		report.Sessions++
 		report.TotalSales += ss.TotalSales
 		report.TotalCash += ss.TotalCash
@@ -106,6 +127,8 @@ func (s *service) GetDailyReport(ctx context.Context, date string) (*entity.Dail
 		row.TotalSalary += ss.TotalSalary
 	}
 
+	report.ByShift = shiftRows(byShift)
+
 	// Hydrate employee names
 	if len(byEmployee) > 0 {
 		ids := make([]string, 0, len(byEmployee))
@@ -146,7 +169,9 @@ func (s *service) GetMonthlyReport(ctx context.Context, year, month int) (*entit
 
 	byDate := make(map[string]*entity.DailyReportDto)
 	byEmployee := make(map[string]*entity.EmployeeReportRowDto)
+	byShift := make(map[string]*entity.ShiftReportRowDto)
 	for _, ss := range sessions {
+		addShiftRow(byShift, &ss)
 		dateKey := ss.Date.Format("2006-01-02")
 		report.Sessions++
 		report.TotalSales += ss.TotalSales
@@ -189,6 +214,7 @@ func (s *service) GetMonthlyReport(ctx context.Context, year, month int) (*entit
 		row.TotalSalary += ss.TotalSalary
 	}
 
+	report.ByShift = shiftRows(byShift)
 	if len(byDate) > 0 {
 		for _, d := range byDate {
 			report.Daily = append(report.Daily, *d)
@@ -271,6 +297,8 @@ func (s *service) GetEmployeePerformance(ctx context.Context, from, to string) (
 		FirstName     string
 		LastName      string
 		Sessions      int64
+		Morning       int64
+		Evening       int64
 		TotalItems    int64
 		TotalSales    float64
 		TotalCash     float64
@@ -288,6 +316,8 @@ func (s *service) GetEmployeePerformance(ctx context.Context, from, to string) (
 		        a.first_name as first_name,
 		        a.last_name as last_name,
 		        COUNT(*) as sessions,
+		        SUM(CASE WHEN ss.shift = 'MORNING' THEN 1 ELSE 0 END) as morning,
+		        SUM(CASE WHEN ss.shift = 'EVENING' THEN 1 ELSE 0 END) as evening,
 		        COALESCE(SUM(ss.total_items), 0) as total_items,
 		        COALESCE(SUM(ss.total_sales), 0) as total_sales,
 		        COALESCE(SUM(ss.total_cash), 0) as total_cash,
@@ -320,10 +350,43 @@ func (s *service) GetEmployeePerformance(ctx context.Context, from, to string) (
 			MealAllowance: r.MealAllowance,
 			BonusTarget:   r.BonusTarget,
 			TotalSalary:   r.TotalSalary,
+
+			MorningSessions: int(r.Morning),
+			EveningSessions: int(r.Evening),
 		})
 	}
 	return out, nil
 }
 
+// addShiftRow adds a session to its shift's report row.
+func addShiftRow(byShift map[string]*entity.ShiftReportRowDto, ss *model.StockSession) {
+	shift := string(ss.Shift)
+	row, ok := byShift[shift]
+	if !ok {
+		row = &entity.ShiftReportRowDto{Shift: shift}
+		byShift[shift] = row
+	}
+	row.Sessions++
+	row.TotalItems += ss.TotalItems
+	row.TotalSales += ss.TotalSales
+	row.TotalCash += ss.TotalCash
+	row.TotalQris += ss.TotalQris
+	row.TotalDiff += ss.Difference
+	row.TotalCommission += ss.TotalCommission
+	row.TotalSalary += ss.TotalSalary
+}
+
+// shiftRows lists the shift rows MORNING first; shifts without
+// sessions are left out.
+func shiftRows(byShift map[string]*entity.ShiftReportRowDto) []entity.ShiftReportRowDto {
+	out := make([]entity.ShiftReportRowDto, 0, len(byShift))
+	for _, shift := range []string{entity.StockSessionShiftMorning, entity.StockSessionShiftEvening} {
+		if row, ok := byShift[shift]; ok {
+			out = append(out, *row)
+		}
+	}
+	return out
+}
+
 // ensure gorm.DB is referenced for compilation
 var _ = gorm.ErrRecordNotFound
@@ -2,6 +2,7 @@ package stocksession
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/raymondsugiarto/coffee-api/pkg/entity"
@@ -10,15 +11,50 @@ import (
 	"gorm.io/gorm"
 )
 
+// Repository persists stock sessions. Every write takes an
+// afterSave hook that runs inside the write's transaction; an error
+// from it rolls the write back, so side-effects such as ledger and
+// stock postings land atomically with the session.
 type Repository interface {
-	Create(ctx context.Context, dto *entity.StockSessionDto) (*entity.StockSessionDto, error)
+	// WithTx returns a repository bound to the caller's transaction.
+	WithTx(tx *gorm.DB) Repository
+	Create(ctx context.Context, dto *entity.StockSessionDto, afterSave func(tx *gorm.DB, saved *entity.StockSessionDto) error) (*entity.StockSessionDto, error)
 	Get(ctx context.Context, id string) (*entity.StockSessionDto, error)
-	GetByEmployeeDate(ctx context.Context, employeeID, date string) (*entity.StockSessionDto, error)
-	Update(ctx context.Context, dto *entity.StockSessionDto) (*entity.StockSessionDto, error)
-	Delete(ctx context.Context, id string) error
+	// GetByEmployeeDate returns the driver's session for the date
+	// and shift. An empty shift picks the day's session that is not
+	// CLOSED yet, or the latest one opened when every shift is
+	// closed.
+	GetByEmployeeDate(ctx context.Context, employeeID, date, shift string) (*entity.StockSessionDto, error)
+	Update(ctx context.Context, dto *entity.StockSessionDto, afterSave func(tx *gorm.DB, saved *entity.StockSessionDto) error) (*entity.StockSessionDto, error)
+	// Close persists the closing write exactly like Update, then
+	// runs afterSave inside the same transaction with the reloaded
+	// session. An error from afterSave rolls the close back, so
+	// side-effects such as ledger postings land atomically with it.
+	Close(ctx context.Context, dto *entity.StockSessionDto, afterSave func(tx *gorm.DB, saved *entity.StockSessionDto) error) (*entity.StockSessionDto, error)
+	Delete(ctx context.Context, id string, afterDelete func(tx *gorm.DB) error) error
 	FindAll(ctx context.Context, req *entity.StockSessionFindAllRequest) (*pagination.ResultPagination, error)
+	// FindOpenUntil returns the OPEN sessions of every organization
+	// dated on or before date (YYYY-MM-DD), with their items.
+	FindOpenUntil(ctx context.Context, date string) ([]*entity.StockSessionDto, error)
+	// FlagPendingReview moves a session from OPEN to PENDING_REVIEW
+	// and writes entry in the same transaction. A session that is no
+	// longer OPEN is left alone (ErrSessionNotOpen).
+	FlagPendingReview(ctx context.Context, id string, entry *entity.SessionLogDto) error
+	// AppendLog writes one session_log row.
+	AppendLog(ctx context.Context, entry *entity.SessionLogDto) error
+	// FindLogs returns a session's log rows, oldest first. Rows of a
+	// deleted session are still returned.
+	FindLogs(ctx context.Context, organizationID, sessionID string) ([]*entity.SessionLogDto, error)
+	// StaleSessionPolicy reads the organization's policy, defaulting
+	// to PENDING_REVIEW.
+	StaleSessionPolicy(ctx context.Context, organizationID string) (string, error)
+	SetStaleSessionPolicy(ctx context.Context, organizationID, policy string) error
 }
 
+// ErrSessionNotOpen is returned by FlagPendingReview when the
+// session was closed or flagged in the meantime.
+var ErrSessionNotOpen = errors.New("stock session is no longer open")
+
 type repository struct {
 	db *gorm.DB
 }
@@ -27,7 +63,15 @@ func NewRepository(db *gorm.DB) Repository {
 	return &repository{db: db}
 }
 
-func (r *repository) Create(ctx context.Context, dto *entity.StockSessionDto) (*entity.StockSessionDto, error) {
+func (r *repository) WithTx(tx *gorm.DB) Repository {
+	return &repository{db: tx}
+}
+
+func (r *repository) Create(
+	ctx context.Context,
+	dto *entity.StockSessionDto,
+	afterSave func(tx *gorm.DB, saved *entity.StockSessionDto) error,
+) (*entity.StockSessionDto, error) {
 	var result *entity.StockSessionDto
 	err := r.db.Transaction(func(tx *gorm.DB) error {
 		m := dto.ToModel()
@@ -62,6 +106,9 @@ func (r *repository) Create(ctx context.Context, dto *entity.StockSessionDto) (*
 			return err
 		}
 		result = entity.NewStockSessionDtoFromModel(&reloaded)
+		if afterSave != nil {
+			return afterSave(tx, result)
+		}
 		return nil
 	})
 	return result, err
@@ -81,27 +128,48 @@ func (r *repository) Get(ctx context.Context, id string) (*entity.StockSessionDt
 	return entity.NewStockSessionDtoFromModel(m), nil
 }
 
-func (r *repository) GetByEmployeeDate(ctx context.Context, employeeID, date string) (*entity.StockSessionDto, error) {
+func (r *repository) GetByEmployeeDate(ctx context.Context, employeeID, date, shift string) (*entity.StockSessionDto, error) {
 	var m *model.StockSession
 	parsedDate, err := time.Parse("2006-01-02", date)
 	if err != nil {
 		return nil, err
 	}
-	if err := r.db.
+	q := r.db.
 		Preload("Employee").
 		Preload("Items").
 		Preload("Items.Item").
-		Where("employee_id = ? AND date = ?", employeeID, parsedDate).First(&m).Error; err != nil {
+		Where("employee_id = ? AND date = ?", employeeID, parsedDate)
+	if shift != "" {
+		q = q.Where("shift = ?", shift)
+	} else {
+		q = q.Order("CASE WHEN status <> 'CLOSED' THEN 0 ELSE 1 END, opened_at DESC")
+	}
+	if err := q.First(&m).Error; err != nil {
 		return nil, err
 	}
 	return entity.NewStockSessionDtoFromModel(m), nil
 }
 
-func (r *repository) Update(ctx context.Context, dto *entity.StockSessionDto) (*entity.StockSessionDto, error) {
-	return r.updateInTx(dto)
+func (r *repository) Update(
+	ctx context.Context,
+	dto *entity.StockSessionDto,
+	afterSave func(tx *gorm.DB, saved *entity.StockSessionDto) error,
+) (*entity.StockSessionDto, error) {
+	return r.updateInTx(dto, afterSave)
+}
+
+func (r *repository) Close(
+	ctx context.Context,
+	dto *entity.StockSessionDto,
+	afterSave func(tx *gorm.DB, saved *entity.StockSessionDto) error,
+) (*entity.StockSessionDto, error) {
+	return r.updateInTx(dto, afterSave)
 }
 
-func (r *repository) updateInTx(dto *entity.StockSessionDto) (*entity.StockSessionDto, error) {
+func (r *repository) updateInTx(
+	dto *entity.StockSessionDto,
+	afterSave func(tx *gorm.DB, saved *entity.StockSessionDto) error,
+) (*entity.StockSessionDto, error) {
 	var result *entity.StockSessionDto
 	err := r.db.Transaction(func(tx *gorm.DB) error {
 		m := dto.ToModel()
@@ -174,6 +242,9 @@ func (r *repository) updateInTx(dto *entity.StockSessionDto) (*entity.StockSessi
 			return err
 		}
 		result = entity.NewStockSessionDtoFromModel(&reloaded)
+		if afterSave != nil {
+			return afterSave(tx, result)
+		}
 		return nil
 	})
 	return result, err
@@ -184,7 +255,8 @@ func (r *repository) updateInTx(dto *entity.StockSessionDto) (*entity.StockSessi
 // single transaction so a partial failure cannot leave orphan
 // children behind. Callers must have already verified the session is
 // still OPEN — this repository method is a pure SQL primitive.
-func (r *repository) Delete(ctx context.Context, id string) error {
+// afterDelete runs last inside the same transaction.
+func (r *repository) Delete(ctx context.Context, id string, afterDelete func(tx *gorm.DB) error) error {
 	return r.db.Transaction(func(tx *gorm.DB) error {
 		// Child rows first so the parent delete can never race
 		// with the cascading cleanup.
@@ -202,6 +274,9 @@ func (r *repository) Delete(ctx context.Context, id string) error {
 		if err := tx.Where("id = ?", id).Delete(&model.StockSession{}).Error; err != nil {
 			return err
 		}
+		if afterDelete != nil {
+			return afterDelete(tx)
+		}
 		return nil
 	})
 }
@@ -219,7 +294,7 @@ func (r *repository) FindAll(ctx context.Context, req *entity.StockSessionFindAl
 		Request:       req,
 		QueryField:    []string{},
 		Data:          &m,
-		AllowedFields: []string{"date", "status", "total_sales"},
+		AllowedFields: []string{"date", "shift", "status", "total_sales"},
 	})
 	if err != nil {
 		return nil, err
@@ -241,3 +316,81 @@ func (r *repository) FindAll(ctx context.Context, req *entity.StockSessionFindAl
 		TotalPages:  result.TotalPages,
 	}, nil
 }
+
+func (r *repository) FindOpenUntil(ctx context.Context, date string) ([]*entity.StockSessionDto, error) {
+	parsedDate, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return nil, err
+	}
+	var rows []model.StockSession
+	if err := r.db.WithContext(ctx).
+		Preload("Items").
+		Where("status = ? AND date <= ?", entity.StockSessionStatusOpen, parsedDate).
+		Order("date ASC, opened_at ASC").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]*entity.StockSessionDto, 0, len(rows))
+	for i := range rows {
+		out = append(out, entity.NewStockSessionDtoFromModel(&rows[i]))
+	}
+	return out, nil
+}
+
+func (r *repository) FlagPendingReview(ctx context.Context, id string, entry *entity.SessionLogDto) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&model.StockSession{}).
+			Where("id = ? AND status = ?", id, entity.StockSessionStatusOpen).
+			Update("status", entity.StockSessionStatusPendingReview)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrSessionNotOpen
+		}
+		return tx.Create(entry.ToModel()).Error
+	})
+}
+
+func (r *repository) AppendLog(ctx context.Context, entry *entity.SessionLogDto) error {
+	return r.db.WithContext(ctx).Create(entry.ToModel()).Error
+}
+
+func (r *repository) FindLogs(ctx context.Context, organizationID, sessionID string) ([]*entity.SessionLogDto, error) {
+	var rows []model.SessionLog
+	if err := r.db.WithContext(ctx).
+		Joins("JOIN stock_session ON stock_session.id = session_log.session_id").
+		Where("session_log.session_id = ? AND stock_session.organization_id = ?", sessionID, organizationID).
+		Order("session_log.created_at ASC").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]*entity.SessionLogDto, 0, len(rows))
+	for i := range rows {
+		out = append(out, entity.NewSessionLogDtoFromModel(&rows[i]))
+	}
+	return out, nil
+}
+
+func (r *repository) StaleSessionPolicy(ctx context.Context, organizationID string) (string, error) {
+	var policy string
+	if err := r.db.WithContext(ctx).
+		Model(&model.Organization{}).
+		Where("id = ?", organizationID).
+		Select("stale_session_policy").
+		Limit(1).
+		Scan(&policy).Error; err != nil {
+		return "", err
+	}
+	if policy == "" {
+		policy = entity.StaleSessionPolicyPendingReview
+	}
+	return policy, nil
+}
+
+func (r *repository) SetStaleSessionPolicy(ctx context.Context, organizationID, policy string) error {
+	return r.db.WithContext(ctx).
+		Model(&model.Organization{}).
+		Where("id = ?", organizationID).
+		Update("stale_session_policy", policy).Error
+}
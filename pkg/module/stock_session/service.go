@@ -9,6 +9,10 @@ import (
 	"github.com/gofiber/fiber/v2/log"
 	"github.com/raymondsugiarto/coffee-api/pkg/entity"
 	"github.com/raymondsugiarto/coffee-api/pkg/model"
+	accounting "github.com/raymondsugiarto/coffee-api/pkg/module/accounting"
+	commissionplan "github.com/raymondsugiarto/coffee-api/pkg/module/commission_plan"
+	"github.com/raymondsugiarto/coffee-api/pkg/module/inventory"
+	"github.com/raymondsugiarto/coffee-api/pkg/module/recipe"
 	salarycomponent "github.com/raymondsugiarto/coffee-api/pkg/module/salary_component"
 	shared "github.com/raymondsugiarto/coffee-api/pkg/shared/context"
 	"github.com/raymondsugiarto/coffee-api/pkg/shared/pagination"
@@ -20,22 +24,45 @@ import (
 type Service interface {
 	Open(ctx context.Context, dto *entity.StockSessionDto, actorID string) (*entity.StockSessionDto, error)
 	Get(ctx context.Context, id string) (*entity.StockSessionDto, error)
-	GetByEmployeeDate(ctx context.Context, employeeID, date string) (*entity.StockSessionDto, error)
+	// GetByEmployeeDate returns the driver's session for the date
+	// and shift; an empty shift picks the day's unclosed session
+	// first.
+	GetByEmployeeDate(ctx context.Context, employeeID, date, shift string) (*entity.StockSessionDto, error)
 	Update(ctx context.Context, dto *entity.StockSessionDto, actorID string) (*entity.StockSessionDto, error)
 	Delete(ctx context.Context, id string, actorID string) error
 	Close(ctx context.Context, id string, dto *entity.StockSessionDto, actorID string) (*entity.StockSessionDto, error)
+	// CarryOver opens the EVENING session of a closed MORNING one,
+	// loaded with the morning returns plus any extra items.
+	CarryOver(ctx context.Context, id string, req *entity.CarryOverStockSessionInputDto, actorID string) (*entity.StockSessionDto, error)
 	FindAll(ctx context.Context, req *entity.StockSessionFindAllRequest) (*pagination.ResultPagination, error)
 	GetDashboard(ctx context.Context) (*entity.DashboardSummaryDto, error)
 	GetDailyReport(ctx context.Context, date string) (*entity.DailyReportDto, error)
 	GetMonthlyReport(ctx context.Context, year int, month int) (*entity.MonthlyReportDto, error)
 	GetTopProducts(ctx context.Context, from, to string, limit int) ([]entity.TopProductRowDto, error)
 	GetEmployeePerformance(ctx context.Context, from, to string) ([]entity.EmployeePerformanceRowDto, error)
+	// ResolveStale handles the sessions of every organization still
+	// OPEN once cutoff (HH:MM, Asia/Jakarta) of their date has passed
+	// at now. Per the organization's policy each one is auto-closed
+	// with nothing sold, or flagged PENDING_REVIEW; either way a
+	// session_log row records it.
+	ResolveStale(ctx context.Context, cutoff string, now time.Time) (*entity.StaleSessionResultDto, error)
+	// FindLogs returns the session's audit trail, oldest first: one
+	// row per open, update, close and delete with the actor and the
+	// changed fields.
+	FindLogs(ctx context.Context, id string) ([]*entity.SessionLogDto, error)
+	GetSettings(ctx context.Context) (*entity.StockSessionSettingsDto, error)
+	UpdateSettings(ctx context.Context, dto *entity.StockSessionSettingsDto) (*entity.StockSessionSettingsDto, error)
 }
 
 type service struct {
 	repo                   Repository
 	db                     *gorm.DB
 	salaryComponentService salarycomponent.Service
+	commissionPlanService  commissionplan.Service
+	postingService         accounting.PostingService
+	periodGuard            accounting.PeriodGuard
+	inventoryService       inventory.Service
+	recipeService          recipe.Service
 }
 
 // NewService wires the dependencies. `salaryComponentService` is
@@ -44,15 +71,41 @@ type service struct {
 // asks the salarycomponent module. That keeps the SQL behind the
 // module boundary (the same module already powers the HTTP CRUD),
 // so order-by-minimum_target tuning lives in one place.
+//
+// `commissionPlanService` supplies the commission plans Close
+// prices the session with.
+//
+// `postingService` is the accounting module's event entry point:
+// Close fires STOCK_SESSION_CLOSED through it inside the close
+// transaction and the posting rules decide which accounts move.
+// `periodGuard` refuses every write to a session dated inside a
+// closed accounting period.
+//
+// `inventoryService` keeps the depot stock ledger in step with the
+// session: every write syncs the items loaded (and, at close, the
+// items returned) inside the write's transaction.
+//
+// `recipeService` explodes the units sold into theoretical
+// ingredient usage when the session closes.
 func NewService(
 	repo Repository,
 	db *gorm.DB,
 	salaryComponentService salarycomponent.Service,
+	commissionPlanService commissionplan.Service,
+	postingService accounting.PostingService,
+	periodGuard accounting.PeriodGuard,
+	inventoryService inventory.Service,
+	recipeService recipe.Service,
 ) Service {
 	return &service{
 		repo:                   repo,
 		db:                     db,
 		salaryComponentService: salaryComponentService,
+		commissionPlanService:  commissionPlanService,
+		postingService:         postingService,
+		periodGuard:            periodGuard,
+		inventoryService:       inventoryService,
+		recipeService:          recipeService,
 	}
 }
 
@@ -66,13 +119,21 @@ func (s *service) Open(ctx context.Context, dto *entity.StockSessionDto, actorID
 	if len(dto.Items) == 0 {
 		return nil, status.New(status.BadRequest, errors.New("at least one item is required"))
 	}
+	if dto.Shift == "" {
+		dto.Shift = entity.StockSessionShiftMorning
+	}
+	if err := s.periodGuard.EnsureOpen(ctx, dto.Date); err != nil {
+		return nil, err
+	}
 	// Open-time items carry a morning OutQty (>= 1, validated on the
 	// wire DTO). The internal DTO has already been normalised from
 	// the open wire shape by the handler, so OutQty is always set here.
 
-	// Reject duplicate (employee, date)
-	if existing, _ := s.repo.GetByEmployeeDate(ctx, dto.EmployeeID, dto.Date); existing != nil {
-		return nil, status.New(status.BadRequest, errors.New("stock session already exists for this employee and date"))
+	// Reject duplicate (employee, date, shift)
+	if existing, _ := s.repo.GetByEmployeeDate(ctx, dto.EmployeeID, dto.Date, dto.Shift); existing != nil {
+		return nil, status.New(status.BadRequest, fmt.Errorf(
+			"%s stock session already exists for this employee and date", dto.Shift,
+		))
 	}
 
 	// Verify driver is EMPLOYEE
@@ -93,7 +154,7 @@ func (s *service) Open(ctx context.Context, dto *entity.StockSessionDto, actorID
 	dto.RecomputeTotals()
 	s.resolveAndApplySalary(ctx, dto)
 
-	result, err := s.repo.Create(ctx, dto)
+	result, err := s.repo.Create(ctx, dto, s.syncStock(ctx, entity.SessionActionOpen, actorID, nil))
 	if err != nil {
 		return nil, err
 	}
@@ -105,8 +166,8 @@ func (s *service) Get(ctx context.Context, id string) (*entity.StockSessionDto,
 	return s.repo.Get(ctx, id)
 }
 
-func (s *service) GetByEmployeeDate(ctx context.Context, employeeID, date string) (*entity.StockSessionDto, error) {
-	return s.repo.GetByEmployeeDate(ctx, employeeID, date)
+func (s *service) GetByEmployeeDate(ctx context.Context, employeeID, date, shift string) (*entity.StockSessionDto, error) {
+	return s.repo.GetByEmployeeDate(ctx, employeeID, date, shift)
 }
 
 // Delete removes an OPEN morning session. Closed sessions are
@@ -121,11 +182,27 @@ func (s *service) Delete(ctx context.Context, id string, actorID string) error {
 	if existing.Status == entity.StockSessionStatusClosed {
 		return status.New(status.BadRequest, errors.New("cannot delete closed session"))
 	}
+	if err := s.periodGuard.EnsureOpen(ctx, existing.Date); err != nil {
+		return err
+	}
 	log.WithContext(ctx).Infof(
 		"[stock-session/delete] removing OPEN session id=%s actor=%s",
 		id, actorID,
 	)
-	return s.repo.Delete(ctx, id)
+	return s.repo.Delete(ctx, id, func(tx *gorm.DB) error {
+		// With no items left, the sync puts the session's load back
+		// into the depot.
+		err := s.inventoryService.WithTx(tx).SyncSession(ctx, &entity.StockSessionDto{
+			ID:             existing.ID,
+			OrganizationID: existing.OrganizationID,
+			Date:           existing.Date,
+			Shift:          existing.Shift,
+		})
+		if err != nil {
+			return err
+		}
+		return s.appendLog(ctx, tx, id, actorID, entity.SessionActionDelete, sessionDiff(nil, existing, nil))
+	})
 }
 
 func (s *service) Update(ctx context.Context, dto *entity.StockSessionDto, actorID string) (*entity.StockSessionDto, error) {
@@ -136,17 +213,39 @@ func (s *service) Update(ctx context.Context, dto *entity.StockSessionDto, actor
 	if existing.Status == entity.StockSessionStatusClosed {
 		return nil, status.New(status.BadRequest, errors.New("cannot update closed session"))
 	}
+	if err := s.periodGuard.EnsureOpen(ctx, existing.Date); err != nil {
+		return nil, err
+	}
+	if dto.Date != "" && dto.Date != existing.Date {
+		if err := s.periodGuard.EnsureOpen(ctx, dto.Date); err != nil {
+			return nil, err
+		}
+	}
+	if dto.Shift == "" {
+		dto.Shift = existing.Shift
+	}
+	if existing.CarriedFromID != "" && dto.Shift != entity.StockSessionShiftEvening {
+		return nil, status.New(status.BadRequest, errors.New("a carried-over session must stay on the EVENING shift"))
+	}
+	if dto.EmployeeID != existing.EmployeeID || dto.Date != existing.Date || dto.Shift != existing.Shift {
+		if other, _ := s.repo.GetByEmployeeDate(ctx, dto.EmployeeID, dto.Date, dto.Shift); other != nil && other.ID != dto.ID {
+			return nil, status.New(status.BadRequest, fmt.Errorf(
+				"%s stock session already exists for this employee and date", dto.Shift,
+			))
+		}
+	}
 	if err := s.hydrateItemSnapshots(ctx, dto); err != nil {
 		return nil, err
 	}
 	dto.OrganizationID = existing.OrganizationID
+	dto.CarriedFromID = existing.CarriedFromID
 	dto.Status = existing.Status
 	dto.OpenedAt = existing.OpenedAt
 	dto.CreatedBy = existing.CreatedBy
 	dto.RecomputeTotals()
 	s.resolveAndApplySalary(ctx, dto)
 
-	result, err := s.repo.Update(ctx, dto)
+	result, err := s.repo.Update(ctx, dto, s.syncStock(ctx, entity.SessionActionUpdate, actorID, existing))
 	if err != nil {
 		return nil, err
 	}
@@ -154,6 +253,18 @@ func (s *service) Update(ctx context.Context, dto *entity.StockSessionDto, actor
 }
 
 func (s *service) Close(ctx context.Context, id string, dto *entity.StockSessionDto, actorID string) (*entity.StockSessionDto, error) {
+	return s.close(ctx, id, dto, actorID, entity.SessionActionClose, nil)
+}
+
+// close is Close, logged as action. detail carries what triggered
+// an automatic close; the changes are added to it.
+func (s *service) close(
+	ctx context.Context,
+	id string,
+	dto *entity.StockSessionDto,
+	actorID, action string,
+	detail *logDetail,
+) (*entity.StockSessionDto, error) {
 	existing, err := s.repo.Get(ctx, id)
 	if err != nil {
 		return nil, err
@@ -161,10 +272,15 @@ func (s *service) Close(ctx context.Context, id string, dto *entity.StockSession
 	if existing.Status == entity.StockSessionStatusClosed {
 		return nil, status.New(status.BadRequest, errors.New("session already closed"))
 	}
+	if err := s.periodGuard.EnsureOpen(ctx, existing.Date); err != nil {
+		return nil, err
+	}
 
 	dto.ID = id
 	dto.EmployeeID = existing.EmployeeID
 	dto.Date = existing.Date
+	dto.Shift = existing.Shift
+	dto.CarriedFromID = existing.CarriedFromID
 	if dto.Notes == "" {
 		dto.Notes = existing.Notes
 	}
@@ -243,15 +359,109 @@ func (s *service) Close(ctx context.Context, id string, dto *entity.StockSession
 	now := time.Now()
 	dto.ClosedAt = &now
 	dto.RecomputeTotals()
+	if err := s.applyCommissionPlans(ctx, dto); err != nil {
+		return nil, err
+	}
 	s.resolveAndApplySalary(ctx, dto)
 
-	result, err := s.repo.Update(ctx, dto)
+	// The ledger and stock postings, the ingredient usage and the
+	// log run inside the close transaction: if any leg fails, the
+	// session stays OPEN and nothing is posted.
+	result, err := s.repo.Close(ctx, dto, func(tx *gorm.DB, saved *entity.StockSessionDto) error {
+		if err := s.postCloseToLedger(ctx, tx, saved); err != nil {
+			return err
+		}
+		if err := s.inventoryService.WithTx(tx).SyncSession(ctx, saved); err != nil {
+			return err
+		}
+		if err := s.recipeService.WithTx(tx).RecordSessionUsage(ctx, saved); err != nil {
+			return err
+		}
+		return s.appendLog(ctx, tx, saved.ID, actorID, action, sessionDiff(detail, existing, saved))
+	})
 	if err != nil {
 		return nil, err
 	}
 	return result, nil
 }
 
+func (s *service) CarryOver(
+	ctx context.Context,
+	id string,
+	req *entity.CarryOverStockSessionInputDto,
+	actorID string,
+) (*entity.StockSessionDto, error) {
+	morning, err := s.repo.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, status.New(status.EntityNotFound, err)
+		}
+		return nil, err
+	}
+	if morning.OrganizationID != shared.GetOrganization(ctx).ID {
+		return nil, status.New(status.EntityNotFound, errors.New("stock session not found"))
+	}
+	if morning.Shift != entity.StockSessionShiftMorning {
+		return nil, status.New(status.BadRequest, errors.New("only a MORNING session can be carried over"))
+	}
+	// The returns are only final once the morning is closed.
+	if morning.Status != entity.StockSessionStatusClosed {
+		return nil, status.New(status.BadRequest, errors.New("close the MORNING session before carrying it over"))
+	}
+
+	// Morning returns first, in their original order; extra items
+	// on the same item add to the carried quantity.
+	qty := make(map[string]int)
+	order := make([]string, 0, len(morning.Items)+len(req.Items))
+	add := func(itemID string, n int) {
+		if n <= 0 {
+			return
+		}
+		if _, ok := qty[itemID]; !ok {
+			order = append(order, itemID)
+		}
+		qty[itemID] += n
+	}
+	for _, it := range morning.Items {
+		add(it.ItemID, it.ReturnQty)
+	}
+	for _, it := range req.Items {
+		add(it.ItemID, it.OutQty)
+	}
+
+	dto := &entity.StockSessionDto{
+		EmployeeID:    morning.EmployeeID,
+		Date:          morning.Date,
+		Shift:         entity.StockSessionShiftEvening,
+		Notes:         req.Notes,
+		CarriedFromID: morning.ID,
+	}
+	for _, itemID := range order {
+		in := &entity.OpenStockSessionItemInputDto{ItemID: itemID, OutQty: qty[itemID]}
+		dto.Items = append(dto.Items, *in.ToStockSessionItemInputDto().ToDto())
+	}
+	if len(dto.Items) == 0 {
+		return nil, status.New(status.BadRequest, errors.New("nothing to carry over: the MORNING session has no returns"))
+	}
+	return s.Open(ctx, dto, actorID)
+}
+
+// syncStock is the afterSave hook of Open and Update: the depot
+// stock follows the items loaded on the saved session, and the write
+// is logged as action against before (nil on open).
+func (s *service) syncStock(
+	ctx context.Context,
+	action, actorID string,
+	before *entity.StockSessionDto,
+) func(tx *gorm.DB, saved *entity.StockSessionDto) error {
+	return func(tx *gorm.DB, saved *entity.StockSessionDto) error {
+		if err := s.inventoryService.WithTx(tx).SyncSession(ctx, saved); err != nil {
+			return err
+		}
+		return s.appendLog(ctx, tx, saved.ID, actorID, action, sessionDiff(nil, before, saved))
+	}
+}
+
 func (s *service) FindAll(ctx context.Context, req *entity.StockSessionFindAllRequest) (*pagination.ResultPagination, error) {
 	if req.FindAllRequest.OrganizationData.ID == "" {
 		req.FindAllRequest.OrganizationData.ID = shared.GetOrganization(ctx).ID
@@ -344,9 +554,10 @@ func (s *service) hydrateItemSnapshots(ctx context.Context, dto *entity.StockSes
 // expose a company-by-employee method yet.
 //
 // The salary breakdown is recomputed on every write (Open / Update /
-// Close) so reports don't need to re-derive it, and so changes to
-// the master salary_component table flow through to past sessions
-// the next time they are touched.
+// Close) so reports don't need to re-derive it. Bands are resolved
+// as of the session date: salary_component rows are versioned, so
+// touching an old session recomputes it with the bands that were in
+// force on its day rather than today's.
 //
 // Errors are logged but non-fatal — a missing company or empty
 // salary bands just produce a 0-amount breakdown for that session.
@@ -354,11 +565,7 @@ func (s *service) resolveAndApplySalary(ctx context.Context, dto *entity.StockSe
 	if dto.EmployeeID == "" {
 		return
 	}
-	var companyID string
-	err := s.db.Model(&model.AdminCompany{}).
-		Select("company_id").
-		Where("admin_id = ?", dto.EmployeeID).
-		Scan(&companyID).Error
+	companyID, err := s.companyOf(ctx, dto.EmployeeID)
 	if err != nil {
 		log.WithContext(ctx).Warnf(
 			"[stock-session] salary lookup failed (admin_company): employee=%s err=%v",
@@ -378,7 +585,15 @@ func (s *service) resolveAndApplySalary(ctx context.Context, dto *entity.StockSe
 	// module. The module orders rows by minimum_target ASC so the
 	// picking loop in RecomputeSalary gets them in the right order
 	// without sorting here.
-	components, err := s.salaryComponentService.FindByCompany(ctx, companyID)
+	sessionDate, err := time.Parse("2006-01-02", dto.Date)
+	if err != nil {
+		log.WithContext(ctx).Warnf(
+			"[stock-session] salary lookup failed (date): session=%s date=%q err=%v",
+			dto.ID, dto.Date, err,
+		)
+		return
+	}
+	components, err := s.salaryComponentService.FindByCompany(ctx, companyID, sessionDate)
 	if err != nil {
 		log.WithContext(ctx).Warnf(
 			"[stock-session] salary lookup failed (salary_component): company=%s err=%v",
@@ -398,15 +613,3 @@ func (s *service) resolveAndApplySalary(ctx context.Context, dto *entity.StockSe
 	}
 	dto.RecomputeSalary(values, dto.TotalItems)
 }
-
-// func (s *service) appendLog(ctx context.Context, sessionID, adminID, action, detail string) {
-// 	logEntry := &model.SessionLog{
-// 		SessionID: sessionID,
-// 		Action:    action,
-// 		AdminID:   adminID,
-// 		Detail:    detail,
-// 	}
-// 	if err := s.db.Create(logEntry).Error; err != nil {
-// 		log.WithContext(ctx).Errorf("failed to write session log: %v", err)
-// 	}
-// }
@@ -0,0 +1,125 @@
+package stocksession
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	shared "github.com/raymondsugiarto/coffee-api/pkg/shared/context"
+)
+
+// defaultStaleCutoff is used when no cutoff is configured.
+const defaultStaleCutoff = "23:59"
+
+func (s *service) ResolveStale(ctx context.Context, cutoff string, now time.Time) (*entity.StaleSessionResultDto, error) {
+	if cutoff == "" {
+		cutoff = defaultStaleCutoff
+	}
+	clock, err := time.Parse("15:04", cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("stale-session cutoff %q is not HH:MM: %w", cutoff, err)
+	}
+	loc, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		return nil, err
+	}
+	now = now.In(loc)
+	sessions, err := s.repo.FindOpenUntil(ctx, now.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+
+	result := &entity.StaleSessionResultDto{}
+	policies := make(map[string]string)
+	for _, session := range sessions {
+		date, err := time.ParseInLocation("2006-01-02", session.Date, loc)
+		if err != nil {
+			return result, err
+		}
+		due := time.Date(date.Year(), date.Month(), date.Day(), clock.Hour(), clock.Minute(), 0, 0, loc)
+		if now.Before(due) {
+			continue
+		}
+		policy, ok := policies[session.OrganizationID]
+		if !ok {
+			if policy, err = s.repo.StaleSessionPolicy(ctx, session.OrganizationID); err != nil {
+				return result, err
+			}
+			policies[session.OrganizationID] = policy
+		}
+		// The write paths read the organization from ctx, as they
+		// would on a request.
+		orgCtx := context.WithValue(ctx, entity.OrganizationKey, &entity.OrganizationData{ID: session.OrganizationID})
+
+		// A session that cannot be closed (e.g. dated in a closed
+		// accounting period) is flagged instead, so it is not
+		// retried every night.
+		reason := ""
+		if policy == entity.StaleSessionPolicyAutoClose {
+			err := s.autoClose(orgCtx, session, due)
+			if err == nil {
+				result.Closed++
+				continue
+			}
+			log.WithContext(ctx).Warnf("[stock-session/stale] auto-close failed id=%s: %v", session.ID, err)
+			reason = "auto-close failed: " + err.Error()
+		}
+		detail := &logDetail{
+			Changes: map[string]fieldChange{
+				"status": {From: entity.StockSessionStatusOpen, To: entity.StockSessionStatusPendingReview},
+			},
+			Cutoff: due.Format(time.RFC3339),
+			Reason: reason,
+		}
+		err = s.repo.FlagPendingReview(orgCtx, session.ID, &entity.SessionLogDto{
+			SessionID: session.ID,
+			Action:    entity.SessionActionFlagForReview,
+			Detail:    detail.String(),
+		})
+		if err != nil {
+			if errors.Is(err, ErrSessionNotOpen) {
+				continue
+			}
+			log.WithContext(ctx).Errorf("[stock-session/stale] flag failed id=%s: %v", session.ID, err)
+			result.Failed++
+			continue
+		}
+		result.Flagged++
+	}
+	return result, nil
+}
+
+// autoClose closes a stale session as if the driver sold nothing
+// and brought everything back: every loaded unit is returned and no
+// payment is recorded. The close is logged as AUTO_CLOSE.
+func (s *service) autoClose(ctx context.Context, session *entity.StockSessionDto, due time.Time) error {
+	dto := &entity.StockSessionDto{Notes: session.Notes}
+	for _, it := range session.Items {
+		dto.Items = append(dto.Items, entity.StockSessionItemDto{ItemID: it.ItemID, ReturnQty: it.OutQty})
+	}
+	_, err := s.close(ctx, session.ID, dto, "", entity.SessionActionAutoClose, &logDetail{
+		Cutoff: due.Format(time.RFC3339),
+	})
+	return err
+}
+
+func (s *service) GetSettings(ctx context.Context) (*entity.StockSessionSettingsDto, error) {
+	policy, err := s.repo.StaleSessionPolicy(ctx, shared.GetOrganization(ctx).ID)
+	if err != nil {
+		return nil, err
+	}
+	return &entity.StockSessionSettingsDto{StaleSessionPolicy: policy}, nil
+}
+
+func (s *service) UpdateSettings(
+	ctx context.Context,
+	dto *entity.StockSessionSettingsDto,
+) (*entity.StockSessionSettingsDto, error) {
+	if err := s.repo.SetStaleSessionPolicy(ctx, shared.GetOrganization(ctx).ID, dto.StaleSessionPolicy); err != nil {
+		return nil, err
+	}
+	return s.GetSettings(ctx)
+}
@@ -0,0 +1,114 @@
+package stocksession
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	"github.com/raymondsugiarto/coffee-api/pkg/model"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/testdb"
+)
+
+// closedPeriod refuses every date, as a closed accounting period
+// would.
+type closedPeriod struct{}
+
+func (closedPeriod) EnsureOpen(context.Context, string) error {
+	return errors.New("accounting period is closed")
+}
+
+func (closedPeriod) EnsureRangeOpen(context.Context, string, string) error {
+	return errors.New("accounting period is closed")
+}
+
+func TestResolveStale(t *testing.T) {
+	db := testdb.New(t,
+		&model.Organization{}, &model.Admin{}, &model.Item{},
+		&model.StockSession{}, &model.StockSessionItem{}, &model.PaymentDetail{},
+		&model.CashAdjustment{}, &model.SessionLog{},
+	)
+	day := func(d int) time.Time { return time.Date(2026, 10, d, 0, 0, 0, 0, time.UTC) }
+	review := &model.Organization{StaleSessionPolicy: entity.StaleSessionPolicyPendingReview}
+	autoClose := &model.Organization{StaleSessionPolicy: entity.StaleSessionPolicyAutoClose}
+	for _, m := range []*model.Organization{review, autoClose} {
+		if err := db.Create(m).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	session := func(org *model.Organization, date time.Time, status string) *model.StockSession {
+		m := &model.StockSession{OrganizationID: org.ID, EmployeeID: "driver", Date: date, OpenedAt: date, Status: status}
+		if err := db.Create(m).Error; err != nil {
+			t.Fatal(err)
+		}
+		return m
+	}
+	past := session(review, day(1), entity.StockSessionStatusOpen)
+	today := session(review, day(2), entity.StockSessionStatusOpen)
+	closed := session(review, day(1), entity.StockSessionStatusClosed)
+	auto := session(autoClose, day(1), entity.StockSessionStatusOpen)
+	s := &service{repo: NewRepository(db), db: db, periodGuard: closedPeriod{}}
+
+	jakarta, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		t.Skip(err)
+	}
+	// 20:00 on 2 Oct: the 1 Oct sessions are past the 23:59 cutoff,
+	// the 2 Oct one is not yet.
+	result, err := s.ResolveStale(context.Background(), "23:59", time.Date(2026, 10, 2, 20, 0, 0, 0, jakarta))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The AUTO_CLOSE session cannot close in a closed period, so it
+	// is flagged like the other one.
+	if result.Closed != 0 || result.Flagged != 2 || result.Failed != 0 {
+		t.Errorf("result = %+v, want 0 closed, 2 flagged, 0 failed", result)
+	}
+
+	want := map[*model.StockSession]string{
+		past:   entity.StockSessionStatusPendingReview,
+		today:  entity.StockSessionStatusOpen,
+		closed: entity.StockSessionStatusClosed,
+		auto:   entity.StockSessionStatusPendingReview,
+	}
+	for m, status := range want {
+		var got model.StockSession
+		if err := db.First(&got, "id = ?", m.ID).Error; err != nil {
+			t.Fatal(err)
+		}
+		if got.Status != status {
+			t.Errorf("session of %s status = %s, want %s", m.Date.Format("2006-01-02"), got.Status, status)
+		}
+	}
+
+	var logs []model.SessionLog
+	if err := db.Find(&logs).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 2 {
+		t.Fatalf("%d session logs, want 2", len(logs))
+	}
+	for _, l := range logs {
+		if l.Action != entity.SessionActionFlagForReview {
+			t.Errorf("session %s logged %s, want %s", l.SessionID, l.Action, entity.SessionActionFlagForReview)
+		}
+		failedClose := strings.Contains(l.Detail, "auto-close failed")
+		if l.SessionID == auto.ID && !failedClose {
+			t.Errorf("AUTO_CLOSE session log %q does not give the failed close as the reason", l.Detail)
+		}
+		if l.SessionID == past.ID && failedClose {
+			t.Errorf("PENDING_REVIEW session log %q gives a failed close as the reason", l.Detail)
+		}
+	}
+
+	// A second run finds nothing left to do.
+	result, err = s.ResolveStale(context.Background(), "23:59", time.Date(2026, 10, 2, 20, 0, 0, 0, jakarta))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Closed != 0 || result.Flagged != 0 || result.Failed != 0 {
+		t.Errorf("second run result = %+v, want nothing done", result)
+	}
+}
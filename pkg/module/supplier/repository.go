@@ -0,0 +1,107 @@
+package supplier
+
+import (
+	"context"
+	"errors"
+
+	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	"github.com/raymondsugiarto/coffee-api/pkg/model"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/pagination"
+	"gorm.io/gorm"
+)
+
+type Repository interface {
+	Create(ctx context.Context, dto *entity.SupplierDto) (*entity.SupplierDto, error)
+	Get(ctx context.Context, id string) (*entity.SupplierDto, error)
+	Update(ctx context.Context, dto *entity.SupplierDto) (*entity.SupplierDto, error)
+	// Delete refuses suppliers that purchase orders or goods
+	// receipts still point at (ErrSupplierInUse).
+	Delete(ctx context.Context, id string) error
+	FindAll(ctx context.Context, req *entity.SupplierFindAllRequest) (*pagination.ResultPagination, error)
+}
+
+// ErrSupplierInUse is returned by Delete when the supplier has
+// purchase documents.
+var ErrSupplierInUse = errors.New("supplier has purchase orders or goods receipts")
+
+type repository struct {
+	db *gorm.DB
+}
+
+func NewRepository(db *gorm.DB) Repository {
+	return &repository{db: db}
+}
+
+func (r *repository) Create(ctx context.Context, dto *entity.SupplierDto) (*entity.SupplierDto, error) {
+	m := dto.ToModel()
+	if err := r.db.WithContext(ctx).Create(m).Error; err != nil {
+		return nil, err
+	}
+	return entity.NewSupplierDtoFromModel(m), nil
+}
+
+func (r *repository) Get(ctx context.Context, id string) (*entity.SupplierDto, error) {
+	var m model.Supplier
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&m).Error; err != nil {
+		return nil, err
+	}
+	return entity.NewSupplierDtoFromModel(&m), nil
+}
+
+func (r *repository) Update(ctx context.Context, dto *entity.SupplierDto) (*entity.SupplierDto, error) {
+	m := dto.ToModel()
+	if err := r.db.WithContext(ctx).Model(m).
+		Select("name", "contact_name", "phone_number", "email", "address", "notes", "is_active").
+		Updates(m).Error; err != nil {
+		return nil, err
+	}
+	return r.Get(ctx, m.ID)
+}
+
+func (r *repository) Delete(ctx context.Context, id string) error {
+	for _, table := range []interface{}{&model.PurchaseOrder{}, &model.GoodsReceipt{}} {
+		var count int64
+		if err := r.db.WithContext(ctx).Model(table).
+			Where("supplier_id = ?", id).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrSupplierInUse
+		}
+	}
+	return r.db.WithContext(ctx).Where("id = ?", id).Delete(&model.Supplier{}).Error
+}
+
+func (r *repository) FindAll(
+	ctx context.Context,
+	req *entity.SupplierFindAllRequest,
+) (*pagination.ResultPagination, error) {
+	var rows []model.Supplier = make([]model.Supplier, 0)
+	tbl := pagination.NewTable(r.db)
+	dataTable, err := tbl.Pagination(func(i interface{}) *gorm.DB {
+		return r.db.WithContext(ctx).Model(&model.Supplier{}).
+			Where("organization_id = ?", req.FindAllRequest.OrganizationData.ID)
+	}, &pagination.TableRequest{
+		Request:       req,
+		QueryField:    []string{"name", "contact_name", "phone_number"},
+		Data:          &rows,
+		AllowedFields: []string{"name", "is_active", "created_at"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := dataTable.(*pagination.ResultPagination)
+	hits := result.Data.(*[]model.Supplier)
+	out := make([]*entity.SupplierDto, 0, len(*hits))
+	for i := range *hits {
+		out = append(out, entity.NewSupplierDtoFromModel(&(*hits)[i]))
+	}
+	return &pagination.ResultPagination{
+		Data:        out,
+		Page:        result.Page,
+		Count:       result.Count,
+		RowsPerPage: result.RowsPerPage,
+		TotalPages:  result.TotalPages,
+	}, nil
+}
@@ -0,0 +1,80 @@
+package supplier
+
+import (
+	"context"
+	"errors"
+
+	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	shared "github.com/raymondsugiarto/coffee-api/pkg/shared/context"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/pagination"
+	"github.com/raymondsugiarto/coffee-api/pkg/shared/response/status"
+	"gorm.io/gorm"
+)
+
+// Service manages the suppliers purchase orders and goods receipts
+// are raised against.
+type Service interface {
+	Create(ctx context.Context, dto *entity.SupplierDto) (*entity.SupplierDto, error)
+	// Get returns the supplier when it belongs to the caller's
+	// organization, EntityNotFound otherwise.
+	Get(ctx context.Context, id string) (*entity.SupplierDto, error)
+	Update(ctx context.Context, dto *entity.SupplierDto) (*entity.SupplierDto, error)
+	Delete(ctx context.Context, id string) error
+	FindAll(ctx context.Context, req *entity.SupplierFindAllRequest) (*pagination.ResultPagination, error)
+}
+
+type service struct {
+	repo Repository
+}
+
+func NewService(repo Repository) Service {
+	return &service{repo: repo}
+}
+
+func (s *service) Create(ctx context.Context, dto *entity.SupplierDto) (*entity.SupplierDto, error) {
+	dto.OrganizationID = shared.GetOrganization(ctx).ID
+	return s.repo.Create(ctx, dto)
+}
+
+func (s *service) Get(ctx context.Context, id string) (*entity.SupplierDto, error) {
+	supplier, err := s.repo.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, status.New(status.EntityNotFound, err)
+		}
+		return nil, err
+	}
+	if supplier.OrganizationID != shared.GetOrganization(ctx).ID {
+		return nil, status.New(status.EntityNotFound, errors.New("supplier not found"))
+	}
+	return supplier, nil
+}
+
+func (s *service) Update(ctx context.Context, dto *entity.SupplierDto) (*entity.SupplierDto, error) {
+	if _, err := s.Get(ctx, dto.ID); err != nil {
+		return nil, err
+	}
+	dto.OrganizationID = shared.GetOrganization(ctx).ID
+	return s.repo.Update(ctx, dto)
+}
+
+func (s *service) Delete(ctx context.Context, id string) error {
+	if _, err := s.Get(ctx, id); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		if errors.Is(err, ErrSupplierInUse) {
+			return status.New(status.EntityConflict, err)
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *service) FindAll(
+	ctx context.Context,
+	req *entity.SupplierFindAllRequest,
+) (*pagination.ResultPagination, error) {
+	req.FindAllRequest.OrganizationData.ID = shared.GetOrganization(ctx).ID
+	return s.repo.FindAll(ctx, req)
+}
@@ -0,0 +1,76 @@
+// Package testdb opens a throwaway database for repository and service
+// tests. It is only imported from _test.go files.
+package testdb
+
+import (
+	"context"
+	"database/sql"
+	"hash/fnv"
+	"sync"
+	"testing"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/raymondsugiarto/coffee-api/pkg/entity"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+const driverName = "sqlite3_testdb"
+
+var registerOnce sync.Once
+
+// register adds a SQLite driver with stand-ins for the postgres
+// functions the repositories call. A test runs on one connection,
+// so the advisory locks have nothing to serialise.
+func register() {
+	sql.Register(driverName, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			if err := conn.RegisterFunc("hashtext", func(s string) int64 {
+				h := fnv.New32a()
+				h.Write([]byte(s))
+				return int64(int32(h.Sum32()))
+			}, true); err != nil {
+				return err
+			}
+			return conn.RegisterFunc("pg_advisory_xact_lock", func(int64) string { return "" }, false)
+		},
+	})
+}
+
+// New opens an in-memory SQLite database with the app's naming
+// strategy and migrates the given models into it. Foreign keys are
+// left out so a test only migrates the tables it touches. The
+// database is closed when the test ends.
+func New(t testing.TB, models ...interface{}) *gorm.DB {
+	t.Helper()
+	registerOnce.Do(register)
+	db, err := gorm.Open(sqlite.New(sqlite.Config{DriverName: driverName, DSN: ":memory:"}), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+		NamingStrategy: schema.NamingStrategy{
+			SingularTable: true,
+		},
+		DisableForeignKeyConstraintWhenMigrating: true,
+	})
+	if err != nil {
+		t.Fatalf("open test database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("open test database: %v", err)
+	}
+	// One connection: every handle sees the same in-memory database.
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := db.AutoMigrate(models...); err != nil {
+		t.Fatalf("migrate test database: %v", err)
+	}
+	return db
+}
+
+// WithOrganization returns ctx as the organization middleware leaves
+// it for a request of organizationID.
+func WithOrganization(ctx context.Context, organizationID string) context.Context {
+	return context.WithValue(ctx, entity.OrganizationKey, &entity.OrganizationData{ID: organizationID})
+}